@@ -1,17 +1,31 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/naxumi/bnsp-jwd/internal/config"
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	tenantDomain "github.com/naxumi/bnsp-jwd/internal/domain/tenant"
 	appHTTP "github.com/naxumi/bnsp-jwd/internal/handler/http"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
 	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/imaging"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
 	"github.com/naxumi/bnsp-jwd/internal/pkg/storage"
 	"github.com/naxumi/bnsp-jwd/internal/repository/postgresql"
+	"github.com/naxumi/bnsp-jwd/internal/service/apikey"
+	"github.com/naxumi/bnsp-jwd/internal/service/audit"
+	"github.com/naxumi/bnsp-jwd/internal/service/category"
 	"github.com/naxumi/bnsp-jwd/internal/service/file"
+	"github.com/naxumi/bnsp-jwd/internal/service/inventory"
 	"github.com/naxumi/bnsp-jwd/internal/service/product"
+	"github.com/naxumi/bnsp-jwd/internal/service/user"
+	"github.com/naxumi/bnsp-jwd/internal/service/variant"
+	"github.com/naxumi/bnsp-jwd/internal/service/warehouse"
 )
 
 func main() {
@@ -28,9 +42,36 @@ func main() {
 		return
 	}
 
+	// Tenant isolation relies on row-level security, which some roles skip.
+	// The repositories do not filter by tenant themselves, so such a role
+	// would serve every tenant's rows to every tenant.
+	bypass, err := db.BypassesRowSecurity(context.Background())
+	if err != nil {
+		log.Fatalf("Error checking database role: %v", err)
+	}
+	if bypass {
+		if !cfg.Database.AllowRowSecurityBypass {
+			log.Fatalf("database user %q bypasses row-level security, so tenants would not be isolated; connect as a role without SUPERUSER and BYPASSRLS, or set DB_ALLOW_RLS_BYPASS=true", cfg.Database.User)
+		}
+		log.Printf("WARNING: database user %q bypasses row-level security and DB_ALLOW_RLS_BYPASS is set, tenants are NOT isolated", cfg.Database.User)
+	}
+
+	transactor := postgresql.NewTransactor(db)
 	productRepo := postgresql.NewProductRepository(db)
+	productRevisionRepo := postgresql.NewProductRevisionRepository(db)
+	productImageRepo := postgresql.NewProductImageRepository(db)
+	stockMovementRepo := postgresql.NewStockMovementRepository(db)
+	warehouseRepo := postgresql.NewWarehouseRepository(db)
+	categoryRepo := postgresql.NewCategoryRepository(db)
+	variantRepo := postgresql.NewVariantRepository(db)
+	auditRepo := postgresql.NewAuditRepository(db)
+	userRepo := postgresql.NewUserRepository(db)
+	refreshTokenRepo := postgresql.NewRefreshTokenRepository(db)
+	apiKeyRepo := postgresql.NewAPIKeyRepository(db)
+	tenantRepo := postgresql.NewTenantRepository(db)
 
 	var fileStorage storage.FileStorage
+	var uploadHandler appHTTP.UploadHandler // serves /uploads for local storage only
 	switch cfg.Storage.Type {
 	case "local":
 		fileStorage, err = storage.NewLocalStorage(
@@ -40,21 +81,90 @@ func main() {
 		if err != nil {
 			log.Fatal("Failed to initialize local storage:", err)
 		}
-	case "minio":
-		// Future: minIO implementation
-		log.Fatal("Minio storage not yet implemented")
+		uploadHandler = appHTTP.NewUploadHandler(fileStorage)
+	case "minio", "s3":
+		fileStorage, err = storage.NewS3Storage(storage.S3StorageOptions{
+			Endpoint:  cfg.Storage.Endpoint,
+			AccessKey: cfg.Storage.AccessKey,
+			SecretKey: cfg.Storage.SecretKey,
+			Bucket:    cfg.Storage.Bucket,
+			Region:    cfg.Storage.Region,
+			UseSSL:    cfg.Storage.UseSSL,
+			PathStyle: cfg.Storage.PathStyle,
+			BaseURL:   cfg.Storage.BaseURL,
+		})
+		if err != nil {
+			log.Fatal("Failed to initialize object storage:", err)
+		}
 	default:
 		log.Fatal("Unsupported storage types: ", cfg.Storage.Type)
 	}
 
-	fileService := file.NewFileService(fileStorage)
-	productService := product.NewProductService(db, productRepo, fileService)
+	// One image policy for the checks of the product service and the file service
+	imagePolicy := imaging.Policy{AllowedFormats: cfg.Image.AllowedTypes, MaxPixels: cfg.Image.MaxPixels}
+	imagePipeline := imaging.Pipeline{MaxDimension: cfg.Image.MaxDimension, WebP: cfg.Image.WebPVariants}
+	fileService := file.NewFileService(fileStorage, imagePolicy)
+	inventoryService := inventory.NewInventoryService(transactor, stockMovementRepo)
+	auditService := audit.NewAuditService(auditRepo)
+	productService := product.NewProductService(transactor, productRepo, productRevisionRepo, productImageRepo, categoryRepo, inventoryService, auditService, fileService, imagePolicy, imagePipeline, cfg.Trash.Retention)
+	// Handlers go through the permission checks, the purge job does not
+	authorizedProductService := product.NewAuthorizedProductService(productService)
+	warehouseService := warehouse.NewWarehouseService(transactor, warehouseRepo, inventoryService)
+	categoryService := category.NewCategoryService(transactor, categoryRepo)
+	variantService := variant.NewVariantService(transactor, variantRepo, productRepo, inventoryService)
+	authorizedVariantService := variant.NewAuthorizedVariantService(variantService)
+
+	if cfg.Trash.PurgeInterval > 0 {
+		go purgeTrash(productService, tenantRepo, cfg.Trash.PurgeInterval)
+	}
+
+	verifier, err := auth.NewVerifier(auth.VerifierOptions{
+		Secret:    cfg.Auth.JWTSecret,
+		JWKSFile:  cfg.Auth.JWKSFile,
+		Issuer:    cfg.Auth.JWTIssuer,
+		Audience:  cfg.Auth.JWTAudience,
+		ClockSkew: cfg.Auth.ClockSkew,
+
+		DefaultTenantID: cfg.Tenant.DefaultID,
+	})
+	if err != nil {
+		log.Fatal("Failed to initialize token verification: ", err)
+	}
+
+	signer, err := auth.NewSigner(auth.SignerOptions{
+		Secret:   cfg.Auth.JWTSecret,
+		KeyFile:  cfg.Auth.SigningKeyFile,
+		Issuer:   cfg.Auth.JWTIssuer,
+		Audience: cfg.Auth.JWTAudience,
+		TTL:      cfg.Auth.AccessTokenTTL,
+	})
+	if err != nil {
+		log.Fatal("Failed to initialize token signing: ", err)
+	}
+	userService := user.NewUserService(transactor, userRepo, refreshTokenRepo, signer, cfg.Auth.RefreshTokenTTL)
+	apiKeyService := apikey.NewAPIKeyService(apiKeyRepo)
 
-	productHandler := appHTTP.NewProductHandler(productService)
+	productHandler := appHTTP.NewProductHandler(authorizedProductService)
+	inventoryHandler := appHTTP.NewInventoryHandler(inventoryService)
+	warehouseHandler := appHTTP.NewWarehouseHandler(warehouseService)
+	categoryHandler := appHTTP.NewCategoryHandler(categoryService)
+	variantHandler := appHTTP.NewVariantHandler(authorizedVariantService)
+	auditHandler := appHTTP.NewAuditHandler(auditService)
+	authHandler := appHTTP.NewAuthHandler(userService)
+	apiKeyHandler := appHTTP.NewAPIKeyHandler(apiKeyService)
 
 	router := appHTTP.NewRouter(
 		productHandler,
-		cfg.Storage.BasePath,
+		inventoryHandler,
+		warehouseHandler,
+		categoryHandler,
+		variantHandler,
+		auditHandler,
+		authHandler,
+		apiKeyHandler,
+		appHTTP.Authenticate(verifier, apiKeyService),
+		cfg.Tenant.DefaultID,
+		uploadHandler,
 	)
 
 	port := fmt.Sprintf(":%d", cfg.App.Port)
@@ -63,3 +173,30 @@ func main() {
 		fmt.Println("Server error:", err)
 	}
 }
+
+// purgeTrash empties the expired part of every tenant's product trash every
+// interval. Row-level security limits each purge to one tenant's products.
+func purgeTrash(productService productDomain.ProductService, tenantRepo tenantDomain.TenantRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		tenants, err := tenantRepo.GetAll(context.Background())
+		if err != nil {
+			log.Printf("Error listing tenants for trash purge: %v", err)
+			continue
+		}
+
+		for _, t := range tenants {
+			ctx := requestctx.WithTenantID(context.Background(), t.ID)
+			result, err := productService.PurgeTrash(ctx)
+			if err != nil {
+				log.Printf("Error purging product trash of tenant %d: %v", t.ID, err)
+				continue
+			}
+			if result.Purged > 0 {
+				log.Printf("Purged %d products of tenant %d deleted before %s", result.Purged, t.ID, result.DeletedBefore)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Command createadmin creates a user, an admin unless -role says otherwise, so
+// a fresh install has someone to log in with. The password is read from
+// ADMIN_PASSWORD, or from the first line of standard input when that is unset:
+//
+//	go run ./cmd/createadmin -username admin
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/naxumi/bnsp-jwd/internal/config"
+	userDomain "github.com/naxumi/bnsp-jwd/internal/domain/user"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+	"github.com/naxumi/bnsp-jwd/internal/repository/postgresql"
+	"github.com/naxumi/bnsp-jwd/internal/service/user"
+)
+
+func main() {
+	username := flag.String("username", "admin", "username of the new user")
+	role := flag.String("role", auth.RoleAdmin, "role of the new user: admin, manager, staff or viewer")
+	tenant := flag.Int64("tenant", 1, "ID of the tenant the user belongs to")
+	flag.Parse()
+
+	password, err := readPassword()
+	if err != nil {
+		log.Fatal("Failed to read password: ", err)
+	}
+
+	req := userDomain.CreateUserRequest{
+		Username: *username,
+		Password: password,
+		Role:     *role,
+		TenantID: *tenant,
+	}
+	if err := req.Validate(); err != nil {
+		log.Fatal("Invalid user: ", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Error loading config: ", err)
+	}
+
+	db, err := database.NewPostgreSQLDB(cfg.DatabaseURL())
+	if err != nil {
+		log.Fatal("Error connecting to database: ", err)
+	}
+
+	// Only CreateUser is used, which needs neither a signer nor token lifetimes
+	userService := user.NewUserService(
+		postgresql.NewTransactor(db),
+		postgresql.NewUserRepository(db),
+		postgresql.NewRefreshTokenRepository(db),
+		nil,
+		0,
+	)
+
+	created, err := userService.CreateUser(context.Background(), req)
+	if err != nil {
+		log.Fatal("Failed to create user: ", err)
+	}
+
+	fmt.Printf("Created %s user %q with ID %d in tenant %d\n", created.Role, created.Username, created.ID, created.TenantID)
+}
+
+func readPassword() (string, error) {
+	if password := os.Getenv("ADMIN_PASSWORD"); password != "" {
+		return password, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
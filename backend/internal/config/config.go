@@ -3,16 +3,24 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/imaging"
 )
 
 type Config struct {
 	Database DatabaseConfig
 	App      AppConfig
 	Storage  StorageConfig
+	Trash    TrashConfig
+	Auth     AuthConfig
+	Tenant   TenantConfig
+	Image    ImageConfig
 }
 
 type DatabaseConfig struct {
@@ -22,6 +30,13 @@ type DatabaseConfig struct {
 	Password string
 	Name     string
 	SSLMode  string
+
+	// Text search configuration for product search, e.g. "simple" or "english"
+	SearchConfig string
+
+	// Start even when User skips row-level security, which leaves tenants
+	// unisolated; only for single-tenant deployments
+	AllowRowSecurityBypass bool
 }
 
 // AppConfig holds application configuration
@@ -33,15 +48,54 @@ type AppConfig struct {
 
 type StorageConfig struct {
 	Type     string // "local", "minio", "s3"
-	BasePath string // "./storage"
-	BaseURL  string // "http://localhost:8080/uploads"
+	BasePath string // "./storage", local only
+	BaseURL  string // "http://localhost:8080/uploads", public address of the files
 
-	// MinIO/S3 config (for future)
-	Endpoint  string
+	// MinIO/S3 config
+	Endpoint  string // host[:port] of the object store
 	AccessKey string
 	SecretKey string
 	Bucket    string
+	Region    string
 	UseSSL    bool
+	PathStyle bool // bucket in the URL path rather than the host name
+}
+
+// TrashConfig controls how long deleted products can be restored
+type TrashConfig struct {
+	Retention     time.Duration // age at which a deleted product is purged
+	PurgeInterval time.Duration // how often the purge runs, 0 disables it
+}
+
+// AuthConfig controls how Bearer tokens are issued and verified. Tokens are
+// signed with either a shared HMAC secret or a private key whose public half
+// is in the JWKS file.
+type AuthConfig struct {
+	JWTSecret      string
+	JWKSFile       string
+	SigningKeyFile string        // private JWK used to sign tokens in JWKS mode
+	JWTIssuer      string        // expected "iss", not checked when empty
+	JWTAudience    string        // expected "aud", not checked when empty
+	ClockSkew      time.Duration // leeway for "exp" and "nbf"
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// TenantConfig controls which tenant a request is scoped to when neither its
+// credentials nor an X-Tenant-ID header name one
+type TenantConfig struct {
+	DefaultID int64 // 0 requires every request to name its tenant
+}
+
+// ImageConfig controls which uploaded images are accepted. The formats are
+// checked against the content of the upload, not its file name, and only
+// limit uploads: the copies the image pipeline encodes are stored regardless.
+type ImageConfig struct {
+	AllowedTypes []string // "jpeg", "png", "gif" and/or "webp"
+	MaxPixels    int      // width times height, guards against decompression bombs
+	MaxDimension int      // longest side of a stored original, 0 keeps the size
+	WebPVariants bool     // also store the resized copies as WebP, needs the libwebp build
 }
 
 func Load() (*Config, error) {
@@ -58,13 +112,22 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
 	}
 
+	allowRowSecurityBypass, err := strconv.ParseBool(getEnv("DB_ALLOW_RLS_BYPASS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_ALLOW_RLS_BYPASS: %w", err)
+	}
+
 	config.Database = DatabaseConfig{
 		Host:     getEnv("DB_HOST", "localhost"),
 		Port:     dbPort,
-		User:     getEnv("DB_USER", "postgres"),
+		User:     getEnv("DB_USER", "product_api"),
 		Password: getEnv("DB_PASSWORD", ""),
 		Name:     getEnv("DB_NAME", "product_management"),
 		SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+
+		SearchConfig: getEnv("DB_SEARCH_CONFIG", "simple"),
+
+		AllowRowSecurityBypass: allowRowSecurityBypass,
 	}
 
 	// Application configuration
@@ -82,10 +145,101 @@ func Load() (*Config, error) {
 	storageType := getEnv("STORAGE_TYPE", "")
 	basePath := getEnv("BASE_PATH", "")
 	baseURL := getEnv("BASE_URL", "")
+	// MinIO serves buckets by path, AWS S3 by host name
+	useSSL, err := strconv.ParseBool(getEnv("S3_USE_SSL", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3_USE_SSL: %w", err)
+	}
+	pathStyle, err := strconv.ParseBool(getEnv("S3_PATH_STYLE", strconv.FormatBool(storageType == "minio")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3_PATH_STYLE: %w", err)
+	}
 	config.Storage = StorageConfig{
 		Type:     storageType,
 		BasePath: basePath,
 		BaseURL:  baseURL,
+
+		Endpoint:  getEnv("S3_ENDPOINT", ""),
+		AccessKey: getEnv("S3_ACCESS_KEY", ""),
+		SecretKey: getEnv("S3_SECRET_KEY", ""),
+		Bucket:    getEnv("S3_BUCKET", ""),
+		Region:    getEnv("S3_REGION", "us-east-1"),
+		UseSSL:    useSSL,
+		PathStyle: pathStyle,
+	}
+
+	// Trash configuration
+	retention, err := time.ParseDuration(getEnv("TRASH_RETENTION", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRASH_RETENTION: %w", err)
+	}
+	purgeInterval, err := time.ParseDuration(getEnv("TRASH_PURGE_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRASH_PURGE_INTERVAL: %w", err)
+	}
+	config.Trash = TrashConfig{
+		Retention:     retention,
+		PurgeInterval: purgeInterval,
+	}
+
+	// Auth configuration
+	clockSkew, err := time.ParseDuration(getEnv("JWT_CLOCK_SKEW", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_CLOCK_SKEW: %w", err)
+	}
+	accessTTL, err := time.ParseDuration(getEnv("JWT_ACCESS_TTL", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_ACCESS_TTL: %w", err)
+	}
+	refreshTTL, err := time.ParseDuration(getEnv("JWT_REFRESH_TTL", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_REFRESH_TTL: %w", err)
+	}
+	config.Auth = AuthConfig{
+		JWTSecret:      getEnv("JWT_SECRET", ""),
+		JWKSFile:       getEnv("JWT_JWKS_FILE", ""),
+		SigningKeyFile: getEnv("JWT_SIGNING_KEY_FILE", ""),
+		JWTIssuer:      getEnv("JWT_ISSUER", ""),
+		JWTAudience:    getEnv("JWT_AUDIENCE", ""),
+		ClockSkew:      clockSkew,
+
+		AccessTokenTTL:  accessTTL,
+		RefreshTokenTTL: refreshTTL,
+	}
+
+	// Tenant configuration
+	defaultTenantID, err := strconv.ParseInt(getEnv("DEFAULT_TENANT_ID", "1"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DEFAULT_TENANT_ID: %w", err)
+	}
+	config.Tenant = TenantConfig{
+		DefaultID: defaultTenantID,
+	}
+
+	// Image configuration
+	maxPixels, err := strconv.Atoi(getEnv("IMAGE_MAX_PIXELS", "40000000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IMAGE_MAX_PIXELS: %w", err)
+	}
+	maxDimension, err := strconv.Atoi(getEnv("IMAGE_MAX_DIMENSION", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IMAGE_MAX_DIMENSION: %w", err)
+	}
+	webpVariants, err := strconv.ParseBool(getEnv("IMAGE_WEBP_VARIANTS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IMAGE_WEBP_VARIANTS: %w", err)
+	}
+	allowedTypes := []string{}
+	for _, t := range strings.Split(getEnv("IMAGE_ALLOWED_TYPES", "jpeg,png,gif,webp"), ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			allowedTypes = append(allowedTypes, t)
+		}
+	}
+	config.Image = ImageConfig{
+		AllowedTypes: allowedTypes,
+		MaxPixels:    maxPixels,
+		MaxDimension: maxDimension,
+		WebPVariants: webpVariants,
 	}
 
 	// Validate required fields
@@ -101,27 +255,81 @@ func (c *Config) Validate() error {
 	if c.Database.Password == "" {
 		return fmt.Errorf("DB_PASSWORD is required")
 	}
-	if c.Storage.Type == "" {
+	switch c.Storage.Type {
+	case "":
 		return fmt.Errorf("STORAGE_TYPE is required")
+	case "local":
+		if c.Storage.BasePath == "" {
+			return fmt.Errorf("BASE_PATH is required")
+		}
+		if c.Storage.BaseURL == "" {
+			return fmt.Errorf("BASE_URL is required")
+		}
+	case "minio", "s3":
+		if c.Storage.Endpoint == "" || c.Storage.Bucket == "" {
+			return fmt.Errorf("S3_ENDPOINT and S3_BUCKET are required")
+		}
+		if c.Storage.AccessKey == "" || c.Storage.SecretKey == "" {
+			return fmt.Errorf("S3_ACCESS_KEY and S3_SECRET_KEY are required")
+		}
+	default:
+		return fmt.Errorf("STORAGE_TYPE must be local, minio or s3")
+	}
+	if c.Trash.Retention < 0 {
+		return fmt.Errorf("TRASH_RETENTION must not be negative")
+	}
+	if c.Trash.PurgeInterval < 0 {
+		return fmt.Errorf("TRASH_PURGE_INTERVAL must not be negative")
+	}
+	if c.Auth.JWTSecret == "" && c.Auth.JWKSFile == "" {
+		return fmt.Errorf("JWT_SECRET or JWT_JWKS_FILE is required")
+	}
+	if c.Auth.JWTSecret != "" && c.Auth.JWKSFile != "" {
+		return fmt.Errorf("set only one of JWT_SECRET and JWT_JWKS_FILE")
+	}
+	if c.Auth.JWKSFile != "" && c.Auth.SigningKeyFile == "" {
+		return fmt.Errorf("JWT_SIGNING_KEY_FILE is required with JWT_JWKS_FILE")
+	}
+	if c.Auth.ClockSkew < 0 {
+		return fmt.Errorf("JWT_CLOCK_SKEW must not be negative")
+	}
+	if c.Auth.AccessTokenTTL <= 0 {
+		return fmt.Errorf("JWT_ACCESS_TTL must be positive")
+	}
+	if c.Auth.RefreshTokenTTL <= 0 {
+		return fmt.Errorf("JWT_REFRESH_TTL must be positive")
+	}
+	if c.Tenant.DefaultID < 0 {
+		return fmt.Errorf("DEFAULT_TENANT_ID must not be negative")
+	}
+	for _, t := range c.Image.AllowedTypes {
+		if !imaging.Supported(t) {
+			return fmt.Errorf("IMAGE_ALLOWED_TYPES may only list jpeg, png, gif and webp, not %q", t)
+		}
+	}
+	if c.Image.WebPVariants && !imaging.WebPAvailable {
+		return fmt.Errorf("IMAGE_WEBP_VARIANTS needs a build with the libwebp tag")
 	}
-	if c.Storage.BasePath == "" {
-		return fmt.Errorf("BASE_PATH is required")
+	if c.Image.MaxPixels <= 0 {
+		return fmt.Errorf("IMAGE_MAX_PIXELS must be positive")
 	}
-	if c.Storage.BaseURL == "" {
-		return fmt.Errorf("BASE_URL is required")
+	if c.Image.MaxDimension < 0 {
+		return fmt.Errorf("IMAGE_MAX_DIMENSION must not be negative")
 	}
 	return nil
 }
 
-// DatabaseURL returns the PostgreSQL connection string
+// DatabaseURL returns the PostgreSQL connection string. The search
+// configuration is passed as a session setting read by product_search_config().
 func (c *Config) DatabaseURL() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s&app.search_config=%s",
 		c.Database.User,
 		c.Database.Password,
 		c.Database.Host,
 		c.Database.Port,
 		c.Database.Name,
 		c.Database.SSLMode,
+		url.QueryEscape(c.Database.SearchConfig),
 	)
 }
 
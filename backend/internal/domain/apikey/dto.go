@@ -0,0 +1,80 @@
+package apikey
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
+)
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+func (r *CreateAPIKeyRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Name
+	if validator.IsEmpty(r.Name) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "name",
+			Message: "name is required",
+		})
+	}
+	if len(r.Name) > 100 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "name",
+			Message: "name must not exceed 100 characters",
+		})
+	}
+
+	// Scopes
+	if len(r.Scopes) == 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "scopes",
+			Message: "at least one scope is required",
+		})
+	}
+	for _, scope := range r.Scopes {
+		if !slices.Contains(auth.KeyScopes, auth.Permission(scope)) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "scopes",
+				Message: "unknown scope " + scope + ", allowed: " + scopeList(),
+			})
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func scopeList() string {
+	scopes := make([]string, len(auth.KeyScopes))
+	for i, scope := range auth.KeyScopes {
+		scopes[i] = string(scope)
+	}
+	return strings.Join(scopes, ", ")
+}
+
+type APIKeyResponse struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  string   `json:"created_by"`
+	CreatedAt  string   `json:"created_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	RevokedAt  *string  `json:"revoked_at"`
+}
+
+// CreatedAPIKeyResponse is a new key with its secret, which is not stored
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,19 @@
+package apikey
+
+import "time"
+
+// APIKey lets a machine client authenticate without a login. Only a hash of
+// the key is stored; Prefix, the start of the key, is kept in clear so a key
+// can be recognised in listings.
+type APIKey struct {
+	ID         int64
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []string
+	TenantID   int64
+	CreatedBy  string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
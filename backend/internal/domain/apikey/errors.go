@@ -0,0 +1,9 @@
+package apikey
+
+import "errors"
+
+var (
+	// API Key Errors
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("invalid or revoked api key")
+)
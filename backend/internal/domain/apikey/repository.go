@@ -0,0 +1,13 @@
+package apikey
+
+import "context"
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key APIKey) (APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (APIKey, error)
+	// GetAll and Revoke only touch the keys of the tenant
+	GetAll(ctx context.Context, tenantID int64) ([]APIKey, error)
+	Revoke(ctx context.Context, tenantID, id int64) error
+	// TouchLastUsed records a use of the key, at most once a minute
+	TouchLastUsed(ctx context.Context, id int64) error
+}
@@ -0,0 +1,18 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+)
+
+type APIKeyService interface {
+	// Create a key; the response is the only time the key itself is shown
+	CreateKey(ctx context.Context, req CreateAPIKeyRequest) (CreatedAPIKeyResponse, error)
+	ListKeys(ctx context.Context) ([]APIKeyResponse, error)
+	RevokeKey(ctx context.Context, id int64) error
+
+	// Authenticate returns the claims of a valid, unrevoked key and records
+	// its use
+	Authenticate(ctx context.Context, key string) (auth.Claims, error)
+}
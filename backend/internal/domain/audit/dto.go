@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"strings"
+	"time"
+
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
+)
+
+// Entry is an audit record to be written; the actor and request ID are taken
+// from the context it is recorded with
+type Entry struct {
+	EntityType string
+	EntityID   int64
+	Action     Action
+	Changes    map[string]Change
+}
+
+// AuditRecordResponse represents one entry of the audit trail
+type AuditRecordResponse struct {
+	ID         int64             `json:"id"`
+	EntityType string            `json:"entity_type"`
+	EntityID   int64             `json:"entity_id"`
+	Action     Action            `json:"action"`
+	Actor      string            `json:"actor"`
+	RequestID  *string           `json:"request_id,omitempty"`
+	Changes    map[string]Change `json:"changes"`
+	CreatedAt  string            `json:"created_at"`
+}
+
+// ListAuditFilter represents the filter for the audit trail. From and To
+// bound created_at, both inclusive.
+type ListAuditFilter struct {
+	EntityType *string    `json:"entity_type,omitempty"`
+	EntityID   *int64     `json:"entity_id,omitempty"`
+	Actor      *string    `json:"actor,omitempty"`
+	Action     *Action    `json:"action,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+
+	// Pagination
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+func (f *ListAuditFilter) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Entity validation
+	if f.EntityID != nil && *f.EntityID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "entity_id",
+			Message: "entity_id must be a positive integer",
+		})
+	}
+
+	// Action validation
+	if f.Action != nil {
+		validActions := make([]string, len(Actions))
+		for i, action := range Actions {
+			validActions[i] = string(action)
+		}
+		if !validator.IsInSlice(string(*f.Action), validActions) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "action",
+				Message: "action must be one of: " + strings.Join(validActions, ", "),
+			})
+		}
+	}
+
+	// Time range validation
+	if f.From != nil && f.To != nil && f.From.After(*f.To) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "from",
+			Message: "from must not be after to",
+		})
+	}
+
+	// Page validation
+	if f.Page < 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "page",
+			Message: "page must be a positive number",
+		})
+	}
+	if f.Page == 0 {
+		f.Page = 1 // Default page
+	}
+
+	// Limit validation
+	if f.Limit < 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "limit",
+			Message: "limit must be a positive number",
+		})
+	}
+	if f.Limit == 0 {
+		f.Limit = 20 // Default limit
+	}
+	if f.Limit > 100 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "limit",
+			Message: "limit must not exceed 100",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// ListAuditResponse represents a page of the audit trail
+type ListAuditResponse struct {
+	TotalCount int64                 `json:"total_count"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	TotalPages int                   `json:"total_pages"`
+	Records    []AuditRecordResponse `json:"records"`
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"reflect"
+	"time"
+)
+
+type Action string
+
+const (
+	ActionCreate      Action = "create"
+	ActionUpdate      Action = "update"
+	ActionDelete      Action = "delete"
+	ActionRestore     Action = "restore"
+	ActionImageUpload Action = "image_upload"
+	ActionImageDelete Action = "image_delete"
+)
+
+// Actions lists every action an audit record can have
+var Actions = []Action{ActionCreate, ActionUpdate, ActionDelete, ActionRestore, ActionImageUpload, ActionImageDelete}
+
+// EntityProduct is the entity type of product audit records
+const EntityProduct = "product"
+
+// Record is one entry of the audit trail: who changed which fields of an
+// entity, and within which request. RequestID is nil for changes made
+// outside a request, such as a background job.
+type Record struct {
+	ID         int64
+	EntityType string
+	EntityID   int64
+	Action     Action
+	Actor      string
+	RequestID  *string
+	Changes    map[string]Change
+	CreatedAt  time.Time
+}
+
+// Change holds a field's value before and after a mutation. Before is nil for
+// a created entity and After is nil for a deleted one.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Diff returns the fields whose value differs between two snapshots of an
+// entity. A nil snapshot stands for the entity not existing, so every field
+// of the other snapshot is reported.
+func Diff(before, after map[string]any) map[string]Change {
+	changes := map[string]Change{}
+	for field, value := range before {
+		if other, ok := after[field]; !ok || !reflect.DeepEqual(value, other) {
+			changes[field] = Change{Before: value, After: other}
+		}
+	}
+	for field, value := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = Change{Before: nil, After: value}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,11 @@
+package audit
+
+import (
+	"context"
+)
+
+type AuditRepository interface {
+	// Create appends a record, within the caller's transaction when there is one
+	Create(ctx context.Context, record Record) (Record, error)
+	List(ctx context.Context, filter ListAuditFilter) ([]Record, int64, error)
+}
@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+)
+
+type AuditService interface {
+	// Record an entry for the actor and request ID carried by ctx. Called
+	// inside the mutation's transaction, so both commit or roll back together.
+	Record(ctx context.Context, entry Entry) error
+
+	// List the audit trail, newest first, with filters and pagination
+	ListRecords(ctx context.Context, filter ListAuditFilter) (ListAuditResponse, error)
+}
@@ -0,0 +1,150 @@
+package category
+
+import (
+	"regexp"
+
+	"github.com/naxumi/bnsp-jwd/internal/pkg/slug"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
+)
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ========================================
+// CATEGORY DTOs
+// ========================================
+
+// CreateCategoryRequest represents the request to create a new category. When
+// Slug is omitted it is derived from the name, prefixed with the parent's slug.
+type CreateCategoryRequest struct {
+	ParentID *int64  `json:"parent_id,omitempty"`
+	Name     string  `json:"name"`
+	Slug     *string `json:"slug,omitempty"`
+}
+
+func (r *CreateCategoryRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Parent ID
+	if r.ParentID != nil && *r.ParentID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "parent_id",
+			Message: "parent_id must be a positive integer",
+		})
+	}
+
+	// Name
+	if validator.IsEmpty(r.Name) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "name",
+			Message: "name is required",
+		})
+	}
+	if len(r.Name) > 100 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "name",
+			Message: "name must not exceed 100 characters",
+		})
+	}
+
+	// Slug
+	if r.Slug != nil {
+		errs = append(errs, validateSlug(*r.Slug)...)
+	} else if !validator.IsEmpty(r.Name) && slug.Make(r.Name) == "" {
+		errs = append(errs, validator.ValidationError{
+			Field:   "slug",
+			Message: "slug is required when the name has no letters or digits",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// UpdateCategoryRequest represents the request to update an existing category.
+// A ParentID of 0 moves the category to the top level. Renaming a category
+// keeps its slug unless a new one is given, so existing links keep working.
+type UpdateCategoryRequest struct {
+	ID       int64   `json:"id"`
+	ParentID *int64  `json:"parent_id,omitempty"`
+	Name     *string `json:"name,omitempty"`
+	Slug     *string `json:"slug,omitempty"`
+}
+
+func (r *UpdateCategoryRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// ID
+	if r.ID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "id",
+			Message: "id must be a positive integer",
+		})
+	}
+
+	// Parent ID
+	if r.ParentID != nil && *r.ParentID < 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "parent_id",
+			Message: "parent_id must be 0 (top level) or a positive integer",
+		})
+	}
+
+	// Name
+	if r.Name != nil {
+		if validator.IsEmpty(*r.Name) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "name",
+				Message: "name must not be empty",
+			})
+		}
+		if len(*r.Name) > 100 {
+			errs = append(errs, validator.ValidationError{
+				Field:   "name",
+				Message: "name must not exceed 100 characters",
+			})
+		}
+	}
+
+	// Slug
+	if r.Slug != nil {
+		errs = append(errs, validateSlug(*r.Slug)...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func validateSlug(s string) validator.ValidationErrors {
+	var errs validator.ValidationErrors
+	if !slugRegex.MatchString(s) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "slug",
+			Message: "slug must contain only lowercase letters, digits and single dashes",
+		})
+	}
+	if len(s) > 255 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "slug",
+			Message: "slug must not exceed 255 characters",
+		})
+	}
+	return errs
+}
+
+// CategoryResponse represents the response containing category data. Children
+// is only filled in when the categories are requested as a tree.
+type CategoryResponse struct {
+	ID        int64              `json:"id"`
+	ParentID  *int64             `json:"parent_id"`
+	Name      string             `json:"name"`
+	Slug      string             `json:"slug"`
+	CreatedAt string             `json:"created_at"`
+	UpdatedAt string             `json:"updated_at"`
+	Children  []CategoryResponse `json:"children,omitempty"`
+}
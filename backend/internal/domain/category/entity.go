@@ -0,0 +1,12 @@
+package category
+
+import "time"
+
+type Category struct {
+	ID        int64
+	ParentID  *int64
+	Name      string
+	Slug      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
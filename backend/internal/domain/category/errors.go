@@ -0,0 +1,13 @@
+package category
+
+import "errors"
+
+var (
+	// Category Errors
+	ErrCategoryNotFound   = errors.New("category not found")
+	ErrParentNotFound     = errors.New("parent category not found")
+	ErrCategorySlugExists = errors.New("category with this slug already exists")
+	ErrCategoryInUse      = errors.New("category still has products or subcategories")
+	ErrCategoryAmbiguous  = errors.New("category name matches more than one category")
+	ErrCategoryCycle      = errors.New("a category cannot be moved under itself or one of its descendants")
+)
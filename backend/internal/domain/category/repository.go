@@ -0,0 +1,19 @@
+package category
+
+import (
+	"context"
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category Category) (Category, error)
+	GetByID(ctx context.Context, id int64) (Category, error)
+	GetBySlug(ctx context.Context, slug string) (Category, error)
+	// GetByName returns every category with this name, ignoring case
+	GetByName(ctx context.Context, name string) ([]Category, error)
+	GetAll(ctx context.Context) ([]Category, error)
+	Update(ctx context.Context, category UpdateCategoryRequest) error
+	Delete(ctx context.Context, id int64) error
+
+	// IsDescendant reports whether candidateID is id itself or lies anywhere below it
+	IsDescendant(ctx context.Context, id int64, candidateID int64) (bool, error)
+}
@@ -0,0 +1,17 @@
+package category
+
+import (
+	"context"
+)
+
+type CategoryService interface {
+	// Category CRUD
+	CreateCategory(ctx context.Context, req CreateCategoryRequest) (CategoryResponse, error)
+	GetCategory(ctx context.Context, id int64) (CategoryResponse, error)
+	ListCategories(ctx context.Context) ([]CategoryResponse, error)
+	UpdateCategory(ctx context.Context, req UpdateCategoryRequest) error
+	DeleteCategory(ctx context.Context, id int64) error
+
+	// Categories nested under their parents, roots first
+	GetCategoryTree(ctx context.Context) ([]CategoryResponse, error)
+}
@@ -0,0 +1,199 @@
+package inventory
+
+import (
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
+)
+
+// ========================================
+// STOCK MOVEMENT DTOs
+// ========================================
+
+// CreateStockMovementRequest represents the request to post a stock movement.
+// Receipts and issues take a positive quantity, the sign is derived from the
+// type. Adjustments and corrections take the signed delta directly. Without a
+// warehouse the movement is booked in the default warehouse.
+type CreateStockMovementRequest struct {
+	ProductID   int64        `json:"-"`
+	WarehouseID *int64       `json:"warehouse_id,omitempty"`
+	Type        MovementType `json:"type"`
+	ReasonCode  string       `json:"reason_code"`
+	Quantity    int          `json:"quantity"`
+	Reference   *string      `json:"reference,omitempty"`
+	Note        *string      `json:"note,omitempty"`
+}
+
+func (r *CreateStockMovementRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Product ID
+	if r.ProductID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "product_id",
+			Message: "product_id must be a positive integer",
+		})
+	}
+
+	// Warehouse ID
+	if r.WarehouseID != nil && *r.WarehouseID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "warehouse_id",
+			Message: "warehouse_id must be a positive integer",
+		})
+	}
+
+	// Type
+	switch r.Type {
+	case MovementTypeReceipt, MovementTypeIssue:
+		if r.Quantity <= 0 {
+			errs = append(errs, validator.ValidationError{
+				Field:   "quantity",
+				Message: "quantity must be greater than 0 for receipts and issues",
+			})
+		}
+	case MovementTypeAdjustment, MovementTypeCorrection:
+		if r.Quantity == 0 {
+			errs = append(errs, validator.ValidationError{
+				Field:   "quantity",
+				Message: "quantity must not be 0",
+			})
+		}
+	default:
+		errs = append(errs, validator.ValidationError{
+			Field:   "type",
+			Message: "type must be one of: Receipt, Issue, Adjustment, Correction",
+		})
+	}
+
+	// Reason Code
+	if validator.IsEmpty(r.ReasonCode) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "reason_code",
+			Message: "reason_code is required",
+		})
+	}
+	if len(r.ReasonCode) > 50 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "reason_code",
+			Message: "reason_code must not exceed 50 characters",
+		})
+	}
+
+	// Reference
+	if r.Reference != nil && len(*r.Reference) > 255 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "reference",
+			Message: "reference must not exceed 255 characters",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Delta returns the signed quantity the movement applies to the stock
+func (r *CreateStockMovementRequest) Delta() int {
+	if r.Type == MovementTypeIssue {
+		return -r.Quantity
+	}
+	return r.Quantity
+}
+
+// StockMovementResponse represents the response containing a ledger entry
+type StockMovementResponse struct {
+	ID           int64        `json:"id"`
+	ProductID    int64        `json:"product_id"`
+	WarehouseID  *int64       `json:"warehouse_id,omitempty"`
+	Type         MovementType `json:"type"`
+	ReasonCode   string       `json:"reason_code"`
+	Quantity     int          `json:"quantity"`
+	BalanceAfter int          `json:"balance_after"`
+	Reference    *string      `json:"reference,omitempty"`
+	Note         *string      `json:"note,omitempty"`
+	Actor        string       `json:"actor"`
+	CreatedAt    string       `json:"created_at"`
+}
+
+// ListStockMovementFilter represents the filter for a product's movement history
+type ListStockMovementFilter struct {
+	ProductID   int64         `json:"product_id"`
+	WarehouseID *int64        `json:"warehouse_id,omitempty"`
+	Type        *MovementType `json:"type,omitempty"`
+
+	// Pagination
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+func (f *ListStockMovementFilter) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Product ID
+	if f.ProductID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "product_id",
+			Message: "product_id must be a positive integer",
+		})
+	}
+
+	// Type validation
+	if f.Type != nil {
+		validTypes := []string{
+			string(MovementTypeReceipt),
+			string(MovementTypeIssue),
+			string(MovementTypeAdjustment),
+			string(MovementTypeCorrection),
+		}
+		if !validator.IsInSlice(string(*f.Type), validTypes) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "type",
+				Message: "type must be one of: Receipt, Issue, Adjustment, Correction",
+			})
+		}
+	}
+
+	// Page validation
+	if f.Page < 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "page",
+			Message: "page must be a positive number",
+		})
+	}
+	if f.Page == 0 {
+		f.Page = 1 // Default page
+	}
+
+	// Limit validation
+	if f.Limit < 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "limit",
+			Message: "limit must be a positive number",
+		})
+	}
+	if f.Limit == 0 {
+		f.Limit = 20 // Default limit
+	}
+	if f.Limit > 100 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "limit",
+			Message: "limit must not exceed 100",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// ListStockMovementResponse represents the paginated movement history of a product
+type ListStockMovementResponse struct {
+	TotalCount int64                   `json:"total_count"`
+	Page       int                     `json:"page"`
+	Limit      int                     `json:"limit"`
+	TotalPages int                     `json:"total_pages"`
+	Movements  []StockMovementResponse `json:"movements"`
+}
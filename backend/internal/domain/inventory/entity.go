@@ -0,0 +1,44 @@
+package inventory
+
+import "time"
+
+type MovementType string
+
+const (
+	MovementTypeReceipt    MovementType = "Receipt"
+	MovementTypeIssue      MovementType = "Issue"
+	MovementTypeAdjustment MovementType = "Adjustment"
+	MovementTypeCorrection MovementType = "Correction"
+)
+
+// Reason codes used by movements the system records on its own
+const (
+	ReasonOpeningBalance = "OPENING_BALANCE"
+	ReasonManualEdit     = "MANUAL_EDIT"
+	ReasonTransferOut    = "TRANSFER_OUT"
+	ReasonTransferIn     = "TRANSFER_IN"
+)
+
+// WarehouseQuantity is the stock of a product held in one warehouse
+type WarehouseQuantity struct {
+	WarehouseID int64
+	Quantity    int
+}
+
+// StockMovement is a single ledger entry. Quantity is the signed delta applied
+// to the product's stock in one warehouse and BalanceAfter is the product's
+// total stock once it was applied. WarehouseID is nil when the warehouse has
+// since been deleted.
+type StockMovement struct {
+	ID           int64
+	ProductID    int64
+	WarehouseID  *int64
+	Type         MovementType
+	ReasonCode   string
+	Quantity     int
+	BalanceAfter int
+	Reference    *string
+	Note         *string
+	Actor        string
+	CreatedAt    time.Time
+}
@@ -0,0 +1,9 @@
+package inventory
+
+import "errors"
+
+var (
+	// Stock Movement Errors
+	ErrInsufficientStock   = errors.New("insufficient stock for this movement")
+	ErrInvalidMovementType = errors.New("invalid stock movement type")
+)
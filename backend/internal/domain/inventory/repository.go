@@ -0,0 +1,24 @@
+package inventory
+
+import (
+	"context"
+)
+
+type StockMovementRepository interface {
+	// GetDefaultWarehouseID returns the warehouse used when a movement names none
+	GetDefaultWarehouseID(ctx context.Context) (int64, error)
+
+	// GetStockForUpdate returns the product's stock and locks its row until the transaction ends
+	GetStockForUpdate(ctx context.Context, productID int64) (int, error)
+
+	// ListWarehouseStock returns the warehouses holding the product's stock,
+	// the default warehouse first, then by quantity from high to low
+	ListWarehouseStock(ctx context.Context, productID int64) ([]WarehouseQuantity, error)
+
+	// ApplyStockDelta adds delta to the product's stock in the warehouse and
+	// returns the product's new total stock
+	ApplyStockDelta(ctx context.Context, productID int64, warehouseID int64, delta int) (int, error)
+
+	Create(ctx context.Context, movement StockMovement) (StockMovement, error)
+	ListByProduct(ctx context.Context, filter ListStockMovementFilter) ([]StockMovement, int64, error)
+}
@@ -0,0 +1,16 @@
+package inventory
+
+import (
+	"context"
+)
+
+type InventoryService interface {
+	// Post a movement to the ledger and update the product's stock
+	PostMovement(ctx context.Context, req CreateStockMovementRequest) (StockMovementResponse, error)
+
+	// Bring the product's stock to target by posting a correction for the difference
+	CorrectStock(ctx context.Context, productID int64, target int) error
+
+	// List a product's movement history with pagination
+	ListMovements(ctx context.Context, filter ListStockMovementFilter) (ListStockMovementResponse, error)
+}
@@ -0,0 +1,44 @@
+package product
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Cursor is a position in a keyset-paginated product list: the sort column
+// value and ID of the row it points past. Clients only see it encoded, as an
+// opaque string.
+type Cursor struct {
+	SortBy    string `json:"s"`
+	SortOrder string `json:"o"`
+	Value     string `json:"v"`
+	ID        int64  `json:"i"`
+
+	// Backward cursors return the rows before the position instead of after it
+	Backward bool `json:"b,omitempty"`
+}
+
+// Encode returns the cursor as an URL-safe string
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a string returned by Cursor.Encode
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, err
+	}
+	if c.SortBy == "" || c.SortOrder == "" || c.ID <= 0 {
+		return Cursor{}, errors.New("incomplete cursor")
+	}
+
+	return c, nil
+}
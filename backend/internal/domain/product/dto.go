@@ -1,6 +1,11 @@
 package product
 
 import (
+	"io"
+	"mime/multipart"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
 	"github.com/shopspring/decimal"
 )
@@ -9,14 +14,18 @@ import (
 // PRODUCT DTOs
 // ========================================
 
-// CreateProductRequest represents the request to create a new product
+// CreateProductRequest represents the request to create a new product. The
+// category is given by ID, or by name for older clients; a name is matched
+// against existing category names, or a path such as "Tools/Power Tools", and
+// never creates a new category.
 type CreateProductRequest struct {
 	SKU         string          `json:"sku"`
 	Name        string          `json:"name"`
 	Description *string         `json:"description,omitempty"`
 	Price       decimal.Decimal `json:"price"`
 	Stock       int             `json:"stock"`
-	Category    string          `json:"category"`
+	CategoryID  *int64          `json:"category_id,omitempty"`
+	Category    string          `json:"category,omitempty"`
 	Status      ProductStatus   `json:"status"`
 }
 
@@ -70,10 +79,16 @@ func (r *CreateProductRequest) Validate() error {
 	}
 
 	// Category
-	if validator.IsEmpty(r.Category) {
+	if r.CategoryID == nil && validator.IsEmpty(r.Category) {
 		errs = append(errs, validator.ValidationError{
-			Field:   "category",
-			Message: "category is required",
+			Field:   "category_id",
+			Message: "category_id is required",
+		})
+	}
+	if r.CategoryID != nil && *r.CategoryID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "category_id",
+			Message: "category_id must be a positive integer",
 		})
 	}
 	if len(r.Category) > 100 {
@@ -106,9 +121,17 @@ type UpdateProductRequest struct {
 	Description *string          `json:"description,omitempty"`
 	Price       *decimal.Decimal `json:"price,omitempty"`
 	Stock       *int             `json:"stock,omitempty"`
+	CategoryID  *int64           `json:"category_id,omitempty"`
 	Category    *string          `json:"category,omitempty"`
 	Status      *ProductStatus   `json:"status,omitempty"`
 	ImageURL    *string          `json:"image_url,omitempty"`
+
+	// Replaces the resized copies of the image, set by the image upload
+	ImageVariants *[]ImageVariant `json:"-"`
+
+	// Version the client last saw, from If-Match; the update fails with
+	// ErrVersionConflict when the product has changed since
+	ExpectedVersion *int `json:"-"`
 }
 
 func (r *UpdateProductRequest) Validate() error {
@@ -175,6 +198,12 @@ func (r *UpdateProductRequest) Validate() error {
 	}
 
 	// Category
+	if r.CategoryID != nil && *r.CategoryID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "category_id",
+			Message: "category_id must be a positive integer",
+		})
+	}
 	if r.Category != nil {
 		if validator.IsEmpty(*r.Category) {
 			errs = append(errs, validator.ValidationError{
@@ -213,38 +242,118 @@ func (r *UpdateProductRequest) Validate() error {
 	return nil
 }
 
-// ProductResponse represents the response containing product data
+// ProductResponse represents the response containing product data. Stock is
+// the total across all warehouses, StockByWarehouse breaks it down per location.
 type ProductResponse struct {
-	ID          int64           `json:"id"`
-	SKU         string          `json:"sku"`
-	Name        string          `json:"name"`
-	Description *string         `json:"description,omitempty"`
-	Price       decimal.Decimal `json:"price"`
-	Stock       int             `json:"stock"`
-	Category    string          `json:"category"`
-	Status      ProductStatus   `json:"status"`
-	ImageURL    *string         `json:"image_url,omitempty"`
-	CreatedAt   string          `json:"created_at"`
-	UpdatedAt   string          `json:"updated_at"`
+	ID               int64                    `json:"id"`
+	SKU              string                   `json:"sku"`
+	Name             string                   `json:"name"`
+	Description      *string                  `json:"description,omitempty"`
+	Price            decimal.Decimal          `json:"price"`
+	Stock            int                      `json:"stock"`
+	StockByWarehouse []WarehouseStockResponse `json:"stock_by_warehouse"`
+	CategoryID       int64                    `json:"category_id"`
+	Category         string                   `json:"category"`
+	Status           ProductStatus            `json:"status"`
+	ImageURL         *string                  `json:"image_url,omitempty"`
+	Images           map[string]ImageResponse `json:"images,omitempty"`
+	Gallery          []ProductImageResponse   `json:"gallery"`
+	Version          int                      `json:"version"`
+	CreatedAt        string                   `json:"created_at"`
+	UpdatedAt        string                   `json:"updated_at"`
+	DeletedAt        *string                  `json:"deleted_at,omitempty"`
+
+	// Set by a SKU lookup that matched one of the product's variants
+	MatchedVariantID *int64 `json:"matched_variant_id,omitempty"`
+
+	// Set when listing with a full-text query
+	Highlight *SearchHighlight `json:"highlight,omitempty"`
+}
+
+// ImageResponse is one size of the product image. Images holds the uploaded
+// original and the thumb, card and detail sizes it is wider than, and with
+// IMAGE_WEBP_VARIANTS their WebP copies thumb_webp, card_webp and detail_webp.
+type ImageResponse struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"`
+}
+
+// SearchHighlight holds snippets of the fields that matched a full-text query,
+// with the matching words wrapped in <mark></mark>
+type SearchHighlight struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+// WarehouseStockResponse represents the stock of a product in one warehouse
+type WarehouseStockResponse struct {
+	WarehouseID   int64  `json:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code"`
+	WarehouseName string `json:"warehouse_name"`
+	Quantity      int    `json:"quantity"`
 }
 
 // ListProductFilter represents the filter for listing products
 type ListProductFilter struct {
-	// Search & Filter
+	// Full-text search over name, SKU and description
+	Q *string `json:"q,omitempty"`
+
+	// Search & Filter. With Fuzzy, name and sku also match misspellings
 	Name     *string        `json:"name,omitempty"`
 	SKU      *string        `json:"sku,omitempty"`
-	Category *string        `json:"category,omitempty"`
+	Fuzzy    bool           `json:"fuzzy,omitempty"`
 	Status   *ProductStatus `json:"status,omitempty"`
 	MinPrice *float64       `json:"min_price,omitempty"`
 	MaxPrice *float64       `json:"max_price,omitempty"`
 
+	// Category: exact match by slug or ID, optionally including every subcategory
+	Category           *string `json:"category,omitempty"`
+	CategoryID         *int64  `json:"category_id,omitempty"`
+	IncludeDescendants bool    `json:"include_descendants,omitempty"`
+
+	// Warehouse: products stocked at a location, or with quantity > 0 there
+	WarehouseID *int64 `json:"warehouse_id,omitempty"`
+	InStockAt   *int64 `json:"in_stock_at,omitempty"`
+
 	// Pagination
 	Page  int `json:"page"`
 	Limit int `json:"limit"`
 
+	// Keyset pagination: Cursor is a next_cursor/prev_cursor from an earlier
+	// response and replaces Page. Validate decodes it into Position.
+	Cursor   *string `json:"cursor,omitempty"`
+	Position *Cursor `json:"-"`
+
+	// SkipCount leaves out the total count (count=false)
+	SkipCount bool `json:"skip_count,omitempty"`
+
+	// Trashed lists the deleted products instead of the live ones
+	Trashed bool `json:"-"`
+
 	// Sorting
 	SortBy    string `json:"sort_by"`
 	SortOrder string `json:"sort_order"`
+
+	// Facets to count for the filtered products: category, status, price_range.
+	// PriceRanges are the ascending band boundaries for the price_range facet.
+	Facets      []string          `json:"facets,omitempty"`
+	PriceRanges []decimal.Decimal `json:"price_ranges,omitempty"`
+}
+
+// DefaultPriceRanges are the price_range facet boundaries used when the
+// request does not give its own
+var DefaultPriceRanges = []decimal.Decimal{
+	decimal.NewFromInt(100000),
+	decimal.NewFromInt(500000),
+	decimal.NewFromInt(1000000),
+	decimal.NewFromInt(5000000),
+}
+
+// HasFacet reports whether the named facet was requested
+func (f *ListProductFilter) HasFacet(name string) bool {
+	return validator.IsInSlice(name, f.Facets)
 }
 
 func (f *ListProductFilter) Validate() error {
@@ -298,15 +407,77 @@ func (f *ListProductFilter) Validate() error {
 		})
 	}
 
+	// Category validation
+	if f.CategoryID != nil && *f.CategoryID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "category_id",
+			Message: "category_id must be a positive integer",
+		})
+	}
+
+	// Warehouse validation
+	if f.WarehouseID != nil && *f.WarehouseID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "warehouse_id",
+			Message: "warehouse_id must be a positive integer",
+		})
+	}
+	if f.InStockAt != nil && *f.InStockAt <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "in_stock_at",
+			Message: "in_stock_at must be a positive integer",
+		})
+	}
+
+	// Search validation
+	if f.Q != nil {
+		if validator.IsEmpty(*f.Q) {
+			f.Q = nil
+		} else if len(*f.Q) > 200 {
+			errs = append(errs, validator.ValidationError{
+				Field:   "q",
+				Message: "q must not exceed 200 characters",
+			})
+		}
+	}
+
+	// Cursor validation, the cursor carries the sort it was created with
+	if f.Cursor != nil {
+		position, err := DecodeCursor(*f.Cursor)
+		if err != nil {
+			errs = append(errs, validator.ValidationError{
+				Field:   "cursor",
+				Message: "cursor is invalid",
+			})
+		} else {
+			f.Position = &position
+			f.SortBy = position.SortBy
+			f.SortOrder = position.SortOrder
+		}
+	}
+
 	// Sort validation
 	if f.SortBy != "" {
-		validSortFields := []string{"id", "sku", "name", "price", "stock", "category", "status", "created_at", "updated_at"}
+		validSortFields := []string{"id", "sku", "name", "price", "stock", "category", "status", "created_at", "updated_at", "relevance"}
+		if f.Trashed {
+			validSortFields = append(validSortFields, "deleted_at")
+		}
 		if !validator.IsInSlice(f.SortBy, validSortFields) {
 			errs = append(errs, validator.ValidationError{
 				Field:   "sort_by",
-				Message: "sort_by must be one of: id, sku, name, price, stock, category, status, created_at, updated_at",
+				Message: "sort_by must be one of: " + strings.Join(validSortFields, ", "),
+			})
+		}
+		if f.SortBy == "relevance" && f.Q == nil {
+			errs = append(errs, validator.ValidationError{
+				Field:   "sort_by",
+				Message: "sort_by=relevance requires q",
 			})
 		}
+	} else if f.Q != nil {
+		f.SortBy = "relevance" // Best matches first when searching
+	} else if f.Trashed {
+		f.SortBy = "deleted_at" // Most recently deleted first
 	} else {
 		f.SortBy = "created_at" // Default sort
 	}
@@ -331,6 +502,36 @@ func (f *ListProductFilter) Validate() error {
 		})
 	}
 
+	// Facet validation
+	validFacets := []string{"category", "status", "price_range"}
+	for _, facet := range f.Facets {
+		if !validator.IsInSlice(facet, validFacets) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "facets",
+				Message: "facets must be a comma-separated list of: category, status, price_range",
+			})
+			break
+		}
+	}
+	if len(f.PriceRanges) > 20 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "price_ranges",
+			Message: "price_ranges must not have more than 20 boundaries",
+		})
+	}
+	for i, boundary := range f.PriceRanges {
+		if boundary.LessThan(decimal.Zero) || (i > 0 && !boundary.GreaterThan(f.PriceRanges[i-1])) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "price_ranges",
+				Message: "price_ranges must be non-negative and strictly ascending",
+			})
+			break
+		}
+	}
+	if f.HasFacet("price_range") && len(f.PriceRanges) == 0 {
+		f.PriceRanges = DefaultPriceRanges
+	}
+
 	if len(errs) > 0 {
 		return errs
 	}
@@ -338,12 +539,587 @@ func (f *ListProductFilter) Validate() error {
 	return nil
 }
 
-// ListProductResponse represents the paginated response for listing products
+// ListProductResponse represents the paginated response for listing products.
+// The totals are omitted when the request skipped counting with count=false.
 type ListProductResponse struct {
-	TotalCount int64             `json:"total_count"`
+	TotalCount *int64            `json:"total_count,omitempty"`
 	Page       int               `json:"page"`
 	Limit      int               `json:"limit"`
-	TotalPages int               `json:"total_pages"`
+	TotalPages *int              `json:"total_pages,omitempty"`
 	Showing    string            `json:"showing"`
 	Products   []ProductResponse `json:"products"`
+
+	// Opaque cursors for the pages after and before this one
+	NextCursor *string `json:"next_cursor,omitempty"`
+	PrevCursor *string `json:"prev_cursor,omitempty"`
+
+	// Set when the request asked for facets
+	Facets *FacetsResponse `json:"facets,omitempty"`
+}
+
+// FacetsResponse holds the requested facet counts for the whole filtered
+// result, not just the current page
+type FacetsResponse struct {
+	Category   []CategoryFacetResponse   `json:"category,omitempty"`
+	Status     []StatusFacetResponse     `json:"status,omitempty"`
+	PriceRange []PriceRangeFacetResponse `json:"price_range,omitempty"`
+}
+
+type CategoryFacetResponse struct {
+	CategoryID int64  `json:"category_id"`
+	Category   string `json:"category"`
+	Count      int64  `json:"count"`
+}
+
+type StatusFacetResponse struct {
+	Status ProductStatus `json:"status"`
+	Count  int64         `json:"count"`
+}
+
+// PriceRangeFacetResponse counts products with min <= price < max; min is
+// omitted for the lowest band and max for the highest
+type PriceRangeFacetResponse struct {
+	Min   *decimal.Decimal `json:"min,omitempty"`
+	Max   *decimal.Decimal `json:"max,omitempty"`
+	Count int64            `json:"count"`
+}
+
+// SuggestProductFilter represents an autocomplete query
+type SuggestProductFilter struct {
+	Q     string `json:"q"`
+	Limit int    `json:"limit"`
+}
+
+// Suggestions below this word similarity are dropped. It is lower than the
+// pg_trgm default of 0.6 so that one or two swapped letters still match.
+const SuggestSimilarityThreshold = 0.3
+
+func (f *SuggestProductFilter) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Q validation
+	f.Q = strings.TrimSpace(f.Q)
+	if f.Q == "" {
+		errs = append(errs, validator.ValidationError{
+			Field:   "q",
+			Message: "q is required",
+		})
+	}
+	if len(f.Q) > 100 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "q",
+			Message: "q must not exceed 100 characters",
+		})
+	}
+
+	// Limit validation
+	if f.Limit < 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "limit",
+			Message: "limit must be a positive number",
+		})
+	}
+	if f.Limit == 0 {
+		f.Limit = 10 // Default limit
+	}
+	if f.Limit > 25 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "limit",
+			Message: "limit must not exceed 25",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// ProductSuggestionResponse represents one autocomplete match
+type ProductSuggestionResponse struct {
+	ID    int64   `json:"id"`
+	SKU   string  `json:"sku"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// ========================================
+// BULK DTOs
+// ========================================
+
+// MaxBulkOperations caps the number of operations in one bulk request
+const MaxBulkOperations = 500
+
+type BulkMode string
+
+const (
+	// BulkModeAtomic applies every operation or none of them
+	BulkModeAtomic BulkMode = "atomic"
+	// BulkModeBestEffort applies each operation on its own and reports the failures
+	BulkModeBestEffort BulkMode = "best_effort"
+)
+
+// BulkProductRequest represents a batch of product operations
+type BulkProductRequest struct {
+	Mode       BulkMode        `json:"mode"`
+	Operations []BulkOperation `json:"operations"`
+}
+
+func (r *BulkProductRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Mode
+	if r.Mode == "" {
+		r.Mode = BulkModeAtomic // Default mode
+	}
+	if r.Mode != BulkModeAtomic && r.Mode != BulkModeBestEffort {
+		errs = append(errs, validator.ValidationError{
+			Field:   "mode",
+			Message: "mode must be either 'atomic' or 'best_effort'",
+		})
+	}
+
+	// Operations, validated one by one by the service so each gets its own result
+	if len(r.Operations) == 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "operations",
+			Message: "operations must not be empty",
+		})
+	}
+	if len(r.Operations) > MaxBulkOperations {
+		errs = append(errs, validator.ValidationError{
+			Field:   "operations",
+			Message: "operations must not exceed 500 items",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// BulkOperation is one item of a bulk request; exactly one of Create, Update
+// or Delete is set
+type BulkOperation struct {
+	Create *CreateProductRequest `json:"create,omitempty"`
+	Update *UpdateProductRequest `json:"update,omitempty"`
+	Delete *BulkDeleteRequest    `json:"delete,omitempty"`
+}
+
+// BulkDeleteRequest names the product a bulk operation deletes
+type BulkDeleteRequest struct {
+	ID int64 `json:"id"`
+}
+
+// Op returns the operation's kind: create, update or delete
+func (o *BulkOperation) Op() string {
+	switch {
+	case o.Create != nil:
+		return "create"
+	case o.Update != nil:
+		return "update"
+	case o.Delete != nil:
+		return "delete"
+	}
+	return ""
+}
+
+func (o *BulkOperation) Validate() error {
+	set := 0
+	for _, isSet := range []bool{o.Create != nil, o.Update != nil, o.Delete != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return validator.ValidationErrors{{
+			Field:   "operation",
+			Message: "operation must have exactly one of create, update or delete",
+		}}
+	}
+
+	switch {
+	case o.Create != nil:
+		return o.Create.Validate()
+	case o.Update != nil:
+		return o.Update.Validate()
+	}
+
+	if o.Delete.ID <= 0 {
+		return validator.ValidationErrors{{
+			Field:   "id",
+			Message: "id must be a positive integer",
+		}}
+	}
+	return nil
+}
+
+type BulkStatus string
+
+const (
+	BulkStatusSucceeded  BulkStatus = "succeeded"
+	BulkStatusFailed     BulkStatus = "failed"
+	BulkStatusRolledBack BulkStatus = "rolled_back" // applied, then undone by a later failure
+	BulkStatusSkipped    BulkStatus = "skipped"     // not attempted because the batch failed
+)
+
+// BulkOperationResult reports the outcome of one operation, by its index in
+// the request. ID is the created, updated or deleted product.
+type BulkOperationResult struct {
+	Index     int               `json:"index"`
+	Op        string            `json:"op"`
+	Status    BulkStatus        `json:"status"`
+	ID        *int64            `json:"id,omitempty"`
+	ErrorCode string            `json:"error_code,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// BulkProductResponse represents the outcome of a bulk request. Committed is
+// false when an atomic batch was rolled back.
+type BulkProductResponse struct {
+	Mode      BulkMode              `json:"mode"`
+	Committed bool                  `json:"committed"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []BulkOperationResult `json:"results"`
+}
+
+// ========================================
+// TRASH DTOs
+// ========================================
+
+// PurgeTrashResponse reports a purge: the number of products (variants
+// included) permanently deleted, all of them trashed before DeletedBefore
+type PurgeTrashResponse struct {
+	Purged        int64  `json:"purged"`
+	DeletedBefore string `json:"deleted_before"`
+}
+
+// ========================================
+// REVISION DTOs
+// ========================================
+
+// ListProductRevisionFilter represents the filter for a product's revisions
+type ListProductRevisionFilter struct {
+	ProductID int64 `json:"product_id"`
+
+	// Pagination
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+func (f *ListProductRevisionFilter) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Product ID
+	if f.ProductID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "product_id",
+			Message: "product_id must be a positive integer",
+		})
+	}
+
+	// Page validation
+	if f.Page < 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "page",
+			Message: "page must be a positive number",
+		})
+	}
+	if f.Page == 0 {
+		f.Page = 1 // Default page
+	}
+
+	// Limit validation
+	if f.Limit < 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "limit",
+			Message: "limit must be a positive number",
+		})
+	}
+	if f.Limit == 0 {
+		f.Limit = 20 // Default limit
+	}
+	if f.Limit > 100 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "limit",
+			Message: "limit must not exceed 100",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// ProductRevisionResponse represents one revision of a product
+type ProductRevisionResponse struct {
+	ProductID int64           `json:"product_id"`
+	Revision  int             `json:"revision"`
+	Actor     string          `json:"actor"`
+	CreatedAt string          `json:"created_at"`
+	Snapshot  ProductSnapshot `json:"snapshot"`
+}
+
+// ListProductRevisionResponse represents the revisions of a product, newest first
+type ListProductRevisionResponse struct {
+	TotalCount int64                     `json:"total_count"`
+	Page       int                       `json:"page"`
+	Limit      int                       `json:"limit"`
+	TotalPages int                       `json:"total_pages"`
+	Revisions  []ProductRevisionResponse `json:"revisions"`
+}
+
+// ========================================
+// IMAGE GALLERY DTOs
+// ========================================
+
+// MaxAltTextLength caps the alt text of a gallery image
+const MaxAltTextLength = 255
+
+// AddProductImageRequest represents an image uploaded to a product's gallery.
+// The first image of a product becomes its primary image.
+type AddProductImageRequest struct {
+	ProductID  int64
+	File       multipart.File
+	FileHeader *multipart.FileHeader
+	AltText    *string
+}
+
+func (r *AddProductImageRequest) Validate() error {
+	var errs validator.ValidationErrors
+	if r.ProductID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "product_id",
+			Message: "product_id must be a positive integer",
+		})
+	}
+	errs = append(errs, validateAltText(r.AltText)...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// UpdateProductImageRequest changes the alt text of a gallery image; an empty
+// alt text removes it
+type UpdateProductImageRequest struct {
+	ProductID int64   `json:"-"`
+	ImageID   int64   `json:"-"`
+	AltText   *string `json:"alt_text"`
+}
+
+func (r *UpdateProductImageRequest) Validate() error {
+	var errs validator.ValidationErrors
+	if r.AltText == nil {
+		errs = append(errs, validator.ValidationError{
+			Field:   "alt_text",
+			Message: "alt_text is required",
+		})
+	}
+	errs = append(errs, validateAltText(r.AltText)...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ReorderProductImagesRequest lists every image ID of a product's gallery in
+// the new display order
+type ReorderProductImagesRequest struct {
+	ProductID int64   `json:"-"`
+	ImageIDs  []int64 `json:"image_ids"`
+}
+
+func (r *ReorderProductImagesRequest) Validate() error {
+	var errs validator.ValidationErrors
+	if len(r.ImageIDs) == 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "image_ids",
+			Message: "image_ids must not be empty",
+		})
+	}
+
+	seen := make(map[int64]bool, len(r.ImageIDs))
+	for _, id := range r.ImageIDs {
+		if id <= 0 || seen[id] {
+			errs = append(errs, validator.ValidationError{
+				Field:   "image_ids",
+				Message: "image_ids must be distinct positive integers",
+			})
+			break
+		}
+		seen[id] = true
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateAltText(altText *string) validator.ValidationErrors {
+	if altText != nil && utf8.RuneCountInString(*altText) > MaxAltTextLength {
+		return validator.ValidationErrors{{
+			Field:   "alt_text",
+			Message: "alt_text must not exceed 255 characters",
+		}}
+	}
+	return nil
+}
+
+// ProductImageResponse represents one image of a product's gallery, with its
+// sizes keyed like ProductResponse.Images
+type ProductImageResponse struct {
+	ID        int64                    `json:"id"`
+	URL       string                   `json:"url"`
+	AltText   *string                  `json:"alt_text,omitempty"`
+	Position  int                      `json:"position"`
+	IsPrimary bool                     `json:"is_primary"`
+	Images    map[string]ImageResponse `json:"images,omitempty"`
+}
+
+// ========================================
+// IMPORT DTOs
+// ========================================
+
+const (
+	// MaxImportRows caps the number of product rows in one import file
+	MaxImportRows = 10000
+	// ImportSyncRowLimit is the largest import applied within the request;
+	// bigger files are applied by a background job
+	ImportSyncRowLimit = 200
+)
+
+// ImportColumns lists the CSV header names an import understands. Each maps
+// to the CreateProductRequest field of the same JSON name.
+var ImportColumns = []string{"sku", "name", "description", "price", "stock", "category_id", "category", "status"}
+
+// ImportProductsRequest represents an uploaded CSV file. A dry run only
+// validates the rows and writes nothing.
+type ImportProductsRequest struct {
+	File   io.Reader
+	DryRun bool
+}
+
+type ImportStatus string
+
+const (
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed" // the job stopped, not just some rows
+)
+
+// ImportRowError reports why one row was rejected. Row is the line number in
+// the file, the header being line 1.
+type ImportRowError struct {
+	Row       int               `json:"row"`
+	SKU       string            `json:"sku,omitempty"`
+	ErrorCode string            `json:"error_code"`
+	Error     string            `json:"error"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// ImportJobResponse represents the progress and error report of an import.
+// JobID is only set for imports running in the background.
+type ImportJobResponse struct {
+	JobID      string           `json:"job_id,omitempty"`
+	Status     ImportStatus     `json:"status"`
+	DryRun     bool             `json:"dry_run"`
+	TotalRows  int              `json:"total_rows"`
+	Processed  int              `json:"processed"`
+	Valid      int              `json:"valid"`
+	Created    int              `json:"created"`
+	Updated    int              `json:"updated"`
+	Failed     int              `json:"failed"`
+	Errors     []ImportRowError `json:"errors"`
+	Error      string           `json:"error,omitempty"`
+	StartedAt  string           `json:"started_at"`
+	FinishedAt *string          `json:"finished_at,omitempty"`
+}
+
+// ========================================
+// EXPORT DTOs
+// ========================================
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV   ExportFormat = "csv"
+	ExportFormatXLSX  ExportFormat = "xlsx"
+	ExportFormatJSONL ExportFormat = "jsonl"
+)
+
+// ExportColumns is the header row of CSV and XLSX exports
+var ExportColumns = []string{"id", "sku", "name", "description", "price", "stock", "category_id", "category", "status", "image_url", "version", "created_at", "updated_at"}
+
+// ExportProductFilter selects the products to export with the list filters.
+// Paging does not apply, every matching product is exported. Delimiter and
+// DecimalSeparator set the CSV dialect; Excel in an Indonesian locale expects
+// ";" and ",".
+type ExportProductFilter struct {
+	ListProductFilter
+	Format           ExportFormat `json:"format"`
+	Delimiter        string       `json:"delimiter"`
+	DecimalSeparator string       `json:"decimal_separator"`
+}
+
+func (f *ExportProductFilter) Validate() error {
+	var errs validator.ValidationErrors
+	if err := f.ListProductFilter.Validate(); err != nil {
+		errs, _ = err.(validator.ValidationErrors)
+	}
+
+	// Format
+	if f.Format == "" {
+		f.Format = ExportFormatCSV // Default format
+	}
+	if !validator.IsInSlice(string(f.Format), []string{string(ExportFormatCSV), string(ExportFormatXLSX), string(ExportFormatJSONL)}) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "format",
+			Message: "format must be one of: csv, xlsx, jsonl",
+		})
+	}
+
+	// CSV dialect
+	if f.Delimiter == "" {
+		f.Delimiter = "," // Default delimiter
+	}
+	if f.Delimiter == "tab" {
+		f.Delimiter = "\t"
+	}
+	if !validator.IsInSlice(f.Delimiter, []string{",", ";", "\t", "|"}) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "delimiter",
+			Message: "delimiter must be one of: , ; | tab",
+		})
+	}
+	if f.DecimalSeparator == "" {
+		f.DecimalSeparator = "." // Default decimal separator
+	}
+	if !validator.IsInSlice(f.DecimalSeparator, []string{".", ","}) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "decimal_separator",
+			Message: "decimal_separator must be either '.' or ','",
+		})
+	} else if f.DecimalSeparator == f.Delimiter {
+		errs = append(errs, validator.ValidationError{
+			Field:   "decimal_separator",
+			Message: "decimal_separator must differ from the delimiter",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
 }
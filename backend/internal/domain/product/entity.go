@@ -1,6 +1,7 @@
 package product
 
 import (
+	"slices"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -15,14 +16,143 @@ const (
 
 type Product struct {
 	ID          int64
+	ParentID    *int64 // set when the product is a variant of another product
 	SKU         string
 	Name        string
 	Description *string
 	Price       decimal.Decimal
 	Stock       int
-	Category    string
+	CategoryID  int64
+	Category    string // name of CategoryID, read-only
 	Status      ProductStatus
 	ImageURL    *string
+	Version     int // incremented by every update, for optimistic concurrency
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	DeletedAt   *time.Time // set while the product is in the trash
+
+	// Per-warehouse breakdown of Stock
+	WarehouseStock []WarehouseStock
+
+	// The uploaded image and its resized copies, generated on upload. Empty
+	// when ImageURL was set without an upload.
+	ImageVariants []ImageVariant
+
+	// Image gallery in display order. ImageURL and ImageVariants are those of
+	// the primary image.
+	Images []ProductImage
+
+	// Search matches with the hits wrapped in <mark>, only set by a full-text search
+	NameHighlight        *string
+	DescriptionHighlight *string
+
+	// Value of the list's sort column as text, set by GetAll for building cursors
+	SortKey string
+}
+
+// WarehouseStock is the quantity of a product held in one warehouse
+type WarehouseStock struct {
+	WarehouseID   int64  `json:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code"`
+	WarehouseName string `json:"warehouse_name"`
+	Quantity      int    `json:"quantity"`
+}
+
+// ImageVariant is one size of a product image, stored as JSON. The uploaded
+// file itself is named "original" and shares its URL with ImageURL.
+type ImageVariant struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageFileURLs returns the URLs of every stored file of an image: the
+// uploaded original and its resized copies
+func ImageFileURLs(imageURL *string, variants []ImageVariant) []string {
+	urls := []string{}
+	if imageURL != nil && *imageURL != "" {
+		urls = append(urls, *imageURL)
+	}
+	for _, v := range variants {
+		if !slices.Contains(urls, v.URL) {
+			urls = append(urls, v.URL)
+		}
+	}
+	return urls
+}
+
+// ProductImage is one image of a product's gallery. Exactly one image of a
+// product with images is the primary one.
+type ProductImage struct {
+	ID        int64          `json:"id"`
+	ProductID int64          `json:"product_id"`
+	URL       string         `json:"url"`
+	Variants  []ImageVariant `json:"variants"`
+	AltText   *string        `json:"alt_text"`
+	Position  int            `json:"position"`
+	IsPrimary bool           `json:"is_primary"`
+}
+
+// ProductSuggestion is a typo-tolerant match for an autocomplete query. Score
+// is the pg_trgm word similarity of the query to the name or SKU, from 0 to 1.
+type ProductSuggestion struct {
+	ID    int64
+	SKU   string
+	Name  string
+	Score float64
+}
+
+// Facets are product counts for one list filter, grouped per category, status
+// and price band. A slice is nil when that facet was not requested.
+type Facets struct {
+	Categories  []CategoryFacet
+	Statuses    []StatusFacet
+	PriceRanges []PriceRangeFacet
+}
+
+type CategoryFacet struct {
+	CategoryID int64
+	Category   string
+	Count      int64
+}
+
+type StatusFacet struct {
+	Status ProductStatus
+	Count  int64
+}
+
+// PriceRangeFacet counts the products priced in [Min, Max). Min is nil for the
+// lowest band and Max for the highest.
+type PriceRangeFacet struct {
+	Min   *decimal.Decimal
+	Max   *decimal.Decimal
+	Count int64
+}
+
+// ProductRevision is a snapshot of a product row taken after a write. It is
+// numbered by the product version it captured, so revisions of one product
+// may skip numbers when a write did not go through the product service.
+type ProductRevision struct {
+	ID        int64
+	ProductID int64
+	Revision  int
+	Snapshot  ProductSnapshot
+	Actor     string
+	CreatedAt time.Time
+}
+
+// ProductSnapshot is the product row as kept in a revision, stored as JSON
+type ProductSnapshot struct {
+	SKU         string          `json:"sku"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description"`
+	Price       decimal.Decimal `json:"price"`
+	Stock       int             `json:"stock"`
+	CategoryID  int64           `json:"category_id"`
+	Status      ProductStatus   `json:"status"`
+	ImageURL    *string         `json:"image_url"`
+	Version     int             `json:"version"`
+	UpdatedAt   time.Time       `json:"updated_at"`
 }
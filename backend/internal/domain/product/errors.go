@@ -10,7 +10,17 @@ var (
 	ErrProductIDRequired    = errors.New("product ID is required")
 	ErrInvalidPrice         = errors.New("invalid price")
 	ErrInvalidStock         = errors.New("invalid stock")
-	ErrInvalidImageFormat   = errors.New("invalid image format, only JPG, JPEG, PNG, GIF are allowed")
+	ErrInvalidImageFormat   = errors.New("invalid image format")
 	ErrImageTooLarge        = errors.New("image file size exceeds maximum limit of 5MB")
+	ErrImageTooManyPixels   = errors.New("image dimensions exceed the maximum pixel count")
 	ErrImageRequired        = errors.New("image file is required")
+	ErrVersionConflict      = errors.New("product was modified by another request")
+	ErrProductNotInTrash    = errors.New("product not found in trash")
+	ErrRevisionNotFound     = errors.New("product revision not found")
+	ErrImageNotFound        = errors.New("product image not found")
+
+	// Import Errors
+	ErrImportFileRequired = errors.New("import file is required")
+	ErrInvalidImportFile  = errors.New("import file is not a valid CSV")
+	ErrImportJobNotFound  = errors.New("import job not found")
 )
@@ -2,13 +2,51 @@ package product
 
 import (
 	"context"
+	"time"
 )
 
 type ProductRepository interface {
 	Create(ctx context.Context, product Product) (Product, error)
 	GetByID(ctx context.Context, id int64) (Product, error)
+	// GetByIDForUpdate is GetByID that also locks the row until the transaction ends
+	GetByIDForUpdate(ctx context.Context, id int64) (Product, error)
 	GetBySKU(ctx context.Context, sku string) (Product, error)
 	GetAll(ctx context.Context, filter ListProductFilter) ([]Product, int64, error)
+	// Export streams every product matching the filter to fn, in list order
+	// and without paging; an error from fn stops the export
+	Export(ctx context.Context, filter ListProductFilter, fn func(Product) error) error
+	GetFacets(ctx context.Context, filter ListProductFilter) (Facets, error)
+	Suggest(ctx context.Context, filter SuggestProductFilter) ([]ProductSuggestion, error)
 	Update(ctx context.Context, product UpdateProductRequest) error
-	Delete(ctx context.Context, id int64) error
+	// Delete moves a product and its variants to the trash
+	Delete(ctx context.Context, id int64, expectedVersion *int) error
+	Restore(ctx context.Context, id int64) error
+	// Purge hard-deletes the products trashed before the given time and
+	// returns how many rows went, and the image and image variant URLs they held
+	Purge(ctx context.Context, before time.Time) (int64, []string, error)
+}
+
+// ProductRevisionRepository stores the snapshots written by the product service
+type ProductRevisionRepository interface {
+	Create(ctx context.Context, revision ProductRevision) (ProductRevision, error)
+	Get(ctx context.Context, productID int64, revision int) (ProductRevision, error)
+	// List returns a product's revisions, newest first, and their total count
+	List(ctx context.Context, filter ListProductRevisionFilter) ([]ProductRevision, int64, error)
+}
+
+// ProductImageRepository stores the image galleries of products. Images are
+// always addressed through their product, so an image ID of another product
+// is not found.
+type ProductImageRepository interface {
+	// Create appends an image to the end of the product's gallery
+	Create(ctx context.Context, image ProductImage) (ProductImage, error)
+	Get(ctx context.Context, productID, id int64) (ProductImage, error)
+	// List returns the product's images in display order
+	List(ctx context.Context, productID int64) ([]ProductImage, error)
+	UpdateAltText(ctx context.Context, productID, id int64, altText *string) error
+	// SetPrimary makes the image the product's only primary image
+	SetPrimary(ctx context.Context, productID, id int64) error
+	// Reorder numbers the product's images in the order of ids
+	Reorder(ctx context.Context, productID int64, ids []int64) error
+	Delete(ctx context.Context, productID, id int64) error
 }
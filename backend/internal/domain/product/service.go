@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"io"
 	"mime/multipart"
 )
 
@@ -15,12 +16,46 @@ type ProductService interface {
 
 	// Update and delete
 	UpdateProduct(ctx context.Context, req UpdateProductRequest) error
-	DeleteProduct(ctx context.Context, id int64) error
+	// A non-nil expectedVersion (from If-Match) makes a stale write fail with ErrVersionConflict
+	DeleteProduct(ctx context.Context, id int64, expectedVersion *int) error
+
+	// Deleted products stay in the trash, where they can be listed and
+	// restored, until PurgeTrash removes those past the retention period
+	ListTrash(ctx context.Context, filter ListProductFilter) (ListProductResponse, error)
+	RestoreProduct(ctx context.Context, id int64) (ProductResponse, error)
+	PurgeTrash(ctx context.Context) (PurgeTrashResponse, error)
+
+	// Every write leaves a revision, a snapshot of the product row. Restoring
+	// a revision applies its snapshot through UpdateProduct.
+	ListRevisions(ctx context.Context, filter ListProductRevisionFilter) (ListProductRevisionResponse, error)
+	GetRevision(ctx context.Context, id int64, revision int) (ProductRevisionResponse, error)
+	RestoreRevision(ctx context.Context, id int64, revision int, expectedVersion *int) (ProductResponse, error)
+
+	// Create, update and delete many products in one request
+	BulkProducts(ctx context.Context, req BulkProductRequest) (BulkProductResponse, error)
+
+	// Upsert products by SKU from a CSV file. Large imports run in the
+	// background and are polled with GetImportJob.
+	ImportProducts(ctx context.Context, req ImportProductsRequest) (ImportJobResponse, error)
+	GetImportJob(ctx context.Context, id string) (ImportJobResponse, error)
 
 	// List products with pagination/filtering
 	ListProducts(ctx context.Context, filter ListProductFilter) (ListProductResponse, error)
 
-	// Upload and delete product image
+	// Write every product matching the filter to w as CSV, XLSX or JSON Lines
+	ExportProducts(ctx context.Context, filter ExportProductFilter, w io.Writer) error
+
+	// Typo-tolerant name/SKU suggestions for autocomplete
+	SuggestProducts(ctx context.Context, filter SuggestProductFilter) ([]ProductSuggestionResponse, error)
+
+	// Replace and delete the primary product image, the one image_url shows
 	UploadImage(ctx context.Context, id int64, file multipart.File, fileHeader *multipart.FileHeader) error
 	DeleteImage(ctx context.Context, id int64) error
+
+	// Manage the image gallery; deleting the primary image promotes the next
+	AddGalleryImage(ctx context.Context, req AddProductImageRequest) (ProductImageResponse, error)
+	UpdateGalleryImage(ctx context.Context, req UpdateProductImageRequest) (ProductImageResponse, error)
+	SetPrimaryImage(ctx context.Context, id, imageID int64) (ProductResponse, error)
+	ReorderGallery(ctx context.Context, req ReorderProductImagesRequest) ([]ProductImageResponse, error)
+	DeleteGalleryImage(ctx context.Context, id, imageID int64) error
 }
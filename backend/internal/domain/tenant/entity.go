@@ -0,0 +1,11 @@
+package tenant
+
+import "time"
+
+// Tenant is a shop sharing the deployment. Products and the rows hanging off
+// them belong to one tenant and are hidden from the others.
+type Tenant struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
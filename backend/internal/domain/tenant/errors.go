@@ -0,0 +1,9 @@
+package tenant
+
+import "errors"
+
+var (
+	// Tenant Errors
+	ErrTenantRequired = errors.New("request is not scoped to a tenant")
+	ErrTenantMismatch = errors.New("credentials belong to another tenant")
+)
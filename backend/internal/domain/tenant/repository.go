@@ -0,0 +1,7 @@
+package tenant
+
+import "context"
+
+type TenantRepository interface {
+	GetAll(ctx context.Context) ([]Tenant, error)
+}
@@ -0,0 +1,139 @@
+package user
+
+import (
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
+)
+
+// bcrypt only reads the first 72 bytes of a password
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+type CreateUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+	TenantID int64  `json:"tenant_id"`
+}
+
+func (r *CreateUserRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Username
+	if validator.IsEmpty(r.Username) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "username",
+			Message: "username is required",
+		})
+	}
+	if len(r.Username) > 100 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "username",
+			Message: "username must not exceed 100 characters",
+		})
+	}
+
+	// Password
+	if len(r.Password) < MinPasswordLength {
+		errs = append(errs, validator.ValidationError{
+			Field:   "password",
+			Message: "password must be at least 8 characters",
+		})
+	}
+	if len(r.Password) > MaxPasswordLength {
+		errs = append(errs, validator.ValidationError{
+			Field:   "password",
+			Message: "password must not exceed 72 bytes",
+		})
+	}
+
+	// Role
+	if r.Role == "" {
+		r.Role = auth.RoleViewer // Default role
+	}
+	if !auth.IsValidRole(r.Role) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "role",
+			Message: "role must be one of: admin, manager, staff, viewer",
+		})
+	}
+
+	// Tenant
+	if r.TenantID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "tenant_id",
+			Message: "tenant_id must be a positive number",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (r *LoginRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	if validator.IsEmpty(r.Username) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "username",
+			Message: "username is required",
+		})
+	}
+	if r.Password == "" {
+		errs = append(errs, validator.ValidationError{
+			Field:   "password",
+			Message: "password is required",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// RefreshRequest carries a refresh token, for a refresh or a logout
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (r *RefreshRequest) Validate() error {
+	if r.RefreshToken == "" {
+		return validator.ValidationErrors{{
+			Field:   "refresh_token",
+			Message: "refresh_token is required",
+		}}
+	}
+	return nil
+}
+
+// TokenResponse is an OAuth 2.0 style token pair. ExpiresIn is the access
+// token's lifetime in seconds.
+type TokenResponse struct {
+	AccessToken           string `json:"access_token"`
+	TokenType             string `json:"token_type"`
+	ExpiresIn             int    `json:"expires_in"`
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiresAt string `json:"refresh_token_expires_at"`
+}
+
+type UserResponse struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	TenantID  int64  `json:"tenant_id"`
+	IsActive  bool   `json:"is_active"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
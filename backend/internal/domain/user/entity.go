@@ -0,0 +1,28 @@
+package user
+
+import "time"
+
+type User struct {
+	ID           int64
+	Username     string
+	PasswordHash string
+	Role         string // one of the auth.Role* roles
+	TenantID     int64
+	IsActive     bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// RefreshToken is a stored refresh token. Only a hash of the token is kept.
+// Each refresh replaces the token with a new one of the same family, so a
+// token that is presented again after being replaced exposes a stolen copy
+// and revokes the whole family.
+type RefreshToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	FamilyID  string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,12 @@
+package user
+
+import "errors"
+
+var (
+	// User Errors
+	ErrUsernameExists     = errors.New("user with this username already exists")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	// Token Errors
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+)
@@ -0,0 +1,19 @@
+package user
+
+import "context"
+
+type UserRepository interface {
+	Create(ctx context.Context, user User) (User, error)
+	GetByID(ctx context.Context, id int64) (User, error)
+	GetByUsername(ctx context.Context, username string) (User, error)
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token RefreshToken) (RefreshToken, error)
+	// GetByHashForUpdate looks a token up by its hash and locks it until the
+	// transaction ends, so a token can only be rotated once
+	GetByHashForUpdate(ctx context.Context, tokenHash string) (RefreshToken, error)
+	Revoke(ctx context.Context, id int64) error
+	// RevokeFamily revokes every token of a family that is not revoked yet
+	RevokeFamily(ctx context.Context, familyID string) error
+}
@@ -0,0 +1,15 @@
+package user
+
+import "context"
+
+type UserService interface {
+	// Create a user with a hashed password, used by the bootstrap command
+	CreateUser(ctx context.Context, req CreateUserRequest) (UserResponse, error)
+
+	// Login checks the credentials and issues an access and a refresh token
+	Login(ctx context.Context, req LoginRequest) (TokenResponse, error)
+	// Refresh exchanges a refresh token for a new pair; the old one is revoked
+	Refresh(ctx context.Context, req RefreshRequest) (TokenResponse, error)
+	// Logout revokes the refresh token and every token rotated from the same login
+	Logout(ctx context.Context, req RefreshRequest) error
+}
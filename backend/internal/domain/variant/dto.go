@@ -0,0 +1,293 @@
+package variant
+
+import (
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
+	"github.com/shopspring/decimal"
+)
+
+// MaxGeneratedVariants caps the size of an option matrix generated in one request
+const MaxGeneratedVariants = 100
+
+// ========================================
+// OPTION DTOs
+// ========================================
+
+// OptionDefinition is an option and the values it can take
+type OptionDefinition struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// SetOptionsRequest replaces the option definitions of a product
+type SetOptionsRequest struct {
+	ProductID int64              `json:"-"`
+	Options   []OptionDefinition `json:"options"`
+}
+
+func (r *SetOptionsRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	if len(r.Options) == 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "options",
+			Message: "at least one option is required",
+		})
+	}
+	errs = append(errs, validateOptionDefinitions(r.Options)...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func validateOptionDefinitions(options []OptionDefinition) validator.ValidationErrors {
+	var errs validator.ValidationErrors
+
+	names := map[string]bool{}
+	for _, o := range options {
+		if validator.IsEmpty(o.Name) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "options",
+				Message: "option name is required",
+			})
+			continue
+		}
+		if len(o.Name) > 50 {
+			errs = append(errs, validator.ValidationError{
+				Field:   "options",
+				Message: "option name must not exceed 50 characters",
+			})
+		}
+		if names[o.Name] {
+			errs = append(errs, validator.ValidationError{
+				Field:   "options",
+				Message: "option '" + o.Name + "' is defined more than once",
+			})
+		}
+		names[o.Name] = true
+
+		if len(o.Values) == 0 {
+			errs = append(errs, validator.ValidationError{
+				Field:   "options",
+				Message: "option '" + o.Name + "' needs at least one value",
+			})
+		}
+		seen := map[string]bool{}
+		for _, v := range o.Values {
+			if validator.IsEmpty(v) || seen[v] {
+				errs = append(errs, validator.ValidationError{
+					Field:   "options",
+					Message: "values of option '" + o.Name + "' must be non-empty and unique",
+				})
+				break
+			}
+			seen[v] = true
+		}
+	}
+
+	return errs
+}
+
+// ========================================
+// VARIANT DTOs
+// ========================================
+
+// CreateVariantRequest represents the request to create one variant. Options
+// must pick exactly one allowed value for every option of the parent.
+type CreateVariantRequest struct {
+	ProductID     int64                        `json:"-"`
+	SKU           string                       `json:"sku"`
+	Options       map[string]string            `json:"options"`
+	PriceOverride *decimal.Decimal             `json:"price_override,omitempty"`
+	Stock         int                          `json:"stock"`
+	Status        *productDomain.ProductStatus `json:"status,omitempty"`
+}
+
+func (r *CreateVariantRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// SKU
+	if validator.IsEmpty(r.SKU) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "sku",
+			Message: "sku is required",
+		})
+	}
+	if len(r.SKU) > 100 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "sku",
+			Message: "sku must not exceed 100 characters",
+		})
+	}
+
+	// Options
+	if len(r.Options) == 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "options",
+			Message: "options are required",
+		})
+	}
+
+	errs = append(errs, validateVariantFields(r.PriceOverride, &r.Stock, r.Status)...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// GenerateVariantsRequest creates a variant for every combination of option
+// values. When Options is given it first replaces the product's definitions.
+// SKUs are derived from the parent SKU and the option values.
+type GenerateVariantsRequest struct {
+	ProductID     int64                        `json:"-"`
+	Options       []OptionDefinition           `json:"options,omitempty"`
+	PriceOverride *decimal.Decimal             `json:"price_override,omitempty"`
+	Stock         int                          `json:"stock"`
+	Status        *productDomain.ProductStatus `json:"status,omitempty"`
+}
+
+func (r *GenerateVariantsRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	errs = append(errs, validateOptionDefinitions(r.Options)...)
+	errs = append(errs, validateVariantFields(r.PriceOverride, &r.Stock, r.Status)...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// UpdateVariantRequest represents the request to update a variant. Set
+// ClearPriceOverride to fall back to the parent's price.
+type UpdateVariantRequest struct {
+	ID                 int64                        `json:"id"`
+	ProductID          int64                        `json:"-"`
+	SKU                *string                      `json:"sku,omitempty"`
+	PriceOverride      *decimal.Decimal             `json:"price_override,omitempty"`
+	ClearPriceOverride bool                         `json:"clear_price_override,omitempty"`
+	Stock              *int                         `json:"stock,omitempty"`
+	Status             *productDomain.ProductStatus `json:"status,omitempty"`
+	ImageURL           *string                      `json:"image_url,omitempty"`
+}
+
+func (r *UpdateVariantRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// ID
+	if r.ID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "id",
+			Message: "id must be a positive integer",
+		})
+	}
+
+	// SKU
+	if r.SKU != nil {
+		if validator.IsEmpty(*r.SKU) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "sku",
+				Message: "sku must not be empty",
+			})
+		}
+		if len(*r.SKU) > 100 {
+			errs = append(errs, validator.ValidationError{
+				Field:   "sku",
+				Message: "sku must not exceed 100 characters",
+			})
+		}
+	}
+
+	// Price override
+	if r.ClearPriceOverride && r.PriceOverride != nil {
+		errs = append(errs, validator.ValidationError{
+			Field:   "price_override",
+			Message: "price_override cannot be set together with clear_price_override",
+		})
+	}
+
+	errs = append(errs, validateVariantFields(r.PriceOverride, r.Stock, r.Status)...)
+
+	// ImageURL
+	if r.ImageURL != nil && len(*r.ImageURL) > 2048 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "image_url",
+			Message: "image_url must not exceed 2048 characters",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func validateVariantFields(priceOverride *decimal.Decimal, stock *int, status *productDomain.ProductStatus) validator.ValidationErrors {
+	var errs validator.ValidationErrors
+
+	// Price override, NUMERIC(10,2) allows max 99,999,999.99
+	if priceOverride != nil {
+		if priceOverride.LessThan(decimal.Zero) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "price_override",
+				Message: "price_override must be greater than or equal to 0",
+			})
+		}
+		if priceOverride.GreaterThan(decimal.NewFromFloat(99999999.99)) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "price_override",
+				Message: "price_override must not exceed 99,999,999.99",
+			})
+		}
+	}
+
+	// Stock
+	if stock != nil && *stock < 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "stock",
+			Message: "stock must be greater than or equal to 0",
+		})
+	}
+
+	// Status
+	if status != nil && *status != productDomain.ProductStatusActive && *status != productDomain.ProductStatusInactive {
+		errs = append(errs, validator.ValidationError{
+			Field:   "status",
+			Message: "status must be either 'Active' or 'Inactive'",
+		})
+	}
+
+	return errs
+}
+
+// VariantResponse represents the response containing variant data. Price is
+// what the variant sells for; PriceOverride is omitted when it inherits the
+// parent's price.
+type VariantResponse struct {
+	ID            int64                       `json:"id"`
+	ProductID     int64                       `json:"product_id"`
+	SKU           string                      `json:"sku"`
+	Name          string                      `json:"name"`
+	Options       map[string]string           `json:"options"`
+	Price         decimal.Decimal             `json:"price"`
+	PriceOverride *decimal.Decimal            `json:"price_override,omitempty"`
+	Stock         int                         `json:"stock"`
+	Status        productDomain.ProductStatus `json:"status"`
+	ImageURL      *string                     `json:"image_url,omitempty"`
+	CreatedAt     string                      `json:"created_at"`
+	UpdatedAt     string                      `json:"updated_at"`
+}
+
+// ListVariantResponse represents a product's option definitions and variants
+type ListVariantResponse struct {
+	ProductID int64              `json:"product_id"`
+	Options   []OptionDefinition `json:"options"`
+	Variants  []VariantResponse  `json:"variants"`
+}
@@ -0,0 +1,32 @@
+package variant
+
+import (
+	"time"
+
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/shopspring/decimal"
+)
+
+// Option is an option a parent product is sold in, e.g. Size or Color, with
+// the values a variant may pick from
+type Option struct {
+	Name   string
+	Values []string
+}
+
+// Variant is a sellable combination of option values under a parent product.
+// It is stored as a product row, so it has its own SKU, stock ledger and image.
+type Variant struct {
+	ID            int64
+	ParentID      int64
+	SKU           string
+	Name          string
+	Options       map[string]string
+	Price         decimal.Decimal  // effective price
+	PriceOverride *decimal.Decimal // nil when the parent's price applies
+	Stock         int
+	Status        productDomain.ProductStatus
+	ImageURL      *string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
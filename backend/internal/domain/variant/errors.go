@@ -0,0 +1,13 @@
+package variant
+
+import "errors"
+
+var (
+	// Variant Errors
+	ErrVariantNotFound     = errors.New("variant not found")
+	ErrVariantExists       = errors.New("a variant with these options already exists")
+	ErrNestedVariant       = errors.New("a variant cannot have variants of its own")
+	ErrNoOptions           = errors.New("product has no options defined")
+	ErrInvalidOptions      = errors.New("variant options do not match the product's option definitions")
+	ErrTooManyCombinations = errors.New("option matrix exceeds the maximum number of variants")
+)
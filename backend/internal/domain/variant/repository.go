@@ -0,0 +1,16 @@
+package variant
+
+import (
+	"context"
+)
+
+type VariantRepository interface {
+	// Option definitions of a parent product, in display order
+	GetOptions(ctx context.Context, productID int64) ([]Option, error)
+	ReplaceOptions(ctx context.Context, productID int64, options []Option) error
+
+	Create(ctx context.Context, variant Variant) (Variant, error)
+	GetByID(ctx context.Context, parentID int64, id int64) (Variant, error)
+	GetByParent(ctx context.Context, parentID int64) ([]Variant, error)
+	Update(ctx context.Context, variant UpdateVariantRequest) error
+}
@@ -0,0 +1,18 @@
+package variant
+
+import (
+	"context"
+)
+
+type VariantService interface {
+	// Define the options (Size, Color, ...) a product is sold in
+	SetOptions(ctx context.Context, req SetOptionsRequest) ([]OptionDefinition, error)
+
+	// Variant CRUD
+	CreateVariant(ctx context.Context, req CreateVariantRequest) (VariantResponse, error)
+	ListVariants(ctx context.Context, productID int64) (ListVariantResponse, error)
+	UpdateVariant(ctx context.Context, req UpdateVariantRequest) error
+
+	// Create a variant for every option combination that does not exist yet
+	GenerateVariants(ctx context.Context, req GenerateVariantsRequest) ([]VariantResponse, error)
+}
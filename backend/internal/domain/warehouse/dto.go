@@ -0,0 +1,184 @@
+package warehouse
+
+import (
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
+)
+
+// ========================================
+// WAREHOUSE DTOs
+// ========================================
+
+// CreateWarehouseRequest represents the request to create a new warehouse
+type CreateWarehouseRequest struct {
+	Code    string  `json:"code"`
+	Name    string  `json:"name"`
+	Address *string `json:"address,omitempty"`
+}
+
+func (r *CreateWarehouseRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Code
+	if validator.IsEmpty(r.Code) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "code",
+			Message: "code is required",
+		})
+	}
+	if len(r.Code) > 50 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "code",
+			Message: "code must not exceed 50 characters",
+		})
+	}
+
+	// Name
+	if validator.IsEmpty(r.Name) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "name",
+			Message: "name is required",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// UpdateWarehouseRequest represents the request to update an existing warehouse
+type UpdateWarehouseRequest struct {
+	ID      int64   `json:"id"`
+	Code    *string `json:"code,omitempty"`
+	Name    *string `json:"name,omitempty"`
+	Address *string `json:"address,omitempty"`
+}
+
+func (r *UpdateWarehouseRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// ID
+	if r.ID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "id",
+			Message: "id must be a positive integer",
+		})
+	}
+
+	// Code
+	if r.Code != nil {
+		if validator.IsEmpty(*r.Code) {
+			errs = append(errs, validator.ValidationError{
+				Field:   "code",
+				Message: "code must not be empty",
+			})
+		}
+		if len(*r.Code) > 50 {
+			errs = append(errs, validator.ValidationError{
+				Field:   "code",
+				Message: "code must not exceed 50 characters",
+			})
+		}
+	}
+
+	// Name
+	if r.Name != nil && validator.IsEmpty(*r.Name) {
+		errs = append(errs, validator.ValidationError{
+			Field:   "name",
+			Message: "name must not be empty",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// WarehouseResponse represents the response containing warehouse data
+type WarehouseResponse struct {
+	ID        int64   `json:"id"`
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Address   *string `json:"address,omitempty"`
+	IsDefault bool    `json:"is_default"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+// TransferStockRequest represents the request to move stock between warehouses
+type TransferStockRequest struct {
+	ProductID       int64   `json:"product_id"`
+	FromWarehouseID int64   `json:"from_warehouse_id"`
+	ToWarehouseID   int64   `json:"to_warehouse_id"`
+	Quantity        int     `json:"quantity"`
+	Reference       *string `json:"reference,omitempty"`
+	Note            *string `json:"note,omitempty"`
+}
+
+func (r *TransferStockRequest) Validate() error {
+	var errs validator.ValidationErrors
+
+	// Product ID
+	if r.ProductID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "product_id",
+			Message: "product_id must be a positive integer",
+		})
+	}
+
+	// Warehouses
+	if r.FromWarehouseID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "from_warehouse_id",
+			Message: "from_warehouse_id must be a positive integer",
+		})
+	}
+	if r.ToWarehouseID <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "to_warehouse_id",
+			Message: "to_warehouse_id must be a positive integer",
+		})
+	}
+	if r.FromWarehouseID > 0 && r.FromWarehouseID == r.ToWarehouseID {
+		errs = append(errs, validator.ValidationError{
+			Field:   "to_warehouse_id",
+			Message: "to_warehouse_id must differ from from_warehouse_id",
+		})
+	}
+
+	// Quantity
+	if r.Quantity <= 0 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "quantity",
+			Message: "quantity must be greater than 0",
+		})
+	}
+
+	// Reference
+	if r.Reference != nil && len(*r.Reference) > 255 {
+		errs = append(errs, validator.ValidationError{
+			Field:   "reference",
+			Message: "reference must not exceed 255 characters",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// TransferStockResponse represents the outcome of a transfer. Each side of the
+// transfer is recorded as its own stock movement.
+type TransferStockResponse struct {
+	ProductID          int64 `json:"product_id"`
+	FromWarehouseID    int64 `json:"from_warehouse_id"`
+	ToWarehouseID      int64 `json:"to_warehouse_id"`
+	Quantity           int   `json:"quantity"`
+	OutgoingMovementID int64 `json:"outgoing_movement_id"`
+	IncomingMovementID int64 `json:"incoming_movement_id"`
+}
@@ -0,0 +1,13 @@
+package warehouse
+
+import "time"
+
+type Warehouse struct {
+	ID        int64
+	Code      string
+	Name      string
+	Address   *string
+	IsDefault bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,12 @@
+package warehouse
+
+import "errors"
+
+var (
+	// Warehouse Errors
+	ErrWarehouseNotFound      = errors.New("warehouse not found")
+	ErrWarehouseCodeExists    = errors.New("warehouse with this code already exists")
+	ErrWarehouseHasStock      = errors.New("warehouse still holds stock")
+	ErrDefaultWarehouseDelete = errors.New("the default warehouse cannot be deleted")
+	ErrSameWarehouseTransfer  = errors.New("source and destination warehouse must differ")
+)
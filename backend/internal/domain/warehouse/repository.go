@@ -0,0 +1,23 @@
+package warehouse
+
+import (
+	"context"
+)
+
+type WarehouseRepository interface {
+	Create(ctx context.Context, warehouse Warehouse) (Warehouse, error)
+	GetByID(ctx context.Context, id int64) (Warehouse, error)
+
+	// GetByIDForUpdate returns the warehouse and locks its row until the
+	// transaction ends, so no stock can be booked into it meanwhile
+	GetByIDForUpdate(ctx context.Context, id int64) (Warehouse, error)
+	GetAll(ctx context.Context) ([]Warehouse, error)
+	Update(ctx context.Context, warehouse UpdateWarehouseRequest) error
+
+	// Delete removes the warehouse with its empty stock rows. Stock rows that
+	// still hold units make it fail with a foreign key violation.
+	Delete(ctx context.Context, id int64) error
+
+	// TotalStock returns the number of units held in the warehouse across all products
+	TotalStock(ctx context.Context, id int64) (int64, error)
+}
@@ -0,0 +1,17 @@
+package warehouse
+
+import (
+	"context"
+)
+
+type WarehouseService interface {
+	// Warehouse CRUD
+	CreateWarehouse(ctx context.Context, req CreateWarehouseRequest) (WarehouseResponse, error)
+	GetWarehouse(ctx context.Context, id int64) (WarehouseResponse, error)
+	ListWarehouses(ctx context.Context) ([]WarehouseResponse, error)
+	UpdateWarehouse(ctx context.Context, req UpdateWarehouseRequest) error
+	DeleteWarehouse(ctx context.Context, id int64) error
+
+	// Move stock of a product between two warehouses atomically
+	TransferStock(ctx context.Context, req TransferStockRequest) (TransferStockResponse, error)
+}
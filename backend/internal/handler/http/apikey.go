@@ -0,0 +1,81 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	apikeyDomain "github.com/naxumi/bnsp-jwd/internal/domain/apikey"
+	"github.com/naxumi/bnsp-jwd/internal/handler/http/response"
+)
+
+type APIKeyHandler interface {
+	CreateAPIKey(w http.ResponseWriter, r *http.Request)
+	ListAPIKeys(w http.ResponseWriter, r *http.Request)
+	RevokeAPIKey(w http.ResponseWriter, r *http.Request)
+}
+
+type APIKeyHandlerImpl struct {
+	apiKeyService apikeyDomain.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService apikeyDomain.APIKeyService) APIKeyHandler {
+	return &APIKeyHandlerImpl{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (h *APIKeyHandlerImpl) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req apikeyDomain.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	createdKey, err := h.apiKeyService.CreateKey(r.Context(), req)
+	if err != nil {
+		log.Printf("Error creating api key: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, "API key created, store it now as it is not shown again", createdKey)
+}
+
+func (h *APIKeyHandlerImpl) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.ListKeys(r.Context())
+	if err != nil {
+		log.Printf("Error listing api keys: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, keys)
+}
+
+func (h *APIKeyHandlerImpl) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid API key ID", nil)
+		return
+	}
+
+	err = h.apiKeyService.RevokeKey(r.Context(), id)
+	if err != nil {
+		log.Printf("Error revoking api key with ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "API key revoked successfully", nil)
+}
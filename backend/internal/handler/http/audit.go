@@ -0,0 +1,130 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	auditDomain "github.com/naxumi/bnsp-jwd/internal/domain/audit"
+	"github.com/naxumi/bnsp-jwd/internal/handler/http/response"
+)
+
+type AuditHandler interface {
+	ListAudit(w http.ResponseWriter, r *http.Request)
+	ListProductHistory(w http.ResponseWriter, r *http.Request)
+}
+
+type AuditHandlerImpl struct {
+	auditService auditDomain.AuditService
+}
+
+func NewAuditHandler(auditService auditDomain.AuditService) AuditHandler {
+	return &AuditHandlerImpl{
+		auditService: auditService,
+	}
+}
+
+func (h *AuditHandlerImpl) ListAudit(w http.ResponseWriter, r *http.Request) {
+	// Parse query parameters
+	queryParams := r.URL.Query()
+	filter, ok := parseAuditFilter(w, queryParams)
+	if !ok {
+		return
+	}
+
+	if entityType := queryParams.Get("entity_type"); entityType != "" {
+		filter.EntityType = &entityType
+	}
+	if entityID := queryParams.Get("entity_id"); entityID != "" {
+		if id, err := strconv.ParseInt(entityID, 10, 64); err == nil {
+			filter.EntityID = &id
+		}
+	}
+
+	h.listRecords(w, r, filter)
+}
+
+func (h *AuditHandlerImpl) ListProductHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	filter, ok := parseAuditFilter(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	// History stays available after the product is deleted or purged
+	entityType := auditDomain.EntityProduct
+	filter.EntityType = &entityType
+	filter.EntityID = &id
+
+	h.listRecords(w, r, filter)
+}
+
+func (h *AuditHandlerImpl) listRecords(w http.ResponseWriter, r *http.Request, filter auditDomain.ListAuditFilter) {
+	// Validate filter
+	if err := filter.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	records, err := h.auditService.ListRecords(r.Context(), filter)
+	if err != nil {
+		log.Printf("Error listing audit records: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, records)
+}
+
+// parseAuditFilter reads the actor, action, time range and pagination
+// parameters. An unparsable time is answered with 400 and ok is false.
+func parseAuditFilter(w http.ResponseWriter, queryParams url.Values) (filter auditDomain.ListAuditFilter, ok bool) {
+	if actor := queryParams.Get("actor"); actor != "" {
+		filter.Actor = &actor
+	}
+	if action := queryParams.Get("action"); action != "" {
+		a := auditDomain.Action(action)
+		filter.Action = &a
+	}
+
+	// Time range, as RFC 3339 timestamps
+	for _, bound := range []struct {
+		param string
+		dest  **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := queryParams.Get(bound.param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.BadRequest(w, "Invalid "+bound.param+", expected an RFC 3339 time such as 2026-01-31T23:59:59+07:00", nil)
+			return filter, false
+		}
+		*bound.dest = &t
+	}
+
+	// Pagination
+	if page := queryParams.Get("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filter.Page = p
+		}
+	}
+	if limit := queryParams.Get("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filter.Limit = l
+		}
+	}
+
+	return filter, true
+}
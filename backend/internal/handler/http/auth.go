@@ -0,0 +1,97 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	userDomain "github.com/naxumi/bnsp-jwd/internal/domain/user"
+	"github.com/naxumi/bnsp-jwd/internal/handler/http/response"
+)
+
+type AuthHandler interface {
+	Login(w http.ResponseWriter, r *http.Request)
+	Refresh(w http.ResponseWriter, r *http.Request)
+	Logout(w http.ResponseWriter, r *http.Request)
+}
+
+type AuthHandlerImpl struct {
+	userService userDomain.UserService
+}
+
+func NewAuthHandler(userService userDomain.UserService) AuthHandler {
+	return &AuthHandlerImpl{
+		userService: userService,
+	}
+}
+
+func (h *AuthHandlerImpl) Login(w http.ResponseWriter, r *http.Request) {
+	var req userDomain.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	tokens, err := h.userService.Login(r.Context(), req)
+	if err != nil {
+		log.Printf("Error logging in user %q: %v", req.Username, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, tokens)
+}
+
+func (h *AuthHandlerImpl) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req userDomain.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	tokens, err := h.userService.Refresh(r.Context(), req)
+	if err != nil {
+		log.Printf("Error refreshing token: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, tokens)
+}
+
+func (h *AuthHandlerImpl) Logout(w http.ResponseWriter, r *http.Request) {
+	var req userDomain.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	if err := h.userService.Logout(r.Context(), req); err != nil {
+		log.Printf("Error logging out: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "Logged out successfully", nil)
+}
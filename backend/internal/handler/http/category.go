@@ -0,0 +1,131 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	categoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/category"
+	"github.com/naxumi/bnsp-jwd/internal/handler/http/response"
+)
+
+type CategoryHandler interface {
+	CreateCategory(w http.ResponseWriter, r *http.Request)
+	GetCategory(w http.ResponseWriter, r *http.Request)
+	ListCategories(w http.ResponseWriter, r *http.Request)
+	UpdateCategory(w http.ResponseWriter, r *http.Request)
+	DeleteCategory(w http.ResponseWriter, r *http.Request)
+}
+
+type CategoryHandlerImpl struct {
+	categoryService categoryDomain.CategoryService
+}
+
+func NewCategoryHandler(categoryService categoryDomain.CategoryService) CategoryHandler {
+	return &CategoryHandlerImpl{
+		categoryService: categoryService,
+	}
+}
+
+func (h *CategoryHandlerImpl) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryDomain.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	createdCategory, err := h.categoryService.CreateCategory(r.Context(), req)
+	if err != nil {
+		log.Printf("Error creating category: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, "Category created successfully", createdCategory)
+}
+
+func (h *CategoryHandlerImpl) GetCategory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid category ID", nil)
+		return
+	}
+
+	category, err := h.categoryService.GetCategory(r.Context(), id)
+	if err != nil {
+		log.Printf("Error getting category with ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, category)
+}
+
+func (h *CategoryHandlerImpl) ListCategories(w http.ResponseWriter, r *http.Request) {
+	// ?tree=true nests subcategories under their parents instead of a flat list
+	listCategories := h.categoryService.ListCategories
+	if r.URL.Query().Get("tree") == "true" {
+		listCategories = h.categoryService.GetCategoryTree
+	}
+
+	categories, err := listCategories(r.Context())
+	if err != nil {
+		log.Printf("Error listing categories: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, categories)
+}
+
+func (h *CategoryHandlerImpl) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryDomain.UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	err := h.categoryService.UpdateCategory(r.Context(), req)
+	if err != nil {
+		log.Printf("Error updating category: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "Category updated successfully", nil)
+}
+
+func (h *CategoryHandlerImpl) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid category ID", nil)
+		return
+	}
+
+	err = h.categoryService.DeleteCategory(r.Context(), id)
+	if err != nil {
+		log.Printf("Error deleting category with ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "Category deleted successfully", nil)
+}
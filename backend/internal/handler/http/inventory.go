@@ -0,0 +1,111 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
+	"github.com/naxumi/bnsp-jwd/internal/handler/http/response"
+)
+
+type InventoryHandler interface {
+	PostMovement(w http.ResponseWriter, r *http.Request)
+	ListMovements(w http.ResponseWriter, r *http.Request)
+}
+
+type InventoryHandlerImpl struct {
+	inventoryService inventoryDomain.InventoryService
+}
+
+func NewInventoryHandler(inventoryService inventoryDomain.InventoryService) InventoryHandler {
+	return &InventoryHandlerImpl{
+		inventoryService: inventoryService,
+	}
+}
+
+func (h *InventoryHandlerImpl) PostMovement(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	var req inventoryDomain.CreateStockMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+	req.ProductID = id
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	movement, err := h.inventoryService.PostMovement(r.Context(), req)
+	if err != nil {
+		log.Printf("Error posting stock movement for product ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, "Stock movement recorded successfully", movement)
+}
+
+func (h *InventoryHandlerImpl) ListMovements(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	filter := inventoryDomain.ListStockMovementFilter{ProductID: id}
+
+	// Parse query parameters
+	queryParams := r.URL.Query()
+
+	if warehouseID := queryParams.Get("warehouse_id"); warehouseID != "" {
+		if id, err := strconv.ParseInt(warehouseID, 10, 64); err == nil {
+			filter.WarehouseID = &id
+		}
+	}
+	if movementType := queryParams.Get("type"); movementType != "" {
+		t := inventoryDomain.MovementType(movementType)
+		filter.Type = &t
+	}
+
+	// Pagination
+	if page := queryParams.Get("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filter.Page = p
+		}
+	}
+	if limit := queryParams.Get("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filter.Limit = l
+		}
+	}
+
+	// Validate filter
+	if err := filter.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	movements, err := h.inventoryService.ListMovements(r.Context(), filter)
+	if err != nil {
+		log.Printf("Error listing stock movements for product ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, movements)
+}
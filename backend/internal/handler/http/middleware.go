@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	apikeyDomain "github.com/naxumi/bnsp-jwd/internal/domain/apikey"
+	tenantDomain "github.com/naxumi/bnsp-jwd/internal/domain/tenant"
+	"github.com/naxumi/bnsp-jwd/internal/handler/http/response"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+)
+
+// RequestContext stores the request ID, set by chi's RequestID middleware,
+// in the request context for the audit trail. The request ID is echoed back
+// so a client can quote it.
+func RequestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if requestID := chiMiddleware.GetReqID(ctx); requestID != "" {
+			ctx = requestctx.WithRequestID(ctx, requestID)
+			w.Header().Set(chiMiddleware.RequestIDHeader, requestID)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// TenantHeader names the tenant of an anonymous request
+const TenantHeader = "X-Tenant-ID"
+
+// ResolveTenant returns a middleware that scopes the request to the tenant
+// named by the X-Tenant-ID header, or to defaultTenantID when it has none.
+// Without either the request is answered with 400. Authenticate later
+// replaces the tenant with the one of the caller's credentials.
+func ResolveTenant(defaultTenantID int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID := defaultTenantID
+			if header := r.Header.Get(TenantHeader); header != "" {
+				id, err := strconv.ParseInt(header, 10, 64)
+				if err != nil || id <= 0 {
+					response.BadRequest(w, "Invalid X-Tenant-ID header", nil)
+					return
+				}
+				tenantID = id
+			}
+			if tenantID == 0 {
+				response.HandleError(w, tenantDomain.ErrTenantRequired)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(requestctx.WithTenantID(r.Context(), tenantID)))
+		})
+	}
+}
+
+// TokenVerifier checks a Bearer token and returns its claims
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (auth.Claims, error)
+}
+
+// APIKeyAuthenticator checks an API key and returns the claims it grants
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (auth.Claims, error)
+}
+
+// Authenticate returns a middleware that answers requests without a valid
+// Bearer token or X-API-Key with 401. An API key is used when the header is
+// present. The accepted claims are stored in the request context, their
+// subject becomes the request's actor and their tenant the request's tenant.
+// Naming another tenant in X-Tenant-ID is answered with 403.
+func Authenticate(verifier TokenVerifier, keys APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key := r.Header.Get("X-API-Key"); key != "" {
+				claims, err := keys.Authenticate(r.Context(), key)
+				if err != nil {
+					log.Printf("Rejected api key: %v", err)
+					if errors.Is(err, apikeyDomain.ErrInvalidAPIKey) {
+						response.Unauthorized(w, "Invalid or revoked API key")
+					} else {
+						response.HandleError(w, err)
+					}
+					return
+				}
+
+				serveAuthenticated(w, r, next, claims)
+				return
+			}
+
+			token := bearerToken(r)
+			if token == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				response.Unauthorized(w, "Missing bearer token")
+				return
+			}
+
+			claims, err := verifier.Verify(r.Context(), token)
+			if err != nil {
+				log.Printf("Rejected bearer token: %v", err)
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				response.Unauthorized(w, "Invalid or expired token")
+				return
+			}
+
+			serveAuthenticated(w, r, next, claims)
+		})
+	}
+}
+
+func serveAuthenticated(w http.ResponseWriter, r *http.Request, next http.Handler, claims auth.Claims) {
+	if header := r.Header.Get(TenantHeader); header != "" {
+		if id, err := strconv.ParseInt(header, 10, 64); err != nil || id != claims.TenantID {
+			log.Printf("Rejected %s for tenant %s: credentials belong to tenant %d", claims.Subject, header, claims.TenantID)
+			response.HandleError(w, tenantDomain.ErrTenantMismatch)
+			return
+		}
+	}
+
+	ctx := auth.NewContext(r.Context(), claims)
+	ctx = requestctx.WithActor(ctx, claims.Subject)
+	ctx = requestctx.WithTenantID(ctx, claims.TenantID)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequirePermission returns a middleware that answers with 403 unless the
+// authenticated caller has the permission. It runs after Authenticate.
+func RequirePermission(permission auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := auth.Require(r.Context(), permission); err != nil {
+				response.HandleError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header, or ""
+func bearerToken(r *http.Request) string {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,232 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apikeyDomain "github.com/naxumi/bnsp-jwd/internal/domain/apikey"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Token Verifier
+type MockTokenVerifier struct {
+	mock.Mock
+}
+
+func (m *MockTokenVerifier) Verify(ctx context.Context, token string) (auth.Claims, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(auth.Claims), args.Error(1)
+}
+
+// Mock API Key Authenticator
+type MockAPIKeyAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyAuthenticator) Authenticate(ctx context.Context, key string) (auth.Claims, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(auth.Claims), args.Error(1)
+}
+
+func TestAuthenticate_ValidToken(t *testing.T) {
+	verifier := new(MockTokenVerifier)
+	verifier.On("Verify", mock.Anything, "good-token").
+		Return(auth.Claims{Subject: "alice"}, nil)
+
+	var actor, subject string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor = requestctx.Actor(r.Context())
+		claims, _ := auth.FromContext(r.Context())
+		subject = claims.Subject
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/product/1", nil)
+	req.Header.Set("Authorization", "Bearer good-token")
+	w := httptest.NewRecorder()
+
+	RequestContext(Authenticate(verifier, new(MockAPIKeyAuthenticator))(next)).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "alice", subject)
+	assert.Equal(t, "alice", actor)
+}
+
+func TestAuthenticate_MissingToken(t *testing.T) {
+	verifier := new(MockTokenVerifier)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not run without a token")
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/product/1", nil)
+	w := httptest.NewRecorder()
+
+	Authenticate(verifier, new(MockAPIKeyAuthenticator))(next).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	verifier.AssertNotCalled(t, "Verify")
+}
+
+func TestAuthenticate_InvalidToken(t *testing.T) {
+	verifier := new(MockTokenVerifier)
+	verifier.On("Verify", mock.Anything, "expired-token").
+		Return(auth.Claims{}, auth.ErrInvalidToken)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not run with an invalid token")
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/product/1", nil)
+	req.Header.Set("Authorization", "bearer expired-token")
+	w := httptest.NewRecorder()
+
+	Authenticate(verifier, new(MockAPIKeyAuthenticator))(next).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "invalid_token")
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	verifier := new(MockTokenVerifier)
+	keys := new(MockAPIKeyAuthenticator)
+	keys.On("Authenticate", mock.Anything, "bnsp_1a2b3c4d_secret").
+		Return(auth.Claims{Subject: "apikey:bnsp_1a2b3c4d", Scopes: []auth.Permission{auth.PermProductWrite}}, nil)
+
+	var actor string
+	var canWrite bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor = requestctx.Actor(r.Context())
+		canWrite = auth.Require(r.Context(), auth.PermProductWrite) == nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/1/stock-movements", nil)
+	req.Header.Set("X-API-Key", "bnsp_1a2b3c4d_secret")
+	w := httptest.NewRecorder()
+
+	Authenticate(verifier, keys)(next).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "apikey:bnsp_1a2b3c4d", actor)
+	assert.True(t, canWrite)
+	verifier.AssertNotCalled(t, "Verify")
+}
+
+func TestAuthenticate_RevokedAPIKey(t *testing.T) {
+	keys := new(MockAPIKeyAuthenticator)
+	keys.On("Authenticate", mock.Anything, "bnsp_1a2b3c4d_revoked").
+		Return(auth.Claims{}, apikeyDomain.ErrInvalidAPIKey)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not run with a revoked key")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/1/stock-movements", nil)
+	req.Header.Set("X-API-Key", "bnsp_1a2b3c4d_revoked")
+	w := httptest.NewRecorder()
+
+	Authenticate(new(MockTokenVerifier), keys)(next).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthenticate_TenantFromCredentials(t *testing.T) {
+	verifier := new(MockTokenVerifier)
+	verifier.On("Verify", mock.Anything, "good-token").
+		Return(auth.Claims{Subject: "alice", TenantID: 2}, nil)
+
+	var tenantID int64
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, _ = requestctx.TenantID(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/product/1", nil)
+	req.Header.Set("Authorization", "Bearer good-token")
+	w := httptest.NewRecorder()
+
+	// The default tenant of the anonymous request is replaced by the token's
+	ResolveTenant(1)(Authenticate(verifier, new(MockAPIKeyAuthenticator))(next)).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(2), tenantID)
+}
+
+func TestAuthenticate_TenantMismatch(t *testing.T) {
+	keys := new(MockAPIKeyAuthenticator)
+	keys.On("Authenticate", mock.Anything, "bnsp_1a2b3c4d_secret").
+		Return(auth.Claims{Subject: "apikey:bnsp_1a2b3c4d", TenantID: 2}, nil)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not run for another tenant")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/1/stock-movements", nil)
+	req.Header.Set("X-API-Key", "bnsp_1a2b3c4d_secret")
+	req.Header.Set("X-Tenant-ID", "3")
+	w := httptest.NewRecorder()
+
+	ResolveTenant(1)(Authenticate(new(MockTokenVerifier), keys)(next)).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestResolveTenant(t *testing.T) {
+	tests := map[string]struct {
+		defaultTenantID int64
+		header          string
+		code            int
+		tenantID        int64
+	}{
+		"header":                {defaultTenantID: 1, header: "4", code: http.StatusOK, tenantID: 4},
+		"default":               {defaultTenantID: 1, code: http.StatusOK, tenantID: 1},
+		"invalid header":        {defaultTenantID: 1, header: "acme", code: http.StatusBadRequest},
+		"negative header":       {defaultTenantID: 1, header: "-4", code: http.StatusBadRequest},
+		"no header, no default": {code: http.StatusBadRequest},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var tenantID int64
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tenantID, _ = requestctx.TenantID(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/product", nil)
+			if tc.header != "" {
+				req.Header.Set("X-Tenant-ID", tc.header)
+			}
+			w := httptest.NewRecorder()
+
+			ResolveTenant(tc.defaultTenantID)(next).ServeHTTP(w, req)
+
+			assert.Equal(t, tc.code, w.Code)
+			assert.Equal(t, tc.tenantID, tenantID)
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := RequirePermission(auth.PermProductWrite)(next)
+
+	tests := map[string]struct {
+		role string
+		code int
+	}{
+		"allowed":   {role: auth.RoleStaff, code: http.StatusOK},
+		"forbidden": {role: auth.RoleViewer, code: http.StatusForbidden},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/product/1/stock-movements", nil)
+			ctx := auth.NewContext(req.Context(), auth.Claims{Subject: "alice", All: map[string]any{"role": tc.role}})
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req.WithContext(ctx))
+
+			assert.Equal(t, tc.code, w.Code)
+		})
+	}
+}
@@ -2,13 +2,19 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
 	"github.com/naxumi/bnsp-jwd/internal/handler/http/response"
+	"github.com/shopspring/decimal"
 )
 
 type ProductHandler interface {
@@ -17,9 +23,25 @@ type ProductHandler interface {
 	GetProductBySKU(w http.ResponseWriter, r *http.Request)
 	UpdateProduct(w http.ResponseWriter, r *http.Request)
 	DeleteProduct(w http.ResponseWriter, r *http.Request)
+	ListTrash(w http.ResponseWriter, r *http.Request)
+	RestoreProduct(w http.ResponseWriter, r *http.Request)
+	PurgeTrash(w http.ResponseWriter, r *http.Request)
+	ListRevisions(w http.ResponseWriter, r *http.Request)
+	GetRevision(w http.ResponseWriter, r *http.Request)
+	RestoreRevision(w http.ResponseWriter, r *http.Request)
+	BulkProducts(w http.ResponseWriter, r *http.Request)
+	ImportProducts(w http.ResponseWriter, r *http.Request)
+	GetImportJob(w http.ResponseWriter, r *http.Request)
 	ListProducts(w http.ResponseWriter, r *http.Request)
+	ExportProducts(w http.ResponseWriter, r *http.Request)
+	SuggestProducts(w http.ResponseWriter, r *http.Request)
 	UploadImage(w http.ResponseWriter, r *http.Request)
 	DeleteImage(w http.ResponseWriter, r *http.Request)
+	AddGalleryImage(w http.ResponseWriter, r *http.Request)
+	UpdateGalleryImage(w http.ResponseWriter, r *http.Request)
+	SetPrimaryImage(w http.ResponseWriter, r *http.Request)
+	ReorderGallery(w http.ResponseWriter, r *http.Request)
+	DeleteGalleryImage(w http.ResponseWriter, r *http.Request)
 }
 
 type ProductHandlerImpl struct {
@@ -86,6 +108,164 @@ func (h *ProductHandlerImpl) DeleteImage(w http.ResponseWriter, r *http.Request)
 	response.SuccessWithMessage(w, "Image deleted successfully", nil)
 }
 
+func (h *ProductHandlerImpl) AddGalleryImage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	// Parse multipart form (max 10MB)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		log.Printf("Failed to parse multipart form: %v", err)
+		response.BadRequest(w, "Failed to parse form", nil)
+		return
+	}
+
+	file, fileHeader, err := r.FormFile("image")
+	if err != nil {
+		log.Printf("Failed to get file from form: %v", err)
+		response.BadRequest(w, "Image file is required", nil)
+		return
+	}
+	defer file.Close()
+
+	req := productDomain.AddProductImageRequest{
+		ProductID:  id,
+		File:       file,
+		FileHeader: fileHeader,
+	}
+	if altText, ok := r.MultipartForm.Value["alt_text"]; ok && len(altText) > 0 {
+		req.AltText = &altText[0]
+	}
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	image, err := h.productService.AddGalleryImage(r.Context(), req)
+	if err != nil {
+		log.Printf("Error adding gallery image for product ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, "Image uploaded successfully", image)
+}
+
+func (h *ProductHandlerImpl) UpdateGalleryImage(w http.ResponseWriter, r *http.Request) {
+	id, imageID, ok := parseImageParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req productDomain.UpdateProductImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+	req.ProductID = id
+	req.ImageID = imageID
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	image, err := h.productService.UpdateGalleryImage(r.Context(), req)
+	if err != nil {
+		log.Printf("Error updating image %d of product ID %d: %v", imageID, id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "Image updated successfully", image)
+}
+
+func (h *ProductHandlerImpl) SetPrimaryImage(w http.ResponseWriter, r *http.Request) {
+	id, imageID, ok := parseImageParams(w, r)
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.SetPrimaryImage(r.Context(), id, imageID)
+	if err != nil {
+		log.Printf("Error setting primary image %d of product ID %d: %v", imageID, id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", productETag(product.Version))
+	response.SuccessWithMessage(w, "Primary image updated successfully", product)
+}
+
+func (h *ProductHandlerImpl) ReorderGallery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	var req productDomain.ReorderProductImagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+	req.ProductID = id
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	gallery, err := h.productService.ReorderGallery(r.Context(), req)
+	if err != nil {
+		log.Printf("Error reordering images of product ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "Images reordered successfully", gallery)
+}
+
+func (h *ProductHandlerImpl) DeleteGalleryImage(w http.ResponseWriter, r *http.Request) {
+	id, imageID, ok := parseImageParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.productService.DeleteGalleryImage(r.Context(), id, imageID); err != nil {
+		log.Printf("Error deleting image %d of product ID %d: %v", imageID, id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "Image deleted successfully", nil)
+}
+
+// parseImageParams reads the product and gallery image IDs from the URL,
+// answering 400 when either is not an integer
+func parseImageParams(w http.ResponseWriter, r *http.Request) (id, imageID int64, ok bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return 0, 0, false
+	}
+
+	imageID, err = strconv.ParseInt(chi.URLParam(r, "imageID"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid image ID", nil)
+		return 0, 0, false
+	}
+
+	return id, imageID, true
+}
+
 func (h *ProductHandlerImpl) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	var req productDomain.CreateProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -125,6 +305,7 @@ func (h *ProductHandlerImpl) GetProduct(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	w.Header().Set("ETag", productETag(product.Version))
 	response.Success(w, product)
 }
 
@@ -158,8 +339,13 @@ func (h *ProductHandlerImpl) UpdateProduct(w http.ResponseWriter, r *http.Reques
 		response.HandleError(w, err)
 		return
 	}
+	req.ExpectedVersion = parseIfMatch(r)
 
 	err := h.productService.UpdateProduct(r.Context(), req)
+	if errors.Is(err, productDomain.ErrVersionConflict) {
+		h.versionConflict(w, r, req.ID)
+		return
+	}
 	if err != nil {
 		log.Printf("Error updating product: %v", err)
 		response.HandleError(w, err)
@@ -177,32 +363,412 @@ func (h *ProductHandlerImpl) DeleteProduct(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err = h.productService.DeleteProduct(r.Context(), id)
+	err = h.productService.DeleteProduct(r.Context(), id, parseIfMatch(r))
+	if errors.Is(err, productDomain.ErrVersionConflict) {
+		h.versionConflict(w, r, id)
+		return
+	}
 	if err != nil {
 		log.Printf("Error deleting product with ID %d: %v", id, err)
 		response.HandleError(w, err)
 		return
 	}
 
-	response.SuccessWithMessage(w, "Product deleted successfully", nil)
+	response.SuccessWithMessage(w, "Product moved to trash", nil)
+}
+
+func (h *ProductHandlerImpl) BulkProducts(w http.ResponseWriter, r *http.Request) {
+	var req productDomain.BulkProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.productService.BulkProducts(r.Context(), req)
+	if err != nil {
+		log.Printf("Error running bulk product operations: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	message := "Bulk operation completed"
+	switch {
+	case !result.Committed:
+		message = "Bulk operation rolled back, no changes were applied"
+	case result.Failed > 0:
+		message = fmt.Sprintf("Bulk operation completed with %d failed operations", result.Failed)
+	}
+	response.SuccessWithMessage(w, message, result)
+}
+
+func (h *ProductHandlerImpl) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		// A misspelled flag must not turn a dry run into a real import
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.BadRequest(w, "Invalid dry_run value, expected true or false", nil)
+			return
+		}
+		dryRun = parsed
+	}
+
+	// Parse multipart form (max 32MB)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		log.Printf("Failed to parse multipart form: %v", err)
+		response.BadRequest(w, "Failed to parse form", nil)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		log.Printf("Failed to get file from form: %v", err)
+		response.HandleError(w, productDomain.ErrImportFileRequired)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.productService.ImportProducts(r.Context(), productDomain.ImportProductsRequest{
+		File:   file,
+		DryRun: dryRun,
+	})
+	if err != nil {
+		log.Printf("Error importing products: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	switch {
+	case result.JobID != "":
+		w.Header().Set("Location", "/api/v1/product/import/"+result.JobID)
+		response.Accepted(w, "Import started, poll the job for progress", result)
+	case result.DryRun:
+		response.SuccessWithMessage(w, fmt.Sprintf("Dry run completed, %d of %d rows are valid", result.Valid, result.TotalRows), result)
+	case result.Failed > 0:
+		response.SuccessWithMessage(w, fmt.Sprintf("Import completed with %d failed rows", result.Failed), result)
+	default:
+		response.SuccessWithMessage(w, "Import completed", result)
+	}
+}
+
+func (h *ProductHandlerImpl) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
+
+	job, err := h.productService.GetImportJob(r.Context(), jobID)
+	if err != nil {
+		log.Printf("Error getting import job %s: %v", jobID, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, job)
 }
 
 func (h *ProductHandlerImpl) ListProducts(w http.ResponseWriter, r *http.Request) {
-	var filter productDomain.ListProductFilter
+	// Parse query parameters
+	queryParams := r.URL.Query()
+	filter := parseProductFilter(queryParams)
+	parseProductPagination(queryParams, &filter)
+
+	// Facets
+	if facets := queryParams.Get("facets"); facets != "" {
+		for _, facet := range strings.Split(facets, ",") {
+			filter.Facets = append(filter.Facets, strings.TrimSpace(facet))
+		}
+	}
+	if priceRanges := queryParams.Get("price_ranges"); priceRanges != "" {
+		for _, boundary := range strings.Split(priceRanges, ",") {
+			d, err := decimal.NewFromString(strings.TrimSpace(boundary))
+			if err != nil {
+				response.BadRequest(w, "Invalid price_ranges, expected comma-separated numbers", nil)
+				return
+			}
+			filter.PriceRanges = append(filter.PriceRanges, d)
+		}
+	}
 
+	// Validate filter
+	if err := filter.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	products, err := h.productService.ListProducts(r.Context(), filter)
+	if err != nil {
+		log.Printf("Error listing products: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, products)
+}
+
+func (h *ProductHandlerImpl) ListTrash(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	queryParams := r.URL.Query()
+	filter := parseProductFilter(queryParams)
+	parseProductPagination(queryParams, &filter)
+	filter.Trashed = true
+
+	// Validate filter
+	if err := filter.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	products, err := h.productService.ListTrash(r.Context(), filter)
+	if err != nil {
+		log.Printf("Error listing trashed products: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, products)
+}
+
+func (h *ProductHandlerImpl) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	product, err := h.productService.RestoreProduct(r.Context(), id)
+	if err != nil {
+		log.Printf("Error restoring product with ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", productETag(product.Version))
+	response.SuccessWithMessage(w, "Product restored successfully", product)
+}
+
+func (h *ProductHandlerImpl) PurgeTrash(w http.ResponseWriter, r *http.Request) {
+	result, err := h.productService.PurgeTrash(r.Context())
+	if err != nil {
+		log.Printf("Error purging product trash: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, fmt.Sprintf("Purged %d products from the trash", result.Purged), result)
+}
+
+func (h *ProductHandlerImpl) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	filter := productDomain.ListProductRevisionFilter{ProductID: id}
+
+	// Pagination
+	queryParams := r.URL.Query()
+	if page := queryParams.Get("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filter.Page = p
+		}
+	}
+	if limit := queryParams.Get("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filter.Limit = l
+		}
+	}
+
+	// Validate filter
+	if err := filter.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	revisions, err := h.productService.ListRevisions(r.Context(), filter)
+	if err != nil {
+		log.Printf("Error listing revisions of product with ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, revisions)
+}
+
+func (h *ProductHandlerImpl) GetRevision(w http.ResponseWriter, r *http.Request) {
+	id, revision, ok := parseRevisionParams(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.productService.GetRevision(r.Context(), id, revision)
+	if err != nil {
+		log.Printf("Error getting revision %d of product with ID %d: %v", revision, id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, result)
+}
+
+func (h *ProductHandlerImpl) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	id, revision, ok := parseRevisionParams(w, r)
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.RestoreRevision(r.Context(), id, revision, parseIfMatch(r))
+	if errors.Is(err, productDomain.ErrVersionConflict) {
+		h.versionConflict(w, r, id)
+		return
+	}
+	if err != nil {
+		log.Printf("Error restoring revision %d of product with ID %d: %v", revision, id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", productETag(product.Version))
+	response.SuccessWithMessage(w, fmt.Sprintf("Product restored to revision %d", revision), product)
+}
+
+// parseRevisionParams reads the product ID and revision number from the URL,
+// answering 400 when either is not a positive integer
+func parseRevisionParams(w http.ResponseWriter, r *http.Request) (id int64, revision int, ok bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return 0, 0, false
+	}
+
+	revision, err = strconv.Atoi(chi.URLParam(r, "rev"))
+	if err != nil || revision <= 0 {
+		response.BadRequest(w, "Invalid revision number", nil)
+		return 0, 0, false
+	}
+
+	return id, revision, true
+}
+
+// exportContentTypes maps each export format to its media type
+var exportContentTypes = map[productDomain.ExportFormat]string{
+	productDomain.ExportFormatCSV:   "text/csv; charset=utf-8",
+	productDomain.ExportFormatXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	productDomain.ExportFormatJSONL: "application/x-ndjson",
+}
+
+func (h *ProductHandlerImpl) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	// Parse query parameters
+	queryParams := r.URL.Query()
+	filter := productDomain.ExportProductFilter{
+		ListProductFilter: parseProductFilter(queryParams),
+		Format:            productDomain.ExportFormat(queryParams.Get("format")),
+		Delimiter:         queryParams.Get("delimiter"),
+		DecimalSeparator:  queryParams.Get("decimal_separator"),
+	}
+
+	// Validate filter
+	if err := filter.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	out := &exportWriter{
+		w:           w,
+		contentType: exportContentTypes[filter.Format],
+		filename:    fmt.Sprintf("products-%s.%s", time.Now().Format("20060102-150405"), filter.Format),
+	}
+	if err := h.productService.ExportProducts(r.Context(), filter, out); err != nil {
+		log.Printf("Error exporting products: %v", err)
+		if !out.started {
+			response.HandleError(w, err)
+			return
+		}
+		// Part of the file is already sent; abort the connection so the
+		// client sees a broken download rather than a truncated file
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportWriter sends the download headers along with the first bytes of an
+// export, so an export that fails before writing can still answer with JSON
+type exportWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (e *exportWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.w.Header().Set("Content-Type", e.contentType)
+		e.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, e.filename))
+	}
+	return e.w.Write(p)
+}
+
+// parseProductPagination reads the page, limit, cursor and count parameters
+// of a product list into filter
+func parseProductPagination(queryParams url.Values, filter *productDomain.ListProductFilter) {
+	if page := queryParams.Get("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filter.Page = p
+		}
+	}
+	if limit := queryParams.Get("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filter.Limit = l
+		}
+	}
+	if cursor := queryParams.Get("cursor"); cursor != "" {
+		filter.Cursor = &cursor
+	}
+	if queryParams.Get("count") == "false" {
+		filter.SkipCount = true
+	}
+}
+
+// parseProductFilter reads the search, filter and sort parameters shared by
+// the product list and export
+func parseProductFilter(queryParams url.Values) productDomain.ListProductFilter {
+	var filter productDomain.ListProductFilter
 
 	// Search & Filter
+	if q := queryParams.Get("q"); q != "" {
+		filter.Q = &q
+	}
 	if name := queryParams.Get("name"); name != "" {
 		filter.Name = &name
 	}
 	if sku := queryParams.Get("sku"); sku != "" {
 		filter.SKU = &sku
 	}
+	if queryParams.Get("fuzzy") == "true" {
+		filter.Fuzzy = true
+	}
 	if category := queryParams.Get("category"); category != "" {
 		filter.Category = &category
 	}
+	if categoryID := queryParams.Get("category_id"); categoryID != "" {
+		if id, err := strconv.ParseInt(categoryID, 10, 64); err == nil {
+			filter.CategoryID = &id
+		}
+	}
+	if queryParams.Get("include_descendants") == "true" {
+		filter.IncludeDescendants = true
+	}
 	if status := queryParams.Get("status"); status != "" {
 		productStatus := productDomain.ProductStatus(status)
 		filter.Status = &productStatus
@@ -220,15 +786,15 @@ func (h *ProductHandlerImpl) ListProducts(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	// Pagination
-	if page := queryParams.Get("page"); page != "" {
-		if p, err := strconv.Atoi(page); err == nil {
-			filter.Page = p
+	// Warehouse
+	if warehouseID := queryParams.Get("warehouse_id"); warehouseID != "" {
+		if id, err := strconv.ParseInt(warehouseID, 10, 64); err == nil {
+			filter.WarehouseID = &id
 		}
 	}
-	if limit := queryParams.Get("limit"); limit != "" {
-		if l, err := strconv.Atoi(limit); err == nil {
-			filter.Limit = l
+	if inStockAt := queryParams.Get("in_stock_at"); inStockAt != "" {
+		if id, err := strconv.ParseInt(inStockAt, 10, 64); err == nil {
+			filter.InStockAt = &id
 		}
 	}
 
@@ -240,19 +806,68 @@ func (h *ProductHandlerImpl) ListProducts(w http.ResponseWriter, r *http.Request
 		filter.SortOrder = sortOrder
 	}
 
-	// Validate filter
+	return filter
+}
+
+func (h *ProductHandlerImpl) SuggestProducts(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	filter := productDomain.SuggestProductFilter{Q: queryParams.Get("q")}
+	if limit := queryParams.Get("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filter.Limit = l
+		}
+	}
+
 	if err := filter.Validate(); err != nil {
 		log.Printf("Validation error: %v", err)
 		response.HandleError(w, err)
 		return
 	}
 
-	products, err := h.productService.ListProducts(r.Context(), filter)
+	suggestions, err := h.productService.SuggestProducts(r.Context(), filter)
 	if err != nil {
-		log.Printf("Error listing products: %v", err)
+		log.Printf("Error suggesting products for %q: %v", filter.Q, err)
 		response.HandleError(w, err)
 		return
 	}
 
-	response.Success(w, products)
+	response.Success(w, suggestions)
+}
+
+// versionConflict answers a stale If-Match with 412 and the product as it is now
+func (h *ProductHandlerImpl) versionConflict(w http.ResponseWriter, r *http.Request, id int64) {
+	product, err := h.productService.GetProduct(r.Context(), id)
+	if err != nil {
+		log.Printf("Error getting product with ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", productETag(product.Version))
+	response.PreconditionFailed(w, "Product was modified by another request", product)
+}
+
+// productETag formats a product version as a strong entity tag
+func productETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// parseIfMatch returns the version named by the first tag of the If-Match
+// header. It is nil without the header or for "*", which matches any version.
+// Weak or malformed tags can never match, so they yield version 0.
+func parseIfMatch(r *http.Request) *int {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	tag := strings.TrimSpace(strings.Split(header, ",")[0])
+	version := 0
+	if len(tag) > 2 && strings.HasPrefix(tag, `"`) && strings.HasSuffix(tag, `"`) {
+		if v, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil && v > 0 {
+			version = v
+		}
+	}
+	return &version
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -44,16 +45,71 @@ func (m *MockProductService) UpdateProduct(ctx context.Context, req productDomai
 	return args.Error(0)
 }
 
-func (m *MockProductService) DeleteProduct(ctx context.Context, id int64) error {
-	args := m.Called(ctx, id)
+func (m *MockProductService) DeleteProduct(ctx context.Context, id int64, expectedVersion *int) error {
+	args := m.Called(ctx, id, expectedVersion)
 	return args.Error(0)
 }
 
+func (m *MockProductService) ListTrash(ctx context.Context, filter productDomain.ListProductFilter) (productDomain.ListProductResponse, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(productDomain.ListProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) RestoreProduct(ctx context.Context, id int64) (productDomain.ProductResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(productDomain.ProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) ListRevisions(ctx context.Context, filter productDomain.ListProductRevisionFilter) (productDomain.ListProductRevisionResponse, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(productDomain.ListProductRevisionResponse), args.Error(1)
+}
+
+func (m *MockProductService) GetRevision(ctx context.Context, id int64, revision int) (productDomain.ProductRevisionResponse, error) {
+	args := m.Called(ctx, id, revision)
+	return args.Get(0).(productDomain.ProductRevisionResponse), args.Error(1)
+}
+
+func (m *MockProductService) RestoreRevision(ctx context.Context, id int64, revision int, expectedVersion *int) (productDomain.ProductResponse, error) {
+	args := m.Called(ctx, id, revision, expectedVersion)
+	return args.Get(0).(productDomain.ProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) PurgeTrash(ctx context.Context) (productDomain.PurgeTrashResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(productDomain.PurgeTrashResponse), args.Error(1)
+}
+
 func (m *MockProductService) ListProducts(ctx context.Context, filter productDomain.ListProductFilter) (productDomain.ListProductResponse, error) {
 	args := m.Called(ctx, filter)
 	return args.Get(0).(productDomain.ListProductResponse), args.Error(1)
 }
 
+func (m *MockProductService) BulkProducts(ctx context.Context, req productDomain.BulkProductRequest) (productDomain.BulkProductResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(productDomain.BulkProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) ImportProducts(ctx context.Context, req productDomain.ImportProductsRequest) (productDomain.ImportJobResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(productDomain.ImportJobResponse), args.Error(1)
+}
+
+func (m *MockProductService) GetImportJob(ctx context.Context, id string) (productDomain.ImportJobResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(productDomain.ImportJobResponse), args.Error(1)
+}
+
+func (m *MockProductService) ExportProducts(ctx context.Context, filter productDomain.ExportProductFilter, w io.Writer) error {
+	args := m.Called(ctx, filter, w)
+	return args.Error(0)
+}
+
+func (m *MockProductService) SuggestProducts(ctx context.Context, filter productDomain.SuggestProductFilter) ([]productDomain.ProductSuggestionResponse, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]productDomain.ProductSuggestionResponse), args.Error(1)
+}
+
 func (m *MockProductService) UploadImage(ctx context.Context, id int64, file multipart.File, fileHeader *multipart.FileHeader) error {
 	args := m.Called(ctx, id, file, fileHeader)
 	return args.Error(0)
@@ -64,6 +120,31 @@ func (m *MockProductService) DeleteImage(ctx context.Context, id int64) error {
 	return args.Error(0)
 }
 
+func (m *MockProductService) AddGalleryImage(ctx context.Context, req productDomain.AddProductImageRequest) (productDomain.ProductImageResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(productDomain.ProductImageResponse), args.Error(1)
+}
+
+func (m *MockProductService) UpdateGalleryImage(ctx context.Context, req productDomain.UpdateProductImageRequest) (productDomain.ProductImageResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(productDomain.ProductImageResponse), args.Error(1)
+}
+
+func (m *MockProductService) SetPrimaryImage(ctx context.Context, id, imageID int64) (productDomain.ProductResponse, error) {
+	args := m.Called(ctx, id, imageID)
+	return args.Get(0).(productDomain.ProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) ReorderGallery(ctx context.Context, req productDomain.ReorderProductImagesRequest) ([]productDomain.ProductImageResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]productDomain.ProductImageResponse), args.Error(1)
+}
+
+func (m *MockProductService) DeleteGalleryImage(ctx context.Context, id, imageID int64) error {
+	args := m.Called(ctx, id, imageID)
+	return args.Error(0)
+}
+
 // Tests for CreateProduct Handler
 func TestProductHandler_CreateProduct_Success(t *testing.T) {
 	mockService := new(MockProductService)
@@ -196,6 +277,7 @@ func TestProductHandler_GetProduct_Success(t *testing.T) {
 		Stock:     100,
 		Category:  "Electronics",
 		Status:    productDomain.ProductStatusActive,
+		Version:   2,
 		CreatedAt: time.Now().Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt: time.Now().Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -212,6 +294,7 @@ func TestProductHandler_GetProduct_Success(t *testing.T) {
 	handler.GetProduct(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
 
 	var response map[string]interface{}
 	json.NewDecoder(w.Body).Decode(&response)
@@ -395,12 +478,72 @@ func TestProductHandler_UpdateProduct_NotFound(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestProductHandler_UpdateProduct_StaleIfMatch(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	name := "Updated Product"
+	reqBody := productDomain.UpdateProductRequest{
+		ID:   1,
+		Name: &name,
+	}
+
+	mockService.On("UpdateProduct", mock.Anything, mock.MatchedBy(func(r productDomain.UpdateProductRequest) bool {
+		return r.ExpectedVersion != nil && *r.ExpectedVersion == 3
+	})).Return(productDomain.ErrVersionConflict)
+	mockService.On("GetProduct", mock.Anything, int64(1)).
+		Return(productDomain.ProductResponse{ID: 1, Name: "Renamed Elsewhere", Version: 4}, nil)
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/product", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"3"`)
+	w := httptest.NewRecorder()
+
+	handler.UpdateProduct(w, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+
+	var response map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&response)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "Renamed Elsewhere", data["name"])
+	mockService.AssertExpectations(t)
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   *int
+	}{
+		{"", nil},
+		{"*", nil},
+		{`"7"`, intPtr(7)},
+		{`"7", "8"`, intPtr(7)},
+		{`W/"7"`, intPtr(0)},
+		{"7", intPtr(0)},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/product", nil)
+		if tt.header != "" {
+			req.Header.Set("If-Match", tt.header)
+		}
+		assert.Equal(t, tt.want, parseIfMatch(req), "If-Match: %s", tt.header)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
 // Tests for DeleteProduct Handler
 func TestProductHandler_DeleteProduct_Success(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := &ProductHandlerImpl{productService: mockService}
 
-	mockService.On("DeleteProduct", mock.Anything, int64(1)).
+	mockService.On("DeleteProduct", mock.Anything, int64(1), (*int)(nil)).
 		Return(nil)
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/v1/product/1", nil)
@@ -439,7 +582,7 @@ func TestProductHandler_DeleteProduct_NotFound(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := &ProductHandlerImpl{productService: mockService}
 
-	mockService.On("DeleteProduct", mock.Anything, int64(999)).
+	mockService.On("DeleteProduct", mock.Anything, int64(999), (*int)(nil)).
 		Return(productDomain.ErrProductNotFound)
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/v1/product/999", nil)
@@ -454,16 +597,309 @@ func TestProductHandler_DeleteProduct_NotFound(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+// Tests for the trash Handlers
+func TestProductHandler_ListTrash_DefaultsToDeletedAt(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("ListTrash", mock.Anything, mock.MatchedBy(func(f productDomain.ListProductFilter) bool {
+		return f.Trashed && f.SortBy == "deleted_at" && f.SortOrder == "desc" && f.Limit == 5
+	})).Return(productDomain.ListProductResponse{Page: 1, Limit: 5}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product/trash?limit=5", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListTrash(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_RestoreProduct_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("RestoreProduct", mock.Anything, int64(4)).
+		Return(productDomain.ProductResponse{ID: 4, SKU: "OLD-001", Version: 3}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/4/restore", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "4")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.RestoreProduct(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_RestoreProduct_NotInTrash(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("RestoreProduct", mock.Anything, int64(4)).
+		Return(productDomain.ProductResponse{}, productDomain.ErrProductNotInTrash)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/4/restore", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "4")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.RestoreProduct(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProductHandler_PurgeTrash_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("PurgeTrash", mock.Anything).
+		Return(productDomain.PurgeTrashResponse{Purged: 2, DeletedBefore: "2026-09-16T10:00:00Z"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/trash/purge", nil)
+	w := httptest.NewRecorder()
+
+	handler.PurgeTrash(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&response)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(2), data["purged"])
+}
+
+// Tests for the revision handlers
+func TestProductHandler_RestoreRevision_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	version := 6
+	mockService.On("RestoreRevision", mock.Anything, int64(21), 3, &version).
+		Return(productDomain.ProductResponse{ID: 21, SKU: "SKU-021", Version: 7}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/21/revisions/3/restore", nil)
+	req.Header.Set("If-Match", `"6"`)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "21")
+	rctx.URLParams.Add("rev", "3")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.RestoreRevision(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"7"`, w.Header().Get("ETag"))
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetRevision_InvalidRevision(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product/21/revisions/latest", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "21")
+	rctx.URLParams.Add("rev", "latest")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.GetRevision(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetRevision")
+}
+
+func TestProductHandler_GetRevision_NotFound(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("GetRevision", mock.Anything, int64(21), 99).
+		Return(productDomain.ProductRevisionResponse{}, productDomain.ErrRevisionNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product/21/revisions/99", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "21")
+	rctx.URLParams.Add("rev", "99")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.GetRevision(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// Tests for BulkProducts Handler
+func TestProductHandler_BulkProducts_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	id := int64(40)
+	mockService.On("BulkProducts", mock.Anything, mock.MatchedBy(func(r productDomain.BulkProductRequest) bool {
+		return r.Mode == productDomain.BulkModeAtomic && len(r.Operations) == 1 && r.Operations[0].Delete.ID == 40
+	})).Return(productDomain.BulkProductResponse{
+		Mode:      productDomain.BulkModeAtomic,
+		Committed: true,
+		Succeeded: 1,
+		Results:   []productDomain.BulkOperationResult{{Index: 0, Op: "delete", Status: productDomain.BulkStatusSucceeded, ID: &id}},
+	}, nil)
+
+	body := []byte(`{"operations":[{"delete":{"id":40}}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/bulk", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.BulkProducts(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_BulkProducts_EmptyOperations(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	body := []byte(`{"mode":"best_effort","operations":[]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/bulk", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.BulkProducts(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockService.AssertNotCalled(t, "BulkProducts")
+}
+
+// Tests for ImportProducts Handler
+func TestProductHandler_ImportProducts_StartsJob(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("ImportProducts", mock.Anything, mock.MatchedBy(func(r productDomain.ImportProductsRequest) bool {
+		return r.File != nil && !r.DryRun
+	})).Return(productDomain.ImportJobResponse{
+		JobID:     "job-1",
+		Status:    productDomain.ImportStatusRunning,
+		TotalRows: 500,
+	}, nil)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "products.csv")
+	io.WriteString(part, "sku,name,price,category_id,status\n")
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+
+	handler.ImportProducts(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/api/v1/product/import/job-1", w.Header().Get("Location"))
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ImportProducts_InvalidDryRun(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/import?dry_run=yes", nil)
+	w := httptest.NewRecorder()
+
+	handler.ImportProducts(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ImportProducts")
+}
+
+func TestProductHandler_GetImportJob_NotFound(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("GetImportJob", mock.Anything, "missing").
+		Return(productDomain.ImportJobResponse{}, productDomain.ErrImportJobNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product/import/missing", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("jobID", "missing")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.GetImportJob(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+// Tests for ExportProducts Handler
+func TestProductHandler_ExportProducts_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("ExportProducts", mock.Anything, mock.MatchedBy(func(f productDomain.ExportProductFilter) bool {
+		return f.Format == productDomain.ExportFormatCSV && f.Delimiter == ";" && f.DecimalSeparator == "," &&
+			*f.Status == productDomain.ProductStatusActive
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		io.WriteString(args.Get(2).(io.Writer), "id;sku\n1;SKU-001\n")
+	}).Return(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product/export?format=csv&delimiter=%3B&decimal_separator=,&status=Active", nil)
+	w := httptest.NewRecorder()
+
+	handler.ExportProducts(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), `attachment; filename="products-`)
+	assert.Equal(t, "id;sku\n1;SKU-001\n", w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ExportProducts_InvalidFormat(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product/export?format=pdf", nil)
+	w := httptest.NewRecorder()
+
+	handler.ExportProducts(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockService.AssertNotCalled(t, "ExportProducts")
+}
+
+func TestProductHandler_ExportProducts_FailsBeforeWriting(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("ExportProducts", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("database error"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product/export?format=jsonl", nil)
+	w := httptest.NewRecorder()
+
+	handler.ExportProducts(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+}
+
 // Tests for ListProducts Handler
 func TestProductHandler_ListProducts_Success(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := &ProductHandlerImpl{productService: mockService}
 
+	totalCount, totalPages := int64(2), 1
 	expectedResp := productDomain.ListProductResponse{
-		TotalCount: 2,
+		TotalCount: &totalCount,
 		Page:       1,
 		Limit:      10,
-		TotalPages: 1,
+		TotalPages: &totalPages,
 		Products: []productDomain.ProductResponse{
 			{
 				ID:       1,
@@ -507,11 +943,12 @@ func TestProductHandler_ListProducts_WithFilters(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := &ProductHandlerImpl{productService: mockService}
 
+	totalCount, totalPages := int64(1), 1
 	expectedResp := productDomain.ListProductResponse{
-		TotalCount: 1,
+		TotalCount: &totalCount,
 		Page:       1,
 		Limit:      10,
-		TotalPages: 1,
+		TotalPages: &totalPages,
 		Products: []productDomain.ProductResponse{
 			{
 				ID:       1,
@@ -541,6 +978,98 @@ func TestProductHandler_ListProducts_WithFilters(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestProductHandler_ListProducts_Cursor(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	cursor := productDomain.Cursor{SortBy: "price", SortOrder: "asc", Value: "15000.00", ID: 42}.Encode()
+
+	mockService.On("ListProducts", mock.Anything, mock.MatchedBy(func(f productDomain.ListProductFilter) bool {
+		return f.Position != nil && f.Position.ID == 42 && f.SortBy == "price" && f.SortOrder == "asc" && f.SkipCount
+	})).Return(productDomain.ListProductResponse{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product?cursor="+cursor+"&count=false&sort_by=name", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListProducts(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ListProducts_InvalidCursor(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product?cursor=not-a-cursor", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListProducts(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockService.AssertNotCalled(t, "ListProducts")
+}
+
+func TestProductHandler_ListProducts_InvalidFacet(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product?facets=category,brand", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListProducts(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockService.AssertNotCalled(t, "ListProducts")
+}
+
+func TestProductHandler_ListProducts_InvalidPriceRanges(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product?facets=price_range&price_ranges=100000,abc", nil)
+	w := httptest.NewRecorder()
+
+	handler.ListProducts(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ListProducts")
+}
+
+// Tests for SuggestProducts Handler
+func TestProductHandler_SuggestProducts_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	expected := []productDomain.ProductSuggestionResponse{
+		{ID: 3, SKU: "LPT-001", Name: "Laptop Pro 14", Score: 0.5},
+	}
+
+	mockService.On("SuggestProducts", mock.Anything, productDomain.SuggestProductFilter{Q: "lpatop", Limit: 5}).
+		Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product/suggest?q=lpatop&limit=5", nil)
+	w := httptest.NewRecorder()
+
+	handler.SuggestProducts(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_SuggestProducts_MissingQuery(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/product/suggest", nil)
+	w := httptest.NewRecorder()
+
+	handler.SuggestProducts(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockService.AssertNotCalled(t, "SuggestProducts")
+}
+
 // Tests for UploadImage Handler
 func TestProductHandler_UploadImage_Success(t *testing.T) {
 	mockService := new(MockProductService)
@@ -573,6 +1102,69 @@ func TestProductHandler_UploadImage_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestProductHandler_AddGalleryImage_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("AddGalleryImage", mock.Anything, mock.MatchedBy(func(req productDomain.AddProductImageRequest) bool {
+		return req.ProductID == 1 && req.AltText != nil && *req.AltText == "Side view" && req.FileHeader.Filename == "side.jpg"
+	})).Return(productDomain.ProductImageResponse{ID: 8, URL: "http://localhost:8080/uploads/products/1/side.jpg", Position: 1}, nil)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("image", "side.jpg")
+	io.WriteString(part, "fake image content")
+	writer.WriteField("alt_text", "Side view")
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/product/1/images", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.AddGalleryImage(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ReorderGallery_DuplicateIDs(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/product/1/images/order", strings.NewReader(`{"image_ids":[8,7,8]}`))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.ReorderGallery(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockService.AssertNotCalled(t, "ReorderGallery")
+}
+
+func TestProductHandler_DeleteGalleryImage_NotFound(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := &ProductHandlerImpl{productService: mockService}
+
+	mockService.On("DeleteGalleryImage", mock.Anything, int64(1), int64(99)).
+		Return(productDomain.ErrImageNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/product/1/images/99", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	rctx.URLParams.Add("imageID", "99")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.DeleteGalleryImage(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 func TestProductHandler_UploadImage_InvalidID(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := &ProductHandlerImpl{productService: mockService}
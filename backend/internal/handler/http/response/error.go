@@ -4,7 +4,16 @@ import (
 	"errors"
 	"net/http"
 
+	apikeyDomain "github.com/naxumi/bnsp-jwd/internal/domain/apikey"
+	categoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/category"
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
 	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	tenantDomain "github.com/naxumi/bnsp-jwd/internal/domain/tenant"
+	userDomain "github.com/naxumi/bnsp-jwd/internal/domain/user"
+	variantDomain "github.com/naxumi/bnsp-jwd/internal/domain/variant"
+	warehouseDomain "github.com/naxumi/bnsp-jwd/internal/domain/warehouse"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/imaging"
 	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
 )
 
@@ -17,6 +26,20 @@ func HandleError(w http.ResponseWriter, err error) {
 		return
 	}
 
+	// Check if the caller lacks a permission
+	var permissionErr *auth.PermissionError
+	if errors.As(err, &permissionErr) {
+		Forbidden(w, "Missing permission "+string(permissionErr.Permission))
+		return
+	}
+
+	// Check if an upload is not in one of the configured image formats
+	var formatErr *imaging.FormatError
+	if errors.As(err, &formatErr) {
+		BadRequest(w, "Invalid image format, only "+formatErr.AllowedNames()+" are allowed", nil)
+		return
+	}
+
 	switch {
 	// Product domain errors
 	case errors.Is(err, productDomain.ErrProductNotFound):
@@ -32,11 +55,93 @@ func HandleError(w http.ResponseWriter, err error) {
 	case errors.Is(err, productDomain.ErrInvalidStock):
 		BadRequest(w, "Invalid stock", nil)
 	case errors.Is(err, productDomain.ErrInvalidImageFormat):
-		BadRequest(w, "Invalid image format, only JPG, JPEG, PNG, GIF are allowed", nil)
+		BadRequest(w, "Invalid image format", nil)
 	case errors.Is(err, productDomain.ErrImageTooLarge):
 		BadRequest(w, "Image file size exceeds maximum limit of 5MB", nil)
+	case errors.Is(err, productDomain.ErrImageTooManyPixels):
+		BadRequest(w, "Image dimensions exceed the maximum pixel count", nil)
 	case errors.Is(err, productDomain.ErrImageRequired):
 		BadRequest(w, "Image file is required", nil)
+	case errors.Is(err, productDomain.ErrVersionConflict):
+		PreconditionFailed(w, "Product was modified by another request", nil)
+	case errors.Is(err, productDomain.ErrProductNotInTrash):
+		NotFound(w, "Product not found in trash")
+	case errors.Is(err, productDomain.ErrRevisionNotFound):
+		NotFound(w, "Product revision not found")
+	case errors.Is(err, productDomain.ErrImageNotFound):
+		NotFound(w, "Product image not found")
+	case errors.Is(err, productDomain.ErrImportFileRequired):
+		BadRequest(w, "Import file is required", nil)
+	case errors.Is(err, productDomain.ErrInvalidImportFile):
+		BadRequest(w, "Import file is not a valid CSV", map[string]string{"file": err.Error()})
+	case errors.Is(err, productDomain.ErrImportJobNotFound):
+		NotFound(w, "Import job not found")
+
+	// Inventory domain errors
+	case errors.Is(err, inventoryDomain.ErrInsufficientStock):
+		Conflict(w, "Insufficient stock for this movement")
+	case errors.Is(err, inventoryDomain.ErrInvalidMovementType):
+		BadRequest(w, "Invalid stock movement type", nil)
+
+	// Warehouse domain errors
+	case errors.Is(err, warehouseDomain.ErrWarehouseNotFound):
+		NotFound(w, "Warehouse not found")
+	case errors.Is(err, warehouseDomain.ErrWarehouseCodeExists):
+		Conflict(w, "Warehouse with this code already exists")
+	case errors.Is(err, warehouseDomain.ErrWarehouseHasStock):
+		Conflict(w, "Warehouse still holds stock, transfer it out before deleting")
+	case errors.Is(err, warehouseDomain.ErrDefaultWarehouseDelete):
+		Conflict(w, "The default warehouse cannot be deleted")
+	case errors.Is(err, warehouseDomain.ErrSameWarehouseTransfer):
+		BadRequest(w, "Source and destination warehouse must differ", nil)
+
+	// Category domain errors
+	case errors.Is(err, categoryDomain.ErrCategoryNotFound):
+		NotFound(w, "Category not found")
+	case errors.Is(err, categoryDomain.ErrParentNotFound):
+		NotFound(w, "Parent category not found")
+	case errors.Is(err, categoryDomain.ErrCategorySlugExists):
+		Conflict(w, "Category with this slug already exists")
+	case errors.Is(err, categoryDomain.ErrCategoryInUse):
+		Conflict(w, "Category still has products or subcategories")
+	case errors.Is(err, categoryDomain.ErrCategoryAmbiguous):
+		BadRequest(w, "Category name matches more than one category, give category_id or the full path", nil)
+	case errors.Is(err, categoryDomain.ErrCategoryCycle):
+		BadRequest(w, "A category cannot be moved under itself or one of its descendants", nil)
+
+	// Variant domain errors
+	case errors.Is(err, variantDomain.ErrVariantNotFound):
+		NotFound(w, "Variant not found")
+	case errors.Is(err, variantDomain.ErrVariantExists):
+		Conflict(w, "A variant with these options already exists")
+	case errors.Is(err, variantDomain.ErrNestedVariant):
+		BadRequest(w, "A variant cannot have variants of its own", nil)
+	case errors.Is(err, variantDomain.ErrNoOptions):
+		BadRequest(w, "Product has no options defined, set them first", nil)
+	case errors.Is(err, variantDomain.ErrInvalidOptions):
+		BadRequest(w, "Variant options must pick one allowed value for every product option", nil)
+	case errors.Is(err, variantDomain.ErrTooManyCombinations):
+		BadRequest(w, "Option matrix exceeds the maximum of 100 variants", nil)
+
+	// User domain errors
+	case errors.Is(err, userDomain.ErrUsernameExists):
+		Conflict(w, "User with this username already exists")
+	case errors.Is(err, userDomain.ErrInvalidCredentials):
+		Unauthorized(w, "Invalid username or password")
+	case errors.Is(err, userDomain.ErrInvalidRefreshToken):
+		Unauthorized(w, "Invalid or expired refresh token")
+
+	// API key domain errors
+	case errors.Is(err, apikeyDomain.ErrAPIKeyNotFound):
+		NotFound(w, "API key not found")
+	case errors.Is(err, apikeyDomain.ErrInvalidAPIKey):
+		Unauthorized(w, "Invalid or revoked API key")
+
+	// Tenant domain errors
+	case errors.Is(err, tenantDomain.ErrTenantRequired):
+		BadRequest(w, "X-Tenant-ID header is required", nil)
+	case errors.Is(err, tenantDomain.ErrTenantMismatch):
+		Forbidden(w, "Credentials belong to another tenant")
 
 	// Default
 	default:
@@ -65,6 +65,16 @@ func Created(w http.ResponseWriter, message string, data interface{}) {
 	})
 }
 
+// Accepted reports work that continues after the response, such as a
+// background job the client can poll
+func Accepted(w http.ResponseWriter, message string, data interface{}) {
+	writeJSON(w, http.StatusAccepted, Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
 func SuccessWithMeta(w http.ResponseWriter, data interface{}, meta *Meta) {
 	writeJSON(w, http.StatusOK, Response{
 		Success: true,
@@ -145,3 +155,16 @@ func Conflict(w http.ResponseWriter, message string) {
 		},
 	})
 }
+
+// PreconditionFailed reports a stale conditional write; data carries the
+// current representation so the client can merge and retry
+func PreconditionFailed(w http.ResponseWriter, message string, data interface{}) {
+	writeJSON(w, http.StatusPreconditionFailed, Response{
+		Success: false,
+		Data:    data,
+		Error: &ErrorDetail{
+			Code:    "PRECONDITION_FAILED",
+			Message: message,
+		},
+	})
+}
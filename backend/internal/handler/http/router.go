@@ -9,9 +9,10 @@ import (
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httplog/v3"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
 )
 
-func NewRouter(productHandler ProductHandler, storageBasePath string) *chi.Mux {
+func NewRouter(productHandler ProductHandler, inventoryHandler InventoryHandler, warehouseHandler WarehouseHandler, categoryHandler CategoryHandler, variantHandler VariantHandler, auditHandler AuditHandler, authHandler AuthHandler, apiKeyHandler APIKeyHandler, authenticate func(http.Handler) http.Handler, defaultTenantID int64, uploadHandler UploadHandler) *chi.Mux {
 	r := chi.NewRouter()
 	logFormat := httplog.SchemaECS.Concise(false)
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
@@ -26,8 +27,8 @@ func NewRouter(productHandler ProductHandler, storageBasePath string) *chi.Mux {
 		AllowedOrigins:   []string{"http://localhost:3000"},
 		AllowCredentials: true,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match", "X-Request-Id", "X-API-Key", TenantHeader},
+		ExposedHeaders:   []string{"Link", "ETag", "Location", "X-Request-Id"},
 		MaxAge:           300,
 	}))
 
@@ -41,21 +42,108 @@ func NewRouter(productHandler ProductHandler, storageBasePath string) *chi.Mux {
 	r.Use(chiMiddleware.AllowContentType("application/json", "multipart/form-data"))
 	r.Use(chiMiddleware.CleanPath)
 	r.Use(chiMiddleware.Recoverer)
+	r.Use(chiMiddleware.RequestID)
+	r.Use(RequestContext)
 	r.Use(chiMiddleware.Heartbeat("/"))
 
-	fileServer := http.FileServer(http.Dir(storageBasePath))
-	r.Handle("/uploads/*", http.StripPrefix("/uploads/", fileServer))
+	// Local uploads are served here, to the tenant they belong to; object
+	// stores serve their own files
+	if uploadHandler != nil {
+		r.With(ResolveTenant(defaultTenantID)).Get("/uploads/*", uploadHandler.ServeUpload)
+	}
 
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Route("/product", func(r chi.Router) {
-			r.Post("/", productHandler.CreateProduct)
-			r.Get("/{id}", productHandler.GetProduct)
-			r.Get("/sku/{sku}", productHandler.GetProductBySKU)
-			r.Put("/", productHandler.UpdateProduct)
-			r.Delete("/{id}", productHandler.DeleteProduct)
-			r.Get("/", productHandler.ListProducts)
-			r.Post("/{id}/image", productHandler.UploadImage)
-			r.Delete("/{id}/image", productHandler.DeleteImage)
+		r.Route("/auth", func(r chi.Router) {
+			r.Post("/login", authHandler.Login)
+			r.Post("/refresh", authHandler.Refresh)
+			r.Post("/logout", authHandler.Logout)
+		})
+
+		// Everything else is scoped to a tenant
+		r.Group(func(r chi.Router) {
+			r.Use(ResolveTenant(defaultTenantID))
+
+			r.Route("/product", func(r chi.Router) {
+				// Catalog reads are public
+				r.Get("/", productHandler.ListProducts)
+				r.Get("/{id}", productHandler.GetProduct)
+				r.Get("/sku/{sku}", productHandler.GetProductBySKU)
+				r.Get("/suggest", productHandler.SuggestProducts)
+				r.Get("/export", productHandler.ExportProducts)
+				r.Get("/{id}/variants", variantHandler.ListVariants)
+
+				r.Group(func(r chi.Router) {
+					r.Use(authenticate)
+					r.Post("/", productHandler.CreateProduct)
+					r.Post("/bulk", productHandler.BulkProducts)
+					r.Post("/import", productHandler.ImportProducts)
+					r.Get("/import/{jobID}", productHandler.GetImportJob)
+					r.Get("/trash", productHandler.ListTrash)
+					r.Post("/trash/purge", productHandler.PurgeTrash)
+					r.Put("/", productHandler.UpdateProduct)
+					r.Delete("/{id}", productHandler.DeleteProduct)
+					r.Post("/{id}/restore", productHandler.RestoreProduct)
+					r.With(RequirePermission(auth.PermProductRead)).Get("/{id}/history", auditHandler.ListProductHistory)
+					r.With(RequirePermission(auth.PermProductRead)).Get("/{id}/stock-movements", inventoryHandler.ListMovements)
+					r.Get("/{id}/revisions", productHandler.ListRevisions)
+					r.Get("/{id}/revisions/{rev}", productHandler.GetRevision)
+					r.Post("/{id}/revisions/{rev}/restore", productHandler.RestoreRevision)
+					r.Post("/{id}/image", productHandler.UploadImage)
+					r.Delete("/{id}/image", productHandler.DeleteImage)
+					r.Post("/{id}/images", productHandler.AddGalleryImage)
+					r.Put("/{id}/images/order", productHandler.ReorderGallery)
+					r.Put("/{id}/images/{imageID}", productHandler.UpdateGalleryImage)
+					r.Post("/{id}/images/{imageID}/primary", productHandler.SetPrimaryImage)
+					r.Delete("/{id}/images/{imageID}", productHandler.DeleteGalleryImage)
+
+					// Product writes outside the product service
+					r.Group(func(r chi.Router) {
+						r.Use(RequirePermission(auth.PermProductWrite))
+						r.Post("/{id}/stock-movements", inventoryHandler.PostMovement)
+						r.Put("/{id}/options", variantHandler.SetOptions)
+						r.Post("/{id}/variants", variantHandler.CreateVariant)
+						r.Put("/{id}/variants", variantHandler.UpdateVariant)
+						r.Post("/{id}/variants/generate", variantHandler.GenerateVariants)
+					})
+				})
+			})
+
+			r.Route("/warehouses", func(r chi.Router) {
+				r.Get("/", warehouseHandler.ListWarehouses)
+				r.Get("/{id}", warehouseHandler.GetWarehouse)
+
+				r.Group(func(r chi.Router) {
+					r.Use(authenticate)
+					r.Use(RequirePermission(auth.PermProductWrite))
+					r.Post("/", warehouseHandler.CreateWarehouse)
+					r.Put("/", warehouseHandler.UpdateWarehouse)
+					r.Delete("/{id}", warehouseHandler.DeleteWarehouse)
+					r.Post("/transfers", warehouseHandler.TransferStock)
+				})
+			})
+
+			r.Route("/categories", func(r chi.Router) {
+				r.Get("/", categoryHandler.ListCategories)
+				r.Get("/{id}", categoryHandler.GetCategory)
+
+				r.Group(func(r chi.Router) {
+					r.Use(authenticate)
+					r.Use(RequirePermission(auth.PermProductWrite))
+					r.Post("/", categoryHandler.CreateCategory)
+					r.Put("/", categoryHandler.UpdateCategory)
+					r.Delete("/{id}", categoryHandler.DeleteCategory)
+				})
+			})
+
+			r.With(authenticate, RequirePermission(auth.PermProductRead)).Get("/audit", auditHandler.ListAudit)
+
+			r.Route("/api-keys", func(r chi.Router) {
+				r.Use(authenticate)
+				r.Use(RequirePermission(auth.PermAPIKeyAdmin))
+				r.Post("/", apiKeyHandler.CreateAPIKey)
+				r.Get("/", apiKeyHandler.ListAPIKeys)
+				r.Delete("/{id}", apiKeyHandler.RevokeAPIKey)
+			})
 		})
 	})
 	return r
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/naxumi/bnsp-jwd/internal/handler/http/response"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/storage"
+)
+
+type UploadHandler interface {
+	ServeUpload(w http.ResponseWriter, r *http.Request)
+}
+
+// UploadHandlerImpl serves locally stored uploads through the storage, so a
+// file in a tenant's directory is only served to a request scoped to that
+// tenant. Directories are not listed.
+type UploadHandlerImpl struct {
+	storage storage.FileStorage
+}
+
+func NewUploadHandler(storage storage.FileStorage) UploadHandler {
+	return &UploadHandlerImpl{
+		storage: storage,
+	}
+}
+
+func (h *UploadHandlerImpl) ServeUpload(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "*")
+
+	file, err := h.storage.Download(r.Context(), name)
+	if err != nil {
+		log.Printf("Error serving upload %s: %v", name, err)
+		response.NotFound(w, "File not found")
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		if _, err := io.Copy(w, file); err != nil {
+			log.Printf("Error serving upload %s: %v", name, err)
+		}
+		return
+	}
+
+	var modTime time.Time
+	if stat, ok := file.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		if info, err := stat.Stat(); err == nil {
+			modTime = info.ModTime()
+		}
+	}
+	http.ServeContent(w, r, path.Base(name), modTime, content)
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUploadRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+	s, err := storage.NewLocalStorage(t.TempDir(), "http://localhost:8080/uploads")
+	require.NoError(t, err)
+
+	ctx := requestctx.WithTenantID(context.Background(), 2)
+	_, err = s.Upload(ctx, strings.NewReader("image"), "products/1/photo.jpg", "image/jpeg")
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.With(ResolveTenant(1)).Get("/uploads/*", NewUploadHandler(s).ServeUpload)
+	return r
+}
+
+func serveUpload(r http.Handler, path, tenant string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if tenant != "" {
+		req.Header.Set(TenantHeader, tenant)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUploadHandler_ServesOwnTenant(t *testing.T) {
+	rec := serveUpload(newUploadRouter(t), "/uploads/tenants/2/products/1/photo.jpg", "2")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image", rec.Body.String())
+	assert.Equal(t, "image/jpeg", rec.Header().Get("Content-Type"))
+}
+
+func TestUploadHandler_OtherTenantNotFound(t *testing.T) {
+	r := newUploadRouter(t)
+
+	// The default tenant, and a tenant named in the header, are not the owner
+	assert.Equal(t, http.StatusNotFound, serveUpload(r, "/uploads/tenants/2/products/1/photo.jpg", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveUpload(r, "/uploads/tenants/2/products/1/photo.jpg", "3").Code)
+}
+
+func TestUploadHandler_NoDirectoryListing(t *testing.T) {
+	r := newUploadRouter(t)
+
+	for _, path := range []string{"/uploads/", "/uploads/tenants/2/", "/uploads/tenants/2/products/1"} {
+		rec := serveUpload(r, path, "2")
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+		assert.NotContains(t, rec.Body.String(), "photo.jpg", path)
+	}
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	variantDomain "github.com/naxumi/bnsp-jwd/internal/domain/variant"
+	"github.com/naxumi/bnsp-jwd/internal/handler/http/response"
+)
+
+type VariantHandler interface {
+	SetOptions(w http.ResponseWriter, r *http.Request)
+	CreateVariant(w http.ResponseWriter, r *http.Request)
+	GenerateVariants(w http.ResponseWriter, r *http.Request)
+	ListVariants(w http.ResponseWriter, r *http.Request)
+	UpdateVariant(w http.ResponseWriter, r *http.Request)
+}
+
+type VariantHandlerImpl struct {
+	variantService variantDomain.VariantService
+}
+
+func NewVariantHandler(variantService variantDomain.VariantService) VariantHandler {
+	return &VariantHandlerImpl{
+		variantService: variantService,
+	}
+}
+
+func (h *VariantHandlerImpl) SetOptions(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	var req variantDomain.SetOptionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+	req.ProductID = id
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	options, err := h.variantService.SetOptions(r.Context(), req)
+	if err != nil {
+		log.Printf("Error setting options for product ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "Product options updated successfully", options)
+}
+
+func (h *VariantHandlerImpl) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	var req variantDomain.CreateVariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+	req.ProductID = id
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	createdVariant, err := h.variantService.CreateVariant(r.Context(), req)
+	if err != nil {
+		log.Printf("Error creating variant for product ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, "Variant created successfully", createdVariant)
+}
+
+func (h *VariantHandlerImpl) GenerateVariants(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	var req variantDomain.GenerateVariantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+	req.ProductID = id
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	createdVariants, err := h.variantService.GenerateVariants(r.Context(), req)
+	if err != nil {
+		log.Printf("Error generating variants for product ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, "Variants generated successfully", createdVariants)
+}
+
+func (h *VariantHandlerImpl) ListVariants(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	variants, err := h.variantService.ListVariants(r.Context(), id)
+	if err != nil {
+		log.Printf("Error listing variants for product ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, variants)
+}
+
+func (h *VariantHandlerImpl) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid product ID", nil)
+		return
+	}
+
+	var req variantDomain.UpdateVariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+	req.ProductID = id
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	err = h.variantService.UpdateVariant(r.Context(), req)
+	if err != nil {
+		log.Printf("Error updating variant: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "Variant updated successfully", nil)
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	warehouseDomain "github.com/naxumi/bnsp-jwd/internal/domain/warehouse"
+	"github.com/naxumi/bnsp-jwd/internal/handler/http/response"
+)
+
+type WarehouseHandler interface {
+	CreateWarehouse(w http.ResponseWriter, r *http.Request)
+	GetWarehouse(w http.ResponseWriter, r *http.Request)
+	ListWarehouses(w http.ResponseWriter, r *http.Request)
+	UpdateWarehouse(w http.ResponseWriter, r *http.Request)
+	DeleteWarehouse(w http.ResponseWriter, r *http.Request)
+	TransferStock(w http.ResponseWriter, r *http.Request)
+}
+
+type WarehouseHandlerImpl struct {
+	warehouseService warehouseDomain.WarehouseService
+}
+
+func NewWarehouseHandler(warehouseService warehouseDomain.WarehouseService) WarehouseHandler {
+	return &WarehouseHandlerImpl{
+		warehouseService: warehouseService,
+	}
+}
+
+func (h *WarehouseHandlerImpl) CreateWarehouse(w http.ResponseWriter, r *http.Request) {
+	var req warehouseDomain.CreateWarehouseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	createdWarehouse, err := h.warehouseService.CreateWarehouse(r.Context(), req)
+	if err != nil {
+		log.Printf("Error creating warehouse: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, "Warehouse created successfully", createdWarehouse)
+}
+
+func (h *WarehouseHandlerImpl) GetWarehouse(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid warehouse ID", nil)
+		return
+	}
+
+	warehouse, err := h.warehouseService.GetWarehouse(r.Context(), id)
+	if err != nil {
+		log.Printf("Error getting warehouse with ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, warehouse)
+}
+
+func (h *WarehouseHandlerImpl) ListWarehouses(w http.ResponseWriter, r *http.Request) {
+	warehouses, err := h.warehouseService.ListWarehouses(r.Context())
+	if err != nil {
+		log.Printf("Error listing warehouses: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, warehouses)
+}
+
+func (h *WarehouseHandlerImpl) UpdateWarehouse(w http.ResponseWriter, r *http.Request) {
+	var req warehouseDomain.UpdateWarehouseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	err := h.warehouseService.UpdateWarehouse(r.Context(), req)
+	if err != nil {
+		log.Printf("Error updating warehouse: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "Warehouse updated successfully", nil)
+}
+
+func (h *WarehouseHandlerImpl) DeleteWarehouse(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid warehouse ID", nil)
+		return
+	}
+
+	err = h.warehouseService.DeleteWarehouse(r.Context(), id)
+	if err != nil {
+		log.Printf("Error deleting warehouse with ID %d: %v", id, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.SuccessWithMessage(w, "Warehouse deleted successfully", nil)
+}
+
+func (h *WarehouseHandlerImpl) TransferStock(w http.ResponseWriter, r *http.Request) {
+	var req warehouseDomain.TransferStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid request format: %v", err)
+		response.BadRequest(w, "Invalid request format", nil)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Printf("Validation error: %v", err)
+		response.HandleError(w, err)
+		return
+	}
+
+	result, err := h.warehouseService.TransferStock(r.Context(), req)
+	if err != nil {
+		log.Printf("Error transferring stock for product ID %d: %v", req.ProductID, err)
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, "Stock transferred successfully", result)
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// Claims are the claims of a verified token. All holds every claim by its
+// JWT name, registered ones included. An API key has no token: its claims
+// carry only a subject and the key's Scopes. TenantID is the tenant the
+// caller acts for.
+type Claims struct {
+	Subject   string
+	ExpiresAt time.Time
+	All       map[string]any
+	Scopes    []Permission
+	TenantID  int64
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the claims of the caller's token
+func NewContext(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// FromContext returns the claims stored by NewContext; ok is false for an
+// unauthenticated request
+func FromContext(ctx context.Context) (claims Claims, ok bool) {
+	claims, ok = ctx.Value(contextKey{}).(Claims)
+	return claims, ok
+}
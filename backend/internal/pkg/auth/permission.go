@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrForbidden is matched by every PermissionError
+var ErrForbidden = errors.New("permission denied")
+
+// Permission names an operation a caller may be allowed to perform
+type Permission string
+
+const (
+	PermProductRead   Permission = "product:read"   // trash, revisions, import jobs, history
+	PermProductWrite  Permission = "product:write"  // create and update products, move stock
+	PermProductPrice  Permission = "product:price"  // set or change a price
+	PermProductDelete Permission = "product:delete" // trash, restore and purge
+	PermProductImage  Permission = "product:image"  // upload and delete images
+
+	PermAPIKeyAdmin Permission = "apikey:admin" // create, list and revoke API keys
+)
+
+// Roles, carried in the "role" claim of an access token
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleStaff   = "staff"
+	RoleViewer  = "viewer"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleAdmin:   {PermProductRead, PermProductWrite, PermProductPrice, PermProductDelete, PermProductImage, PermAPIKeyAdmin},
+	RoleManager: {PermProductRead, PermProductWrite, PermProductPrice, PermProductDelete, PermProductImage},
+	RoleStaff:   {PermProductRead, PermProductWrite, PermProductImage},
+	RoleViewer:  {PermProductRead},
+}
+
+// KeyScopes are the permissions an API key can be given. Keys cannot manage
+// other keys.
+var KeyScopes = []Permission{PermProductRead, PermProductWrite, PermProductPrice, PermProductDelete, PermProductImage}
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// PermissionError reports the permission a caller lacks
+type PermissionError struct {
+	Permission Permission
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("%s: %s is required", ErrForbidden, e.Permission)
+}
+
+func (e *PermissionError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// Role returns the "role" claim, or "" when the token has none
+func (c Claims) Role() string {
+	role, _ := c.All["role"].(string)
+	return role
+}
+
+// HasPermission reports whether the claims grant the permission, through
+// their scopes for an API key or their role otherwise
+func (c Claims) HasPermission(permission Permission) bool {
+	if c.Scopes != nil {
+		return slices.Contains(c.Scopes, permission)
+	}
+	return slices.Contains(rolePermissions[c.Role()], permission)
+}
+
+// Require returns a PermissionError for the first permission the caller in
+// ctx lacks. An unauthenticated context has no permissions.
+func Require(ctx context.Context, permissions ...Permission) error {
+	claims, _ := FromContext(ctx)
+	for _, permission := range permissions {
+		if !claims.HasPermission(permission) {
+			return &PermissionError{Permission: permission}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// SignerOptions configures the access tokens the API issues. Exactly one of
+// Secret and KeyFile is set; Issuer and Audience are only written when set.
+type SignerOptions struct {
+	Secret   string // HMAC secret for HS256 tokens
+	KeyFile  string // path of a JWK with the private key, its "kid" is kept
+	Issuer   string
+	Audience string
+
+	// How long an access token is valid
+	TTL time.Duration
+}
+
+// Signer issues short-lived access tokens that Verifier accepts when both
+// are configured with the same secret, or key pair
+type Signer struct {
+	algorithm jwa.SignatureAlgorithm
+	key       any
+	issuer    string
+	audience  string
+	ttl       time.Duration
+}
+
+func NewSigner(opts SignerOptions) (*Signer, error) {
+	s := &Signer{
+		issuer:   opts.Issuer,
+		audience: opts.Audience,
+		ttl:      opts.TTL,
+	}
+	if s.ttl <= 0 {
+		return nil, errors.New("access token lifetime must be positive")
+	}
+
+	switch {
+	case opts.Secret != "" && opts.KeyFile != "":
+		return nil, errors.New("set either an HMAC secret or a signing key file, not both")
+	case opts.Secret != "":
+		if len(opts.Secret) < minSecretLength {
+			return nil, fmt.Errorf("HMAC secret must be at least %d bytes", minSecretLength)
+		}
+		s.algorithm = jwa.HS256
+		s.key = []byte(opts.Secret)
+	case opts.KeyFile != "":
+		set, err := jwk.ReadFile(opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key file: %w", err)
+		}
+		key, ok := set.Key(0)
+		if !ok {
+			return nil, errors.New("signing key file holds no key")
+		}
+		if private, err := jwk.IsPrivateKey(key); err != nil || !private {
+			return nil, errors.New("signing key file must hold a private key")
+		}
+		s.algorithm, err = signatureAlgorithm(key)
+		if err != nil {
+			return nil, err
+		}
+		s.key = key
+	default:
+		return nil, errors.New("an HMAC secret or a signing key file is required")
+	}
+
+	return s, nil
+}
+
+// signatureAlgorithm returns the key's "alg", or the usual algorithm for its
+// key type when it has none
+func signatureAlgorithm(key jwk.Key) (jwa.SignatureAlgorithm, error) {
+	if alg := key.Algorithm().String(); alg != "" {
+		var algorithm jwa.SignatureAlgorithm
+		if err := algorithm.Accept(alg); err != nil {
+			return "", fmt.Errorf("signing key has an unsupported algorithm %q", alg)
+		}
+		return algorithm, nil
+	}
+
+	switch key.KeyType() {
+	case jwa.RSA:
+		return jwa.RS256, nil
+	case jwa.EC:
+		return jwa.ES256, nil
+	case jwa.OKP:
+		return jwa.EdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported signing key type %q", key.KeyType())
+	}
+}
+
+// Sign issues an access token for subject carrying the extra claims, and
+// returns it with its expiry
+func (s *Signer) Sign(subject string, claims map[string]any) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+
+	builder := jwt.NewBuilder().
+		Subject(subject).
+		IssuedAt(now).
+		NotBefore(now).
+		Expiration(expiresAt)
+	if s.issuer != "" {
+		builder = builder.Issuer(s.issuer)
+	}
+	if s.audience != "" {
+		builder = builder.Audience([]string{s.audience})
+	}
+	for name, value := range claims {
+		builder = builder.Claim(name, value)
+	}
+
+	token, err := builder.Build()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to build access token: %w", err)
+	}
+
+	signed, err := jwt.Sign(token, jwt.WithKey(s.algorithm, s.key))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return string(signed), expiresAt, nil
+}
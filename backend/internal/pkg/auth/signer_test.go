@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner_Sign_VerifiedWithSecret(t *testing.T) {
+	s, err := NewSigner(SignerOptions{
+		Secret:   testSecret,
+		Issuer:   "https://auth.example.com",
+		Audience: "product-api",
+		TTL:      15 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	token, expiresAt, err := s.Sign("alice", map[string]any{"uid": 7})
+	require.NoError(t, err)
+
+	claims, err := newTestVerifier(t).Verify(context.Background(), token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+	assert.EqualValues(t, 7, claims.All["uid"])
+	assert.WithinDuration(t, expiresAt, claims.ExpiresAt, time.Second)
+}
+
+func TestSigner_Sign_VerifiedWithJWKS(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	signingKey, err := jwk.FromRaw(privateKey)
+	require.NoError(t, err)
+	require.NoError(t, signingKey.Set(jwk.KeyIDKey, "key-1"))
+	publicKey, err := signingKey.PublicKey()
+	require.NoError(t, err)
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(publicKey))
+
+	dir := t.TempDir()
+	writeJSON := func(name string, v any) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+
+	s, err := NewSigner(SignerOptions{KeyFile: writeJSON("key.json", signingKey), TTL: time.Minute})
+	require.NoError(t, err)
+	v, err := NewVerifier(VerifierOptions{JWKSFile: writeJSON("jwks.json", set)})
+	require.NoError(t, err)
+
+	token, _, err := s.Sign("alice", map[string]any{TenantClaim: 3})
+	require.NoError(t, err)
+
+	claims, err := v.Verify(context.Background(), token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+	assert.Equal(t, int64(3), claims.TenantID)
+}
+
+func TestNewSigner_Rejected(t *testing.T) {
+	tests := map[string]SignerOptions{
+		"no key":         {TTL: time.Minute},
+		"short secret":   {Secret: "too-short", TTL: time.Minute},
+		"no lifetime":    {Secret: testSecret},
+		"missing file":   {KeyFile: "does-not-exist.json", TTL: time.Minute},
+		"secret and key": {Secret: testSecret, KeyFile: "key.json", TTL: time.Minute},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSigner(opts)
+			assert.Error(t, err)
+		})
+	}
+}
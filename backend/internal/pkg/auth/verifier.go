@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrInvalidToken = errors.New("invalid or expired token")
+)
+
+// minSecretLength is the shortest HMAC secret accepted, the size of an
+// HS256 hash
+const minSecretLength = 32
+
+// VerifierOptions configures how tokens are checked. Exactly one of Secret
+// and JWKSFile is set. Issuer and Audience are only checked when set.
+type VerifierOptions struct {
+	Secret   string // HMAC secret for HS256 tokens
+	JWKSFile string // path of a JSON Web Key Set with the public keys
+	Issuer   string
+	Audience string
+
+	// Leeway for exp and nbf, to allow for clock drift between servers
+	ClockSkew time.Duration
+
+	// Tenant of tokens without a "tid" claim; when zero such tokens are
+	// rejected
+	DefaultTenantID int64
+}
+
+// TenantClaim is the claim naming the tenant a token acts for
+const TenantClaim = "tid"
+
+// Verifier checks the signature and registered claims of JWTs
+type Verifier struct {
+	options         []jwt.ParseOption
+	defaultTenantID int64
+}
+
+func NewVerifier(opts VerifierOptions) (*Verifier, error) {
+	var keyOption jwt.ParseOption
+	switch {
+	case opts.Secret != "" && opts.JWKSFile != "":
+		return nil, errors.New("set either an HMAC secret or a JWKS file, not both")
+	case opts.Secret != "":
+		if len(opts.Secret) < minSecretLength {
+			return nil, fmt.Errorf("HMAC secret must be at least %d bytes", minSecretLength)
+		}
+		keyOption = jwt.WithKey(jwa.HS256, []byte(opts.Secret))
+	case opts.JWKSFile != "":
+		set, err := jwk.ReadFile(opts.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		// Keys without an "alg" are matched by their type; "kid" is still required
+		keyOption = jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true))
+	default:
+		return nil, errors.New("an HMAC secret or a JWKS file is required")
+	}
+
+	v := &Verifier{defaultTenantID: opts.DefaultTenantID}
+	v.options = []jwt.ParseOption{
+		keyOption,
+		jwt.WithValidate(true),
+		jwt.WithRequiredClaim(jwt.ExpirationKey),
+		jwt.WithRequiredClaim(jwt.SubjectKey),
+		jwt.WithAcceptableSkew(opts.ClockSkew),
+	}
+	if opts.Issuer != "" {
+		v.options = append(v.options, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		v.options = append(v.options, jwt.WithAudience(opts.Audience))
+	}
+
+	return v, nil
+}
+
+// Verify parses a compact JWT and returns its claims. Any failure, be it the
+// signature, a registered claim or the format, is reported as
+// ErrInvalidToken wrapping the cause.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	if token == "" {
+		return Claims{}, ErrMissingToken
+	}
+
+	parsed, err := jwt.ParseString(token, v.options...)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	all, err := parsed.AsMap(ctx)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	tenantID, err := v.tenantID(all[TenantClaim])
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return Claims{
+		Subject:   parsed.Subject(),
+		ExpiresAt: parsed.Expiration(),
+		All:       all,
+		TenantID:  tenantID,
+	}, nil
+}
+
+// tenantID reads the "tid" claim, a number or a numeric string, falling back
+// to the default tenant when the token has none
+func (v *Verifier) tenantID(claim any) (int64, error) {
+	var id int64
+	switch tid := claim.(type) {
+	case nil:
+		if v.defaultTenantID == 0 {
+			return 0, errors.New("token has no tenant")
+		}
+		return v.defaultTenantID, nil
+	case float64:
+		id = int64(tid)
+		if float64(id) != tid {
+			return 0, fmt.Errorf("invalid tenant %v", tid)
+		}
+	case int64:
+		id = tid
+	case string:
+		parsed, err := strconv.ParseInt(tid, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid tenant %q", tid)
+		}
+		id = parsed
+	default:
+		return 0, fmt.Errorf("invalid tenant %v", tid)
+	}
+	if id <= 0 {
+		return 0, fmt.Errorf("invalid tenant %d", id)
+	}
+	return id, nil
+}
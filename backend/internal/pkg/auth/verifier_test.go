@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func signHS256(t *testing.T, secret string, build func(b *jwt.Builder) *jwt.Builder) string {
+	t.Helper()
+	token, err := build(jwt.NewBuilder()).Build()
+	require.NoError(t, err)
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.HS256, []byte(secret)))
+	require.NoError(t, err)
+	return string(signed)
+}
+
+func validClaims(b *jwt.Builder) *jwt.Builder {
+	return b.Subject("alice").
+		Issuer("https://auth.example.com").
+		Audience([]string{"product-api"}).
+		Expiration(time.Now().Add(time.Hour)).
+		Claim("role", "admin")
+}
+
+func newTestVerifier(t *testing.T) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(VerifierOptions{
+		Secret:   testSecret,
+		Issuer:   "https://auth.example.com",
+		Audience: "product-api",
+
+		DefaultTenantID: 1,
+	})
+	require.NoError(t, err)
+	return v
+}
+
+func TestVerifier_Verify_ValidHMACToken(t *testing.T) {
+	v := newTestVerifier(t)
+
+	claims, err := v.Verify(context.Background(), signHS256(t, testSecret, validClaims))
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+	assert.Equal(t, "admin", claims.All["role"])
+}
+
+func TestVerifier_Verify_Rejected(t *testing.T) {
+	v := newTestVerifier(t)
+
+	tests := map[string]string{
+		"expired": signHS256(t, testSecret, func(b *jwt.Builder) *jwt.Builder {
+			return validClaims(b).Expiration(time.Now().Add(-time.Hour))
+		}),
+		"not yet valid": signHS256(t, testSecret, func(b *jwt.Builder) *jwt.Builder {
+			return validClaims(b).NotBefore(time.Now().Add(time.Hour))
+		}),
+		"wrong issuer": signHS256(t, testSecret, func(b *jwt.Builder) *jwt.Builder {
+			return validClaims(b).Issuer("https://evil.example.com")
+		}),
+		"wrong audience": signHS256(t, testSecret, func(b *jwt.Builder) *jwt.Builder {
+			return validClaims(b).Audience([]string{"other-api"})
+		}),
+		"no expiry": signHS256(t, testSecret, func(b *jwt.Builder) *jwt.Builder {
+			return b.Subject("alice").Issuer("https://auth.example.com").Audience([]string{"product-api"})
+		}),
+		"no subject": signHS256(t, testSecret, func(b *jwt.Builder) *jwt.Builder {
+			return b.Issuer("https://auth.example.com").Audience([]string{"product-api"}).Expiration(time.Now().Add(time.Hour))
+		}),
+		"wrong secret": signHS256(t, "fedcba9876543210fedcba9876543210", validClaims),
+		"malformed":    "not.a.token",
+	}
+
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestVerifier_Verify_TenantClaim(t *testing.T) {
+	v, err := NewVerifier(VerifierOptions{Secret: testSecret, DefaultTenantID: 1})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		tid    any
+		tenant int64
+	}{
+		"number":     {tid: 7, tenant: 7},
+		"string":     {tid: "7", tenant: 7},
+		"no tid":     {tid: nil, tenant: 1},
+		"non-number": {tid: "acme", tenant: 0},
+		"zero":       {tid: 0, tenant: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			token := signHS256(t, testSecret, func(b *jwt.Builder) *jwt.Builder {
+				b = validClaims(b)
+				if tc.tid != nil {
+					b = b.Claim(TenantClaim, tc.tid)
+				}
+				return b
+			})
+
+			claims, err := v.Verify(context.Background(), token)
+
+			if tc.tenant == 0 {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.tenant, claims.TenantID)
+		})
+	}
+}
+
+func TestVerifier_Verify_TenantRequired(t *testing.T) {
+	v, err := NewVerifier(VerifierOptions{Secret: testSecret})
+	require.NoError(t, err)
+
+	_, err = v.Verify(context.Background(), signHS256(t, testSecret, validClaims))
+
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifier_Verify_JWKSFile(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	signingKey, err := jwk.FromRaw(privateKey)
+	require.NoError(t, err)
+	require.NoError(t, signingKey.Set(jwk.KeyIDKey, "key-1"))
+
+	publicKey, err := signingKey.PublicKey()
+	require.NoError(t, err)
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(publicKey))
+
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	v, err := NewVerifier(VerifierOptions{JWKSFile: path, DefaultTenantID: 1})
+	require.NoError(t, err)
+
+	token, err := validClaims(jwt.NewBuilder()).Build()
+	require.NoError(t, err)
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, signingKey))
+	require.NoError(t, err)
+
+	claims, err := v.Verify(context.Background(), string(signed))
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+}
+
+func TestNewVerifier_ShortSecret(t *testing.T) {
+	_, err := NewVerifier(VerifierOptions{Secret: "too-short"})
+
+	assert.Error(t, err)
+}
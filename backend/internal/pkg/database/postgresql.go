@@ -2,10 +2,12 @@ package database
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
 )
 
 type DB struct {
@@ -23,6 +25,21 @@ func NewPostgreSQLDB(dsn string) (*DB, error) {
 	config.MaxConns = 25
 	config.MinConns = 5
 
+	// Row-level security policies read the tenant from app.tenant_id. It is
+	// set from the context of every query or transaction that acquires a
+	// connection, and cleared when the context has no tenant, so a connection
+	// never keeps the previous user's tenant.
+	config.PrepareConn = func(ctx context.Context, conn *pgx.Conn) (bool, error) {
+		tenantID := ""
+		if id, ok := requestctx.TenantID(ctx); ok {
+			tenantID = strconv.FormatInt(id, 10)
+		}
+		if _, err := conn.Exec(ctx, "SELECT set_config('app.tenant_id', $1, false)", tenantID); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		return nil, err
@@ -35,6 +52,15 @@ func NewPostgreSQLDB(dsn string) (*DB, error) {
 	return &DB{Pool: pool}, nil
 }
 
+// BypassesRowSecurity reports whether the connected role ignores row-level
+// security, as superusers and BYPASSRLS roles do. Tenants are then not
+// isolated from each other.
+func (db *DB) BypassesRowSecurity(ctx context.Context) (bool, error) {
+	var bypass bool
+	err := db.Pool.QueryRow(ctx, "SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass)
+	return bypass, err
+}
+
 func (db *DB) BeginTx(ctx context.Context) (pgx.Tx, error) {
 	return db.Pool.Begin(ctx)
 }
@@ -44,3 +70,10 @@ type Querier interface {
 	Query(ctx context.Context, sql string, arguments ...interface{}) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
 }
+
+// Transactor runs a function inside a database transaction. The context passed
+// to fn carries the transaction, so repositories called with it join the same
+// transaction.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
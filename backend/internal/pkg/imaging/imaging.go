@@ -0,0 +1,154 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Size is a responsive variant, scaled down to Width with the aspect ratio kept
+type Size struct {
+	Name  string
+	Width int
+}
+
+// DefaultSizes are the variants generated for every product image
+var DefaultSizes = []Size{
+	{Name: "thumb", Width: 150},
+	{Name: "card", Width: 600},
+	{Name: "detail", Width: 1200},
+}
+
+// Output formats, as named by image.Decode
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+const jpegQuality = 85
+
+// webpSuffix names the WebP copy of a size, e.g. "thumb_webp"
+const webpSuffix = "_webp"
+
+// Variant is an encoded, resized copy of an image
+type Variant struct {
+	Name   string
+	Format string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// Decode reads a JPEG, PNG, GIF or WebP image and returns it with its format
+func Decode(r io.Reader) (image.Image, string, error) {
+	img, format, err := image.Decode(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, format, nil
+}
+
+// Resize scales img down to width, keeping its aspect ratio. An image that is
+// already narrower is returned as is, images are never scaled up.
+func Resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if width <= 0 || bounds.Dx() <= width {
+		return img
+	}
+
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// OutputFormat is the format variants of img are encoded in: JPEG, unless the
+// image has transparency that JPEG would lose
+func OutputFormat(img image.Image) string {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+		return FormatPNG
+	}
+	return FormatJPEG
+}
+
+// Extension returns the file extension for a format
+func Extension(format string) string {
+	switch format {
+	case FormatPNG:
+		return ".png"
+	case FormatGIF:
+		return ".gif"
+	case FormatWebP:
+		return ".webp"
+	}
+	return ".jpg"
+}
+
+// Encode writes img to w in the given output format. WebP is lossy, with the
+// transparency kept, and only available when WebPAvailable.
+func Encode(w io.Writer, img image.Image, format string) error {
+	var err error
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case FormatPNG:
+		err = png.Encode(w, img)
+	case FormatWebP:
+		err = encodeWebP(w, img)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+	return nil
+}
+
+// Variants resizes img to every size narrower than the image itself and
+// encodes the copies in OutputFormat. With WebP set each copy is also encoded
+// as WebP, under the size's name with webpSuffix. Sizes the image is too small
+// for are left out, so the result may be empty.
+func (p Pipeline) Variants(img image.Image, sizes []Size) ([]Variant, error) {
+	formats := []string{OutputFormat(img)}
+	if p.WebP {
+		formats = append(formats, FormatWebP)
+	}
+
+	variants := []Variant{}
+	for _, size := range sizes {
+		if img.Bounds().Dx() <= size.Width {
+			continue
+		}
+
+		resized := Resize(img, size.Width)
+		for _, format := range formats {
+			var buf bytes.Buffer
+			if err := Encode(&buf, resized, format); err != nil {
+				return nil, err
+			}
+			name := size.Name
+			if format == FormatWebP {
+				name += webpSuffix
+			}
+			variants = append(variants, Variant{
+				Name:   name,
+				Format: format,
+				Width:  resized.Bounds().Dx(),
+				Height: resized.Bounds().Dy(),
+				Data:   buf.Bytes(),
+			})
+		}
+	}
+	return variants, nil
+}
@@ -0,0 +1,76 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newImage(width, height int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: alpha})
+		}
+	}
+	return img
+}
+
+func TestResize_KeepsAspectRatio(t *testing.T) {
+	resized := Resize(newImage(1600, 900, 255), 600)
+
+	assert.Equal(t, 600, resized.Bounds().Dx())
+	assert.Equal(t, 337, resized.Bounds().Dy())
+}
+
+func TestResize_NeverUpscales(t *testing.T) {
+	img := newImage(400, 300, 255)
+
+	assert.Same(t, img, Resize(img, 600))
+}
+
+func TestVariants_SkipsLargerSizes(t *testing.T) {
+	variants, err := Pipeline{}.Variants(newImage(800, 400, 255), DefaultSizes)
+	require.NoError(t, err)
+
+	require.Len(t, variants, 2)
+	assert.Equal(t, "thumb", variants[0].Name)
+	assert.Equal(t, 150, variants[0].Width)
+	assert.Equal(t, 75, variants[0].Height)
+	assert.Equal(t, "card", variants[1].Name)
+	assert.Equal(t, FormatJPEG, variants[1].Format)
+
+	decoded, err := jpeg.Decode(bytes.NewReader(variants[1].Data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 600, 300), decoded.Bounds())
+}
+
+func TestVariants_KeepsTransparency(t *testing.T) {
+	variants, err := Pipeline{}.Variants(newImage(300, 300, 100), DefaultSizes)
+	require.NoError(t, err)
+
+	require.Len(t, variants, 1)
+	assert.Equal(t, FormatPNG, variants[0].Format)
+	_, err = png.Decode(bytes.NewReader(variants[0].Data))
+	assert.NoError(t, err)
+}
+
+func TestDecode(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, newImage(10, 10, 255)))
+
+	img, format, err := Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 10, img.Bounds().Dx())
+
+	_, _, err = Decode(strings.NewReader("not an image"))
+	assert.Error(t, err)
+}
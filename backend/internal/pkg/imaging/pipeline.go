@@ -0,0 +1,170 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// Pipeline turns an uploaded image into the image that is stored: turned
+// upright by its EXIF orientation, scaled down to MaxDimension and encoded
+// again. Encoding again drops EXIF (GPS position, camera details), XMP and ICC
+// profiles, the encoders write pixels only.
+type Pipeline struct {
+	MaxDimension int  // longest side of the stored image, 0 keeps the size
+	WebP         bool // also store the resized copies as WebP, needs WebPAvailable
+}
+
+// Processed is an image as the pipeline stores it
+type Processed struct {
+	Image  image.Image
+	Format string
+	Data   []byte
+}
+
+// Process runs the pipeline on img, decoded from data in format. JPEG and PNG
+// images keep their format; GIF and WebP are stored as PNG or JPEG as
+// OutputFormat picks. WebP is only written for the resized copies, lossy.
+func (p Pipeline) Process(data []byte, img image.Image, format string) (Processed, error) {
+	img = Orient(img, Orientation(data))
+
+	if p.MaxDimension > 0 {
+		bounds := img.Bounds()
+		if bounds.Dx() > p.MaxDimension || bounds.Dy() > p.MaxDimension {
+			width := p.MaxDimension
+			if bounds.Dy() > bounds.Dx() {
+				width = bounds.Dx() * p.MaxDimension / bounds.Dy()
+			}
+			img = Resize(img, max(width, 1))
+		}
+	}
+
+	if format != FormatJPEG && format != FormatPNG {
+		format = OutputFormat(img)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, img, format); err != nil {
+		return Processed{}, err
+	}
+	return Processed{Image: img, Format: format, Data: buf.Bytes()}, nil
+}
+
+// exifOrientationTag is the TIFF tag of the orientation in EXIF data
+const exifOrientationTag = 0x0112
+
+// Orientation returns the EXIF orientation of a JPEG image, 1 to 8. Images
+// without one, or in another format, are upright: 1.
+func Orientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	// Walk the segments before the image data for APP1 "Exif"
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan, end of image
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 || i+2+length > len(data) {
+			break
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of TIFF data
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[offset : offset+2]))
+	for n := 0; n < entries; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:entry+2]) != exifOrientationTag {
+			continue
+		}
+		// A SHORT, stored in the first bytes of the value field
+		if orientation := int(order.Uint16(tiff[entry+8 : entry+10])); orientation >= 1 && orientation <= 8 {
+			return orientation
+		}
+		break
+	}
+	return 1
+}
+
+// Orient turns img upright according to an EXIF orientation: 2 to 4 flip or
+// turn it half way, 5 to 8 swap its width and height
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	src := toNRGBA(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotate 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // flip vertically
+				sx, sy = x, h-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // rotate 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotate 90° counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// toNRGBA converts img to an NRGBA image with its origin at 0, 0
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok && nrgba.Rect.Min == (image.Point{}) {
+		return nrgba
+	}
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	return dst
+}
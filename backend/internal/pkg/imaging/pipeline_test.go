@@ -0,0 +1,131 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withEXIF inserts an APP1 segment with the orientation tag and a GPS note
+// after the start of a JPEG image
+func withEXIF(data []byte, orientation uint16, order binary.AppendByteOrder) []byte {
+	tiff := []byte("MM\x00\x2a")
+	if order == binary.LittleEndian {
+		tiff = []byte("II\x2a\x00")
+	}
+	tiff = order.AppendUint32(tiff, 8)
+	tiff = order.AppendUint16(tiff, 1)
+	tiff = order.AppendUint16(tiff, exifOrientationTag)
+	tiff = order.AppendUint16(tiff, 3) // SHORT
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS 6.2088 S 106.8456 E"...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	return append(append([]byte{0xFF, 0xD8}, app1...), data[2:]...)
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func TestOrientation(t *testing.T) {
+	data := encodeJPEG(t, newImage(8, 4, 255))
+
+	assert.Equal(t, 1, Orientation(data))
+	assert.Equal(t, 6, Orientation(withEXIF(data, 6, binary.BigEndian)))
+	assert.Equal(t, 8, Orientation(withEXIF(data, 8, binary.LittleEndian)))
+	assert.Equal(t, 1, Orientation(withEXIF(data, 9, binary.BigEndian)))
+	assert.Equal(t, 1, Orientation([]byte("not an image")))
+	assert.Equal(t, 1, Orientation(withEXIF(data, 6, binary.BigEndian)[:20]))
+}
+
+func TestOrient(t *testing.T) {
+	img := newImage(3, 2, 255)
+
+	tests := []struct {
+		orientation int
+		width       int
+		// Source pixel of the top-left and top-right pixel of the result
+		topLeft, topRight image.Point
+	}{
+		{orientation: 1, width: 3, topLeft: image.Pt(0, 0), topRight: image.Pt(2, 0)},
+		{orientation: 2, width: 3, topLeft: image.Pt(2, 0), topRight: image.Pt(0, 0)},
+		{orientation: 3, width: 3, topLeft: image.Pt(2, 1), topRight: image.Pt(0, 1)},
+		{orientation: 4, width: 3, topLeft: image.Pt(0, 1), topRight: image.Pt(2, 1)},
+		{orientation: 5, width: 2, topLeft: image.Pt(0, 0), topRight: image.Pt(0, 1)},
+		{orientation: 6, width: 2, topLeft: image.Pt(0, 1), topRight: image.Pt(0, 0)},
+		{orientation: 7, width: 2, topLeft: image.Pt(2, 1), topRight: image.Pt(2, 0)},
+		{orientation: 8, width: 2, topLeft: image.Pt(2, 0), topRight: image.Pt(2, 1)},
+	}
+	for _, tt := range tests {
+		oriented := Orient(img, tt.orientation)
+		bounds := oriented.Bounds()
+
+		assert.Equal(t, tt.width, bounds.Dx(), "orientation %d", tt.orientation)
+		assert.Equal(t, img.At(tt.topLeft.X, tt.topLeft.Y), oriented.At(0, 0), "orientation %d", tt.orientation)
+		assert.Equal(t, img.At(tt.topRight.X, tt.topRight.Y), oriented.At(bounds.Dx()-1, 0), "orientation %d", tt.orientation)
+	}
+}
+
+func TestPipeline_Process_RotatesAndStripsEXIF(t *testing.T) {
+	data := withEXIF(encodeJPEG(t, newImage(80, 40, 255)), 6, binary.BigEndian)
+	img, _, err := Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	processed, err := Pipeline{}.Process(data, img, FormatJPEG)
+
+	require.NoError(t, err)
+	assert.Equal(t, FormatJPEG, processed.Format)
+	assert.Equal(t, image.Rect(0, 0, 40, 80), processed.Image.Bounds())
+	assert.NotContains(t, string(processed.Data), "Exif")
+	assert.NotContains(t, string(processed.Data), "GPS")
+	assert.Equal(t, 1, Orientation(processed.Data))
+
+	decoded, err := jpeg.DecodeConfig(bytes.NewReader(processed.Data))
+	require.NoError(t, err)
+	assert.Equal(t, 40, decoded.Width)
+	assert.Equal(t, 80, decoded.Height)
+}
+
+func TestPipeline_Process_MaxDimension(t *testing.T) {
+	pipeline := Pipeline{MaxDimension: 100}
+
+	processed, err := pipeline.Process(nil, newImage(200, 400, 255), FormatPNG)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 50, 100), processed.Image.Bounds())
+	_, err = png.Decode(bytes.NewReader(processed.Data))
+	assert.NoError(t, err)
+
+	// Smaller images keep their size
+	processed, err = pipeline.Process(nil, newImage(80, 60, 255), FormatPNG)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 80, 60), processed.Image.Bounds())
+}
+
+func TestPipeline_Process_GIFStoredAsPNGOrJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, newImage(10, 10, 255), nil))
+	img, format, err := Decode(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	processed, err := Pipeline{}.Process(buf.Bytes(), img, format)
+
+	require.NoError(t, err)
+	assert.Equal(t, OutputFormat(img), processed.Format)
+	assert.Equal(t, processed.Format, DetectFormat(processed.Data))
+}
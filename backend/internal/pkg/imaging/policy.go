@@ -0,0 +1,115 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Formats uploaded images may be in besides JPEG and PNG, as named by
+// image.Decode
+const (
+	FormatGIF  = "gif"
+	FormatWebP = "webp"
+)
+
+// contentTypes maps the formats this package decodes to the content type
+// http.DetectContentType reports for them
+var contentTypes = map[string]string{
+	FormatJPEG: "image/jpeg",
+	FormatPNG:  "image/png",
+	FormatGIF:  "image/gif",
+	FormatWebP: "image/webp",
+}
+
+// ErrTooManyPixels is returned for an image whose width times height is above
+// the policy's limit
+var ErrTooManyPixels = errors.New("image has too many pixels")
+
+// FormatError is returned for an upload that is not an image in one of the
+// allowed formats
+type FormatError struct {
+	Allowed []string
+}
+
+func (e *FormatError) Error() string {
+	return "invalid image format, only " + e.AllowedNames() + " are allowed"
+}
+
+// AllowedNames lists the allowed formats for people, e.g. "JPEG, PNG"
+func (e *FormatError) AllowedNames() string {
+	return strings.ToUpper(strings.Join(e.Allowed, ", "))
+}
+
+// Supported reports whether format is one uploaded images can be decoded from
+func Supported(format string) bool {
+	_, ok := contentTypes[format]
+	return ok
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// DetectFormat returns the format of an image by its magic bytes, the data
+// itself rather than a file name. Only the first 512 bytes are looked at. It
+// returns "" when data is not in a format this package decodes.
+func DetectFormat(data []byte) string {
+	contentType := http.DetectContentType(data)
+	for format, ct := range contentTypes {
+		if ct == contentType {
+			return format
+		}
+	}
+	return ""
+}
+
+// Policy is what an uploaded image is checked against
+type Policy struct {
+	AllowedFormats []string
+	MaxPixels      int // width times height, 0 for no limit
+}
+
+// Allows reports whether images in format may be uploaded
+func (p Policy) Allows(format string) bool {
+	return slices.Contains(p.AllowedFormats, format)
+}
+
+// CheckFormat detects the format of data and returns it, or a *FormatError
+// when it is not allowed
+func (p Policy) CheckFormat(data []byte) (string, error) {
+	format := DetectFormat(data)
+	if format == "" || !p.Allows(format) {
+		return "", &FormatError{Allowed: p.AllowedFormats}
+	}
+	return format, nil
+}
+
+// Decode checks an uploaded image and decodes it. The format is detected from
+// the content, then the dimensions are read from the header so that an image
+// too large to decode safely is rejected before its pixels are allocated.
+func (p Policy) Decode(data []byte) (image.Image, string, error) {
+	format, err := p.CheckFormat(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	config, configFormat, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || configFormat != format {
+		return nil, "", &FormatError{Allowed: p.AllowedFormats}
+	}
+	if p.MaxPixels > 0 && int64(config.Width)*int64(config.Height) > int64(p.MaxPixels) {
+		return nil, "", fmt.Errorf("%w: %dx%d is above %d", ErrTooManyPixels, config.Width, config.Height, p.MaxPixels)
+	}
+
+	img, _, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", &FormatError{Allowed: p.AllowedFormats}
+	}
+	return img, format, nil
+}
@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = Policy{
+	AllowedFormats: []string{FormatJPEG, FormatPNG},
+	MaxPixels:      10000,
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, newImage(width, height, 255)))
+	return buf.Bytes()
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, FormatPNG, DetectFormat(encodePNG(t, 4, 4)))
+	assert.Equal(t, FormatWebP, DetectFormat([]byte("RIFF\x00\x00\x00\x00WEBPVP8 ")))
+	assert.Equal(t, "", DetectFormat([]byte("%PDF-1.7")))
+	// BMP is sniffed by net/http but not decoded here
+	assert.Equal(t, "", DetectFormat([]byte("BM\x00\x00\x00\x00")))
+}
+
+func TestPolicy_Decode(t *testing.T) {
+	img, format, err := testPolicy.Decode(encodePNG(t, 100, 50))
+
+	require.NoError(t, err)
+	assert.Equal(t, FormatPNG, format)
+	assert.Equal(t, 100, img.Bounds().Dx())
+}
+
+func TestPolicy_Decode_FormatNotAllowed(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, newImage(4, 4, 255), nil))
+
+	_, _, err := testPolicy.Decode(buf.Bytes())
+
+	var formatErr *FormatError
+	require.True(t, errors.As(err, &formatErr))
+	assert.Equal(t, "JPEG, PNG", formatErr.AllowedNames())
+	assert.Equal(t, "invalid image format, only JPEG, PNG are allowed", err.Error())
+}
+
+func TestPolicy_Decode_NotAnImage(t *testing.T) {
+	_, _, err := testPolicy.Decode([]byte("not an image"))
+
+	var formatErr *FormatError
+	assert.True(t, errors.As(err, &formatErr))
+}
+
+func TestPolicy_Decode_Truncated(t *testing.T) {
+	data := encodePNG(t, 50, 50)
+
+	_, _, err := testPolicy.Decode(data[:len(data)/2])
+
+	var formatErr *FormatError
+	assert.True(t, errors.As(err, &formatErr))
+}
+
+func TestPolicy_Decode_TooManyPixels(t *testing.T) {
+	_, _, err := testPolicy.Decode(encodePNG(t, 101, 100))
+
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+}
+
+// Only the header is read: a PNG claiming huge dimensions is rejected without
+// its pixels being allocated
+func TestPolicy_Decode_DecompressionBomb(t *testing.T) {
+	data := encodePNG(t, 1, 1)
+	// IHDR width and height, 65536x65536, and the chunk's CRC
+	copy(data[16:24], []byte{0, 1, 0, 0, 0, 1, 0, 0})
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	_, _, err := Policy{AllowedFormats: []string{FormatPNG}, MaxPixels: 100_000_000}.Decode(data)
+
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+}
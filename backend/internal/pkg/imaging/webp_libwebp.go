@@ -0,0 +1,40 @@
+//go:build cgo && libwebp
+
+package imaging
+
+// #cgo LDFLAGS: -lwebp
+// #include <webp/encode.h>
+import "C"
+
+import (
+	"errors"
+	"image"
+	"io"
+	"unsafe"
+)
+
+// WebPAvailable reports whether this build can encode WebP. It links libwebp,
+// built with the libwebp tag.
+const WebPAvailable = true
+
+const webpQuality = 80
+
+// encodeWebP writes img as a lossy WebP image with libwebp. Transparency is
+// kept.
+func encodeWebP(w io.Writer, img image.Image) error {
+	src := toNRGBA(img)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+	if width < 1 || height < 1 || width > C.WEBP_MAX_DIMENSION || height > C.WEBP_MAX_DIMENSION {
+		return errors.New("webp: image size out of range")
+	}
+
+	var output *C.uint8_t
+	size := C.WebPEncodeRGBA((*C.uint8_t)(unsafe.Pointer(&src.Pix[0])), C.int(width), C.int(height), C.int(src.Stride), C.float(webpQuality), &output)
+	if size == 0 {
+		return errors.New("webp: libwebp failed to encode the image")
+	}
+	defer C.WebPFree(unsafe.Pointer(output))
+
+	_, err := w.Write(C.GoBytes(unsafe.Pointer(output), C.int(size)))
+	return err
+}
@@ -0,0 +1,48 @@
+//go:build cgo && libwebp
+
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func TestVariants_WebPCopies(t *testing.T) {
+	variants, err := Pipeline{WebP: true}.Variants(newImage(800, 400, 255), DefaultSizes)
+	require.NoError(t, err)
+
+	require.Len(t, variants, 4)
+	for i, name := range []string{"thumb_webp", "card_webp"} {
+		v := variants[2*i+1]
+		assert.Equal(t, name, v.Name)
+		assert.Equal(t, FormatWebP, v.Format)
+		assert.Equal(t, variants[2*i].Width, v.Width)
+		assert.Equal(t, variants[2*i].Height, v.Height)
+
+		decoded, err := webp.Decode(bytes.NewReader(v.Data))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, v.Width, v.Height), decoded.Bounds())
+	}
+}
+
+func TestEncode_WebP_KeepsTransparency(t *testing.T) {
+	img := newImage(90, 40, 255)
+	for x := 0; x < 30; x++ {
+		img.Pix[img.PixOffset(x, 10)+3] = 0
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, img, FormatWebP))
+	decoded, err := webp.Decode(&buf)
+	require.NoError(t, err)
+
+	nycbcra, ok := decoded.(*image.NYCbCrA)
+	require.True(t, ok, "decoded a %T", decoded)
+	assert.Equal(t, uint8(0), nycbcra.A[nycbcra.AOffset(5, 10)])
+	assert.Equal(t, uint8(255), nycbcra.A[nycbcra.AOffset(50, 10)])
+}
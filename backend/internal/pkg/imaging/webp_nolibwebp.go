@@ -0,0 +1,17 @@
+//go:build !cgo || !libwebp
+
+package imaging
+
+import (
+	"errors"
+	"image"
+	"io"
+)
+
+// WebPAvailable reports whether this build can encode WebP. It links libwebp,
+// built with the libwebp tag.
+const WebPAvailable = false
+
+func encodeWebP(w io.Writer, img image.Image) error {
+	return errors.New("webp: encoding needs a build with the libwebp tag")
+}
@@ -0,0 +1,22 @@
+//go:build !cgo || !libwebp
+
+package imaging
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode_WebP_Unavailable(t *testing.T) {
+	err := Encode(&bytes.Buffer{}, newImage(10, 10, 255), FormatWebP)
+
+	assert.Error(t, err)
+}
+
+func TestVariants_WebPUnavailable(t *testing.T) {
+	_, err := Pipeline{WebP: true}.Variants(newImage(800, 400, 255), DefaultSizes)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package requestctx
+
+import "context"
+
+// SystemActor is recorded as the actor of changes that are not made by a
+// user or an API key, such as the trash purge and opening balances
+const SystemActor = "system"
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+	tenantIDKey
+)
+
+// WithActor returns a copy of ctx that names who the request is made by
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns the actor stored by WithActor, or "" outside a request
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey).(string)
+	return actor
+}
+
+// ActorOrSystem returns the actor stored by WithActor, or SystemActor when
+// the change is not made within a request
+func ActorOrSystem(ctx context.Context) string {
+	if actor := Actor(ctx); actor != "" {
+		return actor
+	}
+	return SystemActor
+}
+
+// WithRequestID returns a copy of ctx carrying the ID of the HTTP request
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the ID stored by WithRequestID, or "" outside a request
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithTenantID returns a copy of ctx scoped to a tenant. Database
+// connections acquired with it only see that tenant's rows.
+func WithTenantID(ctx context.Context, tenantID int64) context.Context {
+	return context.WithValue(ctx, tenantIDKey, tenantID)
+}
+
+// TenantID returns the tenant stored by WithTenantID; ok is false when the
+// context is not scoped to a tenant
+func TenantID(ctx context.Context) (tenantID int64, ok bool) {
+	tenantID, ok = ctx.Value(tenantIDKey).(int64)
+	return tenantID, ok
+}
@@ -0,0 +1,18 @@
+package slug
+
+import (
+	"regexp"
+	"strings"
+)
+
+var nonAlphanumericRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Make turns a display name into a URL-safe slug, e.g. "Home & Garden" and
+// "home and garden" both become "home-and-garden". Migration 000004 applies the
+// same rules in SQL, keep the two in sync.
+func Make(s string) string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "&", " and ")
+	s = nonAlphanumericRegex.ReplaceAllString(s, "-")
+	return strings.Trim(s, "-")
+}
@@ -35,7 +35,7 @@ func NewLocalStorage(basePath, baseURL string) (*LocalStorage, error) {
 
 func (s *LocalStorage) Upload(ctx context.Context, file io.Reader, path string, contentType string) (string, error) {
 	// Sanitize path to prevent directory traversal
-	cleanPath := filepath.Clean(path)
+	cleanPath := tenantPath(ctx, filepath.Clean(path))
 	fullPath := filepath.Join(s.basePath, cleanPath)
 
 	// Get absolute paths for comparison
@@ -85,6 +85,10 @@ func (s *LocalStorage) Upload(ctx context.Context, file io.Reader, path string,
 }
 
 func (s *LocalStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := checkTenant(ctx, path); err != nil {
+		return nil, err
+	}
+
 	cleanPath := filepath.Clean(path)
 	fullPath := filepath.Join(s.basePath, cleanPath)
 
@@ -112,10 +116,21 @@ func (s *LocalStorage) Download(ctx context.Context, path string) (io.ReadCloser
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
+	// Directories are not downloadable, their listing would name other files
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+
 	return file, nil
 }
 
 func (s *LocalStorage) Delete(ctx context.Context, path string) error {
+	if err := checkTenant(ctx, path); err != nil {
+		return err
+	}
+
 	cleanPath := filepath.Clean(path)
 	fullPath := filepath.Join(s.basePath, cleanPath)
 
@@ -153,6 +168,10 @@ func (s *LocalStorage) GetURL(ctx context.Context, path string, expiry time.Dura
 }
 
 func (s *LocalStorage) Exists(ctx context.Context, path string) (bool, error) {
+	if err := checkTenant(ctx, path); err != nil {
+		return false, err
+	}
+
 	cleanPath := filepath.Clean(path)
 	fullPath := filepath.Join(s.basePath, cleanPath)
 
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage_TenantNamespacing(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir(), "http://localhost:8080/uploads")
+	require.NoError(t, err)
+
+	ctx := requestctx.WithTenantID(context.Background(), 2)
+	path, err := s.Upload(ctx, strings.NewReader("image"), "products/1/photo.jpg", "image/jpeg")
+
+	require.NoError(t, err)
+	assert.Equal(t, "tenants/2/products/1/photo.jpg", path)
+
+	exists, err := s.Exists(ctx, path)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Another tenant can neither read nor delete the file
+	other := requestctx.WithTenantID(context.Background(), 3)
+	_, err = s.Download(other, path)
+	assert.Error(t, err)
+	assert.Error(t, s.Delete(other, path))
+	assert.Error(t, s.Delete(other, "/"+path))
+
+	require.NoError(t, s.Delete(ctx, path))
+	exists, err = s.Exists(ctx, path)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestLocalStorage_LegacyPathWithoutTenant(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir(), "http://localhost:8080/uploads")
+	require.NoError(t, err)
+
+	path, err := s.Upload(context.Background(), strings.NewReader("image"), "products/1/photo.jpg", "image/jpeg")
+	require.NoError(t, err)
+	assert.Equal(t, "products/1/photo.jpg", path)
+
+	exists, err := s.Exists(requestctx.WithTenantID(context.Background(), 2), path)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
@@ -0,0 +1,441 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// s3DefaultRegion is used when none is configured; MinIO accepts any
+	s3DefaultRegion = "us-east-1"
+
+	// s3MaxPresignExpiry is the longest lifetime S3 allows a presigned URL
+	s3MaxPresignExpiry = 7 * 24 * time.Hour
+
+	s3TimeFormat = "20060102T150405Z"
+	s3DateFormat = "20060102"
+)
+
+// S3StorageOptions configures an S3-compatible object store, such as AWS S3
+// or MinIO
+type S3StorageOptions struct {
+	Endpoint  string // host[:port], e.g. "localhost:9000" or "s3.eu-west-1.amazonaws.com"
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string // defaults to us-east-1
+	UseSSL    bool
+
+	// Address the bucket in the path (http://endpoint/bucket/key), as MinIO
+	// expects, instead of the host name (http://bucket.endpoint/key)
+	PathStyle bool
+
+	// Public address of the bucket, returned by GetURL without expiry. It
+	// defaults to the bucket's address on the endpoint.
+	BaseURL string
+
+	HTTPClient *http.Client // defaults to http.DefaultClient
+}
+
+// S3Storage stores files as objects of an S3-compatible bucket. Requests are
+// signed with AWS Signature Version 4.
+type S3Storage struct {
+	endpoint  string
+	accessKey string
+	secretKey string
+	bucket    string
+	region    string
+	scheme    string
+	pathStyle bool
+	baseURL   string
+	client    *http.Client
+
+	now func() time.Time
+}
+
+func NewS3Storage(opts S3StorageOptions) (*S3Storage, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return nil, errors.New("S3 endpoint and bucket are required")
+	}
+	if opts.AccessKey == "" || opts.SecretKey == "" {
+		return nil, errors.New("S3 access key and secret key are required")
+	}
+
+	s := &S3Storage{
+		endpoint:  strings.TrimSuffix(opts.Endpoint, "/"),
+		accessKey: opts.AccessKey,
+		secretKey: opts.SecretKey,
+		bucket:    opts.Bucket,
+		region:    opts.Region,
+		scheme:    "http",
+		pathStyle: opts.PathStyle,
+		baseURL:   strings.TrimSuffix(opts.BaseURL, "/"),
+		client:    opts.HTTPClient,
+		now:       time.Now,
+	}
+	if opts.UseSSL {
+		s.scheme = "https"
+	}
+	if s.region == "" {
+		s.region = s3DefaultRegion
+	}
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+	if s.baseURL == "" {
+		s.baseURL = strings.TrimSuffix(s.objectURL("").String(), "/")
+	}
+
+	return s, nil
+}
+
+func (s *S3Storage) Upload(ctx context.Context, file io.Reader, path string, contentType string) (string, error) {
+	key, err := objectKey(tenantPath(ctx, filepath.Clean(path)))
+	if err != nil {
+		return "", err
+	}
+
+	// S3 needs the length and hash of the body up front, so the file is
+	// buffered; uploads are size-limited images
+	body, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, header, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file: %w", err)
+	}
+	resp.Body.Close()
+
+	return key, nil
+}
+
+func (s *S3Storage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	key, err := s.resolve(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		var s3Err *s3Error
+		if errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("file not found: %s", path)
+		}
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, path string) error {
+	key, err := s.resolve(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	// Deleting a missing object succeeds, as it does for local files
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// GetURL returns a presigned GET URL valid for expiry. Without an expiry it
+// returns the object's public URL, which only works for a public bucket or
+// behind a proxy that serves it.
+func (s *S3Storage) GetURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	key, err := s.resolve(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	if expiry <= 0 {
+		return s.baseURL + "/" + key, nil
+	}
+	if expiry > s3MaxPresignExpiry {
+		return "", fmt.Errorf("presigned URL expiry must not exceed %s", s3MaxPresignExpiry)
+	}
+
+	return s.presign(http.MethodGet, key, expiry).String(), nil
+}
+
+func (s *S3Storage) Exists(ctx context.Context, path string) (bool, error) {
+	key, err := s.resolve(ctx, path)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		var s3Err *s3Error
+		if errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	resp.Body.Close()
+
+	return true, nil
+}
+
+// resolve returns the object key of a path, which may also be a URL returned
+// by GetURL without expiry
+func (s *S3Storage) resolve(ctx context.Context, path string) (string, error) {
+	path = strings.TrimPrefix(path, s.baseURL+"/")
+	if err := checkTenant(ctx, path); err != nil {
+		return "", err
+	}
+	return objectKey(path)
+}
+
+// objectKey turns a file path into an object key, refusing paths that
+// climb out of the bucket
+func objectKey(name string) (string, error) {
+	key := pathpkg.Clean(filepath.ToSlash(name))
+	if key == "." || key == ".." || strings.HasPrefix(key, "../") || strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("invalid file path: %s", name)
+	}
+	return key, nil
+}
+
+// objectURL is the unsigned URL of an object, or of the bucket for key ""
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := &url.URL{Scheme: s.scheme, Host: s.endpoint, Path: "/" + key}
+	if s.pathStyle {
+		u.Path = "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + s.endpoint
+	}
+	u.RawPath = s3EscapePath(u.Path)
+	return u
+}
+
+// do sends a signed request for an object and returns the response when its
+// status is 2xx, or an *s3Error
+func (s *S3Storage) do(ctx context.Context, method, key string, header http.Header, body []byte) (*http.Response, error) {
+	u := s.objectURL(key)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.ContentLength = int64(len(body))
+	if body == nil {
+		req.Body = http.NoBody
+	}
+
+	s.sign(req, hashHex(body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, newS3Error(resp)
+	}
+
+	return resp, nil
+}
+
+// sign adds an Authorization header signing the request's method, URL,
+// host, content type and payload hash
+func (s *S3Storage) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format(s3TimeFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = contentType
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now)
+	signature := s.signature(now, amzDate, scope, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature,
+	))
+}
+
+// presign returns a URL for the object that carries its signature in the
+// query string and is valid for expiry
+func (s *S3Storage) presign(method, key string, expiry time.Duration) *url.URL {
+	now := s.now().UTC()
+	amzDate := now.Format(s3TimeFormat)
+	scope := s.scope(now)
+
+	u := s.objectURL(key)
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		s3CanonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(now, amzDate, scope, canonicalRequest))
+	u.RawQuery = s3CanonicalQuery(query)
+	return u
+}
+
+func (s *S3Storage) scope(t time.Time) string {
+	return t.Format(s3DateFormat) + "/" + s.region + "/s3/aws4_request"
+}
+
+func (s *S3Storage) signature(t time.Time, amzDate, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), t.Format(s3DateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// s3CanonicalQuery encodes a query sorted by name, escaped the way
+// Signature Version 4 requires
+func s3CanonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(name, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func s3EscapePath(path string) string {
+	return s3Escape(path, false)
+}
+
+// s3Escape percent-encodes everything but unreserved characters, and "/"
+// unless encodeSlash is set
+func s3Escape(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error is an error response of the object store
+type s3Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func newS3Error(resp *http.Response) *s3Error {
+	e := &s3Error{}
+	// HEAD responses have no body; the status is all there is
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = xml.Unmarshal(body, e)
+	e.StatusCode = resp.StatusCode
+	return e
+}
+
+func (e *s3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("object store returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("object store returned %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
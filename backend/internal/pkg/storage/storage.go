@@ -2,10 +2,20 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
 )
 
+// tenantDir holds a directory per tenant. Uploads made for a tenant go into
+// its directory, and other tenants cannot read or delete them.
+const tenantDir = "tenants"
+
 type FileStorage interface {
 	// Upload uploads a file and returns the file path/key
 	Upload(ctx context.Context, file io.Reader, path string, contentType string) (string, error)
@@ -28,3 +38,31 @@ type UploadOptions struct {
 	MaxSize     int64
 	AllowedExts []string
 }
+
+// tenantPath places path in the directory of the context's tenant. Without a
+// tenant the path is used as is.
+func tenantPath(ctx context.Context, path string) string {
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return path
+	}
+	return filepath.Join(tenantDir, strconv.FormatInt(tenantID, 10), path)
+}
+
+// checkTenant rejects a path in the directory of a tenant other than the
+// context's. Files stored before tenants existed are outside any tenant
+// directory and stay reachable.
+func checkTenant(ctx context.Context, path string) error {
+	cleanPath := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "/")
+	dir, rest, _ := strings.Cut(cleanPath, "/")
+	if dir != tenantDir {
+		return nil
+	}
+
+	owner, _, _ := strings.Cut(rest, "/")
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok || owner != strconv.FormatInt(tenantID, 10) {
+		return fmt.Errorf("invalid file path: %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	auditDomain "github.com/naxumi/bnsp-jwd/internal/domain/audit"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type auditRepositoryImpl struct {
+	db *database.DB
+}
+
+func NewAuditRepository(db *database.DB) auditDomain.AuditRepository {
+	return &auditRepositoryImpl{db: db}
+}
+
+func (r *auditRepositoryImpl) Create(ctx context.Context, record auditDomain.Record) (auditDomain.Record, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		INSERT INTO audit_log (entity_type, entity_id, action, actor, request_id, changes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at
+	`
+
+	err := q.QueryRow(ctx, query,
+		record.EntityType,
+		record.EntityID,
+		record.Action,
+		record.Actor,
+		record.RequestID,
+		record.Changes,
+	).Scan(&record.ID, &record.CreatedAt)
+	if err != nil {
+		return auditDomain.Record{}, fmt.Errorf("failed to create audit record: %w", err)
+	}
+
+	return record, nil
+}
+
+func (r *auditRepositoryImpl) List(ctx context.Context, filter auditDomain.ListAuditFilter) ([]auditDomain.Record, int64, error) {
+	q := GetQuerier(ctx, r.db)
+
+	whereClauses := []string{"TRUE"}
+	args := []interface{}{}
+	argIdx := 1
+
+	if filter.EntityType != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("entity_type = $%d", argIdx))
+		args = append(args, *filter.EntityType)
+		argIdx++
+	}
+	if filter.EntityID != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("entity_id = $%d", argIdx))
+		args = append(args, *filter.EntityID)
+		argIdx++
+	}
+	if filter.Actor != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("actor = $%d", argIdx))
+		args = append(args, *filter.Actor)
+		argIdx++
+	}
+	if filter.Action != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("action = $%d", argIdx))
+		args = append(args, *filter.Action)
+		argIdx++
+	}
+	if filter.From != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("created_at >= $%d", argIdx))
+		args = append(args, *filter.From)
+		argIdx++
+	}
+	if filter.To != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("created_at <= $%d", argIdx))
+		args = append(args, *filter.To)
+		argIdx++
+	}
+
+	whereSQL := "WHERE " + strings.Join(whereClauses, " AND ")
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM audit_log %s", whereSQL)
+	var total int64
+	err := q.QueryRow(ctx, countQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit records: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, entity_type, entity_id, action, actor, request_id, changes, created_at
+		FROM audit_log
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereSQL, argIdx, argIdx+1)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get audit records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []auditDomain.Record
+	for rows.Next() {
+		var record auditDomain.Record
+		err := rows.Scan(
+			&record.ID,
+			&record.EntityType,
+			&record.EntityID,
+			&record.Action,
+			&record.Actor,
+			&record.RequestID,
+			&record.Changes,
+			&record.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, total, nil
+}
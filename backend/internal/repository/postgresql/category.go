@@ -0,0 +1,266 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	categoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/category"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type categoryRepositoryImpl struct {
+	db *database.DB
+}
+
+func NewCategoryRepository(db *database.DB) categoryDomain.CategoryRepository {
+	return &categoryRepositoryImpl{db: db}
+}
+
+func (r *categoryRepositoryImpl) Create(ctx context.Context, newCategory categoryDomain.Category) (categoryDomain.Category, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		INSERT INTO categories (parent_id, name, slug, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+
+	err := q.QueryRow(ctx, query,
+		newCategory.ParentID,
+		newCategory.Name,
+		newCategory.Slug,
+	).Scan(&newCategory.ID, &newCategory.CreatedAt, &newCategory.UpdatedAt)
+	if err != nil {
+		return categoryDomain.Category{}, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return newCategory, nil
+}
+
+func (r *categoryRepositoryImpl) GetByID(ctx context.Context, id int64) (categoryDomain.Category, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, parent_id, name, slug, created_at, updated_at
+		FROM categories
+		WHERE id = $1
+	`
+
+	var category categoryDomain.Category
+	err := q.QueryRow(ctx, query, id).
+		Scan(
+			&category.ID,
+			&category.ParentID,
+			&category.Name,
+			&category.Slug,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+		)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return categoryDomain.Category{}, fmt.Errorf("category not found: %w", err)
+		}
+		return categoryDomain.Category{}, fmt.Errorf("failed to get category by ID: %w", err)
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepositoryImpl) GetBySlug(ctx context.Context, slug string) (categoryDomain.Category, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, parent_id, name, slug, created_at, updated_at
+		FROM categories
+		WHERE slug = $1
+	`
+
+	var category categoryDomain.Category
+	err := q.QueryRow(ctx, query, slug).
+		Scan(
+			&category.ID,
+			&category.ParentID,
+			&category.Name,
+			&category.Slug,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+		)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return categoryDomain.Category{}, fmt.Errorf("category not found: %w", err)
+		}
+		return categoryDomain.Category{}, fmt.Errorf("failed to get category by slug: %w", err)
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepositoryImpl) GetByName(ctx context.Context, name string) ([]categoryDomain.Category, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, parent_id, name, slug, created_at, updated_at
+		FROM categories
+		WHERE lower(name) = lower($1)
+		ORDER BY id ASC
+	`
+
+	rows, err := q.Query(ctx, query, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories by name: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []categoryDomain.Category
+	for rows.Next() {
+		var category categoryDomain.Category
+		err := rows.Scan(
+			&category.ID,
+			&category.ParentID,
+			&category.Name,
+			&category.Slug,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (r *categoryRepositoryImpl) GetAll(ctx context.Context) ([]categoryDomain.Category, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, parent_id, name, slug, created_at, updated_at
+		FROM categories
+		ORDER BY name ASC, id ASC
+	`
+
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []categoryDomain.Category
+	for rows.Next() {
+		var category categoryDomain.Category
+		err := rows.Scan(
+			&category.ID,
+			&category.ParentID,
+			&category.Name,
+			&category.Slug,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
+func (r *categoryRepositoryImpl) Update(ctx context.Context, category categoryDomain.UpdateCategoryRequest) error {
+	q := GetQuerier(ctx, r.db)
+
+	updates := []string{}
+	args := []interface{}{}
+	argIdx := 1
+
+	if category.ParentID != nil {
+		// 0 moves the category to the top level
+		var parentID *int64
+		if *category.ParentID > 0 {
+			parentID = category.ParentID
+		}
+		updates = append(updates, fmt.Sprintf("parent_id = $%d", argIdx))
+		args = append(args, parentID)
+		argIdx++
+	}
+	if category.Name != nil {
+		updates = append(updates, fmt.Sprintf("name = $%d", argIdx))
+		args = append(args, *category.Name)
+		argIdx++
+	}
+	if category.Slug != nil {
+		updates = append(updates, fmt.Sprintf("slug = $%d", argIdx))
+		args = append(args, *category.Slug)
+		argIdx++
+	}
+
+	if len(updates) == 0 {
+		// No fields to update, just return success
+		return nil
+	}
+
+	args = append(args, category.ID)
+	query := fmt.Sprintf(`
+		UPDATE categories
+		SET %s, updated_at = NOW()
+		WHERE id = $%d
+	`, strings.Join(updates, ", "), argIdx)
+
+	commandTag, err := q.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *categoryRepositoryImpl) Delete(ctx context.Context, id int64) error {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		DELETE FROM categories
+		WHERE id = $1
+	`
+
+	commandTag, err := q.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *categoryRepositoryImpl) IsDescendant(ctx context.Context, id int64, candidateID int64) (bool, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION ALL
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)
+	`
+
+	var isDescendant bool
+	err := q.QueryRow(ctx, query, id, candidateID).Scan(&isDescendant)
+	if err != nil {
+		return false, fmt.Errorf("failed to check category ancestry: %w", err)
+	}
+
+	return isDescendant, nil
+}
@@ -3,11 +3,15 @@ package postgresql
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
 	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/slug"
 	"github.com/shopspring/decimal"
 )
 
@@ -19,13 +23,75 @@ func NewProductRepository(db *database.DB) productDomain.ProductRepository {
 	return &productRepositoryImpl{db: db}
 }
 
+// productColumns is the select list shared by every product query. The
+// warehouse breakdown and the image gallery are aggregated into JSON arrays
+// so a product still maps to a single row.
+const productColumns = `
+	id, parent_id, sku, name, description,
+	COALESCE(price, (SELECT p.price FROM products p WHERE p.id = products.parent_id)),
+	stock, category_id,
+	(SELECT c.name FROM categories c WHERE c.id = products.category_id),
+	status, image_url, image_variants, version, created_at, updated_at, deleted_at,
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'warehouse_id', w.id,
+			'warehouse_code', w.code,
+			'warehouse_name', w.name,
+			'quantity', ws.quantity
+		) ORDER BY w.id)
+		FROM warehouse_stock ws
+		JOIN warehouses w ON w.id = ws.warehouse_id
+		WHERE ws.product_id = products.id
+	), '[]'::json),
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'id', pi.id,
+			'product_id', pi.product_id,
+			'url', pi.url,
+			'variants', pi.variants,
+			'alt_text', pi.alt_text,
+			'position', pi.position,
+			'is_primary', pi.is_primary
+		) ORDER BY pi.position, pi.id)
+		FROM product_images pi
+		WHERE pi.product_id = products.id
+	), '[]'::json)
+`
+
+// scanProduct scans a row selected with productColumns, followed by any extra columns
+func scanProduct(row pgx.Row, extra ...any) (productDomain.Product, error) {
+	var product productDomain.Product
+	dest := []any{
+		&product.ID,
+		&product.ParentID,
+		&product.SKU,
+		&product.Name,
+		&product.Description,
+		&product.Price,
+		&product.Stock,
+		&product.CategoryID,
+		&product.Category,
+		&product.Status,
+		&product.ImageURL,
+		&product.ImageVariants,
+		&product.Version,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+		&product.DeletedAt,
+		&product.WarehouseStock,
+		&product.Images,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return product, err
+}
+
 func (r *productRepositoryImpl) Create(ctx context.Context, newProduct productDomain.Product) (productDomain.Product, error) {
 	q := GetQuerier(ctx, r.db)
 
 	query := `
-		INSERT INTO products (sku, name, description, price, stock, category, status, image_url, created_at, updated_at)
+		INSERT INTO products (sku, name, description, price, stock, category_id, status, image_url, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
-		RETURNING id, created_at, updated_at
+		RETURNING id, version, created_at, updated_at
 	`
 
 	err := q.QueryRow(ctx, query,
@@ -34,10 +100,10 @@ func (r *productRepositoryImpl) Create(ctx context.Context, newProduct productDo
 		newProduct.Description,
 		newProduct.Price,
 		newProduct.Stock,
-		newProduct.Category,
+		newProduct.CategoryID,
 		newProduct.Status,
 		newProduct.ImageURL,
-	).Scan(&newProduct.ID, &newProduct.CreatedAt, &newProduct.UpdatedAt)
+	).Scan(&newProduct.ID, &newProduct.Version, &newProduct.CreatedAt, &newProduct.UpdatedAt)
 	if err != nil {
 		return productDomain.Product{}, fmt.Errorf("failed to create product: %w", err)
 	}
@@ -48,27 +114,13 @@ func (r *productRepositoryImpl) Create(ctx context.Context, newProduct productDo
 func (r *productRepositoryImpl) GetByID(ctx context.Context, id int64) (productDomain.Product, error) {
 	q := GetQuerier(ctx, r.db)
 
-	query := `
-		SELECT id, sku, name, description, price, stock, category, status, image_url, created_at, updated_at
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM products
-		WHERE id = $1
-	`
+		WHERE id = $1 AND deleted_at IS NULL
+	`, productColumns)
 
-	var product productDomain.Product
-	err := q.QueryRow(ctx, query, id).
-		Scan(
-			&product.ID,
-			&product.SKU,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.Stock,
-			&product.Category,
-			&product.Status,
-			&product.ImageURL,
-			&product.CreatedAt,
-			&product.UpdatedAt,
-		)
+	product, err := scanProduct(q.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return productDomain.Product{}, fmt.Errorf("product not found: %w", err)
@@ -79,63 +131,121 @@ func (r *productRepositoryImpl) GetByID(ctx context.Context, id int64) (productD
 	return product, nil
 }
 
-func (r *productRepositoryImpl) GetBySKU(ctx context.Context, sku string) (productDomain.Product, error) {
+func (r *productRepositoryImpl) GetByIDForUpdate(ctx context.Context, id int64) (productDomain.Product, error) {
 	q := GetQuerier(ctx, r.db)
 
-	query := `
-		SELECT id, sku, name, description, price, stock, category, status, image_url, created_at, updated_at
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM products
-		WHERE sku = $1
-	`
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, productColumns)
 
-	var product productDomain.Product
-	err := q.QueryRow(ctx, query, sku).
-		Scan(
-			&product.ID,
-			&product.SKU,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.Stock,
-			&product.Category,
-			&product.Status,
-			&product.ImageURL,
-			&product.CreatedAt,
-			&product.UpdatedAt,
-		)
+	product, err := scanProduct(q.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return productDomain.Product{}, fmt.Errorf("product not found: %w", err)
 		}
-		return productDomain.Product{}, fmt.Errorf("failed to get product by SKU: %w", err)
+		return productDomain.Product{}, fmt.Errorf("failed to get product by ID for update: %w", err)
 	}
 
 	return product, nil
 }
 
-func (r *productRepositoryImpl) GetAll(ctx context.Context, filter productDomain.ListProductFilter) ([]productDomain.Product, int64, error) {
+func (r *productRepositoryImpl) GetBySKU(ctx context.Context, sku string) (productDomain.Product, error) {
 	q := GetQuerier(ctx, r.db)
 
-	whereClauses := []string{}
-	args := []interface{}{}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM products
+		WHERE sku = $1 AND deleted_at IS NULL
+	`, productColumns)
+
+	product, err := scanProduct(q.QueryRow(ctx, query, sku))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return productDomain.Product{}, fmt.Errorf("product not found: %w", err)
+		}
+		return productDomain.Product{}, fmt.Errorf("failed to get product by SKU: %w", err)
+	}
+
+	return product, nil
+}
+
+// buildProductWhere turns a list filter into a WHERE clause and its arguments,
+// shared by the product list and its facet counts. searchQuery is the
+// tsquery expression when the filter has a full-text query, otherwise empty.
+func buildProductWhere(filter productDomain.ListProductFilter) (whereSQL string, args []interface{}, searchQuery string) {
+	// Variants are listed under their parent, not as products of their own
+	whereClauses := []string{"parent_id IS NULL", "deleted_at IS NULL"}
+	if filter.Trashed {
+		// The trash also holds variants deleted on their own; those deleted
+		// along with their parent are restored with it and not listed
+		whereClauses = []string{"deleted_at IS NOT NULL", `NOT EXISTS (
+			SELECT 1 FROM products p
+			WHERE p.id = products.parent_id AND p.deleted_at = products.deleted_at
+		)`}
+	}
+	args = []interface{}{}
 	argIdx := 1
 
-	if filter.Name != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("name ILIKE $%d", argIdx))
-		args = append(args, "%"+*filter.Name+"%")
+	// Full-text search, the query placeholder is reused for ranking and highlighting
+	if filter.Q != nil {
+		searchQuery = fmt.Sprintf("websearch_to_tsquery(product_search_config(), $%d)", argIdx)
+		whereClauses = append(whereClauses, "search_vector @@ "+searchQuery)
+		args = append(args, *filter.Q)
 		argIdx++
 	}
 
+	if filter.Name != nil {
+		if filter.Fuzzy {
+			whereClauses = append(whereClauses, fmt.Sprintf("(name ILIKE $%d OR $%d <%% name)", argIdx, argIdx+1))
+			args = append(args, "%"+*filter.Name+"%", *filter.Name)
+			argIdx += 2
+		} else {
+			whereClauses = append(whereClauses, fmt.Sprintf("name ILIKE $%d", argIdx))
+			args = append(args, "%"+*filter.Name+"%")
+			argIdx++
+		}
+	}
+
 	if filter.SKU != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("sku ILIKE $%d", argIdx))
-		args = append(args, "%"+*filter.SKU+"%")
-		argIdx++
+		if filter.Fuzzy {
+			whereClauses = append(whereClauses, fmt.Sprintf("(sku ILIKE $%d OR $%d <%% sku)", argIdx, argIdx+1))
+			args = append(args, "%"+*filter.SKU+"%", *filter.SKU)
+			argIdx += 2
+		} else {
+			whereClauses = append(whereClauses, fmt.Sprintf("sku ILIKE $%d", argIdx))
+			args = append(args, "%"+*filter.SKU+"%")
+			argIdx++
+		}
 	}
 
-	if filter.Category != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("category ILIKE $%d", argIdx))
-		args = append(args, "%"+*filter.Category+"%")
+	if filter.CategoryID != nil || filter.Category != nil {
+		// Match the category exactly, by ID or by slug; "Home & Garden" and
+		// "home-and-garden" name the same category
+		var seed string
+		if filter.CategoryID != nil {
+			seed = fmt.Sprintf("SELECT id FROM categories WHERE id = $%d", argIdx)
+			args = append(args, *filter.CategoryID)
+		} else {
+			seed = fmt.Sprintf("SELECT id FROM categories WHERE slug = $%d", argIdx)
+			args = append(args, slug.Make(*filter.Category))
+		}
 		argIdx++
+
+		if filter.IncludeDescendants {
+			whereClauses = append(whereClauses, fmt.Sprintf(`category_id IN (
+				WITH RECURSIVE subtree AS (
+					%s
+					UNION ALL
+					SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+				)
+				SELECT id FROM subtree
+			)`, seed))
+		} else {
+			whereClauses = append(whereClauses, fmt.Sprintf("category_id IN (%s)", seed))
+		}
 	}
 
 	if filter.Status != nil {
@@ -155,38 +265,123 @@ func (r *productRepositoryImpl) GetAll(ctx context.Context, filter productDomain
 		args = append(args, decimal.NewFromFloat(*filter.MaxPrice))
 		argIdx++
 	}
-
-	whereSQL := ""
-	if len(whereClauses) > 0 {
-		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
+	if filter.WarehouseID != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM warehouse_stock ws WHERE ws.product_id = products.id AND ws.warehouse_id = $%d)", argIdx))
+		args = append(args, *filter.WarehouseID)
+		argIdx++
 	}
-
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products %s", whereSQL)
-	var total int64
-	err := q.QueryRow(ctx, countQuery, args...).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count products: %w", err)
+	if filter.InStockAt != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM warehouse_stock ws WHERE ws.product_id = products.id AND ws.warehouse_id = $%d AND ws.quantity > 0)", argIdx))
+		args = append(args, *filter.InStockAt)
+		argIdx++
 	}
 
+	whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
+	return whereSQL, args, searchQuery
+}
+
+// productSortTypes maps each sort_by field to the type a cursor value, kept
+// as text, is cast back to for the keyset comparison
+var productSortTypes = map[string]string{
+	"id":         "int",
+	"sku":        "text",
+	"name":       "text",
+	"price":      "numeric",
+	"stock":      "int",
+	"category":   "text",
+	"status":     "product_status",
+	"created_at": "timestamptz",
+	"updated_at": "timestamptz",
+	"deleted_at": "timestamptz",
+	"relevance":  "real",
+}
+
+// productOrder returns the ORDER BY expression and direction of a filter's
+// sort, and the type its values are cast to in a keyset comparison
+func productOrder(filter productDomain.ListProductFilter, searchQuery string) (sortBy, sortOrder, sortType string) {
 	// Default sorting
-	sortBy := "created_at"
-	sortOrder := "DESC"
+	sortBy = "created_at"
+	sortOrder = "DESC"
 	if filter.SortBy != "" {
 		sortBy = filter.SortBy
 	}
 	if filter.SortOrder != "" {
-		sortOrder = filter.SortOrder
+		sortOrder = strings.ToUpper(filter.SortOrder)
+	}
+	sortType = productSortTypes[sortBy]
+	switch sortBy {
+	case "category":
+		sortBy = "(SELECT c.name FROM categories c WHERE c.id = products.category_id)"
+	case "relevance":
+		sortBy = fmt.Sprintf("ts_rank_cd(search_vector, %s)", searchQuery)
+	}
+	return sortBy, sortOrder, sortType
+}
+
+// GetAll lists one page of products. With filter.Position the page starts
+// after (or, backward, ends before) the cursor row instead of at an offset;
+// id breaks ties in the sort column so the position is always exact.
+func (r *productRepositoryImpl) GetAll(ctx context.Context, filter productDomain.ListProductFilter) ([]productDomain.Product, int64, error) {
+	q := GetQuerier(ctx, r.db)
+
+	whereSQL, args, searchQuery := buildProductWhere(filter)
+	argIdx := len(args) + 1
+
+	var total int64
+	if !filter.SkipCount {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products %s", whereSQL)
+		err := q.QueryRow(ctx, countQuery, args...).Scan(&total)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to count products: %w", err)
+		}
+	}
+
+	sortBy, sortOrder, sortType := productOrder(filter, searchQuery)
+
+	offset := (filter.Page - 1) * filter.Limit
+	backward := false
+	if filter.Position != nil {
+		offset = 0
+		backward = filter.Position.Backward
+
+		// Walking backward reverses the order; the rows are flipped back below
+		if backward {
+			if sortOrder == "DESC" {
+				sortOrder = "ASC"
+			} else {
+				sortOrder = "DESC"
+			}
+		}
+		op := ">"
+		if sortOrder == "DESC" {
+			op = "<"
+		}
+		whereSQL += fmt.Sprintf(" AND (%s, id) %s ($%d::%s, $%d)", sortBy, op, argIdx, sortType, argIdx+1)
+		args = append(args, filter.Position.Value, filter.Position.ID)
+		argIdx += 2
+	}
+
+	columns := productColumns + fmt.Sprintf(", (%s)::text", sortBy)
+	if searchQuery != "" {
+		columns += fmt.Sprintf(`,
+			ts_headline(product_search_config(), name, %[1]s, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+			CASE WHEN description IS NOT NULL THEN
+				ts_headline(product_search_config(), description, %[1]s, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')
+			END
+		`, searchQuery)
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, sku, name, description, price, stock, category, status, image_url, created_at, updated_at
+		SELECT %s
 		FROM products
 		%s
-		ORDER BY %s %s
+		ORDER BY %s %s, id %s
 		LIMIT $%d OFFSET $%d
-	`, whereSQL, sortBy, sortOrder, argIdx, argIdx+1)
+	`, columns, whereSQL, sortBy, sortOrder, sortOrder, argIdx, argIdx+1)
 
-	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	args = append(args, filter.Limit, offset)
 
 	rows, err := q.Query(ctx, query, args...)
 	if err != nil {
@@ -196,29 +391,210 @@ func (r *productRepositoryImpl) GetAll(ctx context.Context, filter productDomain
 
 	var products []productDomain.Product
 	for rows.Next() {
-		var product productDomain.Product
-		err := rows.Scan(
-			&product.ID,
-			&product.SKU,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.Stock,
-			&product.Category,
-			&product.Status,
-			&product.ImageURL,
-			&product.CreatedAt,
-			&product.UpdatedAt,
-		)
+		var sortKey string
+		var nameHighlight, descriptionHighlight *string
+		extra := []any{&sortKey}
+		if searchQuery != "" {
+			extra = append(extra, &nameHighlight, &descriptionHighlight)
+		}
+
+		product, err := scanProduct(rows, extra...)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan product: %w", err)
 		}
+		product.SortKey = sortKey
+		product.NameHighlight = nameHighlight
+		product.DescriptionHighlight = descriptionHighlight
 		products = append(products, product)
 	}
 
+	if backward {
+		slices.Reverse(products)
+	}
+
 	return products, total, nil
 }
 
+// Export streams the products matching the filter to fn. Rows are read off
+// the open result set as fn consumes them, so memory use stays flat however
+// large the catalog is.
+func (r *productRepositoryImpl) Export(ctx context.Context, filter productDomain.ListProductFilter, fn func(productDomain.Product) error) error {
+	q := GetQuerier(ctx, r.db)
+
+	whereSQL, args, searchQuery := buildProductWhere(filter)
+	sortBy, sortOrder, _ := productOrder(filter, searchQuery)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM products
+		%s
+		ORDER BY %s %s, id %s
+	`, productColumns, whereSQL, sortBy, sortOrder, sortOrder)
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export products: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan product: %w", err)
+		}
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export products: %w", err)
+	}
+
+	return nil
+}
+
+// Suggest returns the products whose name or SKU is most similar to the query.
+// The similarity threshold is set for the current transaction only, so it
+// must be called inside one for the threshold to apply.
+func (r *productRepositoryImpl) Suggest(ctx context.Context, filter productDomain.SuggestProductFilter) ([]productDomain.ProductSuggestion, error) {
+	q := GetQuerier(ctx, r.db)
+
+	_, err := q.Exec(ctx, "SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)",
+		strconv.FormatFloat(productDomain.SuggestSimilarityThreshold, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set similarity threshold: %w", err)
+	}
+
+	// <% is answered from the trigram indexes, the score only orders the matches
+	query := `
+		SELECT id, sku, name, GREATEST(word_similarity($1, name), word_similarity($1, sku)) AS score
+		FROM products
+		WHERE parent_id IS NULL AND deleted_at IS NULL AND ($1 <% name OR $1 <% sku)
+		ORDER BY score DESC, name ASC
+		LIMIT $2
+	`
+
+	rows, err := q.Query(ctx, query, filter.Q, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest products: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []productDomain.ProductSuggestion{}
+	for rows.Next() {
+		var s productDomain.ProductSuggestion
+		if err := rows.Scan(&s.ID, &s.SKU, &s.Name, &s.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan product suggestion: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions, nil
+}
+
+// GetFacets counts the products matching the list filter per category, status
+// and price band. Only the facets named in filter.Facets are queried.
+func (r *productRepositoryImpl) GetFacets(ctx context.Context, filter productDomain.ListProductFilter) (productDomain.Facets, error) {
+	q := GetQuerier(ctx, r.db)
+
+	whereSQL, args, _ := buildProductWhere(filter)
+	var facets productDomain.Facets
+
+	if filter.HasFacet("category") {
+		query := fmt.Sprintf(`
+			SELECT c.id, c.name, f.count
+			FROM (SELECT category_id, COUNT(*) AS count FROM products %s GROUP BY category_id) f
+			JOIN categories c ON c.id = f.category_id
+			ORDER BY f.count DESC, c.name ASC
+		`, whereSQL)
+
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return productDomain.Facets{}, fmt.Errorf("failed to count category facet: %w", err)
+		}
+		facets.Categories = []productDomain.CategoryFacet{}
+		for rows.Next() {
+			var f productDomain.CategoryFacet
+			if err := rows.Scan(&f.CategoryID, &f.Category, &f.Count); err != nil {
+				rows.Close()
+				return productDomain.Facets{}, fmt.Errorf("failed to scan category facet: %w", err)
+			}
+			facets.Categories = append(facets.Categories, f)
+		}
+		rows.Close()
+	}
+
+	if filter.HasFacet("status") {
+		query := fmt.Sprintf(`
+			SELECT status, COUNT(*)
+			FROM products
+			%s
+			GROUP BY status
+			ORDER BY status
+		`, whereSQL)
+
+		rows, err := q.Query(ctx, query, args...)
+		if err != nil {
+			return productDomain.Facets{}, fmt.Errorf("failed to count status facet: %w", err)
+		}
+		facets.Statuses = []productDomain.StatusFacet{}
+		for rows.Next() {
+			var f productDomain.StatusFacet
+			if err := rows.Scan(&f.Status, &f.Count); err != nil {
+				rows.Close()
+				return productDomain.Facets{}, fmt.Errorf("failed to scan status facet: %w", err)
+			}
+			facets.Statuses = append(facets.Statuses, f)
+		}
+		rows.Close()
+	}
+
+	if filter.HasFacet("price_range") && len(filter.PriceRanges) > 0 {
+		// width_bucket returns 0 below the first boundary and len(boundaries)
+		// at or above the last, so band i is [boundaries[i-1], boundaries[i])
+		boundaries := make([]string, len(filter.PriceRanges))
+		for i, b := range filter.PriceRanges {
+			boundaries[i] = b.String()
+		}
+		query := fmt.Sprintf(`
+			SELECT width_bucket(price, $%d::numeric[]) AS band, COUNT(*)
+			FROM products
+			%s
+			GROUP BY band
+		`, len(args)+1, whereSQL)
+
+		rows, err := q.Query(ctx, query, append(args, boundaries)...)
+		if err != nil {
+			return productDomain.Facets{}, fmt.Errorf("failed to count price range facet: %w", err)
+		}
+		counts := make([]int64, len(filter.PriceRanges)+1)
+		for rows.Next() {
+			var band int
+			var count int64
+			if err := rows.Scan(&band, &count); err != nil {
+				rows.Close()
+				return productDomain.Facets{}, fmt.Errorf("failed to scan price range facet: %w", err)
+			}
+			counts[band] = count
+		}
+		rows.Close()
+
+		// Every band is returned, empty ones with a zero count
+		facets.PriceRanges = make([]productDomain.PriceRangeFacet, len(counts))
+		for i := range counts {
+			if i > 0 {
+				facets.PriceRanges[i].Min = &filter.PriceRanges[i-1]
+			}
+			if i < len(filter.PriceRanges) {
+				facets.PriceRanges[i].Max = &filter.PriceRanges[i]
+			}
+			facets.PriceRanges[i].Count = counts[i]
+		}
+	}
+
+	return facets, nil
+}
+
 func (r *productRepositoryImpl) Update(ctx context.Context, product productDomain.UpdateProductRequest) error {
 	q := GetQuerier(ctx, r.db)
 
@@ -256,9 +632,9 @@ func (r *productRepositoryImpl) Update(ctx context.Context, product productDomai
 		argIdx++
 	}
 
-	if product.Category != nil {
-		updates = append(updates, fmt.Sprintf("category = $%d", argIdx))
-		args = append(args, *product.Category)
+	if product.CategoryID != nil {
+		updates = append(updates, fmt.Sprintf("category_id = $%d", argIdx))
+		args = append(args, *product.CategoryID)
 		argIdx++
 	}
 
@@ -274,17 +650,37 @@ func (r *productRepositoryImpl) Update(ctx context.Context, product productDomai
 		argIdx++
 	}
 
-	if len(updates) == 0 {
-		// No fields to update, just return success
-		return nil
+	// Variants belong to the uploaded file, so an image URL set without them
+	// drops the ones of the previous image
+	if product.ImageVariants != nil && len(*product.ImageVariants) > 0 {
+		variants, err := imageVariantsJSON(*product.ImageVariants)
+		if err != nil {
+			return err
+		}
+		updates = append(updates, fmt.Sprintf("image_variants = $%d", argIdx))
+		args = append(args, variants)
+		argIdx++
+	} else if product.ImageVariants != nil || product.ImageURL != nil {
+		updates = append(updates, "image_variants = NULL")
 	}
 
+	// Every update counts as a new version, even one that only changed the
+	// stock through the ledger, so there is always a statement to run
+	updates = append(updates, "version = version + 1")
+
 	args = append(args, product.ID)
+	where := fmt.Sprintf("id = $%d AND deleted_at IS NULL", argIdx)
+	argIdx++
+	if product.ExpectedVersion != nil {
+		where += fmt.Sprintf(" AND version = $%d", argIdx)
+		args = append(args, *product.ExpectedVersion)
+	}
+
 	query := fmt.Sprintf(`
 		UPDATE products
 		SET %s, updated_at = NOW()
-		WHERE id = $%d
-	`, strings.Join(updates, ", "), argIdx)
+		WHERE %s
+	`, strings.Join(updates, ", "), where)
 
 	commandTag, err := q.Exec(ctx, query, args...)
 	if err != nil {
@@ -298,22 +694,131 @@ func (r *productRepositoryImpl) Update(ctx context.Context, product productDomai
 	return nil
 }
 
-func (r *productRepositoryImpl) Delete(ctx context.Context, id int64) error {
+// Delete moves a product to the trash. Its variants are trashed with it and
+// share its deleted_at, which is how Restore finds them again.
+func (r *productRepositoryImpl) Delete(ctx context.Context, id int64, expectedVersion *int) error {
 	q := GetQuerier(ctx, r.db)
 
 	query := `
-		DELETE FROM products
-		WHERE id = $1
+		WITH deleted AS (
+			UPDATE products
+			SET deleted_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL AND ($2::int IS NULL OR version = $2)
+			RETURNING id, deleted_at
+		), variants AS (
+			UPDATE products
+			SET deleted_at = deleted.deleted_at
+			FROM deleted
+			WHERE products.parent_id = deleted.id AND products.deleted_at IS NULL
+		)
+		SELECT COUNT(*) FROM deleted
 	`
 
-	commandTag, err := q.Exec(ctx, query, id)
-	if err != nil {
+	var count int64
+	if err := q.QueryRow(ctx, query, id, expectedVersion).Scan(&count); err != nil {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
-	if commandTag.RowsAffected() == 0 {
+	if count == 0 {
 		return pgx.ErrNoRows
 	}
 
 	return nil
 }
+
+// Restore takes a product out of the trash, together with the variants that
+// were trashed along with it. A variant cannot be restored while its parent
+// is in the trash.
+func (r *productRepositoryImpl) Restore(ctx context.Context, id int64) error {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		WITH target AS (
+			SELECT id, deleted_at
+			FROM products
+			WHERE id = $1 AND deleted_at IS NOT NULL
+				AND NOT EXISTS (
+					SELECT 1 FROM products p
+					WHERE p.id = products.parent_id AND p.deleted_at IS NOT NULL
+				)
+			FOR UPDATE
+		), restored AS (
+			UPDATE products
+			SET deleted_at = NULL, version = version + 1, updated_at = NOW()
+			FROM target
+			WHERE products.id = target.id OR
+				(products.parent_id = target.id AND products.deleted_at = target.deleted_at)
+			RETURNING products.id
+		)
+		SELECT COUNT(*) FROM restored
+	`
+
+	var count int64
+	if err := q.QueryRow(ctx, query, id).Scan(&count); err != nil {
+		return fmt.Errorf("failed to restore product: %w", err)
+	}
+
+	if count == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// Purge permanently deletes the products trashed before the given time, with
+// the variants of purged parents. It returns the image URLs of the deleted
+// rows, resized copies included, so their files can be removed once the purge is committed.
+func (r *productRepositoryImpl) Purge(ctx context.Context, before time.Time) (int64, []string, error) {
+	q := GetQuerier(ctx, r.db)
+
+	// The gallery rows go by cascade; the select still sees them because
+	// every part of the statement reads the same snapshot
+	query := `
+		WITH purged AS (
+			DELETE FROM products
+			WHERE deleted_at < $1
+				OR parent_id IN (SELECT id FROM products WHERE deleted_at < $1)
+			RETURNING id, image_url, image_variants
+		)
+		SELECT image_url, image_variants,
+			COALESCE((
+				SELECT json_agg(json_build_object('url', pi.url, 'variants', pi.variants))
+				FROM product_images pi
+				WHERE pi.product_id = purged.id
+			), '[]'::json)
+		FROM purged
+	`
+
+	rows, err := q.Query(ctx, query, before)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to purge products: %w", err)
+	}
+	defer rows.Close()
+
+	var purged int64
+	imageURLs := []string{}
+	for rows.Next() {
+		purged++
+		var imageURL *string
+		var variants []productDomain.ImageVariant
+		var images []productDomain.ProductImage
+		if err := rows.Scan(&imageURL, &variants, &images); err != nil {
+			return 0, nil, fmt.Errorf("failed to scan purged product: %w", err)
+		}
+		// The product row repeats the primary image of the gallery
+		urls := productDomain.ImageFileURLs(imageURL, variants)
+		for _, image := range images {
+			urls = append(urls, productDomain.ImageFileURLs(&image.URL, image.Variants)...)
+		}
+		for _, url := range urls {
+			if !slices.Contains(imageURLs, url) {
+				imageURLs = append(imageURLs, url)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("failed to purge products: %w", err)
+	}
+
+	return purged, imageURLs, nil
+}
@@ -0,0 +1,195 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type productImageRepositoryImpl struct {
+	db *database.DB
+}
+
+func NewProductImageRepository(db *database.DB) productDomain.ProductImageRepository {
+	return &productImageRepositoryImpl{db: db}
+}
+
+// imageVariantsJSON encodes image variants for a JSONB column, NULL when there
+// are none
+func imageVariantsJSON(variants []productDomain.ImageVariant) ([]byte, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(variants)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image variants: %w", err)
+	}
+	return data, nil
+}
+
+const productImageColumns = `id, product_id, url, variants, alt_text, position, is_primary`
+
+func scanProductImage(row pgx.Row) (productDomain.ProductImage, error) {
+	var image productDomain.ProductImage
+	err := row.Scan(
+		&image.ID,
+		&image.ProductID,
+		&image.URL,
+		&image.Variants,
+		&image.AltText,
+		&image.Position,
+		&image.IsPrimary,
+	)
+	return image, err
+}
+
+func (r *productImageRepositoryImpl) Create(ctx context.Context, image productDomain.ProductImage) (productDomain.ProductImage, error) {
+	q := GetQuerier(ctx, r.db)
+
+	variants, err := imageVariantsJSON(image.Variants)
+	if err != nil {
+		return productDomain.ProductImage{}, err
+	}
+
+	query := `
+		INSERT INTO product_images (product_id, url, variants, alt_text, position, is_primary)
+		VALUES ($1, $2, $3, $4, (SELECT COALESCE(MAX(position) + 1, 0) FROM product_images WHERE product_id = $1), $5)
+		RETURNING id, position
+	`
+
+	err = q.QueryRow(ctx, query,
+		image.ProductID,
+		image.URL,
+		variants,
+		image.AltText,
+		image.IsPrimary,
+	).Scan(&image.ID, &image.Position)
+	if err != nil {
+		return productDomain.ProductImage{}, fmt.Errorf("failed to create product image: %w", err)
+	}
+
+	return image, nil
+}
+
+func (r *productImageRepositoryImpl) Get(ctx context.Context, productID, id int64) (productDomain.ProductImage, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `SELECT ` + productImageColumns + ` FROM product_images WHERE product_id = $1 AND id = $2`
+
+	image, err := scanProductImage(q.QueryRow(ctx, query, productID, id))
+	if err != nil {
+		return productDomain.ProductImage{}, err
+	}
+
+	return image, nil
+}
+
+func (r *productImageRepositoryImpl) List(ctx context.Context, productID int64) ([]productDomain.ProductImage, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `SELECT ` + productImageColumns + ` FROM product_images WHERE product_id = $1 ORDER BY position, id`
+
+	rows, err := q.Query(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product images: %w", err)
+	}
+	defer rows.Close()
+
+	images := []productDomain.ProductImage{}
+	for rows.Next() {
+		image, err := scanProductImage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product image: %w", err)
+		}
+		images = append(images, image)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get product images: %w", err)
+	}
+
+	return images, nil
+}
+
+func (r *productImageRepositoryImpl) UpdateAltText(ctx context.Context, productID, id int64, altText *string) error {
+	q := GetQuerier(ctx, r.db)
+
+	commandTag, err := q.Exec(ctx,
+		"UPDATE product_images SET alt_text = $3 WHERE product_id = $1 AND id = $2",
+		productID, id, altText)
+	if err != nil {
+		return fmt.Errorf("failed to update product image: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// SetPrimary clears the old primary image first: the unique index on the
+// primary image is checked row by row, not at the end of the statement
+func (r *productImageRepositoryImpl) SetPrimary(ctx context.Context, productID, id int64) error {
+	q := GetQuerier(ctx, r.db)
+
+	_, err := q.Exec(ctx,
+		"UPDATE product_images SET is_primary = FALSE WHERE product_id = $1 AND is_primary AND id <> $2",
+		productID, id)
+	if err != nil {
+		return fmt.Errorf("failed to set primary product image: %w", err)
+	}
+
+	commandTag, err := q.Exec(ctx,
+		"UPDATE product_images SET is_primary = TRUE WHERE product_id = $1 AND id = $2",
+		productID, id)
+	if err != nil {
+		return fmt.Errorf("failed to set primary product image: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *productImageRepositoryImpl) Reorder(ctx context.Context, productID int64, ids []int64) error {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		UPDATE product_images pi
+		SET position = o.ordinality - 1
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, ordinality)
+		WHERE pi.product_id = $1 AND pi.id = o.id
+	`
+
+	commandTag, err := q.Exec(ctx, query, productID, ids)
+	if err != nil {
+		return fmt.Errorf("failed to reorder product images: %w", err)
+	}
+
+	if commandTag.RowsAffected() != int64(len(ids)) {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *productImageRepositoryImpl) Delete(ctx context.Context, productID, id int64) error {
+	q := GetQuerier(ctx, r.db)
+
+	commandTag, err := q.Exec(ctx, "DELETE FROM product_images WHERE product_id = $1 AND id = $2", productID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete product image: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type productRevisionRepositoryImpl struct {
+	db *database.DB
+}
+
+func NewProductRevisionRepository(db *database.DB) productDomain.ProductRevisionRepository {
+	return &productRevisionRepositoryImpl{db: db}
+}
+
+func (r *productRevisionRepositoryImpl) Create(ctx context.Context, revision productDomain.ProductRevision) (productDomain.ProductRevision, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		INSERT INTO product_revisions (product_id, revision, snapshot, actor, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at
+	`
+
+	err := q.QueryRow(ctx, query,
+		revision.ProductID,
+		revision.Revision,
+		revision.Snapshot,
+		revision.Actor,
+	).Scan(&revision.ID, &revision.CreatedAt)
+	if err != nil {
+		return productDomain.ProductRevision{}, fmt.Errorf("failed to create product revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+func (r *productRevisionRepositoryImpl) Get(ctx context.Context, productID int64, revision int) (productDomain.ProductRevision, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, product_id, revision, snapshot, actor, created_at
+		FROM product_revisions
+		WHERE product_id = $1 AND revision = $2
+	`
+
+	var result productDomain.ProductRevision
+	err := q.QueryRow(ctx, query, productID, revision).Scan(
+		&result.ID,
+		&result.ProductID,
+		&result.Revision,
+		&result.Snapshot,
+		&result.Actor,
+		&result.CreatedAt,
+	)
+	if err != nil {
+		return productDomain.ProductRevision{}, err
+	}
+
+	return result, nil
+}
+
+func (r *productRevisionRepositoryImpl) List(ctx context.Context, filter productDomain.ListProductRevisionFilter) ([]productDomain.ProductRevision, int64, error) {
+	q := GetQuerier(ctx, r.db)
+
+	var total int64
+	err := q.QueryRow(ctx, "SELECT COUNT(*) FROM product_revisions WHERE product_id = $1", filter.ProductID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count product revisions: %w", err)
+	}
+
+	query := `
+		SELECT id, product_id, revision, snapshot, actor, created_at
+		FROM product_revisions
+		WHERE product_id = $1
+		ORDER BY revision DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := q.Query(ctx, query, filter.ProductID, filter.Limit, (filter.Page-1)*filter.Limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get product revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []productDomain.ProductRevision
+	for rows.Next() {
+		var revision productDomain.ProductRevision
+		err := rows.Scan(
+			&revision.ID,
+			&revision.ProductID,
+			&revision.Revision,
+			&revision.Snapshot,
+			&revision.Actor,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan product revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, total, nil
+}
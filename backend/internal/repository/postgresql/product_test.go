@@ -2,18 +2,28 @@ package postgresql
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
 	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/slug"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// testContext scopes test queries to the default tenant, which row-level
+// security requires
+func testContext() context.Context {
+	return requestctx.WithTenantID(context.Background(), 1)
+}
+
 func setupProductRepo(t *testing.T) (productDomain.ProductRepository, *database.DB, func()) {
 	// Get DSN from environment or use default test database
 	dsn := os.Getenv("TEST_DATABASE_URL")
@@ -29,15 +39,28 @@ func setupProductRepo(t *testing.T) (productDomain.ProductRepository, *database.
 	// Cleanup function
 	cleanup := func() {
 		// Clean up test data
-		_, _ = db.Exec(context.Background(), "DELETE FROM products WHERE sku LIKE 'TEST-%'")
+		_, _ = db.Exec(testContext(), "DELETE FROM products WHERE sku LIKE 'TEST-%'")
 		db.Close()
 	}
 
 	return repo, db, cleanup
 }
 
+// testCategoryID returns the ID of the category with the given name, creating it if needed
+func testCategoryID(t *testing.T, db *database.DB, name string) int64 {
+	var id int64
+	err := db.QueryRow(testContext(), `
+		INSERT INTO categories (name, slug)
+		VALUES ($1, $2)
+		ON CONFLICT (tenant_id, slug) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id
+	`, name, slug.Make(name)).Scan(&id)
+	require.NoError(t, err, "Failed to create test category")
+	return id
+}
+
 func TestProductRepository_Create_Success(t *testing.T) {
-	repo, _, cleanup := setupProductRepo(t)
+	repo, db, cleanup := setupProductRepo(t)
 	defer cleanup()
 
 	desc := "Test Description"
@@ -47,11 +70,11 @@ func TestProductRepository_Create_Success(t *testing.T) {
 		Description: &desc,
 		Price:       decimal.NewFromInt(10000),
 		Stock:       100,
-		Category:    "Electronics",
+		CategoryID:  testCategoryID(t, db, "Electronics"),
 		Status:      productDomain.ProductStatusActive,
 	}
 
-	createdProduct, err := repo.Create(context.Background(), newProduct)
+	createdProduct, err := repo.Create(testContext(), newProduct)
 	require.NoError(t, err)
 
 	assert.NotZero(t, createdProduct.ID)
@@ -60,14 +83,14 @@ func TestProductRepository_Create_Success(t *testing.T) {
 	assert.Equal(t, newProduct.Description, createdProduct.Description)
 	assert.True(t, newProduct.Price.Equal(createdProduct.Price))
 	assert.Equal(t, newProduct.Stock, createdProduct.Stock)
-	assert.Equal(t, newProduct.Category, createdProduct.Category)
+	assert.Equal(t, newProduct.CategoryID, createdProduct.CategoryID)
 	assert.Equal(t, newProduct.Status, createdProduct.Status)
 	assert.NotZero(t, createdProduct.CreatedAt)
 	assert.NotZero(t, createdProduct.UpdatedAt)
 }
 
 func TestProductRepository_Create_DuplicateSKU(t *testing.T) {
-	repo, _, cleanup := setupProductRepo(t)
+	repo, db, cleanup := setupProductRepo(t)
 	defer cleanup()
 
 	desc := "Test Description"
@@ -77,22 +100,22 @@ func TestProductRepository_Create_DuplicateSKU(t *testing.T) {
 		Description: &desc,
 		Price:       decimal.NewFromInt(10000),
 		Stock:       100,
-		Category:    "Electronics",
+		CategoryID:  testCategoryID(t, db, "Electronics"),
 		Status:      productDomain.ProductStatusActive,
 	}
 
 	// Create first product
-	_, err := repo.Create(context.Background(), newProduct)
+	_, err := repo.Create(testContext(), newProduct)
 	require.NoError(t, err)
 
 	// Try to create duplicate
-	_, err = repo.Create(context.Background(), newProduct)
+	_, err = repo.Create(testContext(), newProduct)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "duplicate key value")
 }
 
 func TestProductRepository_GetByID_Success(t *testing.T) {
-	repo, _, cleanup := setupProductRepo(t)
+	repo, db, cleanup := setupProductRepo(t)
 	defer cleanup()
 
 	// Create a product first
@@ -103,15 +126,15 @@ func TestProductRepository_GetByID_Success(t *testing.T) {
 		Description: &desc,
 		Price:       decimal.NewFromInt(20000),
 		Stock:       50,
-		Category:    "Books",
+		CategoryID:  testCategoryID(t, db, "Books"),
 		Status:      productDomain.ProductStatusActive,
 	}
 
-	createdProduct, err := repo.Create(context.Background(), newProduct)
+	createdProduct, err := repo.Create(testContext(), newProduct)
 	require.NoError(t, err)
 
 	// Get the product by ID
-	foundProduct, err := repo.GetByID(context.Background(), createdProduct.ID)
+	foundProduct, err := repo.GetByID(testContext(), createdProduct.ID)
 	require.NoError(t, err)
 
 	assert.Equal(t, createdProduct.ID, foundProduct.ID)
@@ -124,13 +147,13 @@ func TestProductRepository_GetByID_NotFound(t *testing.T) {
 	repo, _, cleanup := setupProductRepo(t)
 	defer cleanup()
 
-	_, err := repo.GetByID(context.Background(), 999999)
+	_, err := repo.GetByID(testContext(), 999999)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no rows")
 }
 
 func TestProductRepository_GetBySKU_Success(t *testing.T) {
-	repo, _, cleanup := setupProductRepo(t)
+	repo, db, cleanup := setupProductRepo(t)
 	defer cleanup()
 
 	// Create a product first
@@ -141,15 +164,15 @@ func TestProductRepository_GetBySKU_Success(t *testing.T) {
 		Description: &desc,
 		Price:       decimal.NewFromInt(30000),
 		Stock:       75,
-		Category:    "Clothing",
+		CategoryID:  testCategoryID(t, db, "Clothing"),
 		Status:      productDomain.ProductStatusActive,
 	}
 
-	createdProduct, err := repo.Create(context.Background(), newProduct)
+	createdProduct, err := repo.Create(testContext(), newProduct)
 	require.NoError(t, err)
 
 	// Get the product by SKU
-	foundProduct, err := repo.GetBySKU(context.Background(), "TEST-SKU-003")
+	foundProduct, err := repo.GetBySKU(testContext(), "TEST-SKU-003")
 	require.NoError(t, err)
 
 	assert.Equal(t, createdProduct.ID, foundProduct.ID)
@@ -161,37 +184,37 @@ func TestProductRepository_GetBySKU_NotFound(t *testing.T) {
 	repo, _, cleanup := setupProductRepo(t)
 	defer cleanup()
 
-	_, err := repo.GetBySKU(context.Background(), "NONEXISTENT-SKU")
+	_, err := repo.GetBySKU(testContext(), "NONEXISTENT-SKU")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no rows")
 }
 
 func TestProductRepository_GetAll_Success(t *testing.T) {
-	repo, _, cleanup := setupProductRepo(t)
+	repo, db, cleanup := setupProductRepo(t)
 	defer cleanup()
 
 	// Create multiple products
 	products := []productDomain.Product{
 		{
-			SKU:      "TEST-SKU-LIST-1",
-			Name:     "Product 1",
-			Price:    decimal.NewFromInt(10000),
-			Stock:    100,
-			Category: "Electronics",
-			Status:   productDomain.ProductStatusActive,
+			SKU:        "TEST-SKU-LIST-1",
+			Name:       "Product 1",
+			Price:      decimal.NewFromInt(10000),
+			Stock:      100,
+			CategoryID: testCategoryID(t, db, "Electronics"),
+			Status:     productDomain.ProductStatusActive,
 		},
 		{
-			SKU:      "TEST-SKU-LIST-2",
-			Name:     "Product 2",
-			Price:    decimal.NewFromInt(20000),
-			Stock:    50,
-			Category: "Books",
-			Status:   productDomain.ProductStatusActive,
+			SKU:        "TEST-SKU-LIST-2",
+			Name:       "Product 2",
+			Price:      decimal.NewFromInt(20000),
+			Stock:      50,
+			CategoryID: testCategoryID(t, db, "Books"),
+			Status:     productDomain.ProductStatusActive,
 		},
 	}
 
 	for _, p := range products {
-		_, err := repo.Create(context.Background(), p)
+		_, err := repo.Create(testContext(), p)
 		require.NoError(t, err)
 	}
 
@@ -201,7 +224,7 @@ func TestProductRepository_GetAll_Success(t *testing.T) {
 		Limit: 10,
 	}
 
-	foundProducts, total, err := repo.GetAll(context.Background(), filter)
+	foundProducts, total, err := repo.GetAll(testContext(), filter)
 	require.NoError(t, err)
 
 	assert.GreaterOrEqual(t, len(foundProducts), 2)
@@ -209,31 +232,31 @@ func TestProductRepository_GetAll_Success(t *testing.T) {
 }
 
 func TestProductRepository_GetAll_WithFilters(t *testing.T) {
-	repo, _, cleanup := setupProductRepo(t)
+	repo, db, cleanup := setupProductRepo(t)
 	defer cleanup()
 
 	// Create a specific product
 	newProduct := productDomain.Product{
-		SKU:      "TEST-SKU-FILTER",
-		Name:     "Filtered Product",
-		Price:    decimal.NewFromInt(15000),
-		Stock:    25,
-		Category: "Toys",
-		Status:   productDomain.ProductStatusActive,
+		SKU:        "TEST-SKU-FILTER",
+		Name:       "Filtered Product",
+		Price:      decimal.NewFromInt(15000),
+		Stock:      25,
+		CategoryID: testCategoryID(t, db, "Toys"),
+		Status:     productDomain.ProductStatusActive,
 	}
 
-	_, err := repo.Create(context.Background(), newProduct)
+	_, err := repo.Create(testContext(), newProduct)
 	require.NoError(t, err)
 
 	// Filter by category
-	category := "Toys"
+	category := "toys"
 	filter := productDomain.ListProductFilter{
 		Page:     1,
 		Limit:    10,
 		Category: &category,
 	}
 
-	foundProducts, total, err := repo.GetAll(context.Background(), filter)
+	foundProducts, total, err := repo.GetAll(testContext(), filter)
 	require.NoError(t, err)
 
 	assert.GreaterOrEqual(t, total, int64(1))
@@ -242,21 +265,206 @@ func TestProductRepository_GetAll_WithFilters(t *testing.T) {
 	}
 }
 
+func TestProductRepository_GetAll_FullTextSearch(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+
+	desc := "Sepatu lari ringan untuk maraton"
+	newProduct := productDomain.Product{
+		SKU:         "TEST-SKU-SEARCH",
+		Name:        "Trail Runner Pro",
+		Description: &desc,
+		Price:       decimal.NewFromInt(15000),
+		CategoryID:  testCategoryID(t, db, "Apparel"),
+		Status:      productDomain.ProductStatusActive,
+	}
+
+	_, err := repo.Create(testContext(), newProduct)
+	require.NoError(t, err)
+
+	// Matches on the description, which the name/sku filters never looked at
+	q := "maraton"
+	filter := productDomain.ListProductFilter{
+		Q:         &q,
+		Page:      1,
+		Limit:     10,
+		SortBy:    "relevance",
+		SortOrder: "desc",
+	}
+
+	foundProducts, total, err := repo.GetAll(testContext(), filter)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1), total)
+	assert.Equal(t, "TEST-SKU-SEARCH", foundProducts[0].SKU)
+	require.NotNil(t, foundProducts[0].DescriptionHighlight)
+	assert.Contains(t, *foundProducts[0].DescriptionHighlight, "<mark>maraton</mark>")
+}
+
+func TestProductRepository_Suggest_Misspelled(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+
+	newProduct := productDomain.Product{
+		SKU:        "TEST-SKU-SUGGEST",
+		Name:       "Wireless Keyboard",
+		Price:      decimal.NewFromInt(15000),
+		CategoryID: testCategoryID(t, db, "Electronics"),
+		Status:     productDomain.ProductStatusActive,
+	}
+
+	_, err := repo.Create(testContext(), newProduct)
+	require.NoError(t, err)
+
+	// The lowered similarity threshold only holds inside a transaction
+	var suggestions []productDomain.ProductSuggestion
+	err = NewTransactor(db).WithinTransaction(testContext(), func(ctx context.Context) error {
+		var err error
+		suggestions, err = repo.Suggest(ctx, productDomain.SuggestProductFilter{Q: "keybaord", Limit: 5})
+		return err
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, suggestions)
+	assert.Equal(t, "TEST-SKU-SUGGEST", suggestions[0].SKU)
+	assert.Greater(t, suggestions[0].Score, 0.0)
+}
+
+func TestProductRepository_GetFacets(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+
+	categoryID := testCategoryID(t, db, "Facets")
+	for i, price := range []int64{50000, 150000, 2000000} {
+		_, err := repo.Create(testContext(), productDomain.Product{
+			SKU:        fmt.Sprintf("TEST-SKU-FACET-%d", i),
+			Name:       "Facet Product",
+			Price:      decimal.NewFromInt(price),
+			CategoryID: categoryID,
+			Status:     productDomain.ProductStatusActive,
+		})
+		require.NoError(t, err)
+	}
+
+	filter := productDomain.ListProductFilter{
+		CategoryID:  &categoryID,
+		Facets:      []string{"category", "status", "price_range"},
+		PriceRanges: []decimal.Decimal{decimal.NewFromInt(100000), decimal.NewFromInt(1000000)},
+	}
+
+	facets, err := repo.GetFacets(testContext(), filter)
+	require.NoError(t, err)
+
+	require.Len(t, facets.Categories, 1)
+	assert.Equal(t, int64(3), facets.Categories[0].Count)
+	require.Len(t, facets.Statuses, 1)
+	assert.Equal(t, productDomain.ProductStatusActive, facets.Statuses[0].Status)
+
+	// Bands: < 100000, 100000-1000000, >= 1000000
+	require.Len(t, facets.PriceRanges, 3)
+	for _, band := range facets.PriceRanges {
+		assert.Equal(t, int64(1), band.Count)
+	}
+	assert.Nil(t, facets.PriceRanges[0].Min)
+	assert.Nil(t, facets.PriceRanges[2].Max)
+}
+
+func TestProductRepository_GetAll_KeysetPagination(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+
+	// Equal prices make id the tie-breaker
+	categoryID := testCategoryID(t, db, "Keyset")
+	for i := 0; i < 3; i++ {
+		_, err := repo.Create(testContext(), productDomain.Product{
+			SKU:        fmt.Sprintf("TEST-SKU-KEYSET-%d", i),
+			Name:       "Keyset Product",
+			Price:      decimal.NewFromInt(15000),
+			CategoryID: categoryID,
+			Status:     productDomain.ProductStatusActive,
+		})
+		require.NoError(t, err)
+	}
+
+	filter := productDomain.ListProductFilter{
+		CategoryID: &categoryID,
+		Page:       1,
+		Limit:      2,
+		SortBy:     "price",
+		SortOrder:  "asc",
+	}
+
+	firstPage, total, err := repo.GetAll(testContext(), filter)
+	require.NoError(t, err)
+	require.Len(t, firstPage, 2)
+	assert.Equal(t, int64(3), total)
+
+	last := firstPage[1]
+	filter.Position = &productDomain.Cursor{SortBy: "price", SortOrder: "asc", Value: last.SortKey, ID: last.ID}
+	filter.SkipCount = true
+
+	secondPage, _, err := repo.GetAll(testContext(), filter)
+	require.NoError(t, err)
+	require.Len(t, secondPage, 1)
+	assert.Greater(t, secondPage[0].ID, last.ID)
+
+	// Walking back from the second page returns the first in the same order
+	filter.Position = &productDomain.Cursor{SortBy: "price", SortOrder: "asc", Value: secondPage[0].SortKey, ID: secondPage[0].ID, Backward: true}
+
+	previousPage, _, err := repo.GetAll(testContext(), filter)
+	require.NoError(t, err)
+	require.Len(t, previousPage, 2)
+	assert.Equal(t, firstPage[0].ID, previousPage[0].ID)
+	assert.Equal(t, firstPage[1].ID, previousPage[1].ID)
+}
+
+func TestProductRepository_Export_StreamsAllMatches(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+
+	categoryID := testCategoryID(t, db, "Stationery")
+	for i := range 25 {
+		_, err := repo.Create(testContext(), productDomain.Product{
+			SKU:        fmt.Sprintf("TEST-SKU-EXPORT-%02d", i),
+			Name:       fmt.Sprintf("Export Product %02d", i),
+			Price:      decimal.NewFromInt(int64(1000 * (i + 1))),
+			CategoryID: categoryID,
+			Status:     productDomain.ProductStatusActive,
+		})
+		require.NoError(t, err)
+	}
+
+	// No paging applies: every match comes through, in the requested order
+	sku := "TEST-SKU-EXPORT"
+	filter := productDomain.ListProductFilter{SKU: &sku, SortBy: "price", SortOrder: "desc"}
+
+	var skus []string
+	err := repo.Export(testContext(), filter, func(p productDomain.Product) error {
+		skus = append(skus, p.SKU)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, skus, 25)
+	assert.Equal(t, "TEST-SKU-EXPORT-24", skus[0])
+	assert.Equal(t, "TEST-SKU-EXPORT-00", skus[24])
+}
+
 func TestProductRepository_Update_Success(t *testing.T) {
-	repo, _, cleanup := setupProductRepo(t)
+	repo, db, cleanup := setupProductRepo(t)
 	defer cleanup()
 
 	// Create a product first
 	newProduct := productDomain.Product{
-		SKU:      "TEST-SKU-UPDATE",
-		Name:     "Original Name",
-		Price:    decimal.NewFromInt(10000),
-		Stock:    100,
-		Category: "Electronics",
-		Status:   productDomain.ProductStatusActive,
+		SKU:        "TEST-SKU-UPDATE",
+		Name:       "Original Name",
+		Price:      decimal.NewFromInt(10000),
+		Stock:      100,
+		CategoryID: testCategoryID(t, db, "Electronics"),
+		Status:     productDomain.ProductStatusActive,
 	}
 
-	createdProduct, err := repo.Create(context.Background(), newProduct)
+	createdProduct, err := repo.Create(testContext(), newProduct)
 	require.NoError(t, err)
 
 	// Update the product
@@ -265,48 +473,49 @@ func TestProductRepository_Update_Success(t *testing.T) {
 	updatedName := "Updated Name"
 	updatedPrice := decimal.NewFromInt(15000)
 	updatedStock := 150
-	updatedCategory := "Gadgets"
+	updatedCategoryID := testCategoryID(t, db, "Gadgets")
 	updatedStatus := productDomain.ProductStatusInactive
 
 	updateReq := productDomain.UpdateProductRequest{
-		ID:       createdProduct.ID,
-		Name:     &updatedName,
-		Price:    &updatedPrice,
-		Stock:    &updatedStock,
-		Category: &updatedCategory,
-		Status:   &updatedStatus,
+		ID:         createdProduct.ID,
+		Name:       &updatedName,
+		Price:      &updatedPrice,
+		Stock:      &updatedStock,
+		CategoryID: &updatedCategoryID,
+		Status:     &updatedStatus,
 	}
 
-	err = repo.Update(context.Background(), updateReq)
+	err = repo.Update(testContext(), updateReq)
 	require.NoError(t, err)
 
 	// Verify the update
-	foundProduct, err := repo.GetByID(context.Background(), createdProduct.ID)
+	foundProduct, err := repo.GetByID(testContext(), createdProduct.ID)
 	require.NoError(t, err)
 
 	assert.Equal(t, updatedName, foundProduct.Name)
 	assert.True(t, updatedPrice.Equal(foundProduct.Price))
 	assert.Equal(t, updatedStock, foundProduct.Stock)
-	assert.Equal(t, updatedCategory, foundProduct.Category)
+	assert.Equal(t, updatedCategoryID, foundProduct.CategoryID)
+	assert.Equal(t, "Gadgets", foundProduct.Category)
 	assert.Equal(t, updatedStatus, foundProduct.Status)
 	assert.NotEqual(t, createdProduct.UpdatedAt, foundProduct.UpdatedAt)
 }
 
 func TestProductRepository_Update_PartialUpdate(t *testing.T) {
-	repo, _, cleanup := setupProductRepo(t)
+	repo, db, cleanup := setupProductRepo(t)
 	defer cleanup()
 
 	// Create a product first
 	newProduct := productDomain.Product{
-		SKU:      "TEST-SKU-PARTIAL",
-		Name:     "Original Name",
-		Price:    decimal.NewFromInt(10000),
-		Stock:    100,
-		Category: "Electronics",
-		Status:   productDomain.ProductStatusActive,
+		SKU:        "TEST-SKU-PARTIAL",
+		Name:       "Original Name",
+		Price:      decimal.NewFromInt(10000),
+		Stock:      100,
+		CategoryID: testCategoryID(t, db, "Electronics"),
+		Status:     productDomain.ProductStatusActive,
 	}
 
-	createdProduct, err := repo.Create(context.Background(), newProduct)
+	createdProduct, err := repo.Create(testContext(), newProduct)
 	require.NoError(t, err)
 
 	// Update only the name
@@ -316,11 +525,11 @@ func TestProductRepository_Update_PartialUpdate(t *testing.T) {
 		Name: &updatedName,
 	}
 
-	err = repo.Update(context.Background(), updateReq)
+	err = repo.Update(testContext(), updateReq)
 	require.NoError(t, err)
 
 	// Verify only name changed
-	foundProduct, err := repo.GetByID(context.Background(), createdProduct.ID)
+	foundProduct, err := repo.GetByID(testContext(), createdProduct.ID)
 	require.NoError(t, err)
 
 	assert.Equal(t, updatedName, foundProduct.Name)
@@ -328,6 +537,112 @@ func TestProductRepository_Update_PartialUpdate(t *testing.T) {
 	assert.Equal(t, createdProduct.Stock, foundProduct.Stock)
 }
 
+func TestProductRepository_Update_ImageVariants(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+
+	created, err := repo.Create(testContext(), productDomain.Product{
+		SKU:        "TEST-SKU-IMAGE",
+		Name:       "Product With Image",
+		Price:      decimal.NewFromInt(10000),
+		CategoryID: testCategoryID(t, db, "Electronics"),
+		Status:     productDomain.ProductStatusActive,
+	})
+	require.NoError(t, err)
+
+	imageURL := "products/test/photo.jpg"
+	variants := []productDomain.ImageVariant{
+		{Name: "original", Format: "jpeg", URL: imageURL, Width: 800, Height: 600},
+		{Name: "thumb", Format: "jpeg", URL: "products/test/photo-thumb.jpg", Width: 150, Height: 112},
+	}
+	err = repo.Update(testContext(), productDomain.UpdateProductRequest{ID: created.ID, ImageURL: &imageURL, ImageVariants: &variants})
+	require.NoError(t, err)
+
+	product, err := repo.GetByID(testContext(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, variants, product.ImageVariants)
+
+	// An image URL set without an upload drops the variants of the old image
+	otherURL := "https://cdn.example.com/photo.jpg"
+	err = repo.Update(testContext(), productDomain.UpdateProductRequest{ID: created.ID, ImageURL: &otherURL})
+	require.NoError(t, err)
+
+	product, err = repo.GetByID(testContext(), created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, product.ImageVariants)
+}
+
+func TestProductImageRepository_Gallery(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+	images := NewProductImageRepository(db)
+
+	created, err := repo.Create(testContext(), productDomain.Product{
+		SKU:        "TEST-SKU-GALLERY",
+		Name:       "Product With Gallery",
+		Price:      decimal.NewFromInt(10000),
+		CategoryID: testCategoryID(t, db, "Electronics"),
+		Status:     productDomain.ProductStatusActive,
+	})
+	require.NoError(t, err)
+
+	front, err := images.Create(testContext(), productDomain.ProductImage{ProductID: created.ID, URL: "products/test/front.jpg", IsPrimary: true})
+	require.NoError(t, err)
+	back, err := images.Create(testContext(), productDomain.ProductImage{ProductID: created.ID, URL: "products/test/back.jpg"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, front.Position)
+	assert.Equal(t, 1, back.Position)
+
+	require.NoError(t, images.SetPrimary(testContext(), created.ID, back.ID))
+	require.NoError(t, images.Reorder(testContext(), created.ID, []int64{back.ID, front.ID}))
+	altText := "Back view"
+	require.NoError(t, images.UpdateAltText(testContext(), created.ID, back.ID, &altText))
+
+	// The gallery comes with the product, in display order
+	product, err := repo.GetByID(testContext(), created.ID)
+	require.NoError(t, err)
+	require.Len(t, product.Images, 2)
+	assert.Equal(t, back.ID, product.Images[0].ID)
+	assert.True(t, product.Images[0].IsPrimary)
+	assert.Equal(t, "Back view", *product.Images[0].AltText)
+	assert.False(t, product.Images[1].IsPrimary)
+
+	// Images are only reached through their product
+	err = images.Delete(testContext(), created.ID+1, front.ID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	require.NoError(t, images.Delete(testContext(), created.ID, front.ID))
+}
+
+func TestProductRepository_Update_VersionConflict(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+
+	createdProduct, err := repo.Create(testContext(), productDomain.Product{
+		SKU:        "TEST-SKU-VERSION",
+		Name:       "Versioned Product",
+		Price:      decimal.NewFromInt(15000),
+		CategoryID: testCategoryID(t, db, "Electronics"),
+		Status:     productDomain.ProductStatusActive,
+	})
+	require.NoError(t, err)
+
+	// First writer saw version 1 and wins
+	name := "First Writer"
+	version := 1
+	err = repo.Update(testContext(), productDomain.UpdateProductRequest{ID: createdProduct.ID, Name: &name, ExpectedVersion: &version})
+	require.NoError(t, err)
+
+	// Second writer also saw version 1 and matches nothing
+	name = "Second Writer"
+	err = repo.Update(testContext(), productDomain.UpdateProductRequest{ID: createdProduct.ID, Name: &name, ExpectedVersion: &version})
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	found, err := repo.GetByID(testContext(), createdProduct.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First Writer", found.Name)
+	assert.Equal(t, 2, found.Version)
+}
+
 func TestProductRepository_Update_NotFound(t *testing.T) {
 	repo, _, cleanup := setupProductRepo(t)
 	defer cleanup()
@@ -338,7 +653,7 @@ func TestProductRepository_Update_NotFound(t *testing.T) {
 		Name: &updatedName,
 	}
 
-	err := repo.Update(context.Background(), updateReq)
+	err := repo.Update(testContext(), updateReq)
 	assert.Error(t, err)
 	// Could be either "no rows affected" or "no rows in result set"
 	assert.True(t,
@@ -348,28 +663,28 @@ func TestProductRepository_Update_NotFound(t *testing.T) {
 }
 
 func TestProductRepository_Delete_Success(t *testing.T) {
-	repo, _, cleanup := setupProductRepo(t)
+	repo, db, cleanup := setupProductRepo(t)
 	defer cleanup()
 
 	// Create a product first
 	newProduct := productDomain.Product{
-		SKU:      "TEST-SKU-DELETE",
-		Name:     "Product to Delete",
-		Price:    decimal.NewFromInt(10000),
-		Stock:    100,
-		Category: "Electronics",
-		Status:   productDomain.ProductStatusActive,
+		SKU:        "TEST-SKU-DELETE",
+		Name:       "Product to Delete",
+		Price:      decimal.NewFromInt(10000),
+		Stock:      100,
+		CategoryID: testCategoryID(t, db, "Electronics"),
+		Status:     productDomain.ProductStatusActive,
 	}
 
-	createdProduct, err := repo.Create(context.Background(), newProduct)
+	createdProduct, err := repo.Create(testContext(), newProduct)
 	require.NoError(t, err)
 
 	// Delete the product
-	err = repo.Delete(context.Background(), createdProduct.ID)
+	err = repo.Delete(testContext(), createdProduct.ID, nil)
 	require.NoError(t, err)
 
 	// Verify it's deleted
-	_, err = repo.GetByID(context.Background(), createdProduct.ID)
+	_, err = repo.GetByID(testContext(), createdProduct.ID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no rows")
 }
@@ -378,7 +693,7 @@ func TestProductRepository_Delete_NotFound(t *testing.T) {
 	repo, _, cleanup := setupProductRepo(t)
 	defer cleanup()
 
-	err := repo.Delete(context.Background(), 999999)
+	err := repo.Delete(testContext(), 999999, nil)
 	assert.Error(t, err)
 	// Could be either "no rows affected" or "no rows in result set"
 	assert.True(t,
@@ -386,3 +701,109 @@ func TestProductRepository_Delete_NotFound(t *testing.T) {
 			strings.Contains(err.Error(), "no rows in result set"),
 		"error should mention no rows: %v", err)
 }
+
+func TestProductRepository_Restore_Success(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+
+	createdProduct, err := repo.Create(testContext(), productDomain.Product{
+		SKU:        "TEST-SKU-RESTORE",
+		Name:       "Product to Restore",
+		Price:      decimal.NewFromInt(10000),
+		CategoryID: testCategoryID(t, db, "Electronics"),
+		Status:     productDomain.ProductStatusActive,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(testContext(), createdProduct.ID, nil))
+
+	// Trashed products are only listed in the trash
+	trash, _, err := repo.GetAll(testContext(), productDomain.ListProductFilter{
+		SKU: &createdProduct.SKU, Trashed: true, Page: 1, Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.NotNil(t, trash[0].DeletedAt)
+
+	require.NoError(t, repo.Restore(testContext(), createdProduct.ID))
+
+	restored, err := repo.GetByID(testContext(), createdProduct.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+
+	// A live product is not in the trash
+	err = repo.Restore(testContext(), createdProduct.ID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestProductRepository_Restore_SKUTaken(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+
+	categoryID := testCategoryID(t, db, "Electronics")
+	trashed, err := repo.Create(testContext(), productDomain.Product{
+		SKU:        "TEST-SKU-REUSED",
+		Name:       "Original Product",
+		Price:      decimal.NewFromInt(10000),
+		CategoryID: categoryID,
+		Status:     productDomain.ProductStatusActive,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(testContext(), trashed.ID, nil))
+
+	// The SKU of a trashed product is free for a new one
+	_, err = repo.Create(testContext(), productDomain.Product{
+		SKU:        "TEST-SKU-REUSED",
+		Name:       "Replacement Product",
+		Price:      decimal.NewFromInt(20000),
+		CategoryID: categoryID,
+		Status:     productDomain.ProductStatusActive,
+	})
+	require.NoError(t, err)
+
+	err = repo.Restore(testContext(), trashed.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "23505")
+}
+
+func TestProductRepository_Purge_OnlyExpired(t *testing.T) {
+	repo, db, cleanup := setupProductRepo(t)
+	defer cleanup()
+
+	categoryID := testCategoryID(t, db, "Electronics")
+	imageURL := "products/test/photo.jpg"
+	expired, err := repo.Create(testContext(), productDomain.Product{
+		SKU:        "TEST-SKU-EXPIRED",
+		Name:       "Expired Product",
+		Price:      decimal.NewFromInt(10000),
+		CategoryID: categoryID,
+		Status:     productDomain.ProductStatusActive,
+		ImageURL:   &imageURL,
+	})
+	require.NoError(t, err)
+	recent, err := repo.Create(testContext(), productDomain.Product{
+		SKU:        "TEST-SKU-RECENT",
+		Name:       "Recently Deleted Product",
+		Price:      decimal.NewFromInt(10000),
+		CategoryID: categoryID,
+		Status:     productDomain.ProductStatusActive,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(testContext(), expired.ID, nil))
+	require.NoError(t, repo.Delete(testContext(), recent.ID, nil))
+	_, err = db.Exec(testContext(),
+		"UPDATE products SET deleted_at = NOW() - INTERVAL '40 days' WHERE id = $1", expired.ID)
+	require.NoError(t, err)
+
+	purged, imageURLs, err := repo.Purge(testContext(), time.Now().Add(-30*24*time.Hour))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, purged, int64(1))
+	assert.Contains(t, imageURLs, imageURL)
+
+	var count int
+	err = db.QueryRow(testContext(),
+		"SELECT COUNT(*) FROM products WHERE id IN ($1, $2)", expired.ID, recent.ID).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,88 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	userDomain "github.com/naxumi/bnsp-jwd/internal/domain/user"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type refreshTokenRepositoryImpl struct {
+	db *database.DB
+}
+
+func NewRefreshTokenRepository(db *database.DB) userDomain.RefreshTokenRepository {
+	return &refreshTokenRepositoryImpl{db: db}
+}
+
+func (r *refreshTokenRepositoryImpl) Create(ctx context.Context, token userDomain.RefreshToken) (userDomain.RefreshToken, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at
+	`
+
+	err := q.QueryRow(ctx, query,
+		token.UserID,
+		token.TokenHash,
+		token.FamilyID,
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return userDomain.RefreshToken{}, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (r *refreshTokenRepositoryImpl) GetByHashForUpdate(ctx context.Context, tokenHash string) (userDomain.RefreshToken, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, user_id, token_hash, family_id::text, expires_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+
+	var token userDomain.RefreshToken
+	err := q.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.FamilyID,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return userDomain.RefreshToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r *refreshTokenRepositoryImpl) Revoke(ctx context.Context, id int64) error {
+	q := GetQuerier(ctx, r.db)
+
+	_, err := q.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepositoryImpl) RevokeFamily(ctx context.Context, familyID string) error {
+	q := GetQuerier(ctx, r.db)
+
+	_, err := q.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL", familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
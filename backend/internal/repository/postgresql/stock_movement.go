@@ -0,0 +1,216 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type stockMovementRepositoryImpl struct {
+	db *database.DB
+}
+
+func NewStockMovementRepository(db *database.DB) inventoryDomain.StockMovementRepository {
+	return &stockMovementRepositoryImpl{db: db}
+}
+
+func (r *stockMovementRepositoryImpl) GetDefaultWarehouseID(ctx context.Context) (int64, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id
+		FROM warehouses
+		WHERE is_default
+	`
+
+	var id int64
+	err := q.QueryRow(ctx, query).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get default warehouse: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *stockMovementRepositoryImpl) GetStockForUpdate(ctx context.Context, productID int64) (int, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT stock
+		FROM products
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	var stock int
+	err := q.QueryRow(ctx, query, productID).Scan(&stock)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get product stock: %w", err)
+	}
+
+	return stock, nil
+}
+
+func (r *stockMovementRepositoryImpl) ListWarehouseStock(ctx context.Context, productID int64) ([]inventoryDomain.WarehouseQuantity, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT ws.warehouse_id, ws.quantity
+		FROM warehouse_stock ws
+		JOIN warehouses w ON w.id = ws.warehouse_id
+		WHERE ws.product_id = $1 AND ws.quantity > 0
+		ORDER BY w.is_default DESC, ws.quantity DESC, ws.warehouse_id
+	`
+
+	rows, err := q.Query(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warehouse stock: %w", err)
+	}
+	defer rows.Close()
+
+	levels := []inventoryDomain.WarehouseQuantity{}
+	for rows.Next() {
+		var level inventoryDomain.WarehouseQuantity
+		if err := rows.Scan(&level.WarehouseID, &level.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan warehouse stock: %w", err)
+		}
+		levels = append(levels, level)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get warehouse stock: %w", err)
+	}
+
+	return levels, nil
+}
+
+func (r *stockMovementRepositoryImpl) ApplyStockDelta(ctx context.Context, productID int64, warehouseID int64, delta int) (int, error) {
+	q := GetQuerier(ctx, r.db)
+
+	// The row lock taken by UPDATE serializes concurrent movements on the same
+	// product, and the stock >= 0 check constraints reject overdrawn issues.
+	// Both statements must run in the caller's transaction.
+	productQuery := `
+		UPDATE products
+		SET stock = stock + $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING stock
+	`
+
+	var balance int
+	err := q.QueryRow(ctx, productQuery, delta, productID).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply stock delta: %w", err)
+	}
+
+	warehouseQuery := `
+		INSERT INTO warehouse_stock (product_id, warehouse_id, quantity, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (product_id, warehouse_id)
+		DO UPDATE SET quantity = warehouse_stock.quantity + EXCLUDED.quantity, updated_at = NOW()
+	`
+
+	_, err = q.Exec(ctx, warehouseQuery, productID, warehouseID, delta)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply warehouse stock delta: %w", err)
+	}
+
+	return balance, nil
+}
+
+func (r *stockMovementRepositoryImpl) Create(ctx context.Context, movement inventoryDomain.StockMovement) (inventoryDomain.StockMovement, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		INSERT INTO stock_movements (product_id, warehouse_id, movement_type, reason_code, quantity, balance_after, reference, note, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING id, created_at
+	`
+
+	err := q.QueryRow(ctx, query,
+		movement.ProductID,
+		movement.WarehouseID,
+		movement.Type,
+		movement.ReasonCode,
+		movement.Quantity,
+		movement.BalanceAfter,
+		movement.Reference,
+		movement.Note,
+		movement.Actor,
+	).Scan(&movement.ID, &movement.CreatedAt)
+	if err != nil {
+		return inventoryDomain.StockMovement{}, fmt.Errorf("failed to create stock movement: %w", err)
+	}
+
+	return movement, nil
+}
+
+func (r *stockMovementRepositoryImpl) ListByProduct(ctx context.Context, filter inventoryDomain.ListStockMovementFilter) ([]inventoryDomain.StockMovement, int64, error) {
+	q := GetQuerier(ctx, r.db)
+
+	whereClauses := []string{"product_id = $1"}
+	args := []interface{}{filter.ProductID}
+	argIdx := 2
+
+	if filter.WarehouseID != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("warehouse_id = $%d", argIdx))
+		args = append(args, *filter.WarehouseID)
+		argIdx++
+	}
+
+	if filter.Type != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("movement_type = $%d", argIdx))
+		args = append(args, *filter.Type)
+		argIdx++
+	}
+
+	whereSQL := "WHERE " + strings.Join(whereClauses, " AND ")
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM stock_movements %s", whereSQL)
+	var total int64
+	err := q.QueryRow(ctx, countQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count stock movements: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, product_id, warehouse_id, movement_type, reason_code, quantity, balance_after, reference, note, actor, created_at
+		FROM stock_movements
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereSQL, argIdx, argIdx+1)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	var movements []inventoryDomain.StockMovement
+	for rows.Next() {
+		var movement inventoryDomain.StockMovement
+		err := rows.Scan(
+			&movement.ID,
+			&movement.ProductID,
+			&movement.WarehouseID,
+			&movement.Type,
+			&movement.ReasonCode,
+			&movement.Quantity,
+			&movement.BalanceAfter,
+			&movement.Reference,
+			&movement.Note,
+			&movement.Actor,
+			&movement.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, total, nil
+}
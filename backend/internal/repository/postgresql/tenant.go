@@ -0,0 +1,44 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	tenantDomain "github.com/naxumi/bnsp-jwd/internal/domain/tenant"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type tenantRepositoryImpl struct {
+	db *database.DB
+}
+
+func NewTenantRepository(db *database.DB) tenantDomain.TenantRepository {
+	return &tenantRepositoryImpl{db: db}
+}
+
+func (r *tenantRepositoryImpl) GetAll(ctx context.Context) ([]tenantDomain.Tenant, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, name, created_at
+		FROM tenants
+		ORDER BY id
+	`
+
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []tenantDomain.Tenant
+	for rows.Next() {
+		var t tenantDomain.Tenant
+		if err := rows.Scan(&t.ID, &t.Name, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, t)
+	}
+
+	return tenants, nil
+}
@@ -46,3 +46,23 @@ func GetQuerier(ctx context.Context, db *database.DB) database.Querier {
 	}
 	return db.Pool
 }
+
+type transactorImpl struct {
+	db *database.DB
+}
+
+// NewTransactor returns a database.Transactor backed by db
+func NewTransactor(db *database.DB) database.Transactor {
+	return &transactorImpl{db: db}
+}
+
+// WithinTransaction runs fn inside a transaction. If ctx already carries a
+// transaction, fn joins it instead of starting a new one.
+func (t *transactorImpl) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value("tx").(pgx.Tx); ok {
+		return fn(ctx)
+	}
+	return WithTransaction(ctx, t.db, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, "tx", tx))
+	})
+}
@@ -0,0 +1,94 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	userDomain "github.com/naxumi/bnsp-jwd/internal/domain/user"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type userRepositoryImpl struct {
+	db *database.DB
+}
+
+func NewUserRepository(db *database.DB) userDomain.UserRepository {
+	return &userRepositoryImpl{db: db}
+}
+
+func (r *userRepositoryImpl) Create(ctx context.Context, user userDomain.User) (userDomain.User, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		INSERT INTO users (username, password_hash, role, tenant_id, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+
+	err := q.QueryRow(ctx, query,
+		user.Username,
+		user.PasswordHash,
+		user.Role,
+		user.TenantID,
+		user.IsActive,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return userDomain.User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *userRepositoryImpl) GetByID(ctx context.Context, id int64) (userDomain.User, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, username, password_hash, role, tenant_id, is_active, created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`
+
+	var user userDomain.User
+	err := q.QueryRow(ctx, query, id).Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.Role,
+		&user.TenantID,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return userDomain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (r *userRepositoryImpl) GetByUsername(ctx context.Context, username string) (userDomain.User, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, username, password_hash, role, tenant_id, is_active, created_at, updated_at
+		FROM users
+		WHERE username = $1
+	`
+
+	var user userDomain.User
+	err := q.QueryRow(ctx, query, username).Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.Role,
+		&user.TenantID,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return userDomain.User{}, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,229 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	variantDomain "github.com/naxumi/bnsp-jwd/internal/domain/variant"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type variantRepositoryImpl struct {
+	db *database.DB
+}
+
+func NewVariantRepository(db *database.DB) variantDomain.VariantRepository {
+	return &variantRepositoryImpl{db: db}
+}
+
+// variantColumns is the select list shared by every variant query. A variant
+// without its own price inherits the parent's.
+const variantColumns = `
+	id, parent_id, sku, name, option_values,
+	COALESCE(price, (SELECT p.price FROM products p WHERE p.id = products.parent_id)),
+	price, stock, status, image_url, created_at, updated_at
+`
+
+// scanVariant scans a row selected with variantColumns
+func scanVariant(row pgx.Row) (variantDomain.Variant, error) {
+	var variant variantDomain.Variant
+	err := row.Scan(
+		&variant.ID,
+		&variant.ParentID,
+		&variant.SKU,
+		&variant.Name,
+		&variant.Options,
+		&variant.Price,
+		&variant.PriceOverride,
+		&variant.Stock,
+		&variant.Status,
+		&variant.ImageURL,
+		&variant.CreatedAt,
+		&variant.UpdatedAt,
+	)
+	return variant, err
+}
+
+func (r *variantRepositoryImpl) GetOptions(ctx context.Context, productID int64) ([]variantDomain.Option, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT name, allowed_values
+		FROM product_options
+		WHERE product_id = $1
+		ORDER BY position ASC, id ASC
+	`
+
+	rows, err := q.Query(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product options: %w", err)
+	}
+	defer rows.Close()
+
+	var options []variantDomain.Option
+	for rows.Next() {
+		var option variantDomain.Option
+		if err := rows.Scan(&option.Name, &option.Values); err != nil {
+			return nil, fmt.Errorf("failed to scan product option: %w", err)
+		}
+		options = append(options, option)
+	}
+
+	return options, nil
+}
+
+func (r *variantRepositoryImpl) ReplaceOptions(ctx context.Context, productID int64, options []variantDomain.Option) error {
+	q := GetQuerier(ctx, r.db)
+
+	_, err := q.Exec(ctx, `DELETE FROM product_options WHERE product_id = $1`, productID)
+	if err != nil {
+		return fmt.Errorf("failed to clear product options: %w", err)
+	}
+
+	query := `
+		INSERT INTO product_options (product_id, name, allowed_values, position)
+		VALUES ($1, $2, $3, $4)
+	`
+	for position, option := range options {
+		if _, err := q.Exec(ctx, query, productID, option.Name, option.Values, position); err != nil {
+			return fmt.Errorf("failed to create product option: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *variantRepositoryImpl) Create(ctx context.Context, newVariant variantDomain.Variant) (variantDomain.Variant, error) {
+	q := GetQuerier(ctx, r.db)
+
+	// Variants live in the parent's category and start without stock, which
+	// is booked through the ledger afterwards
+	query := `
+		INSERT INTO products (parent_id, sku, name, price, stock, category_id, status, option_values, created_at, updated_at)
+		SELECT $1, $2, $3, $4, 0, p.category_id, $5, $6, NOW(), NOW()
+		FROM products p
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+		RETURNING id
+	`
+
+	var id int64
+	err := q.QueryRow(ctx, query,
+		newVariant.ParentID,
+		newVariant.SKU,
+		newVariant.Name,
+		newVariant.PriceOverride,
+		newVariant.Status,
+		newVariant.Options,
+	).Scan(&id)
+	if err != nil {
+		return variantDomain.Variant{}, fmt.Errorf("failed to create variant: %w", err)
+	}
+
+	return r.GetByID(ctx, newVariant.ParentID, id)
+}
+
+func (r *variantRepositoryImpl) GetByID(ctx context.Context, parentID int64, id int64) (variantDomain.Variant, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM products
+		WHERE id = $1 AND parent_id = $2 AND deleted_at IS NULL
+	`, variantColumns)
+
+	variant, err := scanVariant(q.QueryRow(ctx, query, id, parentID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return variantDomain.Variant{}, fmt.Errorf("variant not found: %w", err)
+		}
+		return variantDomain.Variant{}, fmt.Errorf("failed to get variant by ID: %w", err)
+	}
+
+	return variant, nil
+}
+
+func (r *variantRepositoryImpl) GetByParent(ctx context.Context, parentID int64) ([]variantDomain.Variant, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM products
+		WHERE parent_id = $1 AND deleted_at IS NULL
+		ORDER BY id ASC
+	`, variantColumns)
+
+	rows, err := q.Query(ctx, query, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []variantDomain.Variant
+	for rows.Next() {
+		variant, err := scanVariant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan variant: %w", err)
+		}
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+func (r *variantRepositoryImpl) Update(ctx context.Context, variant variantDomain.UpdateVariantRequest) error {
+	q := GetQuerier(ctx, r.db)
+
+	updates := []string{}
+	args := []interface{}{}
+	argIdx := 1
+
+	if variant.SKU != nil {
+		updates = append(updates, fmt.Sprintf("sku = $%d", argIdx))
+		args = append(args, *variant.SKU)
+		argIdx++
+	}
+	if variant.PriceOverride != nil {
+		updates = append(updates, fmt.Sprintf("price = $%d", argIdx))
+		args = append(args, *variant.PriceOverride)
+		argIdx++
+	}
+	if variant.ClearPriceOverride {
+		updates = append(updates, "price = NULL")
+	}
+	if variant.Status != nil {
+		updates = append(updates, fmt.Sprintf("status = $%d", argIdx))
+		args = append(args, *variant.Status)
+		argIdx++
+	}
+	if variant.ImageURL != nil {
+		// The resized copies of an uploaded image no longer apply
+		updates = append(updates, fmt.Sprintf("image_url = $%d, image_variants = NULL", argIdx))
+		args = append(args, *variant.ImageURL)
+		argIdx++
+	}
+
+	if len(updates) == 0 {
+		// No fields to update, just return success
+		return nil
+	}
+
+	args = append(args, variant.ID, variant.ProductID)
+	query := fmt.Sprintf(`
+		UPDATE products
+		SET %s, version = version + 1, updated_at = NOW()
+		WHERE id = $%d AND parent_id = $%d AND deleted_at IS NULL
+	`, strings.Join(updates, ", "), argIdx, argIdx+1)
+
+	commandTag, err := q.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update variant: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
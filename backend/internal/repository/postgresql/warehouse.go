@@ -0,0 +1,228 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	warehouseDomain "github.com/naxumi/bnsp-jwd/internal/domain/warehouse"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type warehouseRepositoryImpl struct {
+	db *database.DB
+}
+
+func NewWarehouseRepository(db *database.DB) warehouseDomain.WarehouseRepository {
+	return &warehouseRepositoryImpl{db: db}
+}
+
+func (r *warehouseRepositoryImpl) Create(ctx context.Context, newWarehouse warehouseDomain.Warehouse) (warehouseDomain.Warehouse, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		INSERT INTO warehouses (code, name, address, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING id, is_default, created_at, updated_at
+	`
+
+	err := q.QueryRow(ctx, query,
+		newWarehouse.Code,
+		newWarehouse.Name,
+		newWarehouse.Address,
+	).Scan(&newWarehouse.ID, &newWarehouse.IsDefault, &newWarehouse.CreatedAt, &newWarehouse.UpdatedAt)
+	if err != nil {
+		return warehouseDomain.Warehouse{}, fmt.Errorf("failed to create warehouse: %w", err)
+	}
+
+	return newWarehouse, nil
+}
+
+func (r *warehouseRepositoryImpl) GetByID(ctx context.Context, id int64) (warehouseDomain.Warehouse, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, code, name, address, is_default, created_at, updated_at
+		FROM warehouses
+		WHERE id = $1
+	`
+
+	var warehouse warehouseDomain.Warehouse
+	err := q.QueryRow(ctx, query, id).
+		Scan(
+			&warehouse.ID,
+			&warehouse.Code,
+			&warehouse.Name,
+			&warehouse.Address,
+			&warehouse.IsDefault,
+			&warehouse.CreatedAt,
+			&warehouse.UpdatedAt,
+		)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return warehouseDomain.Warehouse{}, fmt.Errorf("warehouse not found: %w", err)
+		}
+		return warehouseDomain.Warehouse{}, fmt.Errorf("failed to get warehouse by ID: %w", err)
+	}
+
+	return warehouse, nil
+}
+
+func (r *warehouseRepositoryImpl) GetByIDForUpdate(ctx context.Context, id int64) (warehouseDomain.Warehouse, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, code, name, address, is_default, created_at, updated_at
+		FROM warehouses
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var warehouse warehouseDomain.Warehouse
+	err := q.QueryRow(ctx, query, id).
+		Scan(
+			&warehouse.ID,
+			&warehouse.Code,
+			&warehouse.Name,
+			&warehouse.Address,
+			&warehouse.IsDefault,
+			&warehouse.CreatedAt,
+			&warehouse.UpdatedAt,
+		)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return warehouseDomain.Warehouse{}, fmt.Errorf("warehouse not found: %w", err)
+		}
+		return warehouseDomain.Warehouse{}, fmt.Errorf("failed to get warehouse by ID for update: %w", err)
+	}
+
+	return warehouse, nil
+}
+
+func (r *warehouseRepositoryImpl) GetAll(ctx context.Context) ([]warehouseDomain.Warehouse, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT id, code, name, address, is_default, created_at, updated_at
+		FROM warehouses
+		ORDER BY id ASC
+	`
+
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warehouses: %w", err)
+	}
+	defer rows.Close()
+
+	var warehouses []warehouseDomain.Warehouse
+	for rows.Next() {
+		var warehouse warehouseDomain.Warehouse
+		err := rows.Scan(
+			&warehouse.ID,
+			&warehouse.Code,
+			&warehouse.Name,
+			&warehouse.Address,
+			&warehouse.IsDefault,
+			&warehouse.CreatedAt,
+			&warehouse.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan warehouse: %w", err)
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+
+	return warehouses, nil
+}
+
+func (r *warehouseRepositoryImpl) Update(ctx context.Context, warehouse warehouseDomain.UpdateWarehouseRequest) error {
+	q := GetQuerier(ctx, r.db)
+
+	updates := []string{}
+	args := []interface{}{}
+	argIdx := 1
+
+	if warehouse.Code != nil {
+		updates = append(updates, fmt.Sprintf("code = $%d", argIdx))
+		args = append(args, *warehouse.Code)
+		argIdx++
+	}
+	if warehouse.Name != nil {
+		updates = append(updates, fmt.Sprintf("name = $%d", argIdx))
+		args = append(args, *warehouse.Name)
+		argIdx++
+	}
+	if warehouse.Address != nil {
+		updates = append(updates, fmt.Sprintf("address = $%d", argIdx))
+		args = append(args, *warehouse.Address)
+		argIdx++
+	}
+
+	if len(updates) == 0 {
+		// No fields to update, just return success
+		return nil
+	}
+
+	args = append(args, warehouse.ID)
+	query := fmt.Sprintf(`
+		UPDATE warehouses
+		SET %s, updated_at = NOW()
+		WHERE id = $%d
+	`, strings.Join(updates, ", "), argIdx)
+
+	commandTag, err := q.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update warehouse: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *warehouseRepositoryImpl) Delete(ctx context.Context, id int64) error {
+	q := GetQuerier(ctx, r.db)
+
+	// Rows emptied by issues and transfers would hold back the delete
+	_, err := q.Exec(ctx, "DELETE FROM warehouse_stock WHERE warehouse_id = $1 AND quantity = 0", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete empty warehouse stock: %w", err)
+	}
+
+	query := `
+		DELETE FROM warehouses
+		WHERE id = $1
+	`
+
+	commandTag, err := q.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete warehouse: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *warehouseRepositoryImpl) TotalStock(ctx context.Context, id int64) (int64, error) {
+	q := GetQuerier(ctx, r.db)
+
+	query := `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM warehouse_stock
+		WHERE warehouse_id = $1
+	`
+
+	var total int64
+	err := q.QueryRow(ctx, query, id).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get warehouse stock: %w", err)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,173 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	apikeyDomain "github.com/naxumi/bnsp-jwd/internal/domain/apikey"
+	tenantDomain "github.com/naxumi/bnsp-jwd/internal/domain/tenant"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+)
+
+// keyPrefix starts every key, so a leaked key is easy to recognise
+const keyPrefix = "bnsp_"
+
+type APIKeyServiceImpl struct {
+	repository apikeyDomain.APIKeyRepository
+}
+
+func NewAPIKeyService(repository apikeyDomain.APIKeyRepository) apikeyDomain.APIKeyService {
+	return &APIKeyServiceImpl{
+		repository: repository,
+	}
+}
+
+func (s *APIKeyServiceImpl) CreateKey(ctx context.Context, req apikeyDomain.CreateAPIKeyRequest) (apikeyDomain.CreatedAPIKeyResponse, error) {
+	// A key acts for the tenant it was created in
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return apikeyDomain.CreatedAPIKeyResponse{}, tenantDomain.ErrTenantRequired
+	}
+
+	key, prefix, err := newKey()
+	if err != nil {
+		return apikeyDomain.CreatedAPIKeyResponse{}, err
+	}
+
+	createdBy := requestctx.ActorOrSystem(ctx)
+
+	created, err := s.repository.Create(ctx, apikeyDomain.APIKey{
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    prefix,
+		KeyHash:   hashKey(key),
+		Scopes:    req.Scopes,
+		TenantID:  tenantID,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		return apikeyDomain.CreatedAPIKeyResponse{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return apikeyDomain.CreatedAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(created),
+		Key:            key,
+	}, nil
+}
+
+func (s *APIKeyServiceImpl) ListKeys(ctx context.Context) ([]apikeyDomain.APIKeyResponse, error) {
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return nil, tenantDomain.ErrTenantRequired
+	}
+
+	keys, err := s.repository.GetAll(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keyResponses := []apikeyDomain.APIKeyResponse{}
+	for _, k := range keys {
+		keyResponses = append(keyResponses, toAPIKeyResponse(k))
+	}
+
+	return keyResponses, nil
+}
+
+func (s *APIKeyServiceImpl) RevokeKey(ctx context.Context, id int64) error {
+	tenantID, ok := requestctx.TenantID(ctx)
+	if !ok {
+		return tenantDomain.ErrTenantRequired
+	}
+
+	err := s.repository.Revoke(ctx, tenantID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apikeyDomain.ErrAPIKeyNotFound
+		}
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	return nil
+}
+
+func (s *APIKeyServiceImpl) Authenticate(ctx context.Context, key string) (auth.Claims, error) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return auth.Claims{}, apikeyDomain.ErrInvalidAPIKey
+	}
+
+	k, err := s.repository.GetByHash(ctx, hashKey(key))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return auth.Claims{}, apikeyDomain.ErrInvalidAPIKey
+		}
+		return auth.Claims{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if k.RevokedAt != nil {
+		return auth.Claims{}, apikeyDomain.ErrInvalidAPIKey
+	}
+
+	// A failed bookkeeping write must not fail the request
+	if err := s.repository.TouchLastUsed(ctx, k.ID); err != nil {
+		log.Printf("Error recording use of api key %d: %v", k.ID, err)
+	}
+
+	scopes := make([]auth.Permission, len(k.Scopes))
+	for i, scope := range k.Scopes {
+		scopes[i] = auth.Permission(scope)
+	}
+
+	return auth.Claims{
+		Subject:  "apikey:" + k.Prefix,
+		Scopes:   scopes,
+		TenantID: k.TenantID,
+	}, nil
+}
+
+// newKey returns a key of the form bnsp_<8 hex>_<secret> and its visible
+// prefix, bnsp_<8 hex>
+func newKey() (key, prefix string, err error) {
+	b := make([]byte, 36)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	prefix = keyPrefix + hex.EncodeToString(b[:4])
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(b[4:])
+	return key, prefix, nil
+}
+
+// hashKey is the stored form of a key. Keys are random, so a fast hash
+// keeps a database leak from exposing usable keys.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func toAPIKeyResponse(k apikeyDomain.APIKey) apikeyDomain.APIKeyResponse {
+	resp := apikeyDomain.APIKeyResponse{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Scopes:    k.Scopes,
+		CreatedBy: k.CreatedBy,
+		CreatedAt: k.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if k.LastUsedAt != nil {
+		lastUsedAt := k.LastUsedAt.Format("2006-01-02T15:04:05Z07:00")
+		resp.LastUsedAt = &lastUsedAt
+	}
+	if k.RevokedAt != nil {
+		revokedAt := k.RevokedAt.Format("2006-01-02T15:04:05Z07:00")
+		resp.RevokedAt = &revokedAt
+	}
+	return resp
+}
@@ -0,0 +1,163 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	apikeyDomain "github.com/naxumi/bnsp-jwd/internal/domain/apikey"
+	tenantDomain "github.com/naxumi/bnsp-jwd/internal/domain/tenant"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock Repository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key apikeyDomain.APIKey) (apikeyDomain.APIKey, error) {
+	args := m.Called(ctx, key)
+	if fn, ok := args.Get(0).(func(apikeyDomain.APIKey) apikeyDomain.APIKey); ok {
+		return fn(key), args.Error(1)
+	}
+	return args.Get(0).(apikeyDomain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (apikeyDomain.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	return args.Get(0).(apikeyDomain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAll(ctx context.Context, tenantID int64) ([]apikeyDomain.APIKey, error) {
+	args := m.Called(ctx, tenantID)
+	return args.Get(0).([]apikeyDomain.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, tenantID, id int64) error {
+	args := m.Called(ctx, tenantID, id)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func newTestService() (*APIKeyServiceImpl, *MockAPIKeyRepository) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := &APIKeyServiceImpl{
+		repository: mockRepo,
+	}
+	return service, mockRepo
+}
+
+// Tests for CreateKey
+func TestAPIKeyService_CreateKey_StoresHashOnly(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	var stored apikeyDomain.APIKey
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(apikeyDomain.APIKey) }).
+		Return(func(key apikeyDomain.APIKey) apikeyDomain.APIKey {
+			key.ID = 1
+			key.CreatedAt = time.Now()
+			return key
+		}, nil)
+
+	ctx := requestctx.WithTenantID(requestctx.WithActor(context.Background(), "alice"), 2)
+	req := apikeyDomain.CreateAPIKeyRequest{Name: "POS terminal 1", Scopes: []string{"product:read", "product:write"}}
+
+	result, err := service.CreateKey(ctx, req)
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result.Key, result.Prefix+"_"))
+	assert.True(t, strings.HasPrefix(result.Prefix, "bnsp_"))
+	assert.Equal(t, hashKey(result.Key), stored.KeyHash)
+	assert.NotContains(t, stored.KeyHash, result.Key)
+	assert.Equal(t, "alice", stored.CreatedBy)
+	assert.Equal(t, int64(2), stored.TenantID)
+	assert.Equal(t, []string{"product:read", "product:write"}, result.Scopes)
+}
+
+// Tests for Authenticate
+func TestAPIKeyService_Authenticate_Success(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	key := "bnsp_1a2b3c4d_secret"
+	mockRepo.On("GetByHash", mock.Anything, hashKey(key)).
+		Return(apikeyDomain.APIKey{ID: 3, Prefix: "bnsp_1a2b3c4d", Scopes: []string{"product:write"}, TenantID: 2}, nil)
+	mockRepo.On("TouchLastUsed", mock.Anything, int64(3)).Return(nil)
+
+	claims, err := service.Authenticate(context.Background(), key)
+
+	require.NoError(t, err)
+	assert.Equal(t, "apikey:bnsp_1a2b3c4d", claims.Subject)
+	assert.Equal(t, int64(2), claims.TenantID)
+	assert.True(t, claims.HasPermission(auth.PermProductWrite))
+	assert.False(t, claims.HasPermission(auth.PermProductPrice))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyService_Authenticate_LastUseFailureIgnored(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	mockRepo.On("GetByHash", mock.Anything, mock.Anything).
+		Return(apikeyDomain.APIKey{ID: 3, Prefix: "bnsp_1a2b3c4d", Scopes: []string{"product:read"}}, nil)
+	mockRepo.On("TouchLastUsed", mock.Anything, int64(3)).Return(errors.New("connection reset"))
+
+	_, err := service.Authenticate(context.Background(), "bnsp_1a2b3c4d_secret")
+
+	assert.NoError(t, err)
+}
+
+func TestAPIKeyService_Authenticate_Rejected(t *testing.T) {
+	revokedAt := time.Now()
+	tests := map[string]struct {
+		key    apikeyDomain.APIKey
+		err    error
+		header string
+	}{
+		"unknown":       {err: pgx.ErrNoRows, header: "bnsp_1a2b3c4d_secret"},
+		"revoked":       {key: apikeyDomain.APIKey{ID: 3, RevokedAt: &revokedAt}, header: "bnsp_1a2b3c4d_secret"},
+		"foreign token": {header: "eyJhbGciOiJIUzI1NiJ9"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			service, mockRepo := newTestService()
+			mockRepo.On("GetByHash", mock.Anything, mock.Anything).Return(tc.key, tc.err)
+
+			_, err := service.Authenticate(context.Background(), tc.header)
+
+			assert.Equal(t, apikeyDomain.ErrInvalidAPIKey, err)
+			mockRepo.AssertNotCalled(t, "TouchLastUsed")
+		})
+	}
+}
+
+// Tests for RevokeKey
+func TestAPIKeyService_RevokeKey_NotFound(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	mockRepo.On("Revoke", mock.Anything, int64(2), int64(99)).Return(pgx.ErrNoRows)
+
+	err := service.RevokeKey(requestctx.WithTenantID(context.Background(), 2), 99)
+
+	assert.Equal(t, apikeyDomain.ErrAPIKeyNotFound, err)
+}
+
+func TestAPIKeyService_RevokeKey_NoTenant(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	err := service.RevokeKey(context.Background(), 99)
+
+	assert.Equal(t, tenantDomain.ErrTenantRequired, err)
+	mockRepo.AssertNotCalled(t, "Revoke")
+}
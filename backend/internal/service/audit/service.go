@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	auditDomain "github.com/naxumi/bnsp-jwd/internal/domain/audit"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+)
+
+type AuditServiceImpl struct {
+	repository auditDomain.AuditRepository
+}
+
+func NewAuditService(repository auditDomain.AuditRepository) auditDomain.AuditService {
+	return &AuditServiceImpl{
+		repository: repository,
+	}
+}
+
+// Record implements auditDomain.AuditService. Changes made without a request,
+// by a background job, are recorded as made by the system.
+func (s *AuditServiceImpl) Record(ctx context.Context, entry auditDomain.Entry) error {
+	record := auditDomain.Record{
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Action:     entry.Action,
+		Actor:      requestctx.ActorOrSystem(ctx),
+		Changes:    entry.Changes,
+	}
+	if requestID := requestctx.RequestID(ctx); requestID != "" {
+		record.RequestID = &requestID
+	}
+	if record.Changes == nil {
+		record.Changes = map[string]auditDomain.Change{}
+	}
+
+	if _, err := s.repository.Create(ctx, record); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListRecords implements auditDomain.AuditService.
+func (s *AuditServiceImpl) ListRecords(ctx context.Context, filter auditDomain.ListAuditFilter) (auditDomain.ListAuditResponse, error) {
+	records, total, err := s.repository.List(ctx, filter)
+	if err != nil {
+		return auditDomain.ListAuditResponse{}, fmt.Errorf("failed to list audit records: %w", err)
+	}
+
+	recordResponses := []auditDomain.AuditRecordResponse{}
+	for _, r := range records {
+		recordResponses = append(recordResponses, toAuditRecordResponse(r))
+	}
+
+	totalPages := (total + int64(filter.Limit) - 1) / int64(filter.Limit)
+
+	return auditDomain.ListAuditResponse{
+		TotalCount: total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: int(totalPages),
+		Records:    recordResponses,
+	}, nil
+}
+
+func toAuditRecordResponse(r auditDomain.Record) auditDomain.AuditRecordResponse {
+	return auditDomain.AuditRecordResponse{
+		ID:         r.ID,
+		EntityType: r.EntityType,
+		EntityID:   r.EntityID,
+		Action:     r.Action,
+		Actor:      r.Actor,
+		RequestID:  r.RequestID,
+		Changes:    r.Changes,
+		CreatedAt:  r.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
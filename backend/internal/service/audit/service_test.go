@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	auditDomain "github.com/naxumi/bnsp-jwd/internal/domain/audit"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Repository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(ctx context.Context, record auditDomain.Record) (auditDomain.Record, error) {
+	args := m.Called(ctx, record)
+	return args.Get(0).(auditDomain.Record), args.Error(1)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter auditDomain.ListAuditFilter) ([]auditDomain.Record, int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]auditDomain.Record), args.Get(1).(int64), args.Error(2)
+}
+
+// Tests for Record
+func TestAuditService_Record_TakesActorFromRequest(t *testing.T) {
+	mockRepo := new(MockAuditRepository)
+	service := &AuditServiceImpl{repository: mockRepo}
+
+	ctx := requestctx.WithActor(context.Background(), "alice")
+	ctx = requestctx.WithRequestID(ctx, "host/abc-000001")
+
+	changes := map[string]auditDomain.Change{"price": {Before: "10000.00", After: "12000.00"}}
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(r auditDomain.Record) bool {
+		return r.EntityType == auditDomain.EntityProduct && r.EntityID == 1 &&
+			r.Action == auditDomain.ActionUpdate && r.Actor == "alice" &&
+			r.RequestID != nil && *r.RequestID == "host/abc-000001" &&
+			assert.ObjectsAreEqual(changes, r.Changes)
+	})).Return(auditDomain.Record{ID: 1}, nil)
+
+	err := service.Record(ctx, auditDomain.Entry{
+		EntityType: auditDomain.EntityProduct,
+		EntityID:   1,
+		Action:     auditDomain.ActionUpdate,
+		Changes:    changes,
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuditService_Record_OutsideRequest(t *testing.T) {
+	mockRepo := new(MockAuditRepository)
+	service := &AuditServiceImpl{repository: mockRepo}
+
+	// Background jobs have neither an actor nor a request ID
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(r auditDomain.Record) bool {
+		return r.Actor == requestctx.SystemActor && r.RequestID == nil && r.Changes != nil
+	})).Return(auditDomain.Record{ID: 2}, nil)
+
+	err := service.Record(context.Background(), auditDomain.Entry{
+		EntityType: auditDomain.EntityProduct,
+		EntityID:   1,
+		Action:     auditDomain.ActionRestore,
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuditService_Record_RepositoryError(t *testing.T) {
+	mockRepo := new(MockAuditRepository)
+	service := &AuditServiceImpl{repository: mockRepo}
+
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(auditDomain.Record{}, errors.New("database error"))
+
+	err := service.Record(context.Background(), auditDomain.Entry{EntityType: auditDomain.EntityProduct, EntityID: 1, Action: auditDomain.ActionCreate})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to record audit entry")
+}
+
+// Tests for ListRecords
+func TestAuditService_ListRecords_Success(t *testing.T) {
+	mockRepo := new(MockAuditRepository)
+	service := &AuditServiceImpl{repository: mockRepo}
+
+	actor := "alice"
+	filter := auditDomain.ListAuditFilter{Actor: &actor, Page: 1, Limit: 2}
+
+	createdAt := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	records := []auditDomain.Record{
+		{ID: 5, EntityType: auditDomain.EntityProduct, EntityID: 1, Action: auditDomain.ActionUpdate, Actor: actor, CreatedAt: createdAt},
+		{ID: 4, EntityType: auditDomain.EntityProduct, EntityID: 1, Action: auditDomain.ActionCreate, Actor: actor, CreatedAt: createdAt},
+	}
+
+	mockRepo.On("List", mock.Anything, filter).
+		Return(records, int64(3), nil)
+
+	result, err := service.ListRecords(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), result.TotalCount)
+	assert.Equal(t, 2, result.TotalPages)
+	assert.Len(t, result.Records, 2)
+	assert.Equal(t, int64(5), result.Records[0].ID)
+	assert.Equal(t, "2026-10-01T09:30:00Z", result.Records[0].CreatedAt)
+}
+
+// Tests for Diff
+func TestDiff_OnlyChangedFields(t *testing.T) {
+	before := map[string]any{"name": "Old", "price": "10000.00", "stock": 5}
+	after := map[string]any{"name": "New", "price": "10000.00", "stock": 5}
+
+	changes := auditDomain.Diff(before, after)
+
+	assert.Equal(t, map[string]auditDomain.Change{"name": {Before: "Old", After: "New"}}, changes)
+}
+
+func TestDiff_CreatedEntity(t *testing.T) {
+	changes := auditDomain.Diff(nil, map[string]any{"sku": "SKU-001"})
+
+	assert.Equal(t, map[string]auditDomain.Change{"sku": {Before: nil, After: "SKU-001"}}, changes)
+}
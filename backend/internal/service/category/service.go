@@ -0,0 +1,183 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	categoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/category"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/slug"
+)
+
+type CategoryServiceImpl struct {
+	transactor database.Transactor
+	repository categoryDomain.CategoryRepository
+}
+
+func NewCategoryService(transactor database.Transactor, repository categoryDomain.CategoryRepository) categoryDomain.CategoryService {
+	return &CategoryServiceImpl{
+		transactor: transactor,
+		repository: repository,
+	}
+}
+
+func (s *CategoryServiceImpl) CreateCategory(ctx context.Context, req categoryDomain.CreateCategoryRequest) (categoryDomain.CategoryResponse, error) {
+	newCategory := categoryDomain.Category{
+		ParentID: req.ParentID,
+		Name:     req.Name,
+	}
+
+	// Children get their parent's slug as prefix, so "Tools" under "Garden"
+	// becomes "garden-tools" and does not clash with a top-level "tools"
+	newCategory.Slug = slug.Make(req.Name)
+	if req.ParentID != nil {
+		parent, err := s.repository.GetByID(ctx, *req.ParentID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return categoryDomain.CategoryResponse{}, categoryDomain.ErrParentNotFound
+			}
+			return categoryDomain.CategoryResponse{}, fmt.Errorf("failed to get parent category: %w", err)
+		}
+		newCategory.Slug = parent.Slug + "-" + newCategory.Slug
+	}
+	if req.Slug != nil {
+		newCategory.Slug = *req.Slug
+	}
+
+	createdCategory, err := s.repository.Create(ctx, newCategory)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // unique violation
+				return categoryDomain.CategoryResponse{}, categoryDomain.ErrCategorySlugExists
+			case "23503": // parent deleted in the meantime
+				return categoryDomain.CategoryResponse{}, categoryDomain.ErrParentNotFound
+			}
+		}
+		return categoryDomain.CategoryResponse{}, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return toCategoryResponse(createdCategory), nil
+}
+
+func (s *CategoryServiceImpl) GetCategory(ctx context.Context, id int64) (categoryDomain.CategoryResponse, error) {
+	c, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return categoryDomain.CategoryResponse{}, categoryDomain.ErrCategoryNotFound
+		}
+		return categoryDomain.CategoryResponse{}, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	return toCategoryResponse(c), nil
+}
+
+func (s *CategoryServiceImpl) ListCategories(ctx context.Context) ([]categoryDomain.CategoryResponse, error) {
+	categories, err := s.repository.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	categoryResponses := []categoryDomain.CategoryResponse{}
+	for _, c := range categories {
+		categoryResponses = append(categoryResponses, toCategoryResponse(c))
+	}
+
+	return categoryResponses, nil
+}
+
+func (s *CategoryServiceImpl) GetCategoryTree(ctx context.Context) ([]categoryDomain.CategoryResponse, error) {
+	categories, err := s.repository.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	childrenOf := map[int64][]categoryDomain.Category{}
+	roots := []categoryDomain.Category{}
+	for _, c := range categories {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+			continue
+		}
+		childrenOf[*c.ParentID] = append(childrenOf[*c.ParentID], c)
+	}
+
+	var build func(c categoryDomain.Category) categoryDomain.CategoryResponse
+	build = func(c categoryDomain.Category) categoryDomain.CategoryResponse {
+		node := toCategoryResponse(c)
+		for _, child := range childrenOf[c.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	tree := []categoryDomain.CategoryResponse{}
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+
+	return tree, nil
+}
+
+func (s *CategoryServiceImpl) UpdateCategory(ctx context.Context, req categoryDomain.UpdateCategoryRequest) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if req.ParentID != nil && *req.ParentID > 0 {
+			if _, err := s.repository.GetByID(ctx, *req.ParentID); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return categoryDomain.ErrParentNotFound
+				}
+				return fmt.Errorf("failed to get parent category: %w", err)
+			}
+
+			// The new parent must not sit inside the subtree being moved
+			isDescendant, err := s.repository.IsDescendant(ctx, req.ID, *req.ParentID)
+			if err != nil {
+				return fmt.Errorf("failed to check category parent: %w", err)
+			}
+			if isDescendant {
+				return categoryDomain.ErrCategoryCycle
+			}
+		}
+
+		if err := s.repository.Update(ctx, req); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return categoryDomain.ErrCategoryNotFound
+			}
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
+				return categoryDomain.ErrCategorySlugExists
+			}
+			return fmt.Errorf("failed to update category: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *CategoryServiceImpl) DeleteCategory(ctx context.Context, id int64) error {
+	if err := s.repository.Delete(ctx, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return categoryDomain.ErrCategoryNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // still referenced by products or children
+			return categoryDomain.ErrCategoryInUse
+		}
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	return nil
+}
+
+func toCategoryResponse(c categoryDomain.Category) categoryDomain.CategoryResponse {
+	return categoryDomain.CategoryResponse{
+		ID:        c.ID,
+		ParentID:  c.ParentID,
+		Name:      c.Name,
+		Slug:      c.Slug,
+		CreatedAt: c.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: c.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,215 @@
+package category
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	categoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/category"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Transactor runs the function directly, without a database
+type MockTransactor struct{}
+
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// Mock Repository
+type MockCategoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockCategoryRepository) Create(ctx context.Context, category categoryDomain.Category) (categoryDomain.Category, error) {
+	args := m.Called(ctx, category)
+	return args.Get(0).(categoryDomain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetByID(ctx context.Context, id int64) (categoryDomain.Category, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(categoryDomain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetBySlug(ctx context.Context, slug string) (categoryDomain.Category, error) {
+	args := m.Called(ctx, slug)
+	return args.Get(0).(categoryDomain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetByName(ctx context.Context, name string) ([]categoryDomain.Category, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).([]categoryDomain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetAll(ctx context.Context) ([]categoryDomain.Category, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]categoryDomain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Update(ctx context.Context, category categoryDomain.UpdateCategoryRequest) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) IsDescendant(ctx context.Context, id int64, candidateID int64) (bool, error) {
+	args := m.Called(ctx, id, candidateID)
+	return args.Bool(0), args.Error(1)
+}
+
+func newTestService() (*CategoryServiceImpl, *MockCategoryRepository) {
+	mockRepo := new(MockCategoryRepository)
+	service := &CategoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+	return service, mockRepo
+}
+
+// Tests for CreateCategory
+func TestCategoryService_CreateCategory_DerivesSlug(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	now := time.Now()
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(c categoryDomain.Category) bool {
+		return c.Name == "Home & Garden" && c.Slug == "home-and-garden" && c.ParentID == nil
+	})).Return(categoryDomain.Category{ID: 1, Name: "Home & Garden", Slug: "home-and-garden", CreatedAt: now, UpdatedAt: now}, nil)
+
+	result, err := service.CreateCategory(context.Background(), categoryDomain.CreateCategoryRequest{Name: "Home & Garden"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "home-and-garden", result.Slug)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCategoryService_CreateCategory_ChildSlugHasParentPrefix(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	parentID := int64(1)
+	mockRepo.On("GetByID", mock.Anything, parentID).
+		Return(categoryDomain.Category{ID: 1, Name: "Garden", Slug: "garden"}, nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(c categoryDomain.Category) bool {
+		return c.Slug == "garden-power-tools" && *c.ParentID == 1
+	})).Return(categoryDomain.Category{ID: 2, ParentID: &parentID, Name: "Power Tools", Slug: "garden-power-tools"}, nil)
+
+	result, err := service.CreateCategory(context.Background(), categoryDomain.CreateCategoryRequest{
+		ParentID: &parentID,
+		Name:     "Power Tools",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *result.ParentID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCategoryService_CreateCategory_UnknownParent(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	parentID := int64(99)
+	mockRepo.On("GetByID", mock.Anything, parentID).
+		Return(categoryDomain.Category{}, pgx.ErrNoRows)
+
+	_, err := service.CreateCategory(context.Background(), categoryDomain.CreateCategoryRequest{
+		ParentID: &parentID,
+		Name:     "Tools",
+	})
+
+	assert.Equal(t, categoryDomain.ErrParentNotFound, err)
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestCategoryService_CreateCategory_DuplicateSlug(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	pgErr := &pgconn.PgError{Code: "23505"}
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(categoryDomain.Category{}, pgErr)
+
+	_, err := service.CreateCategory(context.Background(), categoryDomain.CreateCategoryRequest{Name: "home and garden"})
+
+	assert.Equal(t, categoryDomain.ErrCategorySlugExists, err)
+}
+
+// Tests for GetCategoryTree
+func TestCategoryService_GetCategoryTree_NestsChildren(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	garden := int64(1)
+	tools := int64(2)
+	mockRepo.On("GetAll", mock.Anything).Return([]categoryDomain.Category{
+		{ID: 1, Name: "Garden", Slug: "garden"},
+		{ID: 2, ParentID: &garden, Name: "Tools", Slug: "garden-tools"},
+		{ID: 3, ParentID: &tools, Name: "Shovels", Slug: "garden-tools-shovels"},
+		{ID: 4, Name: "Kitchen", Slug: "kitchen"},
+	}, nil)
+
+	tree, err := service.GetCategoryTree(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, tree, 2)
+	assert.Len(t, tree[0].Children, 1)
+	assert.Equal(t, "Shovels", tree[0].Children[0].Children[0].Name)
+	assert.Empty(t, tree[1].Children)
+}
+
+// Tests for UpdateCategory
+func TestCategoryService_UpdateCategory_RejectsCycle(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	newParent := int64(3)
+	req := categoryDomain.UpdateCategoryRequest{ID: 1, ParentID: &newParent}
+
+	mockRepo.On("GetByID", mock.Anything, newParent).
+		Return(categoryDomain.Category{ID: 3}, nil)
+	mockRepo.On("IsDescendant", mock.Anything, int64(1), newParent).
+		Return(true, nil)
+
+	err := service.UpdateCategory(context.Background(), req)
+
+	assert.Equal(t, categoryDomain.ErrCategoryCycle, err)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestCategoryService_UpdateCategory_MoveToTopLevel(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	topLevel := int64(0)
+	req := categoryDomain.UpdateCategoryRequest{ID: 2, ParentID: &topLevel}
+
+	mockRepo.On("Update", mock.Anything, req).Return(nil)
+
+	err := service.UpdateCategory(context.Background(), req)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "IsDescendant")
+	mockRepo.AssertExpectations(t)
+}
+
+// Tests for DeleteCategory
+func TestCategoryService_DeleteCategory_InUse(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	pgErr := &pgconn.PgError{Code: "23503"}
+	mockRepo.On("Delete", mock.Anything, int64(1)).Return(pgErr)
+
+	err := service.DeleteCategory(context.Background(), 1)
+
+	assert.Equal(t, categoryDomain.ErrCategoryInUse, err)
+}
+
+func TestCategoryService_DeleteCategory_NotFound(t *testing.T) {
+	service, mockRepo := newTestService()
+
+	mockRepo.On("Delete", mock.Anything, int64(99)).Return(pgx.ErrNoRows)
+
+	err := service.DeleteCategory(context.Background(), 99)
+
+	assert.Equal(t, categoryDomain.ErrCategoryNotFound, err)
+}
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/imaging"
 	"github.com/naxumi/bnsp-jwd/internal/pkg/storage"
 )
 
@@ -28,8 +30,8 @@ type FileService interface {
 	// UploadCompanyLogo uploads a company logo
 	UploadCompanyLogo(ctx context.Context, companyUsername string, file io.Reader, filename string) (string, error)
 
-	// Product image uploads
-	UploadProductImage(ctx context.Context, productID string, file io.Reader, filename string) (string, error)
+	// Product image uploads, already checked and encoded by the image pipeline
+	UploadProductImage(ctx context.Context, productID string, file io.Reader, format string) (string, error)
 
 	// Generic operations
 	DeleteFile(ctx context.Context, path string) error
@@ -38,45 +40,32 @@ type FileService interface {
 
 type fileServiceImpl struct {
 	storage storage.FileStorage
+
+	// Formats images may be uploaded in
+	imagePolicy imaging.Policy
 }
 
-func NewFileService(storage storage.FileStorage) FileService {
+func NewFileService(storage storage.FileStorage, imagePolicy imaging.Policy) FileService {
 	return &fileServiceImpl{
-		storage: storage,
+		storage:     storage,
+		imagePolicy: imagePolicy,
 	}
 }
 
 // UploadAvatar uploads employee avatar
 func (s *fileServiceImpl) UploadAvatar(ctx context.Context, employeeID string, file io.Reader, filename string) (string, error) {
-	// Validate file extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	allowedExts := []string{".jpg", ".jpeg", ".png"}
-
-	isValid := false
-	for _, allowed := range allowedExts {
-		if ext == allowed {
-			isValid = true
-			break
-		}
-	}
-
-	if !isValid {
-		return "", fmt.Errorf("invalid file type: only jpg, jpeg, png allowed")
+	content, format, err := s.checkImage(file)
+	if err != nil {
+		return "", err
 	}
 
 	// Generate unique filename
 	uniqueID := uuid.New().String()
-	newFilename := fmt.Sprintf("%s-%s%s", employeeID, uniqueID, ext)
+	newFilename := fmt.Sprintf("%s-%s%s", employeeID, uniqueID, imaging.Extension(format))
 	path := filepath.Join("avatars", employeeID, newFilename)
 
-	// Determine content type
-	contentType := "image/jpeg"
-	if ext == ".png" {
-		contentType = "image/png"
-	}
-
 	// Upload
-	uploadedPath, err := s.storage.Upload(ctx, file, path, contentType)
+	uploadedPath, err := s.storage.Upload(ctx, content, path, imaging.ContentType(format))
 	if err != nil {
 		return "", fmt.Errorf("failed to upload avatar: %w", err)
 	}
@@ -104,25 +93,18 @@ func (s *fileServiceImpl) UploadDocument(ctx context.Context, employeeID string,
 
 // UploadAttendanceProof uploads attendance clock-in/out proof photo
 func (s *fileServiceImpl) UploadAttendanceProof(ctx context.Context, employeeID string, date time.Time, file io.Reader, filename string, clockType string) (string, error) {
-	ext := strings.ToLower(filepath.Ext(filename))
-
-	// Validate image format
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
-		return "", fmt.Errorf("invalid file type: only jpg, jpeg, png allowed")
+	content, format, err := s.checkImage(file)
+	if err != nil {
+		return "", err
 	}
 
 	// Generate path: attendance/{date}/{employeeID}-{clockType}-{timestamp}.jpg
 	dateStr := date.Format("2006-01-02")
 	timestamp := time.Now().Unix()
-	newFilename := fmt.Sprintf("%s-%s-%d%s", employeeID, clockType, timestamp, ext)
+	newFilename := fmt.Sprintf("%s-%s-%d%s", employeeID, clockType, timestamp, imaging.Extension(format))
 	path := filepath.Join("attendance", dateStr, newFilename)
 
-	contentType := "image/jpeg"
-	if ext == ".png" {
-		contentType = "image/png"
-	}
-
-	uploadedPath, err := s.storage.Upload(ctx, file, path, contentType)
+	uploadedPath, err := s.storage.Upload(ctx, content, path, imaging.ContentType(format))
 	if err != nil {
 		return "", fmt.Errorf("failed to upload attendance proof: %w", err)
 	}
@@ -160,35 +142,18 @@ func (s *fileServiceImpl) GetFileURL(ctx context.Context, path string, expiry ti
 
 // UploadCompanyLogo uploads a company logo
 func (s *fileServiceImpl) UploadCompanyLogo(ctx context.Context, companyUsername string, file io.Reader, filename string) (string, error) {
-	// Validate file extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	allowedExts := []string{".jpg", ".jpeg", ".png"}
-
-	isValid := false
-	for _, allowed := range allowedExts {
-		if ext == allowed {
-			isValid = true
-			break
-		}
-	}
-
-	if !isValid {
-		return "", fmt.Errorf("invalid file type: only jpg, jpeg, png allowed")
+	content, format, err := s.checkImage(file)
+	if err != nil {
+		return "", err
 	}
 
 	// Generate unique filename
 	uniqueID := uuid.New().String()
-	newFilename := fmt.Sprintf("%s-%s%s", companyUsername, uniqueID, ext)
+	newFilename := fmt.Sprintf("%s-%s%s", companyUsername, uniqueID, imaging.Extension(format))
 	path := filepath.Join("logos", companyUsername, newFilename)
 
-	// Determine content type
-	contentType := "image/jpeg"
-	if ext == ".png" {
-		contentType = "image/png"
-	}
-
 	// Upload
-	uploadedPath, err := s.storage.Upload(ctx, file, path, contentType)
+	uploadedPath, err := s.storage.Upload(ctx, content, path, imaging.ContentType(format))
 	if err != nil {
 		return "", fmt.Errorf("failed to upload company logo: %w", err)
 	}
@@ -196,43 +161,37 @@ func (s *fileServiceImpl) UploadCompanyLogo(ctx context.Context, companyUsername
 	return uploadedPath, nil
 }
 
-// UploadProductImage uploads product image
-func (s *fileServiceImpl) UploadProductImage(ctx context.Context, productID string, file io.Reader, filename string) (string, error) {
-	// Validate file extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	allowedExts := []string{".jpg", ".jpeg", ".png", ".webp"}
-
-	isValid := false
-	for _, allowed := range allowedExts {
-		if ext == allowed {
-			isValid = true
-			break
-		}
-	}
-
-	if !isValid {
-		return "", fmt.Errorf("invalid file type: only jpg, jpeg, png, webp allowed")
-	}
-
+// UploadProductImage uploads a product image in format. The image pipeline
+// has checked the upload against the image policy and encoded the file, so it
+// is stored as it is, whatever the allowed upload formats are.
+func (s *fileServiceImpl) UploadProductImage(ctx context.Context, productID string, file io.Reader, format string) (string, error) {
 	// Generate unique filename
 	uniqueID := uuid.New().String()
-	newFilename := fmt.Sprintf("%s-%s%s", productID, uniqueID, ext)
+	newFilename := fmt.Sprintf("%s-%s%s", productID, uniqueID, imaging.Extension(format))
 	path := filepath.Join("products", productID, newFilename)
 
-	// Determine content type
-	contentType := "image/jpeg"
-	switch ext {
-	case ".png":
-		contentType = "image/png"
-	case ".webp":
-		contentType = "image/webp"
-	}
-
 	// Upload
-	uploadedPath, err := s.storage.Upload(ctx, file, path, contentType)
+	uploadedPath, err := s.storage.Upload(ctx, file, path, imaging.ContentType(format))
 	if err != nil {
 		return "", fmt.Errorf("failed to upload product image: %w", err)
 	}
 
 	return uploadedPath, nil
 }
+
+// checkImage checks an uploaded image against the image policy. The format
+// is detected from the content rather than the file name; the returned reader
+// yields the whole file.
+func (s *fileServiceImpl) checkImage(file io.Reader) (io.Reader, string, error) {
+	// http.DetectContentType looks at 512 bytes at most
+	content := bufio.NewReaderSize(file, 512)
+	head, err := content.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+	format, err := s.imagePolicy.CheckFormat(head)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid file type: %w", err)
+	}
+	return content, format, nil
+}
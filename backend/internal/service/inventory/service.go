@@ -0,0 +1,178 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	warehouseDomain "github.com/naxumi/bnsp-jwd/internal/domain/warehouse"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+)
+
+type InventoryServiceImpl struct {
+	transactor database.Transactor
+	repository inventoryDomain.StockMovementRepository
+}
+
+func NewInventoryService(transactor database.Transactor, repository inventoryDomain.StockMovementRepository) inventoryDomain.InventoryService {
+	return &InventoryServiceImpl{
+		transactor: transactor,
+		repository: repository,
+	}
+}
+
+// PostMovement implements inventoryDomain.InventoryService.
+func (s *InventoryServiceImpl) PostMovement(ctx context.Context, req inventoryDomain.CreateStockMovementRequest) (inventoryDomain.StockMovementResponse, error) {
+	movement := inventoryDomain.StockMovement{
+		ProductID:   req.ProductID,
+		WarehouseID: req.WarehouseID,
+		Type:        req.Type,
+		ReasonCode:  req.ReasonCode,
+		Quantity:    req.Delta(),
+		Reference:   req.Reference,
+		Note:        req.Note,
+		Actor:       requestctx.ActorOrSystem(ctx),
+	}
+
+	// The stock update and the ledger entry must succeed or fail together
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if movement.WarehouseID == nil {
+			warehouseID, err := s.repository.GetDefaultWarehouseID(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to resolve default warehouse: %w", err)
+			}
+			movement.WarehouseID = &warehouseID
+		}
+
+		balance, err := s.repository.ApplyStockDelta(ctx, movement.ProductID, *movement.WarehouseID, movement.Quantity)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return productDomain.ErrProductNotFound
+			}
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case "23514": // check violation
+					return inventoryDomain.ErrInsufficientStock
+				case "23503": // foreign key violation
+					return warehouseDomain.ErrWarehouseNotFound
+				}
+			}
+			return fmt.Errorf("failed to update product stock: %w", err)
+		}
+		movement.BalanceAfter = balance
+
+		movement, err = s.repository.Create(ctx, movement)
+		if err != nil {
+			return fmt.Errorf("failed to create stock movement: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return inventoryDomain.StockMovementResponse{}, err
+	}
+
+	return toStockMovementResponse(movement), nil
+}
+
+// CorrectStock implements inventoryDomain.InventoryService. An increase is
+// booked into the default warehouse. A reduction is taken from the default
+// warehouse first and then from the other warehouses, fullest first, with one
+// correction per warehouse, so it succeeds whenever the product's total stock
+// covers it.
+func (s *InventoryServiceImpl) CorrectStock(ctx context.Context, productID int64, target int) error {
+	if target < 0 {
+		return inventoryDomain.ErrInsufficientStock
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.repository.GetStockForUpdate(ctx, productID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return productDomain.ErrProductNotFound
+			}
+			return fmt.Errorf("failed to get product stock: %w", err)
+		}
+
+		if current == target {
+			return nil
+		}
+
+		correction := inventoryDomain.CreateStockMovementRequest{
+			ProductID:  productID,
+			Type:       inventoryDomain.MovementTypeCorrection,
+			ReasonCode: inventoryDomain.ReasonManualEdit,
+			Quantity:   target - current,
+		}
+		if target > current {
+			_, err = s.PostMovement(ctx, correction)
+			return err
+		}
+
+		levels, err := s.repository.ListWarehouseStock(ctx, productID)
+		if err != nil {
+			return fmt.Errorf("failed to get warehouse stock: %w", err)
+		}
+		remaining := current - target
+		for _, level := range levels {
+			if remaining == 0 {
+				break
+			}
+			taken := min(remaining, level.Quantity)
+			correction.WarehouseID = &level.WarehouseID
+			correction.Quantity = -taken
+			if _, err := s.PostMovement(ctx, correction); err != nil {
+				return err
+			}
+			remaining -= taken
+		}
+		if remaining > 0 {
+			return inventoryDomain.ErrInsufficientStock
+		}
+		return nil
+	})
+}
+
+// ListMovements implements inventoryDomain.InventoryService.
+func (s *InventoryServiceImpl) ListMovements(ctx context.Context, filter inventoryDomain.ListStockMovementFilter) (inventoryDomain.ListStockMovementResponse, error) {
+	movements, total, err := s.repository.ListByProduct(ctx, filter)
+	if err != nil {
+		return inventoryDomain.ListStockMovementResponse{}, fmt.Errorf("failed to list stock movements: %w", err)
+	}
+
+	movementResponses := []inventoryDomain.StockMovementResponse{}
+	for _, m := range movements {
+		movementResponses = append(movementResponses, toStockMovementResponse(m))
+	}
+
+	totalPages := (total + int64(filter.Limit) - 1) / int64(filter.Limit)
+
+	return inventoryDomain.ListStockMovementResponse{
+		TotalCount: total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: int(totalPages),
+		Movements:  movementResponses,
+	}, nil
+}
+
+func toStockMovementResponse(m inventoryDomain.StockMovement) inventoryDomain.StockMovementResponse {
+	return inventoryDomain.StockMovementResponse{
+		ID:           m.ID,
+		ProductID:    m.ProductID,
+		WarehouseID:  m.WarehouseID,
+		Type:         m.Type,
+		ReasonCode:   m.ReasonCode,
+		Quantity:     m.Quantity,
+		BalanceAfter: m.BalanceAfter,
+		Reference:    m.Reference,
+		Note:         m.Note,
+		Actor:        m.Actor,
+		CreatedAt:    m.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,332 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	warehouseDomain "github.com/naxumi/bnsp-jwd/internal/domain/warehouse"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Transactor runs the function directly, without a database
+type MockTransactor struct{}
+
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// Mock Repository
+type MockStockMovementRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockMovementRepository) GetDefaultWarehouseID(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStockMovementRepository) GetStockForUpdate(ctx context.Context, productID int64) (int, error) {
+	args := m.Called(ctx, productID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStockMovementRepository) ListWarehouseStock(ctx context.Context, productID int64) ([]inventoryDomain.WarehouseQuantity, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).([]inventoryDomain.WarehouseQuantity), args.Error(1)
+}
+
+func (m *MockStockMovementRepository) ApplyStockDelta(ctx context.Context, productID int64, warehouseID int64, delta int) (int, error) {
+	args := m.Called(ctx, productID, warehouseID, delta)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStockMovementRepository) Create(ctx context.Context, movement inventoryDomain.StockMovement) (inventoryDomain.StockMovement, error) {
+	args := m.Called(ctx, movement)
+	return args.Get(0).(inventoryDomain.StockMovement), args.Error(1)
+}
+
+func (m *MockStockMovementRepository) ListByProduct(ctx context.Context, filter inventoryDomain.ListStockMovementFilter) ([]inventoryDomain.StockMovement, int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]inventoryDomain.StockMovement), args.Get(1).(int64), args.Error(2)
+}
+
+// Tests for PostMovement
+func TestInventoryService_PostMovement_Issue(t *testing.T) {
+	mockRepo := new(MockStockMovementRepository)
+	service := &InventoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	reference := "SO-1001"
+	req := inventoryDomain.CreateStockMovementRequest{
+		ProductID:  1,
+		Type:       inventoryDomain.MovementTypeIssue,
+		ReasonCode: "SALE",
+		Quantity:   5,
+		Reference:  &reference,
+	}
+
+	// Issues are stored as a negative delta, booked in the default warehouse
+	mockRepo.On("GetDefaultWarehouseID", mock.Anything).
+		Return(int64(1), nil)
+	mockRepo.On("ApplyStockDelta", mock.Anything, int64(1), int64(1), -5).
+		Return(95, nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(m inventoryDomain.StockMovement) bool {
+		return m.ProductID == 1 && *m.WarehouseID == 1 && m.Quantity == -5 && m.BalanceAfter == 95 && m.Actor == "warehouse-1"
+	})).Return(inventoryDomain.StockMovement{
+		ID:           10,
+		ProductID:    1,
+		Type:         inventoryDomain.MovementTypeIssue,
+		ReasonCode:   "SALE",
+		Quantity:     -5,
+		BalanceAfter: 95,
+		Reference:    &reference,
+		Actor:        "warehouse-1",
+		CreatedAt:    time.Now(),
+	}, nil)
+
+	// The actor is the caller of the request, not a field of its body
+	result, err := service.PostMovement(requestctx.WithActor(context.Background(), "warehouse-1"), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), result.ID)
+	assert.Equal(t, -5, result.Quantity)
+	assert.Equal(t, 95, result.BalanceAfter)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInventoryService_PostMovement_InsufficientStock(t *testing.T) {
+	mockRepo := new(MockStockMovementRepository)
+	service := &InventoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	req := inventoryDomain.CreateStockMovementRequest{
+		ProductID:  1,
+		Type:       inventoryDomain.MovementTypeIssue,
+		ReasonCode: "SALE",
+		Quantity:   500,
+	}
+
+	pgErr := &pgconn.PgError{Code: "23514"}
+	mockRepo.On("GetDefaultWarehouseID", mock.Anything).
+		Return(int64(1), nil)
+	mockRepo.On("ApplyStockDelta", mock.Anything, int64(1), int64(1), -500).
+		Return(0, pgErr)
+
+	_, err := service.PostMovement(context.Background(), req)
+
+	assert.Equal(t, inventoryDomain.ErrInsufficientStock, err)
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestInventoryService_PostMovement_ProductNotFound(t *testing.T) {
+	mockRepo := new(MockStockMovementRepository)
+	service := &InventoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	req := inventoryDomain.CreateStockMovementRequest{
+		ProductID:  999,
+		Type:       inventoryDomain.MovementTypeReceipt,
+		ReasonCode: "PURCHASE",
+		Quantity:   10,
+	}
+
+	mockRepo.On("GetDefaultWarehouseID", mock.Anything).
+		Return(int64(1), nil)
+	mockRepo.On("ApplyStockDelta", mock.Anything, int64(999), int64(1), 10).
+		Return(0, pgx.ErrNoRows)
+
+	_, err := service.PostMovement(context.Background(), req)
+
+	assert.Equal(t, productDomain.ErrProductNotFound, err)
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestInventoryService_PostMovement_UnknownWarehouse(t *testing.T) {
+	mockRepo := new(MockStockMovementRepository)
+	service := &InventoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	warehouseID := int64(42)
+	req := inventoryDomain.CreateStockMovementRequest{
+		ProductID:   1,
+		WarehouseID: &warehouseID,
+		Type:        inventoryDomain.MovementTypeReceipt,
+		ReasonCode:  "PURCHASE",
+		Quantity:    10,
+	}
+
+	pgErr := &pgconn.PgError{Code: "23503"}
+	mockRepo.On("ApplyStockDelta", mock.Anything, int64(1), int64(42), 10).
+		Return(0, pgErr)
+
+	_, err := service.PostMovement(context.Background(), req)
+
+	assert.Equal(t, warehouseDomain.ErrWarehouseNotFound, err)
+	mockRepo.AssertNotCalled(t, "GetDefaultWarehouseID")
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+// Tests for CorrectStock
+func TestInventoryService_CorrectStock_PostsDifference(t *testing.T) {
+	mockRepo := new(MockStockMovementRepository)
+	service := &InventoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	mockRepo.On("GetStockForUpdate", mock.Anything, int64(1)).
+		Return(100, nil)
+	mockRepo.On("ListWarehouseStock", mock.Anything, int64(1)).
+		Return([]inventoryDomain.WarehouseQuantity{{WarehouseID: 1, Quantity: 100}}, nil)
+	mockRepo.On("ApplyStockDelta", mock.Anything, int64(1), int64(1), -30).
+		Return(70, nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(m inventoryDomain.StockMovement) bool {
+		return m.Type == inventoryDomain.MovementTypeCorrection &&
+			m.ReasonCode == inventoryDomain.ReasonManualEdit &&
+			m.Quantity == -30 &&
+			m.BalanceAfter == 70
+	})).Return(inventoryDomain.StockMovement{ID: 1}, nil)
+
+	err := service.CorrectStock(context.Background(), 1, 70)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInventoryService_CorrectStock_IncreaseGoesToDefaultWarehouse(t *testing.T) {
+	mockRepo := new(MockStockMovementRepository)
+	service := &InventoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	mockRepo.On("GetStockForUpdate", mock.Anything, int64(1)).
+		Return(100, nil)
+	mockRepo.On("GetDefaultWarehouseID", mock.Anything).
+		Return(int64(1), nil)
+	mockRepo.On("ApplyStockDelta", mock.Anything, int64(1), int64(1), 20).
+		Return(120, nil)
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(inventoryDomain.StockMovement{ID: 1}, nil)
+
+	err := service.CorrectStock(context.Background(), 1, 120)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "ListWarehouseStock", mock.Anything, mock.Anything)
+}
+
+// A reduction larger than the default warehouse holds takes the rest from the
+// other warehouses
+func TestInventoryService_CorrectStock_SpreadsReduction(t *testing.T) {
+	mockRepo := new(MockStockMovementRepository)
+	service := &InventoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	mockRepo.On("GetStockForUpdate", mock.Anything, int64(1)).
+		Return(100, nil)
+	mockRepo.On("ListWarehouseStock", mock.Anything, int64(1)).
+		Return([]inventoryDomain.WarehouseQuantity{
+			{WarehouseID: 1, Quantity: 10},
+			{WarehouseID: 3, Quantity: 60},
+			{WarehouseID: 2, Quantity: 30},
+		}, nil)
+	mockRepo.On("ApplyStockDelta", mock.Anything, int64(1), int64(1), -10).
+		Return(90, nil).Once()
+	mockRepo.On("ApplyStockDelta", mock.Anything, int64(1), int64(3), -40).
+		Return(50, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(m inventoryDomain.StockMovement) bool {
+		return m.Type == inventoryDomain.MovementTypeCorrection && m.Actor == "alice"
+	})).Return(inventoryDomain.StockMovement{ID: 1}, nil).Twice()
+
+	err := service.CorrectStock(requestctx.WithActor(context.Background(), "alice"), 1, 50)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "ApplyStockDelta", mock.Anything, int64(1), int64(2), mock.Anything)
+}
+
+func TestInventoryService_CorrectStock_Unchanged(t *testing.T) {
+	mockRepo := new(MockStockMovementRepository)
+	service := &InventoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	mockRepo.On("GetStockForUpdate", mock.Anything, int64(1)).
+		Return(100, nil)
+
+	err := service.CorrectStock(context.Background(), 1, 100)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "ApplyStockDelta")
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+// Tests for ListMovements
+func TestInventoryService_ListMovements_Success(t *testing.T) {
+	mockRepo := new(MockStockMovementRepository)
+	service := &InventoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	filter := inventoryDomain.ListStockMovementFilter{
+		ProductID: 1,
+		Page:      1,
+		Limit:     2,
+	}
+
+	now := time.Now()
+	movements := []inventoryDomain.StockMovement{
+		{ID: 3, ProductID: 1, Type: inventoryDomain.MovementTypeIssue, Quantity: -2, BalanceAfter: 8, CreatedAt: now},
+		{ID: 2, ProductID: 1, Type: inventoryDomain.MovementTypeReceipt, Quantity: 10, BalanceAfter: 10, CreatedAt: now},
+	}
+
+	mockRepo.On("ListByProduct", mock.Anything, filter).
+		Return(movements, int64(3), nil)
+
+	result, err := service.ListMovements(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), result.TotalCount)
+	assert.Equal(t, 2, result.TotalPages)
+	assert.Len(t, result.Movements, 2)
+	assert.Equal(t, int64(3), result.Movements[0].ID)
+}
+
+func TestInventoryService_ListMovements_RepositoryError(t *testing.T) {
+	mockRepo := new(MockStockMovementRepository)
+	service := &InventoryServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	filter := inventoryDomain.ListStockMovementFilter{ProductID: 1, Page: 1, Limit: 20}
+
+	mockRepo.On("ListByProduct", mock.Anything, filter).
+		Return([]inventoryDomain.StockMovement{}, int64(0), errors.New("database error"))
+
+	_, err := service.ListMovements(context.Background(), filter)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list stock movements")
+}
@@ -0,0 +1,71 @@
+package product
+
+import (
+	"context"
+
+	auditDomain "github.com/naxumi/bnsp-jwd/internal/domain/audit"
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+)
+
+// productAuditFields returns the audited fields of a product, keyed by their
+// JSON name. Values are normalized so equal fields compare equal: the price
+// as a fixed two-decimal string and an empty image URL as nil.
+func productAuditFields(p *productDomain.Product) map[string]any {
+	if p == nil {
+		return nil
+	}
+
+	var description, imageURL any
+	if p.Description != nil {
+		description = *p.Description
+	}
+	if p.ImageURL != nil && *p.ImageURL != "" {
+		imageURL = *p.ImageURL
+	}
+
+	return map[string]any{
+		"sku":         p.SKU,
+		"name":        p.Name,
+		"description": description,
+		"price":       p.Price.StringFixed(2),
+		"stock":       p.Stock,
+		"category_id": p.CategoryID,
+		"status":      string(p.Status),
+		"image_url":   imageURL,
+	}
+}
+
+// recordAudit writes the audit record of a product mutation, with the diff of
+// the two snapshots; nil before means created, nil after means deleted. An
+// update that changed no audited field is not recorded.
+func (s *ProductServiceImpl) recordAudit(ctx context.Context, id int64, action auditDomain.Action, before, after *productDomain.Product) error {
+	changes := auditDomain.Diff(productAuditFields(before), productAuditFields(after))
+	if len(changes) == 0 && action == auditDomain.ActionUpdate {
+		return nil
+	}
+
+	return s.auditService.Record(ctx, auditDomain.Entry{
+		EntityType: auditDomain.EntityProduct,
+		EntityID:   id,
+		Action:     action,
+		Changes:    changes,
+	})
+}
+
+// recordImageAudit writes the audit record of a gallery image added or
+// deleted without touching the product row, naming the image in the changes
+// since no product field differs
+func (s *ProductServiceImpl) recordImageAudit(ctx context.Context, image productDomain.ProductImage, action auditDomain.Action) error {
+	value := map[string]any{"id": image.ID, "url": image.URL}
+	change := auditDomain.Change{After: value}
+	if action == auditDomain.ActionImageDelete {
+		change = auditDomain.Change{Before: value}
+	}
+
+	return s.auditService.Record(ctx, auditDomain.Entry{
+		EntityType: auditDomain.EntityProduct,
+		EntityID:   image.ProductID,
+		Action:     action,
+		Changes:    map[string]auditDomain.Change{"image": change},
+	})
+}
@@ -0,0 +1,256 @@
+package product
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/shopspring/decimal"
+)
+
+// AuthorizedProductService checks the caller's permissions, from the token
+// claims in the context, before handing a call to the wrapped service. The
+// public catalog reads are passed through unchecked.
+type AuthorizedProductService struct {
+	next productDomain.ProductService
+}
+
+func NewAuthorizedProductService(next productDomain.ProductService) productDomain.ProductService {
+	return &AuthorizedProductService{
+		next: next,
+	}
+}
+
+func (s *AuthorizedProductService) CreateProduct(ctx context.Context, req productDomain.CreateProductRequest) (productDomain.ProductResponse, error) {
+	// A new product always comes with a price
+	if err := auth.Require(ctx, auth.PermProductWrite, auth.PermProductPrice); err != nil {
+		return productDomain.ProductResponse{}, err
+	}
+	return s.next.CreateProduct(ctx, req)
+}
+
+func (s *AuthorizedProductService) GetProduct(ctx context.Context, id int64) (productDomain.ProductResponse, error) {
+	return s.next.GetProduct(ctx, id)
+}
+
+func (s *AuthorizedProductService) GetProductBySKU(ctx context.Context, sku string) (productDomain.ProductResponse, error) {
+	return s.next.GetProductBySKU(ctx, sku)
+}
+
+func (s *AuthorizedProductService) UpdateProduct(ctx context.Context, req productDomain.UpdateProductRequest) error {
+	if err := s.requireUpdate(ctx, req); err != nil {
+		return err
+	}
+	return s.next.UpdateProduct(withPriceCheck(ctx), req)
+}
+
+func (s *AuthorizedProductService) DeleteProduct(ctx context.Context, id int64, expectedVersion *int) error {
+	if err := auth.Require(ctx, auth.PermProductDelete); err != nil {
+		return err
+	}
+	return s.next.DeleteProduct(ctx, id, expectedVersion)
+}
+
+func (s *AuthorizedProductService) ListTrash(ctx context.Context, filter productDomain.ListProductFilter) (productDomain.ListProductResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductRead); err != nil {
+		return productDomain.ListProductResponse{}, err
+	}
+	return s.next.ListTrash(ctx, filter)
+}
+
+func (s *AuthorizedProductService) RestoreProduct(ctx context.Context, id int64) (productDomain.ProductResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductDelete); err != nil {
+		return productDomain.ProductResponse{}, err
+	}
+	return s.next.RestoreProduct(ctx, id)
+}
+
+func (s *AuthorizedProductService) PurgeTrash(ctx context.Context) (productDomain.PurgeTrashResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductDelete); err != nil {
+		return productDomain.PurgeTrashResponse{}, err
+	}
+	return s.next.PurgeTrash(ctx)
+}
+
+func (s *AuthorizedProductService) ListRevisions(ctx context.Context, filter productDomain.ListProductRevisionFilter) (productDomain.ListProductRevisionResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductRead); err != nil {
+		return productDomain.ListProductRevisionResponse{}, err
+	}
+	return s.next.ListRevisions(ctx, filter)
+}
+
+func (s *AuthorizedProductService) GetRevision(ctx context.Context, id int64, revision int) (productDomain.ProductRevisionResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductRead); err != nil {
+		return productDomain.ProductRevisionResponse{}, err
+	}
+	return s.next.GetRevision(ctx, id, revision)
+}
+
+func (s *AuthorizedProductService) RestoreRevision(ctx context.Context, id int64, revision int, expectedVersion *int) (productDomain.ProductResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductWrite); err != nil {
+		return productDomain.ProductResponse{}, err
+	}
+
+	// Restoring is an update, it needs the price permission when the
+	// revision's price differs from the current one
+	return s.next.RestoreRevision(withPriceCheck(ctx), id, revision, expectedVersion)
+}
+
+func (s *AuthorizedProductService) BulkProducts(ctx context.Context, req productDomain.BulkProductRequest) (productDomain.BulkProductResponse, error) {
+	// The whole batch is refused if any operation is not allowed. Price
+	// changes are looked up here to refuse the batch before it starts, and
+	// checked again by each update against the locked row.
+	for _, op := range req.Operations {
+		var err error
+		switch {
+		case op.Create != nil:
+			err = auth.Require(ctx, auth.PermProductWrite, auth.PermProductPrice)
+		case op.Update != nil:
+			err = s.requireUpdate(ctx, *op.Update)
+			if err == nil {
+				err = s.requirePriceChange(ctx, op.Update.ID, op.Update.Price)
+			}
+		case op.Delete != nil:
+			err = auth.Require(ctx, auth.PermProductDelete)
+		}
+		if err != nil {
+			return productDomain.BulkProductResponse{}, err
+		}
+	}
+	return s.next.BulkProducts(withPriceCheck(ctx), req)
+}
+
+func (s *AuthorizedProductService) ImportProducts(ctx context.Context, req productDomain.ImportProductsRequest) (productDomain.ImportJobResponse, error) {
+	// Every imported row sets a price
+	if err := auth.Require(ctx, auth.PermProductWrite, auth.PermProductPrice); err != nil {
+		return productDomain.ImportJobResponse{}, err
+	}
+	return s.next.ImportProducts(ctx, req)
+}
+
+func (s *AuthorizedProductService) GetImportJob(ctx context.Context, id string) (productDomain.ImportJobResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductRead); err != nil {
+		return productDomain.ImportJobResponse{}, err
+	}
+	return s.next.GetImportJob(ctx, id)
+}
+
+func (s *AuthorizedProductService) ListProducts(ctx context.Context, filter productDomain.ListProductFilter) (productDomain.ListProductResponse, error) {
+	return s.next.ListProducts(ctx, filter)
+}
+
+func (s *AuthorizedProductService) ExportProducts(ctx context.Context, filter productDomain.ExportProductFilter, w io.Writer) error {
+	return s.next.ExportProducts(ctx, filter, w)
+}
+
+func (s *AuthorizedProductService) SuggestProducts(ctx context.Context, filter productDomain.SuggestProductFilter) ([]productDomain.ProductSuggestionResponse, error) {
+	return s.next.SuggestProducts(ctx, filter)
+}
+
+func (s *AuthorizedProductService) UploadImage(ctx context.Context, id int64, file multipart.File, fileHeader *multipart.FileHeader) error {
+	if err := auth.Require(ctx, auth.PermProductImage); err != nil {
+		return err
+	}
+	return s.next.UploadImage(ctx, id, file, fileHeader)
+}
+
+func (s *AuthorizedProductService) DeleteImage(ctx context.Context, id int64) error {
+	if err := auth.Require(ctx, auth.PermProductImage); err != nil {
+		return err
+	}
+	return s.next.DeleteImage(ctx, id)
+}
+
+func (s *AuthorizedProductService) AddGalleryImage(ctx context.Context, req productDomain.AddProductImageRequest) (productDomain.ProductImageResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductImage); err != nil {
+		return productDomain.ProductImageResponse{}, err
+	}
+	return s.next.AddGalleryImage(ctx, req)
+}
+
+func (s *AuthorizedProductService) UpdateGalleryImage(ctx context.Context, req productDomain.UpdateProductImageRequest) (productDomain.ProductImageResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductImage); err != nil {
+		return productDomain.ProductImageResponse{}, err
+	}
+	return s.next.UpdateGalleryImage(ctx, req)
+}
+
+func (s *AuthorizedProductService) SetPrimaryImage(ctx context.Context, id, imageID int64) (productDomain.ProductResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductImage); err != nil {
+		return productDomain.ProductResponse{}, err
+	}
+	return s.next.SetPrimaryImage(ctx, id, imageID)
+}
+
+func (s *AuthorizedProductService) ReorderGallery(ctx context.Context, req productDomain.ReorderProductImagesRequest) ([]productDomain.ProductImageResponse, error) {
+	if err := auth.Require(ctx, auth.PermProductImage); err != nil {
+		return nil, err
+	}
+	return s.next.ReorderGallery(ctx, req)
+}
+
+func (s *AuthorizedProductService) DeleteGalleryImage(ctx context.Context, id, imageID int64) error {
+	if err := auth.Require(ctx, auth.PermProductImage); err != nil {
+		return err
+	}
+	return s.next.DeleteGalleryImage(ctx, id, imageID)
+}
+
+// requireUpdate checks an update request: product:write for any change and
+// product:image for the image URL. Whether the price changes is checked by
+// the update itself, see withPriceCheck.
+func (s *AuthorizedProductService) requireUpdate(ctx context.Context, req productDomain.UpdateProductRequest) error {
+	if err := auth.Require(ctx, auth.PermProductWrite); err != nil {
+		return err
+	}
+	if req.ImageURL != nil {
+		if err := auth.Require(ctx, auth.PermProductImage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// priceCheckKey marks a context whose caller may not change prices
+type priceCheckKey struct{}
+
+// withPriceCheck marks ctx when its caller lacks product:price. Updates made
+// with it then refuse a price that differs from the current one; they compare
+// against the row they have locked, so a concurrent price change cannot slip
+// between the check and the write. Sending the unchanged price, as an edit
+// form does, needs no price permission.
+func withPriceCheck(ctx context.Context) context.Context {
+	if auth.Require(ctx, auth.PermProductPrice) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, priceCheckKey{}, true)
+}
+
+// requirePriceUnchanged returns the permission error for an update made with
+// withPriceCheck that changes current's price
+func requirePriceUnchanged(ctx context.Context, current decimal.Decimal, price *decimal.Decimal) error {
+	if checked, _ := ctx.Value(priceCheckKey{}).(bool); !checked || price == nil || current.Equal(*price) {
+		return nil
+	}
+	return &auth.PermissionError{Permission: auth.PermProductPrice}
+}
+
+// requirePriceChange demands product:price when price is set and differs
+// from the product's current price, as read outside any transaction. It only
+// refuses a bulk request early, requirePriceUnchanged is what holds.
+func (s *AuthorizedProductService) requirePriceChange(ctx context.Context, id int64, price *decimal.Decimal) error {
+	if price == nil || auth.Require(ctx, auth.PermProductPrice) == nil {
+		return nil
+	}
+
+	current, err := s.next.GetProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !current.Price.Equal(*price) {
+		return &auth.PermissionError{Permission: auth.PermProductPrice}
+	}
+	return nil
+}
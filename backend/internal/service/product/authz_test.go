@@ -0,0 +1,146 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Product Service, only the methods the tests reach are implemented
+type MockProductService struct {
+	mock.Mock
+	productDomain.ProductService
+}
+
+func (m *MockProductService) GetProduct(ctx context.Context, id int64) (productDomain.ProductResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(productDomain.ProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) UpdateProduct(ctx context.Context, req productDomain.UpdateProductRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockProductService) DeleteProduct(ctx context.Context, id int64, expectedVersion *int) error {
+	args := m.Called(ctx, id, expectedVersion)
+	return args.Error(0)
+}
+
+func (m *MockProductService) BulkProducts(ctx context.Context, req productDomain.BulkProductRequest) (productDomain.BulkProductResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(productDomain.BulkProductResponse), args.Error(1)
+}
+
+func contextWithRole(role string) context.Context {
+	return auth.NewContext(context.Background(), auth.Claims{
+		Subject: "alice",
+		All:     map[string]any{"role": role},
+	})
+}
+
+// priceChecked reports whether a context reaching the product service has
+// the caller's price changes checked
+func priceChecked(ctx context.Context) bool {
+	checked, _ := ctx.Value(priceCheckKey{}).(bool)
+	return checked
+}
+
+func TestAuthorizedProductService_UpdateProduct_ChecksPriceForStaff(t *testing.T) {
+	next := new(MockProductService)
+	service := NewAuthorizedProductService(next)
+
+	// The price is compared by the update itself, against the locked row
+	next.On("UpdateProduct", mock.MatchedBy(priceChecked), mock.Anything).Return(nil)
+
+	price := decimal.NewFromInt(15000)
+	err := service.UpdateProduct(contextWithRole(auth.RoleStaff), productDomain.UpdateProductRequest{ID: 1, Price: &price})
+
+	assert.NoError(t, err)
+	next.AssertExpectations(t)
+	next.AssertNotCalled(t, "GetProduct")
+}
+
+func TestAuthorizedProductService_UpdateProduct_PriceChangeAllowedForManager(t *testing.T) {
+	next := new(MockProductService)
+	service := NewAuthorizedProductService(next)
+
+	next.On("UpdateProduct", mock.MatchedBy(func(ctx context.Context) bool { return !priceChecked(ctx) }), mock.Anything).Return(nil)
+
+	price := decimal.NewFromInt(15000)
+	err := service.UpdateProduct(contextWithRole(auth.RoleManager), productDomain.UpdateProductRequest{ID: 1, Price: &price})
+
+	assert.NoError(t, err)
+	next.AssertExpectations(t)
+	next.AssertNotCalled(t, "GetProduct")
+}
+
+func TestAuthorizedProductService_ViewerCannotWrite(t *testing.T) {
+	next := new(MockProductService)
+	service := NewAuthorizedProductService(next)
+	ctx := contextWithRole(auth.RoleViewer)
+
+	name := "Renamed"
+	err := service.UpdateProduct(ctx, productDomain.UpdateProductRequest{ID: 1, Name: &name})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	err = service.DeleteProduct(ctx, 1, nil)
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	next.AssertNotCalled(t, "UpdateProduct")
+	next.AssertNotCalled(t, "DeleteProduct")
+}
+
+func TestAuthorizedProductService_Unauthenticated(t *testing.T) {
+	next := new(MockProductService)
+	service := NewAuthorizedProductService(next)
+
+	err := service.DeleteProduct(context.Background(), 1, nil)
+
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+}
+
+func TestAuthorizedProductService_BulkProducts_RefusesWholeBatch(t *testing.T) {
+	next := new(MockProductService)
+	service := NewAuthorizedProductService(next)
+
+	name := "Renamed"
+	req := productDomain.BulkProductRequest{
+		Operations: []productDomain.BulkOperation{
+			{Update: &productDomain.UpdateProductRequest{ID: 1, Name: &name}},
+			{Delete: &productDomain.BulkDeleteRequest{ID: 2}},
+		},
+	}
+
+	_, err := service.BulkProducts(contextWithRole(auth.RoleStaff), req)
+
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+	next.AssertNotCalled(t, "BulkProducts")
+}
+
+func TestAuthorizedProductService_BulkProducts_RefusesPriceChange(t *testing.T) {
+	next := new(MockProductService)
+	service := NewAuthorizedProductService(next)
+
+	next.On("GetProduct", mock.Anything, int64(1)).
+		Return(productDomain.ProductResponse{ID: 1, Price: decimal.NewFromInt(10000)}, nil)
+
+	price := decimal.NewFromInt(15000)
+	req := productDomain.BulkProductRequest{
+		Operations: []productDomain.BulkOperation{
+			{Update: &productDomain.UpdateProductRequest{ID: 1, Price: &price}},
+		},
+	}
+
+	_, err := service.BulkProducts(contextWithRole(auth.RoleStaff), req)
+
+	var permissionErr *auth.PermissionError
+	assert.ErrorAs(t, err, &permissionErr)
+	assert.Equal(t, auth.PermProductPrice, permissionErr.Permission)
+	next.AssertNotCalled(t, "BulkProducts")
+}
@@ -0,0 +1,226 @@
+package product
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/xuri/excelize/v2"
+)
+
+// productExporter writes products to an export file one at a time
+type productExporter interface {
+	WriteHeader() error
+	Write(p productDomain.ProductResponse) error
+	// Flush writes out anything still buffered; the file is incomplete before
+	Flush() error
+	// Close releases the exporter's resources, whether or not it was flushed
+	Close() error
+}
+
+// ExportProducts implements productDomain.ProductService. Products are
+// written as the repository streams them, nothing is held in memory besides
+// the exporter's buffer.
+func (s *ProductServiceImpl) ExportProducts(ctx context.Context, filter productDomain.ExportProductFilter, w io.Writer) error {
+	var exporter productExporter
+	switch filter.Format {
+	case productDomain.ExportFormatXLSX:
+		exporter = newXLSXExporter(w)
+	case productDomain.ExportFormatJSONL:
+		exporter = newJSONLExporter(w)
+	default:
+		exporter = newCSVExporter(w, filter.Delimiter, filter.DecimalSeparator)
+	}
+	defer exporter.Close()
+
+	if err := exporter.WriteHeader(); err != nil {
+		return fmt.Errorf("failed to write export header: %w", err)
+	}
+
+	err := s.repository.Export(ctx, filter.ListProductFilter, func(p productDomain.Product) error {
+		return exporter.Write(toProductResponse(p))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export products: %w", err)
+	}
+
+	if err := exporter.Flush(); err != nil {
+		return fmt.Errorf("failed to finish export: %w", err)
+	}
+	return nil
+}
+
+// exportRecord returns a product's values in ExportColumns order, with the
+// price written using decimalSeparator
+func exportRecord(p productDomain.ProductResponse, decimalSeparator string) []string {
+	description := ""
+	if p.Description != nil {
+		description = *p.Description
+	}
+	imageURL := ""
+	if p.ImageURL != nil {
+		imageURL = *p.ImageURL
+	}
+
+	return []string{
+		strconv.FormatInt(p.ID, 10),
+		p.SKU,
+		p.Name,
+		description,
+		strings.Replace(p.Price.StringFixed(2), ".", decimalSeparator, 1),
+		strconv.Itoa(p.Stock),
+		strconv.FormatInt(p.CategoryID, 10),
+		p.Category,
+		string(p.Status),
+		imageURL,
+		strconv.Itoa(p.Version),
+		p.CreatedAt,
+		p.UpdatedAt,
+	}
+}
+
+type csvExporter struct {
+	out              io.Writer
+	writer           *csv.Writer
+	decimalSeparator string
+}
+
+func newCSVExporter(w io.Writer, delimiter, decimalSeparator string) *csvExporter {
+	writer := csv.NewWriter(w)
+	writer.Comma = []rune(delimiter)[0]
+	return &csvExporter{out: w, writer: writer, decimalSeparator: decimalSeparator}
+}
+
+func (e *csvExporter) WriteHeader() error {
+	// Excel only reads a CSV file as UTF-8 when it starts with a byte order mark
+	if _, err := io.WriteString(e.out, "\ufeff"); err != nil {
+		return err
+	}
+	return e.writer.Write(productDomain.ExportColumns)
+}
+
+func (e *csvExporter) Write(p productDomain.ProductResponse) error {
+	return e.writer.Write(exportRecord(p, e.decimalSeparator))
+}
+
+func (e *csvExporter) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvExporter) Close() error {
+	return nil
+}
+
+type jsonlExporter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newJSONLExporter(w io.Writer) *jsonlExporter {
+	buffer := bufio.NewWriter(w)
+	return &jsonlExporter{buffer: buffer, encoder: json.NewEncoder(buffer)}
+}
+
+// WriteHeader writes nothing, every line is a complete product
+func (e *jsonlExporter) WriteHeader() error {
+	return nil
+}
+
+func (e *jsonlExporter) Write(p productDomain.ProductResponse) error {
+	return e.encoder.Encode(p)
+}
+
+func (e *jsonlExporter) Flush() error {
+	return e.buffer.Flush()
+}
+
+func (e *jsonlExporter) Close() error {
+	return nil
+}
+
+// xlsxExporter writes a single "Products" sheet. The stream writer moves rows
+// to a temporary file once they outgrow its memory buffer; the workbook is
+// only written to the output on Flush, as an XLSX file is a zip archive.
+type xlsxExporter struct {
+	out        io.Writer
+	file       *excelize.File
+	stream     *excelize.StreamWriter
+	priceStyle int
+	row        int
+}
+
+const xlsxSheet = "Products"
+
+func newXLSXExporter(w io.Writer) *xlsxExporter {
+	return &xlsxExporter{out: w, file: excelize.NewFile()}
+}
+
+func (e *xlsxExporter) WriteHeader() error {
+	if err := e.file.SetSheetName("Sheet1", xlsxSheet); err != nil {
+		return err
+	}
+
+	// Built-in number format 4 is "#,##0.00", shown with the reader's locale
+	priceStyle, err := e.file.NewStyle(&excelize.Style{NumFmt: 4})
+	if err != nil {
+		return err
+	}
+	e.priceStyle = priceStyle
+
+	e.stream, err = e.file.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(productDomain.ExportColumns))
+	for i, column := range productDomain.ExportColumns {
+		header[i] = column
+	}
+	return e.nextRow(header)
+}
+
+func (e *xlsxExporter) Write(p productDomain.ProductResponse) error {
+	record := exportRecord(p, ".")
+
+	// Numbers are written as numbers so they can be summed and sorted
+	values := make([]interface{}, len(record))
+	for i, value := range record {
+		values[i] = value
+	}
+	values[0] = p.ID
+	values[4] = excelize.Cell{StyleID: e.priceStyle, Value: p.Price.InexactFloat64()}
+	values[5] = p.Stock
+	values[6] = p.CategoryID
+	values[10] = p.Version
+
+	return e.nextRow(values)
+}
+
+func (e *xlsxExporter) nextRow(values []interface{}) error {
+	e.row++
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	return e.stream.SetRow(cell, values)
+}
+
+func (e *xlsxExporter) Flush() error {
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := e.file.WriteTo(e.out)
+	return err
+}
+
+// Close removes the temporary files of the stream writer
+func (e *xlsxExporter) Close() error {
+	return e.file.Close()
+}
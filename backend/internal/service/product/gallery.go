@@ -0,0 +1,303 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	auditDomain "github.com/naxumi/bnsp-jwd/internal/domain/audit"
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
+)
+
+// AddGalleryImage stores an uploaded image with its resized copies at the end
+// of the product's gallery. The first image becomes the primary image.
+func (s *ProductServiceImpl) AddGalleryImage(ctx context.Context, req productDomain.AddProductImageRequest) (productDomain.ProductImageResponse, error) {
+	upload, err := s.readImageFile(req.File, req.FileHeader)
+	if err != nil {
+		return productDomain.ProductImageResponse{}, err
+	}
+
+	existingProduct, err := s.repository.GetByID(ctx, req.ProductID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return productDomain.ProductImageResponse{}, productDomain.ErrProductNotFound
+		}
+		return productDomain.ProductImageResponse{}, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	imageURL, variants, err := s.storeImageFiles(ctx, req.ProductID, upload)
+	if err != nil {
+		return productDomain.ProductImageResponse{}, err
+	}
+
+	var image productDomain.ProductImage
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		image, err = s.imageRepository.Create(ctx, productDomain.ProductImage{
+			ProductID: req.ProductID,
+			URL:       imageURL,
+			Variants:  variants,
+			AltText:   nonEmpty(req.AltText),
+			IsPrimary: len(existingProduct.Images) == 0,
+		})
+		if err != nil {
+			return err
+		}
+		if !image.IsPrimary {
+			return s.recordImageAudit(ctx, image, auditDomain.ActionImageUpload)
+		}
+		return s.recordPrimaryImageChange(ctx, auditDomain.ActionImageUpload, existingProduct, &image)
+	})
+	if err != nil {
+		s.deleteImageFiles(ctx, productDomain.ImageFileURLs(&imageURL, variants))
+		return productDomain.ProductImageResponse{}, err
+	}
+
+	// A product that had an image_url without a gallery loses the old file
+	if image.IsPrimary {
+		s.deleteImageFiles(ctx, productDomain.ImageFileURLs(existingProduct.ImageURL, existingProduct.ImageVariants))
+	}
+
+	return toProductImageResponse(image), nil
+}
+
+// UpdateGalleryImage changes the alt text of a gallery image
+func (s *ProductServiceImpl) UpdateGalleryImage(ctx context.Context, req productDomain.UpdateProductImageRequest) (productDomain.ProductImageResponse, error) {
+	if err := s.imageRepository.UpdateAltText(ctx, req.ProductID, req.ImageID, nonEmpty(req.AltText)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return productDomain.ProductImageResponse{}, productDomain.ErrImageNotFound
+		}
+		return productDomain.ProductImageResponse{}, err
+	}
+
+	image, err := s.imageRepository.Get(ctx, req.ProductID, req.ImageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return productDomain.ProductImageResponse{}, productDomain.ErrImageNotFound
+		}
+		return productDomain.ProductImageResponse{}, fmt.Errorf("failed to get product image: %w", err)
+	}
+
+	return toProductImageResponse(image), nil
+}
+
+// SetPrimaryImage makes a gallery image the primary one, which image_url
+// then points at
+func (s *ProductServiceImpl) SetPrimaryImage(ctx context.Context, id, imageID int64) (productDomain.ProductResponse, error) {
+	existingProduct, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return productDomain.ProductResponse{}, productDomain.ErrProductNotFound
+		}
+		return productDomain.ProductResponse{}, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	image := findImage(existingProduct.Images, imageID)
+	if image == nil {
+		return productDomain.ProductResponse{}, productDomain.ErrImageNotFound
+	}
+	if image.IsPrimary {
+		return toProductResponse(existingProduct), nil
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.imageRepository.SetPrimary(ctx, id, imageID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return productDomain.ErrImageNotFound
+			}
+			return err
+		}
+		return s.recordPrimaryImageChange(ctx, auditDomain.ActionUpdate, existingProduct, image)
+	})
+	if err != nil {
+		return productDomain.ProductResponse{}, err
+	}
+
+	return s.GetProduct(ctx, id)
+}
+
+// ReorderGallery puts the product's images in the order of the request, which
+// must list each of them once
+func (s *ProductServiceImpl) ReorderGallery(ctx context.Context, req productDomain.ReorderProductImagesRequest) ([]productDomain.ProductImageResponse, error) {
+	existingProduct, err := s.repository.GetByID(ctx, req.ProductID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, productDomain.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	for _, imageID := range req.ImageIDs {
+		if findImage(existingProduct.Images, imageID) == nil {
+			return nil, productDomain.ErrImageNotFound
+		}
+	}
+	if len(req.ImageIDs) != len(existingProduct.Images) {
+		return nil, validator.ValidationErrors{{
+			Field:   "image_ids",
+			Message: "image_ids must list every image of the product",
+		}}
+	}
+
+	var images []productDomain.ProductImage
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.imageRepository.Reorder(ctx, req.ProductID, req.ImageIDs); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return productDomain.ErrImageNotFound
+			}
+			return err
+		}
+		images, err = s.imageRepository.List(ctx, req.ProductID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toGalleryResponse(images), nil
+}
+
+// DeleteGalleryImage deletes one image of the gallery and its files. When it
+// was the primary image, the next one takes its place.
+func (s *ProductServiceImpl) DeleteGalleryImage(ctx context.Context, id, imageID int64) error {
+	existingProduct, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return productDomain.ErrProductNotFound
+		}
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+
+	image := findImage(existingProduct.Images, imageID)
+	if image == nil {
+		return productDomain.ErrImageNotFound
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.imageRepository.Delete(ctx, id, imageID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return productDomain.ErrImageNotFound
+			}
+			return err
+		}
+		if !image.IsPrimary {
+			return s.recordImageAudit(ctx, *image, auditDomain.ActionImageDelete)
+		}
+
+		next, err := s.promoteNextImage(ctx, id, existingProduct.Images, imageID)
+		if err != nil {
+			return err
+		}
+		return s.recordPrimaryImageChange(ctx, auditDomain.ActionImageDelete, existingProduct, next)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.deleteImageFiles(ctx, productDomain.ImageFileURLs(&image.URL, image.Variants))
+	return nil
+}
+
+// recordPrimaryImageChange copies a new primary image, or none, to the product
+// row and records the audit entry and revision of that write
+func (s *ProductServiceImpl) recordPrimaryImageChange(ctx context.Context, action auditDomain.Action, before productDomain.Product, primary *productDomain.ProductImage) error {
+	if err := s.syncPrimaryImage(ctx, before.ID, primary); err != nil {
+		return err
+	}
+
+	updatedProduct, err := s.repository.GetByID(ctx, before.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get updated product: %w", err)
+	}
+	if err := s.recordAudit(ctx, before.ID, action, &before, &updatedProduct); err != nil {
+		return err
+	}
+	return s.recordRevision(ctx, updatedProduct)
+}
+
+// syncPrimaryImage copies the primary image to the product's image_url and
+// image_variants, or clears them when there is no primary image
+func (s *ProductServiceImpl) syncPrimaryImage(ctx context.Context, id int64, primary *productDomain.ProductImage) error {
+	imageURL := ""
+	variants := []productDomain.ImageVariant{}
+	if primary != nil {
+		imageURL = primary.URL
+		variants = primary.Variants
+	}
+
+	err := s.repository.Update(ctx, productDomain.UpdateProductRequest{
+		ID:            id,
+		ImageURL:      &imageURL,
+		ImageVariants: &variants,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return productDomain.ErrProductNotFound
+		}
+		return fmt.Errorf("failed to update product image URL: %w", err)
+	}
+	return nil
+}
+
+// promoteNextImage makes the first image of the gallery other than removedID
+// the primary image and returns it, nil when there is none
+func (s *ProductServiceImpl) promoteNextImage(ctx context.Context, id int64, images []productDomain.ProductImage, removedID int64) (*productDomain.ProductImage, error) {
+	for _, image := range images {
+		if image.ID == removedID {
+			continue
+		}
+		if err := s.imageRepository.SetPrimary(ctx, id, image.ID); err != nil {
+			return nil, fmt.Errorf("failed to set primary product image: %w", err)
+		}
+		image.IsPrimary = true
+		return &image, nil
+	}
+	return nil, nil
+}
+
+func primaryImage(images []productDomain.ProductImage) *productDomain.ProductImage {
+	for i := range images {
+		if images[i].IsPrimary {
+			return &images[i]
+		}
+	}
+	return nil
+}
+
+func findImage(images []productDomain.ProductImage, id int64) *productDomain.ProductImage {
+	for i := range images {
+		if images[i].ID == id {
+			return &images[i]
+		}
+	}
+	return nil
+}
+
+// nonEmpty turns an empty alt text into none
+func nonEmpty(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
+
+func toProductImageResponse(image productDomain.ProductImage) productDomain.ProductImageResponse {
+	return productDomain.ProductImageResponse{
+		ID:        image.ID,
+		URL:       image.URL,
+		AltText:   image.AltText,
+		Position:  image.Position,
+		IsPrimary: image.IsPrimary,
+		Images:    toImagesResponse(image.Variants),
+	}
+}
+
+func toGalleryResponse(images []productDomain.ProductImage) []productDomain.ProductImageResponse {
+	gallery := []productDomain.ProductImageResponse{}
+	for _, image := range images {
+		gallery = append(gallery, toProductImageResponse(image))
+	}
+	return gallery
+}
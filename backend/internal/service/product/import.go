@@ -0,0 +1,482 @@
+package product
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
+	"github.com/shopspring/decimal"
+)
+
+// importJobRetention is how long a finished import job can still be polled
+const importJobRetention = 24 * time.Hour
+
+// importRow is one parsed data row of an import file
+type importRow struct {
+	line    int
+	product productDomain.CreateProductRequest
+	columns map[string]int // header name to record index, shared by all rows
+	errs    validator.ValidationErrors
+}
+
+// has reports whether the file has the given column
+func (r importRow) has(column string) bool {
+	_, ok := r.columns[column]
+	return ok
+}
+
+// ImportProducts implements productDomain.ProductService. Rows are validated
+// up front; a dry run reports the result of that and stops. Otherwise every
+// valid row is upserted by SKU in its own transaction, so one bad row does
+// not hold back the rest of the file.
+func (s *ProductServiceImpl) ImportProducts(ctx context.Context, req productDomain.ImportProductsRequest) (productDomain.ImportJobResponse, error) {
+	if req.File == nil {
+		return productDomain.ImportJobResponse{}, productDomain.ErrImportFileRequired
+	}
+
+	rows, err := parseImportCSV(req.File)
+	if err != nil {
+		return productDomain.ImportJobResponse{}, err
+	}
+
+	report := productDomain.ImportJobResponse{
+		Status:    productDomain.ImportStatusRunning,
+		DryRun:    req.DryRun,
+		TotalRows: len(rows),
+		Errors:    []productDomain.ImportRowError{},
+		StartedAt: time.Now().Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	var valid []importRow
+	for _, row := range rows {
+		if len(row.errs) > 0 {
+			report.Processed++
+			addImportFailure(&report, row, row.errs)
+			continue
+		}
+		valid = append(valid, row)
+	}
+	report.Valid = len(valid)
+
+	if req.DryRun {
+		report.Processed += len(valid)
+		finishImport(&report)
+		return report, nil
+	}
+
+	if len(rows) <= productDomain.ImportSyncRowLimit {
+		s.applyImport(ctx, valid, func(fn func(*productDomain.ImportJobResponse)) {
+			fn(&report)
+		})
+		finishImport(&report)
+		return report, nil
+	}
+
+	// The job outlives the request, so it keeps the request's values but
+	// not its cancellation
+	report.JobID = uuid.New().String()
+	tenantID, _ := requestctx.TenantID(ctx)
+	s.importJobs.add(tenantID, report)
+	go s.runImportJob(context.WithoutCancel(ctx), report.JobID, valid)
+
+	return report, nil
+}
+
+// GetImportJob implements productDomain.ProductService.
+func (s *ProductServiceImpl) GetImportJob(ctx context.Context, id string) (productDomain.ImportJobResponse, error) {
+	// Job IDs are not secret across tenants, so a job is only found by
+	// the tenant that started it
+	tenantID, _ := requestctx.TenantID(ctx)
+	report, ok := s.importJobs.get(tenantID, id)
+	if !ok {
+		return productDomain.ImportJobResponse{}, productDomain.ErrImportJobNotFound
+	}
+	return report, nil
+}
+
+// runImportJob applies an import in the background, recording its progress
+// in the job store
+func (s *ProductServiceImpl) runImportJob(ctx context.Context, id string, rows []importRow) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Import job %s stopped: %v", id, r)
+			s.importJobs.update(id, func(report *productDomain.ImportJobResponse) {
+				report.Status = productDomain.ImportStatusFailed
+				report.Error = "Import stopped unexpectedly"
+				finishImport(report)
+			})
+		}
+	}()
+
+	s.applyImport(ctx, rows, func(fn func(*productDomain.ImportJobResponse)) {
+		s.importJobs.update(id, fn)
+	})
+	s.importJobs.update(id, finishImport)
+}
+
+// applyImport upserts each row and passes its outcome to progress
+func (s *ProductServiceImpl) applyImport(ctx context.Context, rows []importRow, progress func(func(*productDomain.ImportJobResponse))) {
+	for _, row := range rows {
+		created, err := s.upsertImportRow(ctx, row)
+		progress(func(report *productDomain.ImportJobResponse) {
+			report.Processed++
+			switch {
+			case err != nil:
+				addImportFailure(report, row, err)
+			case created:
+				report.Created++
+			default:
+				report.Updated++
+			}
+		})
+	}
+}
+
+// upsertImportRow creates the row's product, or updates the product that
+// already has its SKU, and reports whether it was created. An update leaves
+// the description and stock alone when the file has no column for them.
+func (s *ProductServiceImpl) upsertImportRow(ctx context.Context, row importRow) (bool, error) {
+	created := false
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := s.repository.GetBySKU(ctx, row.product.SKU)
+		if errors.Is(err, pgx.ErrNoRows) {
+			created = true
+			_, err = s.CreateProduct(ctx, row.product)
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get product by SKU: %w", err)
+		}
+		if existing.ParentID != nil {
+			return validator.ValidationErrors{{
+				Field:   "sku",
+				Message: "sku belongs to a product variant, variants cannot be imported",
+			}}
+		}
+
+		p := row.product
+		update := productDomain.UpdateProductRequest{
+			ID:         existing.ID,
+			Name:       &p.Name,
+			Price:      &p.Price,
+			CategoryID: p.CategoryID,
+			Status:     &p.Status,
+		}
+		if p.CategoryID == nil {
+			update.Category = &p.Category
+		}
+		if row.has("description") {
+			description := ""
+			if p.Description != nil {
+				description = *p.Description
+			}
+			update.Description = &description
+		}
+		if row.has("stock") {
+			update.Stock = &p.Stock
+		}
+		return s.UpdateProduct(ctx, update)
+	})
+	return created, err
+}
+
+// addImportFailure records a rejected row on the report
+func addImportFailure(report *productDomain.ImportJobResponse, row importRow, err error) {
+	code, message, details := describeFailure(err)
+	if code == "INTERNAL_ERROR" {
+		log.Printf("Import row %d failed: %v", row.line, err)
+	}
+
+	report.Failed++
+	report.Errors = append(report.Errors, productDomain.ImportRowError{
+		Row:       row.line,
+		SKU:       row.product.SKU,
+		ErrorCode: code,
+		Error:     message,
+		Details:   details,
+	})
+}
+
+// finishImport marks a report as done, with its errors in file order
+func finishImport(report *productDomain.ImportJobResponse) {
+	if report.Status == productDomain.ImportStatusRunning {
+		report.Status = productDomain.ImportStatusCompleted
+	}
+	slices.SortFunc(report.Errors, func(a, b productDomain.ImportRowError) int {
+		return cmp.Compare(a.Row, b.Row)
+	})
+	finishedAt := time.Now().Format("2006-01-02T15:04:05Z07:00")
+	report.FinishedAt = &finishedAt
+}
+
+// parseImportCSV reads an import file. Problems with the header or the file
+// as a whole fail the import; problems with a row are recorded on the row.
+func parseImportCSV(file io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, validator.ValidationErrors{{
+			Field:   "file",
+			Message: "file must contain a header row and at least one product row",
+		}}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", productDomain.ErrInvalidImportFile, err)
+	}
+
+	columns, err := parseImportHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []importRow
+	firstLine := make(map[string]int) // SKU to the line it first appears on
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, fmt.Errorf("%w: %v", productDomain.ErrInvalidImportFile, err)
+		}
+
+		if len(rows) == productDomain.MaxImportRows {
+			return nil, validator.ValidationErrors{{
+				Field:   "file",
+				Message: fmt.Sprintf("file must not exceed %d product rows", productDomain.MaxImportRows),
+			}}
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importRow{line: line, columns: columns}
+		if err != nil {
+			row.errs = validator.ValidationErrors{{
+				Field:   "row",
+				Message: fmt.Sprintf("row has %d columns, the header has %d", len(record), len(header)),
+			}}
+			rows = append(rows, row)
+			continue
+		}
+
+		// Spreadsheets tend to export trailing rows of empty cells
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		row.product, row.errs = parseImportRecord(record, columns)
+		if sku := row.product.SKU; sku != "" {
+			if first, ok := firstLine[sku]; ok {
+				row.errs = append(row.errs, validator.ValidationError{
+					Field:   "sku",
+					Message: fmt.Sprintf("sku already appears on row %d", first),
+				})
+			} else {
+				firstLine[sku] = line
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, validator.ValidationErrors{{
+			Field:   "file",
+			Message: "file must contain a header row and at least one product row",
+		}}
+	}
+
+	return rows, nil
+}
+
+// parseImportHeader maps the header names, matched case-insensitively, to
+// their column index
+func parseImportHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	var problems []string
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // byte order mark written by Excel
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if !validator.IsInSlice(name, productDomain.ImportColumns) {
+			problems = append(problems, fmt.Sprintf("unknown column '%s'", name))
+			continue
+		}
+		if _, ok := columns[name]; ok {
+			problems = append(problems, fmt.Sprintf("duplicate column '%s'", name))
+			continue
+		}
+		columns[name] = i
+	}
+
+	for _, required := range []string{"sku", "name", "price", "status"} {
+		if _, ok := columns[required]; !ok {
+			problems = append(problems, fmt.Sprintf("missing column '%s'", required))
+		}
+	}
+	_, hasCategoryID := columns["category_id"]
+	_, hasCategory := columns["category"]
+	if !hasCategoryID && !hasCategory {
+		problems = append(problems, "missing column 'category_id' or 'category'")
+	}
+
+	if len(problems) > 0 {
+		return nil, validator.ValidationErrors{{
+			Field:   "columns",
+			Message: strings.Join(problems, "; "),
+		}}
+	}
+
+	return columns, nil
+}
+
+// parseImportRecord converts a data row into a create request and validates
+// it. Cells that do not parse are reported after the request's own checks, so
+// their message wins for the field.
+func parseImportRecord(record []string, columns map[string]int) (productDomain.CreateProductRequest, validator.ValidationErrors) {
+	cell := func(name string) string {
+		i, ok := columns[name]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var parseErrs validator.ValidationErrors
+	req := productDomain.CreateProductRequest{
+		SKU:      cell("sku"),
+		Name:     cell("name"),
+		Category: cell("category"),
+	}
+
+	if description := cell("description"); description != "" {
+		req.Description = &description
+	}
+
+	if price := cell("price"); price == "" {
+		parseErrs = append(parseErrs, validator.ValidationError{
+			Field:   "price",
+			Message: "price is required",
+		})
+	} else if value, err := decimal.NewFromString(price); err != nil {
+		parseErrs = append(parseErrs, validator.ValidationError{
+			Field:   "price",
+			Message: "price must be a number",
+		})
+	} else {
+		req.Price = value
+	}
+
+	if stock := cell("stock"); stock != "" {
+		value, err := strconv.Atoi(stock)
+		if err != nil {
+			parseErrs = append(parseErrs, validator.ValidationError{
+				Field:   "stock",
+				Message: "stock must be a whole number",
+			})
+		}
+		req.Stock = value
+	}
+
+	if categoryID := cell("category_id"); categoryID != "" {
+		value, err := strconv.ParseInt(categoryID, 10, 64)
+		if err != nil {
+			parseErrs = append(parseErrs, validator.ValidationError{
+				Field:   "category_id",
+				Message: "category_id must be a positive integer",
+			})
+		} else {
+			req.CategoryID = &value
+		}
+	}
+
+	// Spreadsheet users tend not to mind the case of the status
+	status := cell("status")
+	for _, known := range []productDomain.ProductStatus{productDomain.ProductStatusActive, productDomain.ProductStatusInactive} {
+		if strings.EqualFold(status, string(known)) {
+			status = string(known)
+		}
+	}
+	req.Status = productDomain.ProductStatus(status)
+
+	var errs validator.ValidationErrors
+	if err := req.Validate(); err != nil {
+		errors.As(err, &errs)
+	}
+	errs = append(errs, parseErrs...)
+
+	return req, errs
+}
+
+// importJobStore keeps background import jobs in memory, so they do not
+// survive a restart. Reports are copied in and out under the lock.
+type importJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*importJob
+}
+
+type importJob struct {
+	tenantID   int64
+	report     productDomain.ImportJobResponse
+	finishedAt time.Time
+}
+
+func newImportJobStore() *importJobStore {
+	return &importJobStore{jobs: make(map[string]*importJob)}
+}
+
+// add registers a new job and drops finished jobs past their retention
+func (s *importJobStore) add(tenantID int64, report productDomain.ImportJobResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, job := range s.jobs {
+		if !job.finishedAt.IsZero() && time.Since(job.finishedAt) > importJobRetention {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobs[report.JobID] = &importJob{tenantID: tenantID, report: report}
+}
+
+func (s *importJobStore) update(id string, fn func(*productDomain.ImportJobResponse)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+	fn(&job.report)
+	if job.report.FinishedAt != nil && job.finishedAt.IsZero() {
+		job.finishedAt = time.Now()
+	}
+}
+
+func (s *importJobStore) get(tenantID int64, id string) (productDomain.ImportJobResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok || job.tenantID != tenantID {
+		return productDomain.ImportJobResponse{}, false
+	}
+	report := job.report
+	report.Errors = slices.Clone(job.report.Errors)
+	return report, true
+}
@@ -0,0 +1,120 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+)
+
+// recordRevision snapshots a product as it is after a write, within the
+// write's transaction
+func (s *ProductServiceImpl) recordRevision(ctx context.Context, p productDomain.Product) error {
+	actor := requestctx.ActorOrSystem(ctx)
+
+	_, err := s.revisionRepository.Create(ctx, productDomain.ProductRevision{
+		ProductID: p.ID,
+		Revision:  p.Version,
+		Snapshot: productDomain.ProductSnapshot{
+			SKU:         p.SKU,
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       p.Price,
+			Stock:       p.Stock,
+			CategoryID:  p.CategoryID,
+			Status:      p.Status,
+			ImageURL:    p.ImageURL,
+			Version:     p.Version,
+			UpdatedAt:   p.UpdatedAt,
+		},
+		Actor: actor,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record product revision: %w", err)
+	}
+	return nil
+}
+
+func (s *ProductServiceImpl) ListRevisions(ctx context.Context, filter productDomain.ListProductRevisionFilter) (productDomain.ListProductRevisionResponse, error) {
+	revisions, total, err := s.revisionRepository.List(ctx, filter)
+	if err != nil {
+		return productDomain.ListProductRevisionResponse{}, fmt.Errorf("failed to list product revisions: %w", err)
+	}
+
+	revisionResponses := []productDomain.ProductRevisionResponse{}
+	for _, r := range revisions {
+		revisionResponses = append(revisionResponses, toProductRevisionResponse(r))
+	}
+
+	totalPages := (total + int64(filter.Limit) - 1) / int64(filter.Limit)
+
+	return productDomain.ListProductRevisionResponse{
+		TotalCount: total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: int(totalPages),
+		Revisions:  revisionResponses,
+	}, nil
+}
+
+func (s *ProductServiceImpl) GetRevision(ctx context.Context, id int64, revision int) (productDomain.ProductRevisionResponse, error) {
+	r, err := s.revisionRepository.Get(ctx, id, revision)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return productDomain.ProductRevisionResponse{}, productDomain.ErrRevisionNotFound
+		}
+		return productDomain.ProductRevisionResponse{}, fmt.Errorf("failed to get product revision: %w", err)
+	}
+
+	return toProductRevisionResponse(r), nil
+}
+
+// RestoreRevision puts a product's editable fields back to a revision as an
+// ordinary update, which itself becomes the newest revision. Stock and the
+// image are left alone: stock only changes through the ledger, and an older
+// image file may no longer exist.
+func (s *ProductServiceImpl) RestoreRevision(ctx context.Context, id int64, revision int, expectedVersion *int) (productDomain.ProductResponse, error) {
+	r, err := s.GetRevision(ctx, id, revision)
+	if err != nil {
+		return productDomain.ProductResponse{}, err
+	}
+
+	// An absent description is restored as an empty one, as the update
+	// request can't tell NULL from "leave unchanged"
+	description := ""
+	if r.Snapshot.Description != nil {
+		description = *r.Snapshot.Description
+	}
+	req := productDomain.UpdateProductRequest{
+		ID:              id,
+		SKU:             &r.Snapshot.SKU,
+		Name:            &r.Snapshot.Name,
+		Description:     &description,
+		Price:           &r.Snapshot.Price,
+		CategoryID:      &r.Snapshot.CategoryID,
+		Status:          &r.Snapshot.Status,
+		ExpectedVersion: expectedVersion,
+	}
+	if err := req.Validate(); err != nil {
+		return productDomain.ProductResponse{}, err
+	}
+
+	if err := s.UpdateProduct(ctx, req); err != nil {
+		return productDomain.ProductResponse{}, err
+	}
+
+	return s.GetProduct(ctx, id)
+}
+
+func toProductRevisionResponse(r productDomain.ProductRevision) productDomain.ProductRevisionResponse {
+	return productDomain.ProductRevisionResponse{
+		ProductID: r.ProductID,
+		Revision:  r.Revision,
+		Actor:     r.Actor,
+		CreatedAt: r.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Snapshot:  r.Snapshot,
+	}
+}
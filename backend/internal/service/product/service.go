@@ -1,60 +1,68 @@
 package product
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"mime/multipart"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	auditDomain "github.com/naxumi/bnsp-jwd/internal/domain/audit"
+	categoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/category"
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
 	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
 	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/imaging"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
 	"github.com/naxumi/bnsp-jwd/internal/service/file"
 )
 
 type ProductServiceImpl struct {
-	db          *database.DB
-	repository  productDomain.ProductRepository
-	fileService file.FileService
+	transactor         database.Transactor
+	repository         productDomain.ProductRepository
+	revisionRepository productDomain.ProductRevisionRepository
+	imageRepository    productDomain.ProductImageRepository
+	categoryRepository categoryDomain.CategoryRepository
+	inventoryService   inventoryDomain.InventoryService
+	auditService       auditDomain.AuditService
+	fileService        file.FileService
+	imagePolicy        imaging.Policy
+	imagePipeline      imaging.Pipeline
+	importJobs         *importJobStore
+
+	// How long deleted products stay in the trash before PurgeTrash removes them
+	trashRetention time.Duration
 }
 
-func NewProductService(db *database.DB, repository productDomain.ProductRepository, fileService file.FileService) productDomain.ProductService {
+func NewProductService(transactor database.Transactor, repository productDomain.ProductRepository, revisionRepository productDomain.ProductRevisionRepository, imageRepository productDomain.ProductImageRepository, categoryRepository categoryDomain.CategoryRepository, inventoryService inventoryDomain.InventoryService, auditService auditDomain.AuditService, fileService file.FileService, imagePolicy imaging.Policy, imagePipeline imaging.Pipeline, trashRetention time.Duration) productDomain.ProductService {
 	return &ProductServiceImpl{
-		db:          db,
-		repository:  repository,
-		fileService: fileService,
+		transactor:         transactor,
+		repository:         repository,
+		revisionRepository: revisionRepository,
+		imageRepository:    imageRepository,
+		categoryRepository: categoryRepository,
+		inventoryService:   inventoryService,
+		auditService:       auditService,
+		fileService:        fileService,
+		imagePolicy:        imagePolicy,
+		imagePipeline:      imagePipeline,
+		importJobs:         newImportJobStore(),
+		trashRetention:     trashRetention,
 	}
 }
 
-// UploadImage implements productDomain.ProductService.
+// UploadImage replaces the product's primary image. The new image is added to
+// the end of the gallery; without a primary image it becomes the first one.
 func (s *ProductServiceImpl) UploadImage(ctx context.Context, id int64, file multipart.File, fileHeader *multipart.FileHeader) error {
-	// Validate file is provided
-	if fileHeader == nil {
-		return productDomain.ErrImageRequired
-	}
-
-	// Validate file size (5MB max)
-	const maxFileSize = 5 * 1024 * 1024 // 5MB in bytes
-	if fileHeader.Size > maxFileSize {
-		return productDomain.ErrImageTooLarge
-	}
-
-	// Validate file type
-	ext := filepath.Ext(fileHeader.Filename)
-	ext = strings.ToLower(ext)
-	allowedExts := []string{".jpg", ".jpeg", ".png", ".gif"}
-	isValidExt := false
-	for _, allowed := range allowedExts {
-		if ext == allowed {
-			isValidExt = true
-			break
-		}
-	}
-	if !isValidExt {
-		return productDomain.ErrInvalidImageFormat
+	upload, err := s.readImageFile(file, fileHeader)
+	if err != nil {
+		return err
 	}
 
 	// Get existing product to check for old image
@@ -66,92 +74,202 @@ func (s *ProductServiceImpl) UploadImage(ctx context.Context, id int64, file mul
 		return fmt.Errorf("failed to get product: %w", err)
 	}
 
-	// Generate unique filename
-	uniqueFilename := fmt.Sprintf("product-%d-image%s", id, ext)
-
-	// Upload file using fileService
-	uploadedPath, err := s.fileService.UploadProductImage(ctx, fmt.Sprintf("%d", id), file, uniqueFilename)
+	imageURL, variants, err := s.storeImageFiles(ctx, id, upload)
 	if err != nil {
-		return fmt.Errorf("failed to upload product image: %w", err)
+		return err
 	}
 
-	// Get full URL for the uploaded file
-	imageURL, err := s.fileService.GetFileURL(ctx, uploadedPath, 0)
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if primary := primaryImage(existingProduct.Images); primary != nil {
+			if err := s.imageRepository.Delete(ctx, id, primary.ID); err != nil {
+				return fmt.Errorf("failed to delete primary product image: %w", err)
+			}
+		}
+		image, err := s.imageRepository.Create(ctx, productDomain.ProductImage{
+			ProductID: id,
+			URL:       imageURL,
+			Variants:  variants,
+			IsPrimary: true,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.syncPrimaryImage(ctx, id, &image); err != nil {
+			return err
+		}
+
+		updatedProduct, err := s.repository.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get updated product: %w", err)
+		}
+		if err := s.recordAudit(ctx, id, auditDomain.ActionImageUpload, &existingProduct, &updatedProduct); err != nil {
+			return err
+		}
+		return s.recordRevision(ctx, updatedProduct)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get image URL: %w", err)
+		s.deleteImageFiles(ctx, productDomain.ImageFileURLs(&imageURL, variants))
+		return err
 	}
 
-	// Update product's image URL in the repository
-	updateReq := productDomain.UpdateProductRequest{
-		ID:       id,
-		ImageURL: &imageURL,
+	// Delete old image and its variants if they exist
+	s.deleteImageFiles(ctx, productDomain.ImageFileURLs(existingProduct.ImageURL, existingProduct.ImageVariants))
+
+	return nil
+}
+
+// maxImageFileSize is the largest product image that can be uploaded
+const maxImageFileSize = 5 * 1024 * 1024 // 5MB in bytes
+
+// readImageFile reads an uploaded image and checks it against the image
+// policy: its format is detected from the content, whatever the file name
+// says, and it must decode within the pixel limit. The image pipeline then
+// turns it upright, scales it down and strips its metadata.
+func (s *ProductServiceImpl) readImageFile(file io.Reader, fileHeader *multipart.FileHeader) (imaging.Processed, error) {
+	// Validate file is provided
+	if file == nil || fileHeader == nil {
+		return imaging.Processed{}, productDomain.ErrImageRequired
 	}
-	if err := s.repository.Update(ctx, updateReq); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return productDomain.ErrProductNotFound
-		}
-		return fmt.Errorf("failed to update product image URL: %w", err)
+
+	// Validate file size (5MB max), the header is not trusted for it
+	if fileHeader.Size > maxImageFileSize {
+		return imaging.Processed{}, productDomain.ErrImageTooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxImageFileSize+1))
+	if err != nil {
+		return imaging.Processed{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageFileSize {
+		return imaging.Processed{}, productDomain.ErrImageTooLarge
 	}
 
-	// Delete old image if exists
-	if existingProduct.ImageURL != nil && *existingProduct.ImageURL != "" {
-		// Extract relative path from URL if it's a full URL
-		oldImagePath := *existingProduct.ImageURL
-		if len(oldImagePath) > 0 && (strings.HasPrefix(oldImagePath, "http://") || strings.HasPrefix(oldImagePath, "https://")) {
-			// It's a full URL, extract the path after /uploads/
-			parts := strings.Split(oldImagePath, "/uploads/")
-			if len(parts) > 1 {
-				oldImagePath = parts[1]
-			}
+	img, format, err := s.imagePolicy.Decode(data)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooManyPixels) {
+			return imaging.Processed{}, fmt.Errorf("%w: %w", productDomain.ErrImageTooManyPixels, err)
 		}
-		if err := s.fileService.DeleteFile(ctx, oldImagePath); err != nil {
-			// Log error but don't fail the operation
-			fmt.Printf("Warning: failed to delete old image %s: %v\n", oldImagePath, err)
+		return imaging.Processed{}, fmt.Errorf("%w: %w", productDomain.ErrInvalidImageFormat, err)
+	}
+
+	processed, err := s.imagePipeline.Process(data, img, format)
+	if err != nil {
+		return imaging.Processed{}, fmt.Errorf("failed to process image: %w", err)
+	}
+
+	return processed, nil
+}
+
+// storeImageFiles stores an uploaded image with its resized copies and returns
+// the URL of the original and every size, the original included. Files
+// already stored are removed again if a later one fails.
+func (s *ProductServiceImpl) storeImageFiles(ctx context.Context, id int64, upload imaging.Processed) (string, []productDomain.ImageVariant, error) {
+	resized, err := s.imagePipeline.Variants(upload.Image, imaging.DefaultSizes)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resize product image: %w", err)
+	}
+
+	imageURL, err := s.storeImage(ctx, id, bytes.NewReader(upload.Data), upload.Format)
+	if err != nil {
+		return "", nil, err
+	}
+	variants := []productDomain.ImageVariant{{
+		Name:   "original",
+		Format: upload.Format,
+		URL:    imageURL,
+		Width:  upload.Image.Bounds().Dx(),
+		Height: upload.Image.Bounds().Dy(),
+	}}
+	for _, v := range resized {
+		url, err := s.storeImage(ctx, id, bytes.NewReader(v.Data), v.Format)
+		if err != nil {
+			s.deleteImageFiles(ctx, productDomain.ImageFileURLs(&imageURL, variants))
+			return "", nil, err
 		}
+		variants = append(variants, productDomain.ImageVariant{
+			Name:   v.Name,
+			Format: v.Format,
+			URL:    url,
+			Width:  v.Width,
+			Height: v.Height,
+		})
 	}
 
-	return nil
+	return imageURL, variants, nil
+}
+
+// storeImage uploads one file of a product image, encoded in format, and
+// returns its URL
+func (s *ProductServiceImpl) storeImage(ctx context.Context, id int64, file io.Reader, format string) (string, error) {
+	uploadedPath, err := s.fileService.UploadProductImage(ctx, fmt.Sprintf("%d", id), file, format)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload product image: %w", err)
+	}
+
+	imageURL, err := s.fileService.GetFileURL(ctx, uploadedPath, 0)
+	if err != nil {
+		s.deleteImageFiles(ctx, []string{uploadedPath})
+		return "", fmt.Errorf("failed to get image URL: %w", err)
+	}
+	return imageURL, nil
 }
 
 func (s *ProductServiceImpl) CreateProduct(ctx context.Context, req productDomain.CreateProductRequest) (productDomain.ProductResponse, error) {
+	categoryID, err := s.resolveCategoryID(ctx, req.CategoryID, req.Category)
+	if err != nil {
+		return productDomain.ProductResponse{}, err
+	}
 
+	// Initial stock is not written directly, it is posted to the ledger as an
+	// opening balance so that every unit can be traced back to a movement.
 	newProduct := productDomain.Product{
 		SKU:         req.SKU,
 		Name:        req.Name,
 		Description: req.Description,
 		Price:       req.Price,
-		Stock:       req.Stock,
-		Category:    req.Category,
+		CategoryID:  categoryID,
 		Status:      req.Status,
 	}
 
-	createdProduct, err := s.repository.Create(ctx, newProduct)
-	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
-			return productDomain.ProductResponse{}, productDomain.ErrProductSKUExists
+	var createdProduct productDomain.Product
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		createdProduct, err = s.repository.Create(ctx, newProduct)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
+				return productDomain.ErrProductSKUExists
+			}
+			return fmt.Errorf("failed to create product: %w", err)
 		}
-		return productDomain.ProductResponse{}, fmt.Errorf("failed to create product: %w", err)
-	}
 
-	return productDomain.ProductResponse{
-		ID:          createdProduct.ID,
-		SKU:         createdProduct.SKU,
-		Name:        createdProduct.Name,
-		Description: createdProduct.Description,
-		Price:       createdProduct.Price,
-		Stock:       createdProduct.Stock,
-		Category:    createdProduct.Category,
-		Status:      createdProduct.Status,
-		ImageURL: func() *string {
-			if createdProduct.ImageURL == nil || *createdProduct.ImageURL == "" {
-				return nil
+		if req.Stock > 0 {
+			_, err := s.inventoryService.PostMovement(ctx, inventoryDomain.CreateStockMovementRequest{
+				ProductID:  createdProduct.ID,
+				Type:       inventoryDomain.MovementTypeReceipt,
+				ReasonCode: inventoryDomain.ReasonOpeningBalance,
+				Quantity:   req.Stock,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to record opening stock: %w", err)
 			}
-			return createdProduct.ImageURL
-		}(),
-		CreatedAt: createdProduct.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: createdProduct.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+
+			// Reload to pick up the stock and its warehouse breakdown
+			createdProduct, err = s.repository.GetByID(ctx, createdProduct.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get created product: %w", err)
+			}
+		}
+
+		if err := s.recordAudit(ctx, createdProduct.ID, auditDomain.ActionCreate, nil, &createdProduct); err != nil {
+			return err
+		}
+		return s.recordRevision(ctx, createdProduct)
+	})
+	if err != nil {
+		return productDomain.ProductResponse{}, err
+	}
+
+	return toProductResponse(createdProduct), nil
 }
 
 func (s *ProductServiceImpl) GetProduct(ctx context.Context, id int64) (productDomain.ProductResponse, error) {
@@ -163,24 +281,7 @@ func (s *ProductServiceImpl) GetProduct(ctx context.Context, id int64) (productD
 		return productDomain.ProductResponse{}, fmt.Errorf("failed to get product: %w", err)
 	}
 
-	return productDomain.ProductResponse{
-		ID:          p.ID,
-		SKU:         p.SKU,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-		Stock:       p.Stock,
-		Category:    p.Category,
-		Status:      p.Status,
-		ImageURL: func() *string {
-			if p.ImageURL == nil || *p.ImageURL == "" {
-				return nil
-			}
-			return p.ImageURL
-		}(),
-		CreatedAt: p.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: p.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+	return toProductResponse(p), nil
 }
 
 func (s *ProductServiceImpl) GetProductBySKU(ctx context.Context, sku string) (productDomain.ProductResponse, error) {
@@ -192,80 +293,336 @@ func (s *ProductServiceImpl) GetProductBySKU(ctx context.Context, sku string) (p
 		return productDomain.ProductResponse{}, fmt.Errorf("failed to get product by SKU: %w", err)
 	}
 
-	return productDomain.ProductResponse{
-		ID:          p.ID,
-		SKU:         p.SKU,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-		Stock:       p.Stock,
-		Category:    p.Category,
-		Status:      p.Status,
-		ImageURL: func() *string {
-			if p.ImageURL == nil || *p.ImageURL == "" {
-				return nil
+	// A variant SKU resolves to its parent product
+	if p.ParentID != nil {
+		variantID := p.ID
+		p, err = s.repository.GetByID(ctx, *p.ParentID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return productDomain.ProductResponse{}, productDomain.ErrProductNotFound
 			}
-			return p.ImageURL
-		}(),
-		CreatedAt: p.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: p.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+			return productDomain.ProductResponse{}, fmt.Errorf("failed to get parent product: %w", err)
+		}
+
+		response := toProductResponse(p)
+		response.MatchedVariantID = &variantID
+		return response, nil
+	}
+
+	return toProductResponse(p), nil
 }
 
 func (s *ProductServiceImpl) UpdateProduct(ctx context.Context, req productDomain.UpdateProductRequest) error {
+	if req.CategoryID != nil || req.Category != nil {
+		name := ""
+		if req.Category != nil {
+			name = *req.Category
+		}
+		categoryID, err := s.resolveCategoryID(ctx, req.CategoryID, name)
+		if err != nil {
+			return err
+		}
+		req.CategoryID = &categoryID
+		req.Category = nil
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Lock the row so the audited "before" is the state this update replaces
+		before, err := s.repository.GetByIDForUpdate(ctx, req.ID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return productDomain.ErrProductNotFound
+			}
+			return fmt.Errorf("failed to get product: %w", err)
+		}
+		if req.ExpectedVersion != nil && before.Version != *req.ExpectedVersion {
+			return productDomain.ErrVersionConflict
+		}
+		if err := requirePriceUnchanged(ctx, before.Price, req.Price); err != nil {
+			return err
+		}
+
+		// A new stock level is booked as a correction in the ledger rather
+		// than overwriting products.stock
+		if req.Stock != nil {
+			if err := s.inventoryService.CorrectStock(ctx, req.ID, *req.Stock); err != nil {
+				return err
+			}
+			req.Stock = nil
+		}
 
-	if err := s.repository.Update(ctx, req); err != nil {
+		if err := s.repository.Update(ctx, req); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return s.missingOrConflict(ctx, req.ID, req.ExpectedVersion)
+			}
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
+				return productDomain.ErrProductSKUExists
+			}
+			return fmt.Errorf("failed to update product: %w", err)
+		}
+
+		after, err := s.repository.GetByID(ctx, req.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get updated product: %w", err)
+		}
+		if err := s.recordAudit(ctx, req.ID, auditDomain.ActionUpdate, &before, &after); err != nil {
+			return err
+		}
+		return s.recordRevision(ctx, after)
+	})
+}
+
+// missingOrConflict tells apart the two reasons a versioned write matched no
+// row: the product is gone, or its version moved on.
+func (s *ProductServiceImpl) missingOrConflict(ctx context.Context, id int64, expectedVersion *int) error {
+	if expectedVersion == nil {
+		return productDomain.ErrProductNotFound
+	}
+	if _, err := s.repository.GetByID(ctx, id); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return productDomain.ErrProductNotFound
 		}
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
-			return productDomain.ErrProductSKUExists
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+	return productDomain.ErrVersionConflict
+}
+
+// DeleteProduct moves the product to the trash. Its image file is kept, the
+// product can still be restored; PurgeTrash removes the file later.
+func (s *ProductServiceImpl) DeleteProduct(ctx context.Context, id int64, expectedVersion *int) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Check the product exists and its version still matches
+		product, err := s.repository.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return productDomain.ErrProductNotFound
+			}
+			return fmt.Errorf("failed to get product: %w", err)
 		}
-		return fmt.Errorf("failed to update product: %w", err)
+
+		if expectedVersion != nil && product.Version != *expectedVersion {
+			return productDomain.ErrVersionConflict
+		}
+
+		if err := s.repository.Delete(ctx, id, expectedVersion); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return s.missingOrConflict(ctx, id, expectedVersion)
+			}
+			return fmt.Errorf("failed to delete product: %w", err)
+		}
+
+		return s.recordAudit(ctx, id, auditDomain.ActionDelete, &product, nil)
+	})
+}
+
+// ListTrash lists the deleted products, the filter is applied as in ListProducts
+func (s *ProductServiceImpl) ListTrash(ctx context.Context, filter productDomain.ListProductFilter) (productDomain.ListProductResponse, error) {
+	filter.Trashed = true
+	return s.ListProducts(ctx, filter)
+}
+
+// RestoreProduct takes a product, and the variants deleted with it, out of
+// the trash. It fails with ErrProductSKUExists when a live product has taken
+// the SKU in the meantime.
+func (s *ProductServiceImpl) RestoreProduct(ctx context.Context, id int64) (productDomain.ProductResponse, error) {
+	var product productDomain.Product
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.Restore(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return productDomain.ErrProductNotInTrash
+			}
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
+				return productDomain.ErrProductSKUExists
+			}
+			return fmt.Errorf("failed to restore product: %w", err)
+		}
+
+		var err error
+		product, err = s.repository.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get product: %w", err)
+		}
+		if err := s.recordAudit(ctx, id, auditDomain.ActionRestore, nil, &product); err != nil {
+			return err
+		}
+		return s.recordRevision(ctx, product)
+	})
+	if err != nil {
+		return productDomain.ProductResponse{}, err
 	}
-	return nil
+
+	return toProductResponse(product), nil
 }
 
-func (s *ProductServiceImpl) DeleteProduct(ctx context.Context, id int64) error {
-	// Get product to check for image
-	product, err := s.repository.GetByID(ctx, id)
+// PurgeTrash permanently deletes the products that have been in the trash
+// longer than the retention period. Image files are removed only after the
+// purge is committed, so a rolled back purge never loses a file.
+func (s *ProductServiceImpl) PurgeTrash(ctx context.Context) (productDomain.PurgeTrashResponse, error) {
+	before := time.Now().Add(-s.trashRetention)
+
+	var purged int64
+	var imageURLs []string
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		purged, imageURLs, err = s.repository.Purge(ctx, before)
+		return err
+	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return productDomain.ErrProductNotFound
+		return productDomain.PurgeTrashResponse{}, fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	s.deleteImageFiles(ctx, imageURLs)
+
+	return productDomain.PurgeTrashResponse{
+		Purged:        purged,
+		DeletedBefore: before.Format("2006-01-02T15:04:05Z07:00"),
+	}, nil
+}
+
+// imagePath turns an image URL into the path it is stored under. URLs of the
+// local storage are served below /uploads/; other URLs are resolved by the
+// storage itself.
+func imagePath(imageURL string) string {
+	if strings.HasPrefix(imageURL, "http://") || strings.HasPrefix(imageURL, "https://") {
+		if _, path, ok := strings.Cut(imageURL, "/uploads/"); ok {
+			return path
+		}
+	}
+	return imageURL
+}
+
+// deleteImageFiles deletes the stored files behind image URLs. Failures are
+// only logged: the files are no longer referenced by any product.
+func (s *ProductServiceImpl) deleteImageFiles(ctx context.Context, imageURLs []string) {
+	for _, imageURL := range imageURLs {
+		path := imagePath(imageURL)
+		if err := s.fileService.DeleteFile(ctx, path); err != nil {
+			fmt.Printf("Warning: failed to delete product image %s: %v\n", path, err)
 		}
-		return fmt.Errorf("failed to get product: %w", err)
 	}
+}
 
-	// Delete product from database
-	if err := s.repository.Delete(ctx, id); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return productDomain.ErrProductNotFound
+// BulkProducts validates every operation first. An atomic batch then runs in
+// one transaction and stops at the first failure; a best-effort batch runs
+// each valid operation in its own transaction.
+func (s *ProductServiceImpl) BulkProducts(ctx context.Context, req productDomain.BulkProductRequest) (productDomain.BulkProductResponse, error) {
+	results := make([]productDomain.BulkOperationResult, len(req.Operations))
+	invalid := false
+	for i := range req.Operations {
+		results[i] = productDomain.BulkOperationResult{Index: i, Op: req.Operations[i].Op()}
+		if err := req.Operations[i].Validate(); err != nil {
+			setBulkFailure(&results[i], err)
+			invalid = true
 		}
-		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
-	// Delete product image if exists
-	if product.ImageURL != nil && *product.ImageURL != "" {
-		imagePath := *product.ImageURL
-		// Extract relative path from URL if it's a full URL
-		if len(imagePath) > 0 && (strings.HasPrefix(imagePath, "http://") || strings.HasPrefix(imagePath, "https://")) {
-			// It's a full URL, extract the path after /uploads/
-			parts := strings.Split(imagePath, "/uploads/")
-			if len(parts) > 1 {
-				imagePath = parts[1]
+	result := productDomain.BulkProductResponse{Mode: req.Mode, Results: results}
+
+	if req.Mode == productDomain.BulkModeBestEffort {
+		for i, op := range req.Operations {
+			if results[i].Status == productDomain.BulkStatusFailed {
+				continue
+			}
+			id, err := s.applyBulkOperation(ctx, op)
+			if err != nil {
+				setBulkFailure(&results[i], err)
+				continue
+			}
+			results[i].Status = productDomain.BulkStatusSucceeded
+			results[i].ID = &id
+		}
+		result.Committed = true
+	} else if !invalid {
+		failedAt := -1
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			for i, op := range req.Operations {
+				id, err := s.applyBulkOperation(ctx, op)
+				if err != nil {
+					failedAt = i
+					return err
+				}
+				results[i].Status = productDomain.BulkStatusSucceeded
+				results[i].ID = &id
 			}
+			return nil
+		})
+		if err != nil && failedAt < 0 {
+			return productDomain.BulkProductResponse{}, fmt.Errorf("failed to commit bulk operation: %w", err)
 		}
-		if err := s.fileService.DeleteFile(ctx, imagePath); err != nil {
-			// Log error but don't fail the operation since product is already deleted
-			fmt.Printf("Warning: failed to delete product image %s: %v\n", imagePath, err)
+		if err != nil {
+			for i := range results[:failedAt] {
+				results[i].Status = productDomain.BulkStatusRolledBack
+			}
+			setBulkFailure(&results[failedAt], err)
+		} else {
+			result.Committed = true
 		}
 	}
 
-	return nil
+	for i := range results {
+		switch results[i].Status {
+		case productDomain.BulkStatusSucceeded:
+			result.Succeeded++
+		case productDomain.BulkStatusFailed:
+			result.Failed++
+		case "":
+			results[i].Status = productDomain.BulkStatusSkipped
+		}
+	}
+
+	return result, nil
+}
+
+// applyBulkOperation runs one bulk operation through the regular service
+// method and returns the affected product ID
+func (s *ProductServiceImpl) applyBulkOperation(ctx context.Context, op productDomain.BulkOperation) (int64, error) {
+	switch {
+	case op.Create != nil:
+		created, err := s.CreateProduct(ctx, *op.Create)
+		return created.ID, err
+	case op.Update != nil:
+		return op.Update.ID, s.UpdateProduct(ctx, *op.Update)
+	default:
+		return op.Delete.ID, s.DeleteProduct(ctx, op.Delete.ID, nil)
+	}
+}
+
+// setBulkFailure records err on a bulk result. Unexpected errors are reported
+// generically, their details only go to the log.
+func setBulkFailure(result *productDomain.BulkOperationResult, err error) {
+	result.Status = productDomain.BulkStatusFailed
+	result.ErrorCode, result.Error, result.Details = describeFailure(err)
+	if result.ErrorCode == "INTERNAL_ERROR" {
+		log.Printf("Bulk operation %d failed: %v", result.Index, err)
+	}
 }
 
-// DeleteImage implements productDomain.ProductService.
+// describeFailure maps the error of a single product write to the error code,
+// message and field details reported for it in a batch
+func describeFailure(err error) (code, message string, details map[string]string) {
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrs):
+		return "VALIDATION_ERROR", "Validation failed", validationErrs.ToMap()
+	case errors.Is(err, productDomain.ErrProductNotFound):
+		return "NOT_FOUND", "Product not found", nil
+	case errors.Is(err, productDomain.ErrProductSKUExists):
+		return "SKU_EXISTS", "Product with this SKU already exists", nil
+	case errors.Is(err, categoryDomain.ErrCategoryNotFound):
+		return "CATEGORY_NOT_FOUND", "Category not found", nil
+	case errors.Is(err, categoryDomain.ErrCategoryAmbiguous):
+		return "CATEGORY_AMBIGUOUS", "Category name matches more than one category", nil
+	case errors.Is(err, inventoryDomain.ErrInsufficientStock):
+		return "INSUFFICIENT_STOCK", "Insufficient stock for this movement", nil
+	default:
+		return "INTERNAL_ERROR", "An unexpected error occurred", nil
+	}
+}
+
+// DeleteImage deletes the product's primary image. The next image of the
+// gallery, if any, becomes the primary image.
 func (s *ProductServiceImpl) DeleteImage(ctx context.Context, id int64) error {
 	// Get product to check if image exists
 	product, err := s.repository.GetByID(ctx, id)
@@ -278,38 +635,40 @@ func (s *ProductServiceImpl) DeleteImage(ctx context.Context, id int64) error {
 	}
 
 	if product.ImageURL == nil || *product.ImageURL == "" {
-		return fmt.Errorf("product has no image to delete")
+		return productDomain.ErrImageNotFound
 	}
 
-	// Extract relative path from URL if it's a full URL
-	imagePath := *product.ImageURL
-	if strings.HasPrefix(imagePath, "http://") || strings.HasPrefix(imagePath, "https://") {
-		// It's a full URL, extract the path after /uploads/
-		parts := strings.Split(imagePath, "/uploads/")
-		if len(parts) > 1 {
-			imagePath = parts[1]
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var next *productDomain.ProductImage
+		if primary := primaryImage(product.Images); primary != nil {
+			if err := s.imageRepository.Delete(ctx, id, primary.ID); err != nil {
+				return fmt.Errorf("failed to delete primary product image: %w", err)
+			}
+			next, err = s.promoteNextImage(ctx, id, product.Images, primary.ID)
+			if err != nil {
+				return err
+			}
+		}
+		if err := s.syncPrimaryImage(ctx, id, next); err != nil {
+			return err
 		}
-	}
-
-	// Delete the physical file
-	if err := s.fileService.DeleteFile(ctx, imagePath); err != nil {
-		return fmt.Errorf("failed to delete image file: %w", err)
-	}
-
-	// Update product to remove image URL
-	emptyString := ""
-	updateReq := productDomain.UpdateProductRequest{
-		ID:       id,
-		ImageURL: &emptyString,
-	}
 
-	if err := s.repository.Update(ctx, updateReq); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return productDomain.ErrProductNotFound
+		updatedProduct, err := s.repository.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get updated product: %w", err)
 		}
-		return fmt.Errorf("failed to update product image URL: %w", err)
+		if err := s.recordAudit(ctx, id, auditDomain.ActionImageDelete, &product, &updatedProduct); err != nil {
+			return err
+		}
+		return s.recordRevision(ctx, updatedProduct)
+	})
+	if err != nil {
+		return err
 	}
 
+	// The files go once the product no longer points at them
+	s.deleteImageFiles(ctx, productDomain.ImageFileURLs(product.ImageURL, product.ImageVariants))
+
 	return nil
 }
 
@@ -324,38 +683,291 @@ func (s *ProductServiceImpl) ListProducts(ctx context.Context, filter productDom
 
 	var productResponses []productDomain.ProductResponse
 	for _, p := range products {
-		productResponses = append(productResponses, productDomain.ProductResponse{
-			ID:          p.ID,
-			SKU:         p.SKU,
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-			Stock:       p.Stock,
-			Category:    p.Category,
-			Status:      p.Status,
-			ImageURL: func() *string {
-				if p.ImageURL == nil || *p.ImageURL == "" {
-					return nil
-				}
-				return p.ImageURL
-			}(),
-			CreatedAt: p.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt: p.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		productResponses = append(productResponses, toProductResponse(p))
+	}
+
+	result := productDomain.ListProductResponse{
+		Page:     filter.Page,
+		Limit:    filter.Limit,
+		Products: productResponses,
+	}
+
+	if !filter.SkipCount {
+		totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
+		result.TotalCount = &total
+		result.TotalPages = &totalPages
+	}
+
+	switch {
+	case filter.Position != nil && filter.SkipCount:
+		result.Showing = fmt.Sprintf("Showing %d products", len(productResponses))
+	case filter.Position != nil:
+		result.Showing = fmt.Sprintf("Showing %d of %d products", len(productResponses), total)
+	default:
+		startIdx := (filter.Page-1)*filter.Limit + 1
+		endIdx := startIdx + len(productResponses) - 1
+		if filter.SkipCount {
+			result.Showing = fmt.Sprintf("Showing %d to %d", startIdx, endIdx)
+		} else {
+			result.Showing = fmt.Sprintf("Showing %d to %d of %d products", startIdx, endIdx, total)
+		}
+	}
+
+	result.NextCursor, result.PrevCursor = listCursors(filter, products, total)
+
+	if len(filter.Facets) > 0 {
+		facets, err := s.repository.GetFacets(ctx, filter)
+		if err != nil {
+			return productDomain.ListProductResponse{}, fmt.Errorf("failed to count product facets: %w", err)
+		}
+		result.Facets = toFacetsResponse(facets)
+	}
+
+	return result, nil
+}
+
+// listCursors returns the cursors for the pages around a listed page. Without
+// a count, a full page is taken to mean more rows follow, so the last page may
+// be followed by an empty one.
+func listCursors(filter productDomain.ListProductFilter, products []productDomain.Product, total int64) (next, prev *string) {
+	if len(products) == 0 {
+		return nil, nil
+	}
+
+	cursorAt := func(p productDomain.Product, backward bool) *string {
+		encoded := productDomain.Cursor{
+			SortBy:    filter.SortBy,
+			SortOrder: filter.SortOrder,
+			Value:     p.SortKey,
+			ID:        p.ID,
+			Backward:  backward,
+		}.Encode()
+		return &encoded
+	}
+	first, last := products[0], products[len(products)-1]
+	full := len(products) == filter.Limit
+
+	switch {
+	case filter.Position == nil:
+		hasNext := full
+		if !filter.SkipCount {
+			hasNext = int64(filter.Page*filter.Limit) < total
+		}
+		if hasNext {
+			next = cursorAt(last, false)
+		}
+		if filter.Page > 1 {
+			prev = cursorAt(first, true)
+		}
+	case filter.Position.Backward:
+		next = cursorAt(last, false)
+		if full {
+			prev = cursorAt(first, true)
+		}
+	default:
+		if full {
+			next = cursorAt(last, false)
+		}
+		prev = cursorAt(first, true)
+	}
+
+	return next, prev
+}
+
+// SuggestProducts runs the lookup in a transaction so the lowered similarity
+// threshold stays on the connection that runs the query.
+func (s *ProductServiceImpl) SuggestProducts(ctx context.Context, filter productDomain.SuggestProductFilter) ([]productDomain.ProductSuggestionResponse, error) {
+	var suggestions []productDomain.ProductSuggestion
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		suggestions, err = s.repository.Suggest(ctx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest products: %w", err)
+	}
+
+	suggestionResponses := []productDomain.ProductSuggestionResponse{}
+	for _, sg := range suggestions {
+		suggestionResponses = append(suggestionResponses, productDomain.ProductSuggestionResponse{
+			ID:    sg.ID,
+			SKU:   sg.SKU,
+			Name:  sg.Name,
+			Score: sg.Score,
 		})
 	}
 
-	totalPages := (total + int64(filter.Limit) - 1) / int64(filter.Limit)
+	return suggestionResponses, nil
+}
+
+// resolveCategoryID checks that the category exists. Without an ID the category
+// is looked up by its stored name, which keeps clients that still send a
+// category name working; a name shared by several categories is refused, and
+// can be told apart with its full path from the top level, e.g. "Tools/Power Tools".
+func (s *ProductServiceImpl) resolveCategoryID(ctx context.Context, id *int64, name string) (int64, error) {
+	if id == nil {
+		return s.resolveCategoryName(ctx, name)
+	}
 
-	startIdx := (filter.Page-1)*filter.Limit + 1
-	endIdx := startIdx + len(productResponses) - 1
-	showing := fmt.Sprintf("Showing %d to %d of %d products", startIdx, endIdx, total)
+	c, err := s.categoryRepository.GetByID(ctx, *id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, categoryDomain.ErrCategoryNotFound
+		}
+		return 0, fmt.Errorf("failed to get category: %w", err)
+	}
+	return c.ID, nil
+}
 
-	return productDomain.ListProductResponse{
-		TotalCount: total,
-		Page:       filter.Page,
-		Limit:      filter.Limit,
-		TotalPages: int(totalPages),
-		Showing:    showing,
-		Products:   productResponses,
-	}, nil
+func (s *ProductServiceImpl) resolveCategoryName(ctx context.Context, name string) (int64, error) {
+	name = strings.TrimSpace(name)
+	matches, err := s.categoryRepository.GetByName(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	// A name that is not stored as such may be a path of names
+	if len(matches) == 0 && strings.Contains(name, "/") {
+		path := strings.Split(name, "/")
+		for i := range path {
+			path[i] = strings.TrimSpace(path[i])
+		}
+
+		candidates, err := s.categoryRepository.GetByName(ctx, path[len(path)-1])
+		if err != nil {
+			return 0, fmt.Errorf("failed to get category: %w", err)
+		}
+		for _, c := range candidates {
+			ok, err := s.categoryHasPath(ctx, c, path[:len(path)-1])
+			if err != nil {
+				return 0, err
+			}
+			if ok {
+				matches = append(matches, c)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return 0, categoryDomain.ErrCategoryNotFound
+	case 1:
+		return matches[0].ID, nil
+	default:
+		return 0, categoryDomain.ErrCategoryAmbiguous
+	}
+}
+
+// categoryHasPath reports whether the ancestors of c, from the top level down,
+// carry exactly the given names
+func (s *ProductServiceImpl) categoryHasPath(ctx context.Context, c categoryDomain.Category, ancestors []string) (bool, error) {
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if c.ParentID == nil {
+			return false, nil
+		}
+		parent, err := s.categoryRepository.GetByID(ctx, *c.ParentID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get parent category: %w", err)
+		}
+		if !strings.EqualFold(parent.Name, ancestors[i]) {
+			return false, nil
+		}
+		c = parent
+	}
+	return c.ParentID == nil, nil
+}
+
+func toProductResponse(p productDomain.Product) productDomain.ProductResponse {
+	stockByWarehouse := []productDomain.WarehouseStockResponse{}
+	for _, ws := range p.WarehouseStock {
+		stockByWarehouse = append(stockByWarehouse, productDomain.WarehouseStockResponse{
+			WarehouseID:   ws.WarehouseID,
+			WarehouseCode: ws.WarehouseCode,
+			WarehouseName: ws.WarehouseName,
+			Quantity:      ws.Quantity,
+		})
+	}
+
+	return productDomain.ProductResponse{
+		ID:               p.ID,
+		SKU:              p.SKU,
+		Name:             p.Name,
+		Description:      p.Description,
+		Price:            p.Price,
+		Stock:            p.Stock,
+		StockByWarehouse: stockByWarehouse,
+		CategoryID:       p.CategoryID,
+		Category:         p.Category,
+		Status:           p.Status,
+		Version:          p.Version,
+		ImageURL: func() *string {
+			if p.ImageURL == nil || *p.ImageURL == "" {
+				return nil
+			}
+			return p.ImageURL
+		}(),
+		Images:  toImagesResponse(p.ImageVariants),
+		Gallery: toGalleryResponse(p.Images),
+		Highlight: func() *productDomain.SearchHighlight {
+			if p.NameHighlight == nil {
+				return nil
+			}
+			return &productDomain.SearchHighlight{
+				Name:        *p.NameHighlight,
+				Description: p.DescriptionHighlight,
+			}
+		}(),
+		DeletedAt: func() *string {
+			if p.DeletedAt == nil {
+				return nil
+			}
+			deletedAt := p.DeletedAt.Format("2006-01-02T15:04:05Z07:00")
+			return &deletedAt
+		}(),
+		CreatedAt: p.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: p.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// toImagesResponse keys the sizes of a product image by name, nil when the
+// image has none
+func toImagesResponse(variants []productDomain.ImageVariant) map[string]productDomain.ImageResponse {
+	if len(variants) == 0 {
+		return nil
+	}
+	images := make(map[string]productDomain.ImageResponse, len(variants))
+	for _, v := range variants {
+		images[v.Name] = productDomain.ImageResponse{
+			URL:    v.URL,
+			Width:  v.Width,
+			Height: v.Height,
+			Format: v.Format,
+		}
+	}
+	return images
+}
+
+func toFacetsResponse(f productDomain.Facets) *productDomain.FacetsResponse {
+	resp := &productDomain.FacetsResponse{}
+	for _, c := range f.Categories {
+		resp.Category = append(resp.Category, productDomain.CategoryFacetResponse{
+			CategoryID: c.CategoryID,
+			Category:   c.Category,
+			Count:      c.Count,
+		})
+	}
+	for _, st := range f.Statuses {
+		resp.Status = append(resp.Status, productDomain.StatusFacetResponse{
+			Status: st.Status,
+			Count:  st.Count,
+		})
+	}
+	for _, pr := range f.PriceRanges {
+		resp.PriceRange = append(resp.PriceRange, productDomain.PriceRangeFacetResponse{
+			Min:   pr.Min,
+			Max:   pr.Max,
+			Count: pr.Count,
+		})
+	}
+	return resp
 }
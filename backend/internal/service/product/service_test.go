@@ -1,20 +1,37 @@
 package product
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
 	"io"
 	"mime/multipart"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	auditDomain "github.com/naxumi/bnsp-jwd/internal/domain/audit"
+	categoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/category"
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
 	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/imaging"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/requestctx"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/validator"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
 )
 
 // Mock File implements multipart.File interface
@@ -34,6 +51,19 @@ func NewMockFile(content string) *MockFile {
 	return &MockFile{Reader: strings.NewReader(content)}
 }
 
+// newTestJPEG returns the bytes of a JPEG of the given size
+func newTestJPEG(t *testing.T, width, height int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)), nil))
+	return buf.String()
+}
+
+var testImagePolicy = imaging.Policy{
+	AllowedFormats: []string{imaging.FormatJPEG, imaging.FormatPNG, imaging.FormatGIF, imaging.FormatWebP},
+	MaxPixels:      4000000,
+}
+
 // Mock Repository
 type MockProductRepository struct {
 	mock.Mock
@@ -49,6 +79,11 @@ func (m *MockProductRepository) GetByID(ctx context.Context, id int64) (productD
 	return args.Get(0).(productDomain.Product), args.Error(1)
 }
 
+func (m *MockProductRepository) GetByIDForUpdate(ctx context.Context, id int64) (productDomain.Product, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(productDomain.Product), args.Error(1)
+}
+
 func (m *MockProductRepository) GetBySKU(ctx context.Context, sku string) (productDomain.Product, error) {
 	args := m.Called(ctx, sku)
 	return args.Get(0).(productDomain.Product), args.Error(1)
@@ -59,23 +94,249 @@ func (m *MockProductRepository) GetAll(ctx context.Context, filter productDomain
 	return args.Get(0).([]productDomain.Product), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockProductRepository) Export(ctx context.Context, filter productDomain.ListProductFilter, fn func(productDomain.Product) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) GetFacets(ctx context.Context, filter productDomain.ListProductFilter) (productDomain.Facets, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(productDomain.Facets), args.Error(1)
+}
+
+func (m *MockProductRepository) Suggest(ctx context.Context, filter productDomain.SuggestProductFilter) ([]productDomain.ProductSuggestion, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]productDomain.ProductSuggestion), args.Error(1)
+}
+
 func (m *MockProductRepository) Update(ctx context.Context, product productDomain.UpdateProductRequest) error {
 	args := m.Called(ctx, product)
 	return args.Error(0)
 }
 
-func (m *MockProductRepository) Delete(ctx context.Context, id int64) error {
+func (m *MockProductRepository) Delete(ctx context.Context, id int64, expectedVersion *int) error {
+	args := m.Called(ctx, id, expectedVersion)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Restore(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Purge(ctx context.Context, before time.Time) (int64, []string, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Get(1).([]string), args.Error(2)
+}
+
+// Mock Category Repository
+type MockCategoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockCategoryRepository) Create(ctx context.Context, category categoryDomain.Category) (categoryDomain.Category, error) {
+	args := m.Called(ctx, category)
+	return args.Get(0).(categoryDomain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetByID(ctx context.Context, id int64) (categoryDomain.Category, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(categoryDomain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetBySlug(ctx context.Context, slug string) (categoryDomain.Category, error) {
+	args := m.Called(ctx, slug)
+	return args.Get(0).(categoryDomain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetByName(ctx context.Context, name string) ([]categoryDomain.Category, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).([]categoryDomain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetAll(ctx context.Context) ([]categoryDomain.Category, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]categoryDomain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Update(ctx context.Context, category categoryDomain.UpdateCategoryRequest) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) Delete(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockCategoryRepository) IsDescendant(ctx context.Context, id int64, candidateID int64) (bool, error) {
+	args := m.Called(ctx, id, candidateID)
+	return args.Bool(0), args.Error(1)
+}
+
+// Mock Transactor runs the function directly, without a database
+type MockTransactor struct{}
+
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// Mock Audit Service keeps the recorded entries for assertions
+type MockAuditService struct {
+	entries []auditDomain.Entry
+}
+
+func (m *MockAuditService) Record(ctx context.Context, entry auditDomain.Entry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *MockAuditService) ListRecords(ctx context.Context, filter auditDomain.ListAuditFilter) (auditDomain.ListAuditResponse, error) {
+	return auditDomain.ListAuditResponse{}, nil
+}
+
+// Mock Product Revision Repository keeps the revisions in memory
+type MockProductRevisionRepository struct {
+	revisions []productDomain.ProductRevision
+}
+
+func (m *MockProductRevisionRepository) Create(ctx context.Context, revision productDomain.ProductRevision) (productDomain.ProductRevision, error) {
+	revision.ID = int64(len(m.revisions) + 1)
+	m.revisions = append(m.revisions, revision)
+	return revision, nil
+}
+
+func (m *MockProductRevisionRepository) Get(ctx context.Context, productID int64, revision int) (productDomain.ProductRevision, error) {
+	for _, r := range m.revisions {
+		if r.ProductID == productID && r.Revision == revision {
+			return r, nil
+		}
+	}
+	return productDomain.ProductRevision{}, pgx.ErrNoRows
+}
+
+func (m *MockProductRevisionRepository) List(ctx context.Context, filter productDomain.ListProductRevisionFilter) ([]productDomain.ProductRevision, int64, error) {
+	var revisions []productDomain.ProductRevision
+	for i := len(m.revisions) - 1; i >= 0; i-- {
+		if m.revisions[i].ProductID == filter.ProductID {
+			revisions = append(revisions, m.revisions[i])
+		}
+	}
+	return revisions, int64(len(revisions)), nil
+}
+
+// Mock Inventory Service
+type MockInventoryService struct {
+	mock.Mock
+}
+
+func (m *MockInventoryService) PostMovement(ctx context.Context, req inventoryDomain.CreateStockMovementRequest) (inventoryDomain.StockMovementResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(inventoryDomain.StockMovementResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) CorrectStock(ctx context.Context, productID int64, target int) error {
+	args := m.Called(ctx, productID, target)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) ListMovements(ctx context.Context, filter inventoryDomain.ListStockMovementFilter) (inventoryDomain.ListStockMovementResponse, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(inventoryDomain.ListStockMovementResponse), args.Error(1)
+}
+
 // Mock File Service
+// MockProductImageRepository keeps galleries in memory
+type MockProductImageRepository struct {
+	images []productDomain.ProductImage
+}
+
+func (m *MockProductImageRepository) Create(ctx context.Context, image productDomain.ProductImage) (productDomain.ProductImage, error) {
+	image.ID = int64(100 + len(m.images))
+	for _, existing := range m.images {
+		if existing.ProductID == image.ProductID && existing.Position >= image.Position {
+			image.Position = existing.Position + 1
+		}
+	}
+	m.images = append(m.images, image)
+	return image, nil
+}
+
+func (m *MockProductImageRepository) find(productID, id int64) *productDomain.ProductImage {
+	for i := range m.images {
+		if m.images[i].ProductID == productID && m.images[i].ID == id {
+			return &m.images[i]
+		}
+	}
+	return nil
+}
+
+func (m *MockProductImageRepository) Get(ctx context.Context, productID, id int64) (productDomain.ProductImage, error) {
+	if image := m.find(productID, id); image != nil {
+		return *image, nil
+	}
+	return productDomain.ProductImage{}, pgx.ErrNoRows
+}
+
+func (m *MockProductImageRepository) List(ctx context.Context, productID int64) ([]productDomain.ProductImage, error) {
+	images := []productDomain.ProductImage{}
+	for _, image := range m.images {
+		if image.ProductID == productID {
+			images = append(images, image)
+		}
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Position < images[j].Position })
+	return images, nil
+}
+
+func (m *MockProductImageRepository) UpdateAltText(ctx context.Context, productID, id int64, altText *string) error {
+	image := m.find(productID, id)
+	if image == nil {
+		return pgx.ErrNoRows
+	}
+	image.AltText = altText
+	return nil
+}
+
+func (m *MockProductImageRepository) SetPrimary(ctx context.Context, productID, id int64) error {
+	if m.find(productID, id) == nil {
+		return pgx.ErrNoRows
+	}
+	for i := range m.images {
+		if m.images[i].ProductID == productID {
+			m.images[i].IsPrimary = m.images[i].ID == id
+		}
+	}
+	return nil
+}
+
+func (m *MockProductImageRepository) Reorder(ctx context.Context, productID int64, ids []int64) error {
+	for position, id := range ids {
+		image := m.find(productID, id)
+		if image == nil {
+			return pgx.ErrNoRows
+		}
+		image.Position = position
+	}
+	return nil
+}
+
+func (m *MockProductImageRepository) Delete(ctx context.Context, productID, id int64) error {
+	for i := range m.images {
+		if m.images[i].ProductID == productID && m.images[i].ID == id {
+			m.images = append(m.images[:i], m.images[i+1:]...)
+			return nil
+		}
+	}
+	return pgx.ErrNoRows
+}
+
 type MockFileService struct {
 	mock.Mock
 }
 
-func (m *MockFileService) UploadProductImage(ctx context.Context, productID string, file io.Reader, filename string) (string, error) {
-	args := m.Called(ctx, productID, file, filename)
+func (m *MockFileService) UploadProductImage(ctx context.Context, productID string, file io.Reader, format string) (string, error) {
+	args := m.Called(ctx, productID, file, format)
 	return args.String(0), args.Error(1)
 }
 
@@ -117,11 +378,18 @@ func (m *MockFileService) GetFileURL(ctx context.Context, path string, expiry ti
 // Tests for CreateProduct
 func TestProductService_CreateProduct_Success(t *testing.T) {
 	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockInventoryService := new(MockInventoryService)
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+		inventoryService:   mockInventoryService,
+		fileService:        mockFileService,
 	}
 
 	req := productDomain.CreateProductRequest{
@@ -133,23 +401,43 @@ func TestProductService_CreateProduct_Success(t *testing.T) {
 		Status:   productDomain.ProductStatusActive,
 	}
 
+	// The legacy category name is resolved through its stored name
+	mockCategoryRepo.On("GetByName", mock.Anything, "Electronics").
+		Return([]categoryDomain.Category{{ID: 3, Name: "Electronics", Slug: "electronics"}}, nil)
+
 	now := time.Now()
 	expectedProduct := productDomain.Product{
-		ID:        1,
-		SKU:       req.SKU,
-		Name:      req.Name,
-		Price:     req.Price,
-		Stock:     req.Stock,
-		Category:  req.Category,
-		Status:    req.Status,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:         1,
+		SKU:        req.SKU,
+		Name:       req.Name,
+		Price:      req.Price,
+		CategoryID: 3,
+		Category:   req.Category,
+		Status:     req.Status,
+		CreatedAt:  now,
+		UpdatedAt:  now,
 	}
 
+	// Product is inserted without stock, the opening balance goes through the ledger
 	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(p productDomain.Product) bool {
-		return p.SKU == req.SKU && p.Name == req.Name && p.Price.Equal(req.Price)
+		return p.SKU == req.SKU && p.Name == req.Name && p.Price.Equal(req.Price) && p.Stock == 0 && p.CategoryID == 3
 	})).Return(expectedProduct, nil)
 
+	mockInventoryService.On("PostMovement", mock.Anything, mock.MatchedBy(func(m inventoryDomain.CreateStockMovementRequest) bool {
+		return m.ProductID == 1 &&
+			m.Type == inventoryDomain.MovementTypeReceipt &&
+			m.ReasonCode == inventoryDomain.ReasonOpeningBalance &&
+			m.Quantity == 100
+	})).Return(inventoryDomain.StockMovementResponse{ProductID: 1, Quantity: 100, BalanceAfter: 100}, nil)
+
+	stockedProduct := expectedProduct
+	stockedProduct.Stock = 100
+	stockedProduct.WarehouseStock = []productDomain.WarehouseStock{
+		{WarehouseID: 1, WarehouseCode: "MAIN", WarehouseName: "Main Warehouse", Quantity: 100},
+	}
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(stockedProduct, nil)
+
 	result, err := service.CreateProduct(context.Background(), req)
 
 	assert.NoError(t, err)
@@ -157,16 +445,63 @@ func TestProductService_CreateProduct_Success(t *testing.T) {
 	assert.Equal(t, expectedProduct.SKU, result.SKU)
 	assert.Equal(t, expectedProduct.Name, result.Name)
 	assert.True(t, expectedProduct.Price.Equal(result.Price))
+	assert.Equal(t, 100, result.Stock)
+	assert.Len(t, result.StockByWarehouse, 1)
+	assert.Equal(t, "MAIN", result.StockByWarehouse[0].WarehouseCode)
 	mockRepo.AssertExpectations(t)
+	mockInventoryService.AssertExpectations(t)
+}
+
+func TestProductService_CreateProduct_WithoutStock(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockInventoryService := new(MockInventoryService)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+		inventoryService:   mockInventoryService,
+		fileService:        mockFileService,
+	}
+
+	req := productDomain.CreateProductRequest{
+		SKU:      "TEST-SKU-002",
+		Name:     "Test Product",
+		Price:    decimal.NewFromInt(10000),
+		Category: "Electronics",
+		Status:   productDomain.ProductStatusActive,
+	}
+
+	// The legacy category name is resolved through its stored name
+	mockCategoryRepo.On("GetByName", mock.Anything, "Electronics").
+		Return([]categoryDomain.Category{{ID: 3, Name: "Electronics", Slug: "electronics"}}, nil)
+
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(productDomain.Product{ID: 2, SKU: req.SKU}, nil)
+
+	result, err := service.CreateProduct(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Stock)
+	mockInventoryService.AssertNotCalled(t, "PostMovement")
 }
 
 func TestProductService_CreateProduct_RepositoryError(t *testing.T) {
 	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+		fileService:        mockFileService,
 	}
 
 	req := productDomain.CreateProductRequest{
@@ -178,6 +513,10 @@ func TestProductService_CreateProduct_RepositoryError(t *testing.T) {
 		Status:   productDomain.ProductStatusActive,
 	}
 
+	// The legacy category name is resolved through its stored name
+	mockCategoryRepo.On("GetByName", mock.Anything, "Electronics").
+		Return([]categoryDomain.Category{{ID: 3, Name: "Electronics", Slug: "electronics"}}, nil)
+
 	// Simulate repository error
 	mockRepo.On("Create", mock.Anything, mock.Anything).
 		Return(productDomain.Product{}, errors.New("database error"))
@@ -191,11 +530,16 @@ func TestProductService_CreateProduct_RepositoryError(t *testing.T) {
 
 func TestProductService_CreateProduct_DuplicateSKU(t *testing.T) {
 	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+		fileService:        mockFileService,
 	}
 
 	req := productDomain.CreateProductRequest{
@@ -207,6 +551,10 @@ func TestProductService_CreateProduct_DuplicateSKU(t *testing.T) {
 		Status:   productDomain.ProductStatusActive,
 	}
 
+	// The legacy category name is resolved through its stored name
+	mockCategoryRepo.On("GetByName", mock.Anything, "Electronics").
+		Return([]categoryDomain.Category{{ID: 3, Name: "Electronics", Slug: "electronics"}}, nil)
+
 	pgErr := &pgconn.PgError{Code: "23505"}
 	mockRepo.On("Create", mock.Anything, mock.Anything).
 		Return(productDomain.Product{}, pgErr)
@@ -218,12 +566,43 @@ func TestProductService_CreateProduct_DuplicateSKU(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_CreateProduct_UnknownCategory(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+	}
+
+	categoryID := int64(42)
+	req := productDomain.CreateProductRequest{
+		SKU:        "TEST-SKU-003",
+		Name:       "Test Product",
+		Price:      decimal.NewFromInt(10000),
+		CategoryID: &categoryID,
+		Status:     productDomain.ProductStatusActive,
+	}
+
+	mockCategoryRepo.On("GetByID", mock.Anything, categoryID).
+		Return(categoryDomain.Category{}, pgx.ErrNoRows)
+
+	_, err := service.CreateProduct(context.Background(), req)
+
+	assert.Equal(t, categoryDomain.ErrCategoryNotFound, err)
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
 // Tests for GetProduct
 func TestProductService_GetProduct_Success(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
+		transactor:  &MockTransactor{},
 		repository:  mockRepo,
 		fileService: mockFileService,
 	}
@@ -257,6 +636,7 @@ func TestProductService_GetProduct_NotFound(t *testing.T) {
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
+		transactor:  &MockTransactor{},
 		repository:  mockRepo,
 		fileService: mockFileService,
 	}
@@ -277,6 +657,7 @@ func TestProductService_GetProductBySKU_Success(t *testing.T) {
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
+		transactor:  &MockTransactor{},
 		repository:  mockRepo,
 		fileService: mockFileService,
 	}
@@ -304,11 +685,35 @@ func TestProductService_GetProductBySKU_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_GetProductBySKU_VariantResolvesToParent(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	parentID := int64(12)
+	mockRepo.On("GetBySKU", mock.Anything, "SKU-012-38-RED").
+		Return(productDomain.Product{ID: 51, ParentID: &parentID, SKU: "SKU-012-38-RED"}, nil)
+	mockRepo.On("GetByID", mock.Anything, parentID).
+		Return(productDomain.Product{ID: 12, SKU: "SKU-012", Name: "Women's Running Shoes"}, nil)
+
+	result, err := service.GetProductBySKU(context.Background(), "SKU-012-38-RED")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), result.ID)
+	assert.Equal(t, "SKU-012", result.SKU)
+	assert.Equal(t, int64(51), *result.MatchedVariantID)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestProductService_GetProductBySKU_NotFound(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
+		transactor:  &MockTransactor{},
 		repository:  mockRepo,
 		fileService: mockFileService,
 	}
@@ -328,9 +733,13 @@ func TestProductService_UpdateProduct_Success(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	mockFileService := new(MockFileService)
 
+	mockAuditService := &MockAuditService{}
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       mockAuditService,
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		fileService:        mockFileService,
 	}
 
 	name := "Updated Product"
@@ -342,13 +751,26 @@ func TestProductService_UpdateProduct_Success(t *testing.T) {
 		Price: &price,
 	}
 
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, SKU: "SKU-001", Name: "Test Product", Price: decimal.NewFromInt(10000)}, nil)
 	mockRepo.On("Update", mock.Anything, req).
 		Return(nil)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, SKU: "SKU-001", Name: name, Price: price}, nil)
 
 	err := service.UpdateProduct(context.Background(), req)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+
+	// Only the changed fields are audited
+	require.Len(t, mockAuditService.entries, 1)
+	entry := mockAuditService.entries[0]
+	assert.Equal(t, auditDomain.ActionUpdate, entry.Action)
+	assert.Equal(t, map[string]auditDomain.Change{
+		"name":  {Before: "Test Product", After: "Updated Product"},
+		"price": {Before: "10000.00", After: "15000.00"},
+	}, entry.Changes)
 }
 
 func TestProductService_UpdateProduct_RepositoryError(t *testing.T) {
@@ -356,8 +778,11 @@ func TestProductService_UpdateProduct_RepositoryError(t *testing.T) {
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		fileService:        mockFileService,
 	}
 
 	name := "Updated Product"
@@ -367,6 +792,8 @@ func TestProductService_UpdateProduct_RepositoryError(t *testing.T) {
 	}
 
 	// Simulate repository error
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1}, nil)
 	mockRepo.On("Update", mock.Anything, req).
 		Return(errors.New("database error"))
 
@@ -382,8 +809,11 @@ func TestProductService_UpdateProduct_NotFound(t *testing.T) {
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		fileService:        mockFileService,
 	}
 
 	name := "Updated Product"
@@ -392,14 +822,42 @@ func TestProductService_UpdateProduct_NotFound(t *testing.T) {
 		Name: &name,
 	}
 
-	mockRepo.On("Update", mock.Anything, req).
-		Return(pgx.ErrNoRows)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(999)).
+		Return(productDomain.Product{}, pgx.ErrNoRows)
 
 	err := service.UpdateProduct(context.Background(), req)
 
 	assert.Error(t, err)
 	assert.Equal(t, productDomain.ErrProductNotFound, err)
-	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestProductService_UpdateProduct_VersionConflict(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+	}
+
+	name := "Updated Product"
+	version := 3
+	req := productDomain.UpdateProductRequest{
+		ID:              1,
+		Name:            &name,
+		ExpectedVersion: &version,
+	}
+
+	// The product has moved on to a newer version
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, Version: 4}, nil)
+
+	err := service.UpdateProduct(context.Background(), req)
+
+	assert.Equal(t, productDomain.ErrVersionConflict, err)
+	mockRepo.AssertNotCalled(t, "Update")
 }
 
 func TestProductService_UpdateProduct_DuplicateSKU(t *testing.T) {
@@ -407,8 +865,11 @@ func TestProductService_UpdateProduct_DuplicateSKU(t *testing.T) {
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		fileService:        mockFileService,
 	}
 
 	sku := "DUPLICATE-SKU"
@@ -418,6 +879,8 @@ func TestProductService_UpdateProduct_DuplicateSKU(t *testing.T) {
 	}
 
 	pgErr := &pgconn.PgError{Code: "23505"}
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1}, nil)
 	mockRepo.On("Update", mock.Anything, req).
 		Return(pgErr)
 
@@ -428,201 +891,382 @@ func TestProductService_UpdateProduct_DuplicateSKU(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-// Tests for DeleteProduct
-func TestProductService_DeleteProduct_Success(t *testing.T) {
+func TestProductService_UpdateProduct_StockGoesThroughLedger(t *testing.T) {
 	mockRepo := new(MockProductRepository)
+	mockInventoryService := new(MockInventoryService)
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		inventoryService:   mockInventoryService,
+		fileService:        mockFileService,
 	}
 
-	now := time.Now()
-	product := productDomain.Product{
-		ID:        1,
-		SKU:       "TEST-SKU-001",
-		Name:      "Test Product",
-		Price:     decimal.NewFromInt(10000),
-		Stock:     100,
-		Category:  "Electronics",
-		Status:    productDomain.ProductStatusActive,
-		ImageURL:  nil, // No image
-		CreatedAt: now,
-		UpdatedAt: now,
+	name := "Updated Product"
+	stock := 40
+	req := productDomain.UpdateProductRequest{
+		ID:    1,
+		Name:  &name,
+		Stock: &stock,
 	}
 
-	mockRepo.On("GetByID", mock.Anything, int64(1)).
-		Return(product, nil)
-	mockRepo.On("Delete", mock.Anything, int64(1)).
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, Stock: 25}, nil)
+	mockInventoryService.On("CorrectStock", mock.Anything, int64(1), 40).
 		Return(nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(r productDomain.UpdateProductRequest) bool {
+		return r.ID == 1 && r.Name != nil && *r.Name == name && r.Stock == nil
+	})).Return(nil)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, Name: name, Stock: 40}, nil)
 
-	err := service.DeleteProduct(context.Background(), 1)
+	err := service.UpdateProduct(context.Background(), req)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockInventoryService.AssertExpectations(t)
 }
 
-func TestProductService_DeleteProduct_WithImage(t *testing.T) {
+func TestProductService_UpdateProduct_PriceChangeForbidden(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		transactor: &MockTransactor{},
+		repository: mockRepo,
 	}
 
-	imageURL := "http://localhost:8080/uploads/products/1/image.jpg"
-	now := time.Now()
-	product := productDomain.Product{
-		ID:        1,
-		SKU:       "TEST-SKU-001",
-		Name:      "Test Product",
-		Price:     decimal.NewFromInt(10000),
-		Stock:     100,
-		Category:  "Electronics",
-		Status:    productDomain.ProductStatusActive,
-		ImageURL:  &imageURL,
-		CreatedAt: now,
-		UpdatedAt: now,
+	// The price is compared with the locked row, not an earlier read
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, Price: decimal.NewFromInt(12000)}, nil)
+
+	price := decimal.NewFromInt(10000)
+	ctx := withPriceCheck(contextWithRole(auth.RoleStaff))
+	err := service.UpdateProduct(ctx, productDomain.UpdateProductRequest{ID: 1, Price: &price})
+
+	var permissionErr *auth.PermissionError
+	assert.ErrorAs(t, err, &permissionErr)
+	assert.Equal(t, auth.PermProductPrice, permissionErr.Permission)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestProductService_UpdateProduct_UnchangedPriceAllowed(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
 	}
 
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, Price: decimal.NewFromInt(10000)}, nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
 	mockRepo.On("GetByID", mock.Anything, int64(1)).
-		Return(product, nil)
-	mockRepo.On("Delete", mock.Anything, int64(1)).
-		Return(nil)
-	mockFileService.On("DeleteFile", mock.Anything, mock.Anything).
-		Return(nil)
+		Return(productDomain.Product{ID: 1, Price: decimal.NewFromInt(10000)}, nil)
 
-	err := service.DeleteProduct(context.Background(), 1)
+	name := "Renamed"
+	price := decimal.RequireFromString("10000.00")
+	ctx := withPriceCheck(contextWithRole(auth.RoleStaff))
+	err := service.UpdateProduct(ctx, productDomain.UpdateProductRequest{ID: 1, Name: &name, Price: &price})
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
-	mockFileService.AssertExpectations(t)
 }
 
-func TestProductService_DeleteProduct_NotFound(t *testing.T) {
+func TestProductService_UpdateProduct_StockCorrectionFails(t *testing.T) {
 	mockRepo := new(MockProductRepository)
+	mockInventoryService := new(MockInventoryService)
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		inventoryService:   mockInventoryService,
+		fileService:        mockFileService,
 	}
 
-	mockRepo.On("GetByID", mock.Anything, int64(999)).
-		Return(productDomain.Product{}, pgx.ErrNoRows)
-
-	err := service.DeleteProduct(context.Background(), 999)
+	stock := 5
+	req := productDomain.UpdateProductRequest{
+		ID:    999,
+		Stock: &stock,
+	}
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(999)).
+		Return(productDomain.Product{ID: 999}, nil)
+	mockInventoryService.On("CorrectStock", mock.Anything, int64(999), 5).
+		Return(productDomain.ErrProductNotFound)
+
+	err := service.UpdateProduct(context.Background(), req)
 
-	assert.Error(t, err)
 	assert.Equal(t, productDomain.ErrProductNotFound, err)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestProductService_UpdateProduct_CategoryByName(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+	}
+
+	category := "Home & Garden"
+	req := productDomain.UpdateProductRequest{
+		ID:       1,
+		Category: &category,
+	}
+
+	mockCategoryRepo.On("GetByName", mock.Anything, "Home & Garden").
+		Return([]categoryDomain.Category{{ID: 7, Name: "Home & Garden", Slug: "home-and-garden"}}, nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, CategoryID: 3}, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u productDomain.UpdateProductRequest) bool {
+		return u.CategoryID != nil && *u.CategoryID == 7 && u.Category == nil
+	})).Return(nil)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, CategoryID: 7}, nil)
+
+	err := service.UpdateProduct(context.Background(), req)
+
+	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
-	mockRepo.AssertNotCalled(t, "Delete")
 }
 
-// Tests for ListProducts
-func TestProductService_ListProducts_Success(t *testing.T) {
+func TestProductService_UpdateProduct_ChildCategoryByName(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	mockFileService := new(MockFileService)
+	mockCategoryRepo := new(MockCategoryRepository)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
 	}
 
-	filter := productDomain.ListProductFilter{
-		Page:      1,
-		Limit:     10,
-		SortBy:    "created_at",
-		SortOrder: "desc",
+	category := "power tools"
+	req := productDomain.UpdateProductRequest{
+		ID:       1,
+		Category: &category,
 	}
 
-	now := time.Now()
-	products := []productDomain.Product{
-		{
-			ID:        1,
-			SKU:       "SKU-001",
-			Name:      "Product 1",
-			Price:     decimal.NewFromInt(10000),
-			Stock:     100,
-			Category:  "Electronics",
-			Status:    productDomain.ProductStatusActive,
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-		{
-			ID:        2,
-			SKU:       "SKU-002",
-			Name:      "Product 2",
-			Price:     decimal.NewFromInt(20000),
-			Stock:     50,
-			Category:  "Electronics",
-			Status:    productDomain.ProductStatusActive,
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
+	// The slug of a subcategory carries its parent's, the name does not
+	tools := int64(2)
+	mockCategoryRepo.On("GetByName", mock.Anything, "power tools").
+		Return([]categoryDomain.Category{{ID: 5, ParentID: &tools, Name: "Power Tools", Slug: "tools-power-tools"}}, nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, CategoryID: 3}, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u productDomain.UpdateProductRequest) bool {
+		return u.CategoryID != nil && *u.CategoryID == 5
+	})).Return(nil)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, CategoryID: 5}, nil)
+
+	err := service.UpdateProduct(context.Background(), req)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_UpdateProduct_CategoryByPath(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
 	}
 
-	mockRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(f productDomain.ListProductFilter) bool {
-		return f.Page == 1 && f.Limit == 10
-	})).Return(products, int64(2), nil)
+	category := "Garden / Tools"
+	req := productDomain.UpdateProductRequest{
+		ID:       1,
+		Category: &category,
+	}
 
-	result, err := service.ListProducts(context.Background(), filter)
+	garden, kitchen := int64(1), int64(4)
+	mockCategoryRepo.On("GetByName", mock.Anything, "Garden / Tools").
+		Return([]categoryDomain.Category{}, nil)
+	mockCategoryRepo.On("GetByName", mock.Anything, "Tools").
+		Return([]categoryDomain.Category{
+			{ID: 2, ParentID: &garden, Name: "Tools", Slug: "garden-tools"},
+			{ID: 6, ParentID: &kitchen, Name: "Tools", Slug: "kitchen-tools"},
+		}, nil)
+	mockCategoryRepo.On("GetByID", mock.Anything, garden).
+		Return(categoryDomain.Category{ID: 1, Name: "Garden", Slug: "garden"}, nil)
+	mockCategoryRepo.On("GetByID", mock.Anything, kitchen).
+		Return(categoryDomain.Category{ID: 4, Name: "Kitchen", Slug: "kitchen"}, nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, CategoryID: 3}, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u productDomain.UpdateProductRequest) bool {
+		return u.CategoryID != nil && *u.CategoryID == 2
+	})).Return(nil)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, CategoryID: 2}, nil)
+
+	err := service.UpdateProduct(context.Background(), req)
 
 	assert.NoError(t, err)
-	assert.Equal(t, int64(2), result.TotalCount)
-	assert.Len(t, result.Products, 2)
-	assert.Equal(t, "SKU-001", result.Products[0].SKU)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestProductService_ListProducts_RepositoryError(t *testing.T) {
+func TestProductService_UpdateProduct_AmbiguousCategoryName(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	mockFileService := new(MockFileService)
+	mockCategoryRepo := new(MockCategoryRepository)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
 	}
 
-	filter := productDomain.ListProductFilter{
-		Page:      1,
-		Limit:     10,
-		SortBy:    "created_at",
-		SortOrder: "desc",
+	category := "Tools"
+	req := productDomain.UpdateProductRequest{
+		ID:       1,
+		Category: &category,
 	}
 
-	// Simulate repository error
-	mockRepo.On("GetAll", mock.Anything, mock.Anything).
-		Return([]productDomain.Product{}, int64(0), errors.New("database error"))
+	garden, kitchen := int64(1), int64(4)
+	mockCategoryRepo.On("GetByName", mock.Anything, "Tools").
+		Return([]categoryDomain.Category{
+			{ID: 2, ParentID: &garden, Name: "Tools", Slug: "garden-tools"},
+			{ID: 6, ParentID: &kitchen, Name: "Tools", Slug: "kitchen-tools"},
+		}, nil)
 
-	_, err := service.ListProducts(context.Background(), filter)
+	err := service.UpdateProduct(context.Background(), req)
 
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to list products")
+	assert.ErrorIs(t, err, categoryDomain.ErrCategoryAmbiguous)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+// Tests for revisions
+func TestProductService_UpdateProduct_RecordsRevision(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	revisionRepo := &MockProductRevisionRepository{}
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: revisionRepo,
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+	}
+
+	price := decimal.NewFromInt(12000)
+	req := productDomain.UpdateProductRequest{ID: 21, Price: &price}
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(21)).
+		Return(productDomain.Product{ID: 21, SKU: "SKU-021", Price: decimal.NewFromInt(10000), Version: 2}, nil)
+	mockRepo.On("Update", mock.Anything, req).
+		Return(nil)
+	mockRepo.On("GetByID", mock.Anything, int64(21)).
+		Return(productDomain.Product{ID: 21, SKU: "SKU-021", Price: price, Version: 3}, nil)
+
+	ctx := requestctx.WithActor(context.Background(), "alice")
+	err := service.UpdateProduct(ctx, req)
+
+	assert.NoError(t, err)
+	require.Len(t, revisionRepo.revisions, 1)
+	revision := revisionRepo.revisions[0]
+	assert.Equal(t, 3, revision.Revision)
+	assert.Equal(t, "alice", revision.Actor)
+	assert.True(t, revision.Snapshot.Price.Equal(price))
+}
+
+func TestProductService_RestoreRevision_AppliesSnapshot(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	revisionRepo := &MockProductRevisionRepository{
+		revisions: []productDomain.ProductRevision{{
+			ProductID: 21,
+			Revision:  2,
+			Snapshot: productDomain.ProductSnapshot{
+				SKU:        "SKU-021",
+				Name:       "Old Name",
+				Price:      decimal.NewFromInt(10000),
+				Stock:      50,
+				CategoryID: 3,
+				Status:     productDomain.ProductStatusActive,
+				Version:    2,
+			},
+		}},
+	}
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: revisionRepo,
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+	}
+
+	version := 5
+	mockCategoryRepo.On("GetByID", mock.Anything, int64(3)).
+		Return(categoryDomain.Category{ID: 3}, nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(21)).
+		Return(productDomain.Product{ID: 21, SKU: "SKU-021", Name: "New Name", Price: decimal.NewFromInt(15000), Stock: 8, Version: 5}, nil)
+	// Stock and image are not part of the restore
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(r productDomain.UpdateProductRequest) bool {
+		return r.ID == 21 && *r.Name == "Old Name" && r.Price.Equal(decimal.NewFromInt(10000)) &&
+			*r.CategoryID == 3 && r.Stock == nil && r.ImageURL == nil && *r.ExpectedVersion == 5
+	})).Return(nil)
+	mockRepo.On("GetByID", mock.Anything, int64(21)).
+		Return(productDomain.Product{ID: 21, SKU: "SKU-021", Name: "Old Name", Price: decimal.NewFromInt(10000), Stock: 8, Version: 6}, nil)
+
+	result, err := service.RestoreRevision(context.Background(), 21, 2, &version)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, result.Version)
+	assert.Equal(t, 8, result.Stock)
 	mockRepo.AssertExpectations(t)
+
+	// The restore is itself the newest revision
+	require.Len(t, revisionRepo.revisions, 2)
+	assert.Equal(t, 6, revisionRepo.revisions[1].Revision)
 }
 
-// Tests for UploadImage
-func TestProductService_UploadImage_Success(t *testing.T) {
+func TestProductService_RestoreRevision_NotFound(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
 	}
 
-	// Create a mock file
-	file := NewMockFile("fake image content")
-	fileHeader := &multipart.FileHeader{
-		Filename: "test.jpg",
-		Size:     100,
+	_, err := service.RestoreRevision(context.Background(), 21, 9, nil)
+
+	assert.Equal(t, productDomain.ErrRevisionNotFound, err)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+// Tests for DeleteProduct
+func TestProductService_DeleteProduct_Success(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+	mockAuditService := &MockAuditService{}
+
+	service := &ProductServiceImpl{
+		auditService:       mockAuditService,
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		fileService:        mockFileService,
 	}
 
 	now := time.Now()
-	existingProduct := productDomain.Product{
+	product := productDomain.Product{
 		ID:        1,
 		SKU:       "TEST-SKU-001",
 		Name:      "Test Product",
@@ -630,52 +1274,40 @@ func TestProductService_UploadImage_Success(t *testing.T) {
 		Stock:     100,
 		Category:  "Electronics",
 		Status:    productDomain.ProductStatusActive,
-		ImageURL:  nil, // No existing image
+		ImageURL:  nil, // No image
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	uploadedPath := "products/1/product-1-image.jpg"
-	fullURL := "http://localhost:8080/uploads/products/1/product-1-image.jpg"
-
-	mockRepo.On("GetByID", mock.Anything, int64(1)).
-		Return(existingProduct, nil)
-
-	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "product-1-image.jpg").
-		Return(uploadedPath, nil)
-
-	mockFileService.On("GetFileURL", mock.Anything, uploadedPath, time.Duration(0)).
-		Return(fullURL, nil)
-
-	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
-		return req.ID == 1 && req.ImageURL != nil && *req.ImageURL == fullURL
-	})).Return(nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(product, nil)
+	mockRepo.On("Delete", mock.Anything, int64(1), (*int)(nil)).
+		Return(nil)
 
-	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+	err := service.DeleteProduct(context.Background(), 1, nil)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
-	mockFileService.AssertExpectations(t)
+	require.Len(t, mockAuditService.entries, 1)
+	assert.Equal(t, auditDomain.ActionDelete, mockAuditService.entries[0].Action)
+	assert.Equal(t, auditDomain.Change{Before: "TEST-SKU-001", After: nil}, mockAuditService.entries[0].Changes["sku"])
 }
 
-func TestProductService_UploadImage_ReplaceExisting(t *testing.T) {
+func TestProductService_DeleteProduct_KeepsImage(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
-	}
-
-	file := NewMockFile("fake image content")
-	fileHeader := &multipart.FileHeader{
-		Filename: "test.jpg",
-		Size:     100,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		fileService:        mockFileService,
 	}
 
-	oldImageURL := "http://localhost:8080/uploads/products/1/old-image.jpg"
+	imageURL := "http://localhost:8080/uploads/products/1/image.jpg"
 	now := time.Now()
-	existingProduct := productDomain.Product{
+	product := productDomain.Product{
 		ID:        1,
 		SKU:       "TEST-SKU-001",
 		Name:      "Test Product",
@@ -683,83 +1315,1633 @@ func TestProductService_UploadImage_ReplaceExisting(t *testing.T) {
 		Stock:     100,
 		Category:  "Electronics",
 		Status:    productDomain.ProductStatusActive,
-		ImageURL:  &oldImageURL, // Has existing image
+		ImageURL:  &imageURL,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	uploadedPath := "products/1/product-1-image.jpg"
-	fullURL := "http://localhost:8080/uploads/products/1/product-1-image.jpg"
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(product, nil)
+	mockRepo.On("Delete", mock.Anything, int64(1), (*int)(nil)).
+		Return(nil)
 
-	mockRepo.On("GetByID", mock.Anything, int64(1)).
-		Return(existingProduct, nil)
+	err := service.DeleteProduct(context.Background(), 1, nil)
 
-	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "product-1-image.jpg").
-		Return(uploadedPath, nil)
+	// The product is only trashed, its image must survive for a restore
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockFileService.AssertNotCalled(t, "DeleteFile")
+}
 
-	mockFileService.On("GetFileURL", mock.Anything, uploadedPath, time.Duration(0)).
-		Return(fullURL, nil)
+func TestProductService_DeleteProduct_VersionConflict(t *testing.T) {
+	mockRepo := new(MockProductRepository)
 
-	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
-		return req.ID == 1 && req.ImageURL != nil && *req.ImageURL == fullURL
-	})).Return(nil)
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+	}
 
-	mockFileService.On("DeleteFile", mock.Anything, "products/1/old-image.jpg").
-		Return(nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, Version: 5}, nil)
 
-	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+	version := 4
+	err := service.DeleteProduct(context.Background(), 1, &version)
 
-	assert.NoError(t, err)
-	mockRepo.AssertExpectations(t)
-	mockFileService.AssertExpectations(t)
+	assert.Equal(t, productDomain.ErrVersionConflict, err)
+	mockRepo.AssertNotCalled(t, "Delete")
 }
 
-func TestProductService_UploadImage_InvalidFileType(t *testing.T) {
+func TestProductService_DeleteProduct_NotFound(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		fileService:        mockFileService,
 	}
 
-	file := NewMockFile("fake file content")
-	fileHeader := &multipart.FileHeader{
-		Filename: "test.pdf", // Invalid file type
-		Size:     100,
-	}
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(999)).
+		Return(productDomain.Product{}, pgx.ErrNoRows)
 
-	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+	err := service.DeleteProduct(context.Background(), 999, nil)
 
 	assert.Error(t, err)
-	assert.Equal(t, productDomain.ErrInvalidImageFormat, err)
-	mockFileService.AssertNotCalled(t, "UploadProductImage")
-	mockRepo.AssertNotCalled(t, "GetByID")
-	mockRepo.AssertNotCalled(t, "Update")
+	assert.Equal(t, productDomain.ErrProductNotFound, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Delete")
 }
 
-func TestProductService_UploadImage_ProductNotFound(t *testing.T) {
+// Tests for the trash
+func TestProductService_ListTrash_OnlyTrashed(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	mockFileService := new(MockFileService)
 
 	service := &ProductServiceImpl{
-		repository:  mockRepo,
-		fileService: mockFileService,
+		transactor: &MockTransactor{},
+		repository: mockRepo,
 	}
 
-	file := NewMockFile("fake image content")
-	fileHeader := &multipart.FileHeader{
-		Filename: "test.jpg",
-		Size:     100,
+	deletedAt := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	filter := productDomain.ListProductFilter{Page: 1, Limit: 20, SortBy: "deleted_at", SortOrder: "desc"}
+
+	mockRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(f productDomain.ListProductFilter) bool {
+		return f.Trashed
+	})).Return([]productDomain.Product{{ID: 4, SKU: "OLD-001", DeletedAt: &deletedAt}}, int64(1), nil)
+
+	result, err := service.ListTrash(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Products, 1)
+	assert.Equal(t, "2026-10-01T09:30:00Z", *result.Products[0].DeletedAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_RestoreProduct_Success(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
 	}
 
-	mockRepo.On("GetByID", mock.Anything, int64(999)).
-		Return(productDomain.Product{}, pgx.ErrNoRows)
+	mockRepo.On("Restore", mock.Anything, int64(4)).
+		Return(nil)
+	mockRepo.On("GetByID", mock.Anything, int64(4)).
+		Return(productDomain.Product{ID: 4, SKU: "OLD-001", Version: 3}, nil)
 
-	err := service.UploadImage(context.Background(), 999, file, fileHeader)
+	result, err := service.RestoreProduct(context.Background(), 4)
 
-	assert.Error(t, err)
-	assert.Equal(t, productDomain.ErrProductNotFound, err)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), result.ID)
+	assert.Nil(t, result.DeletedAt)
 	mockRepo.AssertExpectations(t)
-	mockFileService.AssertNotCalled(t, "UploadProductImage")
+}
+
+func TestProductService_RestoreProduct_NotInTrash(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+	}
+
+	mockRepo.On("Restore", mock.Anything, int64(4)).
+		Return(pgx.ErrNoRows)
+
+	_, err := service.RestoreProduct(context.Background(), 4)
+
+	assert.Equal(t, productDomain.ErrProductNotInTrash, err)
+	mockRepo.AssertNotCalled(t, "GetByID")
+}
+
+func TestProductService_RestoreProduct_SKUTaken(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+	}
+
+	mockRepo.On("Restore", mock.Anything, int64(4)).
+		Return(&pgconn.PgError{Code: "23505"})
+
+	_, err := service.RestoreProduct(context.Background(), 4)
+
+	assert.Equal(t, productDomain.ErrProductSKUExists, err)
+}
+
+func TestProductService_PurgeTrash_DeletesFilesAfterRetention(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		transactor:     &MockTransactor{},
+		repository:     mockRepo,
+		fileService:    mockFileService,
+		trashRetention: 30 * 24 * time.Hour,
+	}
+
+	start := time.Now()
+	mockRepo.On("Purge", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		cutoff := start.Add(-30 * 24 * time.Hour)
+		return !before.Before(cutoff) && before.Sub(cutoff) < time.Minute
+	})).Return(int64(3), []string{"products/4/photo.jpg", "http://localhost:8080/uploads/products/5/photo.jpg"}, nil)
+	mockFileService.On("DeleteFile", mock.Anything, "products/4/photo.jpg").
+		Return(nil)
+	mockFileService.On("DeleteFile", mock.Anything, "products/5/photo.jpg").
+		Return(nil)
+
+	result, err := service.PurgeTrash(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), result.Purged)
+	mockRepo.AssertExpectations(t)
+	mockFileService.AssertExpectations(t)
+}
+
+func TestProductService_PurgeTrash_KeepsFilesOnFailure(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		transactor:  &MockTransactor{},
+		repository:  mockRepo,
+		fileService: mockFileService,
+	}
+
+	mockRepo.On("Purge", mock.Anything, mock.Anything).
+		Return(int64(0), []string(nil), errors.New("database error"))
+
+	_, err := service.PurgeTrash(context.Background())
+
+	assert.Error(t, err)
+	mockFileService.AssertNotCalled(t, "DeleteFile")
+}
+
+// Tests for ListProducts
+func TestProductService_ListProducts_Success(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		transactor:  &MockTransactor{},
+		repository:  mockRepo,
+		fileService: mockFileService,
+	}
+
+	filter := productDomain.ListProductFilter{
+		Page:      1,
+		Limit:     10,
+		SortBy:    "created_at",
+		SortOrder: "desc",
+	}
+
+	now := time.Now()
+	products := []productDomain.Product{
+		{
+			ID:        1,
+			SKU:       "SKU-001",
+			Name:      "Product 1",
+			Price:     decimal.NewFromInt(10000),
+			Stock:     100,
+			Category:  "Electronics",
+			Status:    productDomain.ProductStatusActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		{
+			ID:        2,
+			SKU:       "SKU-002",
+			Name:      "Product 2",
+			Price:     decimal.NewFromInt(20000),
+			Stock:     50,
+			Category:  "Electronics",
+			Status:    productDomain.ProductStatusActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	mockRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(f productDomain.ListProductFilter) bool {
+		return f.Page == 1 && f.Limit == 10
+	})).Return(products, int64(2), nil)
+
+	result, err := service.ListProducts(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *result.TotalCount)
+	assert.Len(t, result.Products, 2)
+	assert.Equal(t, "SKU-001", result.Products[0].SKU)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_ListProducts_SearchHighlight(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	q := "running shoes"
+	filter := productDomain.ListProductFilter{Q: &q, Page: 1, Limit: 10}
+	assert.NoError(t, filter.Validate())
+	assert.Equal(t, "relevance", filter.SortBy)
+
+	nameHighlight := "Women's <mark>Running</mark> <mark>Shoes</mark>"
+	mockRepo.On("GetAll", mock.Anything, filter).Return([]productDomain.Product{
+		{ID: 12, SKU: "SKU-012", Name: "Women's Running Shoes", NameHighlight: &nameHighlight},
+	}, int64(1), nil)
+
+	result, err := service.ListProducts(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, nameHighlight, result.Products[0].Highlight.Name)
+	assert.Nil(t, result.Products[0].Highlight.Description)
+}
+
+func TestProductService_ListProducts_WithFacets(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	filter := productDomain.ListProductFilter{Page: 1, Limit: 10, Facets: []string{"status", "price_range"}}
+	assert.NoError(t, filter.Validate())
+	assert.Equal(t, productDomain.DefaultPriceRanges, filter.PriceRanges)
+
+	low := decimal.NewFromInt(100000)
+	mockRepo.On("GetAll", mock.Anything, filter).Return([]productDomain.Product{{ID: 1}}, int64(1), nil)
+	mockRepo.On("GetFacets", mock.Anything, filter).Return(productDomain.Facets{
+		Statuses: []productDomain.StatusFacet{
+			{Status: productDomain.ProductStatusActive, Count: 1},
+		},
+		PriceRanges: []productDomain.PriceRangeFacet{
+			{Max: &low, Count: 1},
+			{Min: &low, Count: 0},
+		},
+	}, nil)
+
+	result, err := service.ListProducts(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result.Facets)
+	assert.Nil(t, result.Facets.Category)
+	assert.Equal(t, int64(1), result.Facets.Status[0].Count)
+	assert.Len(t, result.Facets.PriceRange, 2)
+	assert.Nil(t, result.Facets.PriceRange[0].Min)
+	assert.True(t, low.Equal(*result.Facets.PriceRange[1].Min))
+}
+
+func TestProductService_ListProducts_WithoutFacets(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	filter := productDomain.ListProductFilter{Page: 1, Limit: 10}
+	mockRepo.On("GetAll", mock.Anything, filter).Return([]productDomain.Product{{ID: 1}}, int64(1), nil)
+
+	result, err := service.ListProducts(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Nil(t, result.Facets)
+	mockRepo.AssertNotCalled(t, "GetFacets")
+}
+
+func TestProductService_ListProducts_PageCursors(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	filter := productDomain.ListProductFilter{Page: 2, Limit: 2, SortBy: "price", SortOrder: "asc"}
+	mockRepo.On("GetAll", mock.Anything, filter).Return([]productDomain.Product{
+		{ID: 7, SortKey: "15000.00"},
+		{ID: 3, SortKey: "20000.00"},
+	}, int64(5), nil)
+
+	result, err := service.ListProducts(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, *result.TotalPages)
+
+	require.NotNil(t, result.NextCursor)
+	next, err := productDomain.DecodeCursor(*result.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, productDomain.Cursor{SortBy: "price", SortOrder: "asc", Value: "20000.00", ID: 3}, next)
+
+	require.NotNil(t, result.PrevCursor)
+	prev, err := productDomain.DecodeCursor(*result.PrevCursor)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), prev.ID)
+	assert.True(t, prev.Backward)
+}
+
+func TestProductService_ListProducts_CursorWithoutCount(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	cursor := productDomain.Cursor{SortBy: "created_at", SortOrder: "desc", Value: "2025-01-01 00:00:00+00", ID: 10}.Encode()
+	filter := productDomain.ListProductFilter{Cursor: &cursor, SkipCount: true, Limit: 2}
+	require.NoError(t, filter.Validate())
+
+	// A short page is the last one
+	mockRepo.On("GetAll", mock.Anything, filter).Return([]productDomain.Product{
+		{ID: 9, SortKey: "2024-12-31 00:00:00+00"},
+	}, int64(0), nil)
+
+	result, err := service.ListProducts(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Nil(t, result.TotalCount)
+	assert.Nil(t, result.TotalPages)
+	assert.Nil(t, result.NextCursor)
+	assert.NotNil(t, result.PrevCursor)
+	assert.Equal(t, "Showing 1 products", result.Showing)
+}
+
+func TestProductService_ListProducts_RepositoryError(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		transactor:  &MockTransactor{},
+		repository:  mockRepo,
+		fileService: mockFileService,
+	}
+
+	filter := productDomain.ListProductFilter{
+		Page:      1,
+		Limit:     10,
+		SortBy:    "created_at",
+		SortOrder: "desc",
+	}
+
+	// Simulate repository error
+	mockRepo.On("GetAll", mock.Anything, mock.Anything).
+		Return([]productDomain.Product{}, int64(0), errors.New("database error"))
+
+	_, err := service.ListProducts(context.Background(), filter)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list products")
+	mockRepo.AssertExpectations(t)
+}
+
+// Tests for UploadImage
+func TestProductService_UploadImage_Success(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		imageRepository:    &MockProductImageRepository{},
+		fileService:        mockFileService,
+		imagePolicy:        testImagePolicy,
+	}
+
+	// Create a mock file, too small for any resized copy
+	file := NewMockFile(newTestJPEG(t, 100, 50))
+	fileHeader := &multipart.FileHeader{
+		Filename: "test.jpg",
+		Size:     100,
+	}
+
+	now := time.Now()
+	existingProduct := productDomain.Product{
+		ID:        1,
+		SKU:       "TEST-SKU-001",
+		Name:      "Test Product",
+		Price:     decimal.NewFromInt(10000),
+		Stock:     100,
+		Category:  "Electronics",
+		Status:    productDomain.ProductStatusActive,
+		ImageURL:  nil, // No existing image
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	uploadedPath := "products/1/product-1-image.jpg"
+	fullURL := "http://localhost:8080/uploads/products/1/product-1-image.jpg"
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(existingProduct, nil)
+
+	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "jpeg").
+		Return(uploadedPath, nil)
+
+	mockFileService.On("GetFileURL", mock.Anything, uploadedPath, time.Duration(0)).
+		Return(fullURL, nil)
+
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
+		return req.ID == 1 && req.ImageURL != nil && *req.ImageURL == fullURL &&
+			req.ImageVariants != nil && len(*req.ImageVariants) == 1 &&
+			(*req.ImageVariants)[0] == productDomain.ImageVariant{Name: "original", Format: "jpeg", URL: fullURL, Width: 100, Height: 50}
+	})).Return(nil)
+
+	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockFileService.AssertExpectations(t)
+}
+
+func TestProductService_UploadImage_ReplaceExisting(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+	imageRepo := &MockProductImageRepository{}
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		imageRepository:    imageRepo,
+		fileService:        mockFileService,
+		imagePolicy:        testImagePolicy,
+	}
+
+	file := NewMockFile(newTestJPEG(t, 100, 50))
+	fileHeader := &multipart.FileHeader{
+		Filename: "test.jpg",
+		Size:     100,
+	}
+
+	oldImageURL := "http://localhost:8080/uploads/products/1/old-image.jpg"
+	oldVariants := []productDomain.ImageVariant{
+		{Name: "original", Format: "jpeg", URL: oldImageURL, Width: 800, Height: 600},
+		{Name: "thumb", Format: "jpeg", URL: "http://localhost:8080/uploads/products/1/old-image-thumb.jpg", Width: 150, Height: 112},
+	}
+	now := time.Now()
+	existingProduct := productDomain.Product{
+		ID:            1,
+		SKU:           "TEST-SKU-001",
+		Name:          "Test Product",
+		Price:         decimal.NewFromInt(10000),
+		Stock:         100,
+		Category:      "Electronics",
+		Status:        productDomain.ProductStatusActive,
+		ImageURL:      &oldImageURL, // Has existing image
+		ImageVariants: oldVariants,
+		Images: []productDomain.ProductImage{
+			{ID: 7, ProductID: 1, URL: oldImageURL, Variants: oldVariants, IsPrimary: true},
+			{ID: 8, ProductID: 1, URL: "http://localhost:8080/uploads/products/1/side.jpg", Position: 1},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	imageRepo.images = existingProduct.Images
+	uploadedPath := "products/1/product-1-image.jpg"
+	fullURL := "http://localhost:8080/uploads/products/1/product-1-image.jpg"
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(existingProduct, nil)
+
+	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "jpeg").
+		Return(uploadedPath, nil)
+
+	mockFileService.On("GetFileURL", mock.Anything, uploadedPath, time.Duration(0)).
+		Return(fullURL, nil)
+
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
+		return req.ID == 1 && req.ImageURL != nil && *req.ImageURL == fullURL
+	})).Return(nil)
+
+	mockFileService.On("DeleteFile", mock.Anything, "products/1/old-image.jpg").
+		Return(nil)
+	mockFileService.On("DeleteFile", mock.Anything, "products/1/old-image-thumb.jpg").
+		Return(nil)
+
+	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+
+	assert.NoError(t, err)
+	// The old primary image is gone, the side image stays
+	gallery, _ := imageRepo.List(context.Background(), 1)
+	require.Len(t, gallery, 2)
+	assert.Equal(t, int64(8), gallery[0].ID)
+	assert.False(t, gallery[0].IsPrimary)
+	assert.Equal(t, fullURL, gallery[1].URL)
+	assert.True(t, gallery[1].IsPrimary)
+	mockRepo.AssertExpectations(t)
+	mockFileService.AssertExpectations(t)
+}
+
+func TestProductService_UploadImage_InvalidFileType(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		fileService:        mockFileService,
+		imagePolicy:        testImagePolicy,
+	}
+
+	file := NewMockFile("%PDF-1.7 fake file content")
+	fileHeader := &multipart.FileHeader{
+		Filename: "test.pdf", // Invalid file type
+		Size:     100,
+	}
+
+	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, productDomain.ErrInvalidImageFormat)
+	mockFileService.AssertNotCalled(t, "UploadProductImage")
+	mockRepo.AssertNotCalled(t, "GetByID")
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestProductService_UploadImage_ProductNotFound(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		fileService:        mockFileService,
+		imagePolicy:        testImagePolicy,
+	}
+
+	file := NewMockFile(newTestJPEG(t, 100, 50))
+	fileHeader := &multipart.FileHeader{
+		Filename: "test.jpg",
+		Size:     100,
+	}
+
+	mockRepo.On("GetByID", mock.Anything, int64(999)).
+		Return(productDomain.Product{}, pgx.ErrNoRows)
+
+	err := service.UploadImage(context.Background(), 999, file, fileHeader)
+
+	assert.Error(t, err)
+	assert.Equal(t, productDomain.ErrProductNotFound, err)
+	mockRepo.AssertExpectations(t)
+	mockFileService.AssertNotCalled(t, "UploadProductImage")
+}
+
+func TestProductService_UploadImage_GeneratesVariants(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		imageRepository:    &MockProductImageRepository{},
+		fileService:        mockFileService,
+		imagePolicy:        testImagePolicy,
+	}
+
+	file := NewMockFile(newTestJPEG(t, 1600, 800))
+	fileHeader := &multipart.FileHeader{
+		Filename: "photo.JPG",
+		Size:     100,
+	}
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, SKU: "TEST-SKU-001"}, nil)
+
+	for _, name := range []string{"image", "image-thumb", "image-card", "image-detail"} {
+		path := "products/1/product-1-" + name + ".jpg"
+		mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "jpeg").
+			Return(path, nil).Once()
+		mockFileService.On("GetFileURL", mock.Anything, path, time.Duration(0)).
+			Return("http://localhost:8080/uploads/"+path, nil).Once()
+	}
+
+	var saved []productDomain.ImageVariant
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
+		return req.ID == 1 && req.ImageVariants != nil
+	})).Run(func(args mock.Arguments) {
+		saved = *args.Get(1).(productDomain.UpdateProductRequest).ImageVariants
+	}).Return(nil)
+
+	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+
+	require.NoError(t, err)
+	assert.Equal(t, []productDomain.ImageVariant{
+		{Name: "original", Format: "jpeg", URL: "http://localhost:8080/uploads/products/1/product-1-image.jpg", Width: 1600, Height: 800},
+		{Name: "thumb", Format: "jpeg", URL: "http://localhost:8080/uploads/products/1/product-1-image-thumb.jpg", Width: 150, Height: 75},
+		{Name: "card", Format: "jpeg", URL: "http://localhost:8080/uploads/products/1/product-1-image-card.jpg", Width: 600, Height: 300},
+		{Name: "detail", Format: "jpeg", URL: "http://localhost:8080/uploads/products/1/product-1-image-detail.jpg", Width: 1200, Height: 600},
+	}, saved)
+	mockFileService.AssertExpectations(t)
+
+	images := toProductResponse(productDomain.Product{ImageVariants: saved}).Images
+	assert.Len(t, images, 4)
+	assert.Equal(t, productDomain.ImageResponse{
+		URL:    "http://localhost:8080/uploads/products/1/product-1-image-thumb.jpg",
+		Width:  150,
+		Height: 75,
+		Format: "jpeg",
+	}, images["thumb"])
+}
+
+func TestProductService_UploadImage_VariantUploadFails(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		transactor:  &MockTransactor{},
+		repository:  mockRepo,
+		fileService: mockFileService,
+		imagePolicy: testImagePolicy,
+	}
+
+	file := NewMockFile(newTestJPEG(t, 400, 400))
+	fileHeader := &multipart.FileHeader{
+		Filename: "photo.jpg",
+		Size:     100,
+	}
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1}, nil)
+	// The original is stored, its thumb is not
+	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "jpeg").
+		Return("products/1/product-1-image.jpg", nil).Once()
+	mockFileService.On("GetFileURL", mock.Anything, "products/1/product-1-image.jpg", time.Duration(0)).
+		Return("http://localhost:8080/uploads/products/1/product-1-image.jpg", nil)
+	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "jpeg").
+		Return("", errors.New("disk full")).Once()
+	mockFileService.On("DeleteFile", mock.Anything, "products/1/product-1-image.jpg").
+		Return(nil)
+
+	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+
+	assert.Error(t, err)
+	mockFileService.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update")
+}
+
+func TestProductService_UploadImage_NotAnImage(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		transactor:  &MockTransactor{},
+		repository:  mockRepo,
+		fileService: mockFileService,
+		imagePolicy: testImagePolicy,
+	}
+
+	file := NewMockFile("fake image content")
+	fileHeader := &multipart.FileHeader{
+		Filename: "test.jpg",
+		Size:     100,
+	}
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1}, nil)
+
+	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+
+	assert.ErrorIs(t, err, productDomain.ErrInvalidImageFormat)
+	mockFileService.AssertNotCalled(t, "UploadProductImage")
+	mockRepo.AssertNotCalled(t, "GetByID")
+}
+
+// The file name is not trusted: a PNG named .jpg is stored as a PNG
+func TestProductService_UploadImage_FormatFromContent(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		imageRepository:    &MockProductImageRepository{},
+		fileService:        mockFileService,
+		imagePolicy:        testImagePolicy,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 100, 50))))
+	file := NewMockFile(buf.String())
+	fileHeader := &multipart.FileHeader{
+		Filename: "photo.jpg",
+		Size:     int64(buf.Len()),
+	}
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1}, nil)
+	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "png").
+		Return("products/1/product-1-image.png", nil)
+	mockFileService.On("GetFileURL", mock.Anything, "products/1/product-1-image.png", time.Duration(0)).
+		Return("http://localhost:8080/uploads/products/1/product-1-image.png", nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
+		return req.ImageVariants != nil && (*req.ImageVariants)[0].Format == "png"
+	})).Return(nil)
+
+	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+
+	require.NoError(t, err)
+	mockFileService.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_UploadImage_FormatNotAllowed(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		repository:  mockRepo,
+		imagePolicy: imaging.Policy{AllowedFormats: []string{imaging.FormatJPEG, imaging.FormatPNG}, MaxPixels: 4000000},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, image.NewGray(image.Rect(0, 0, 10, 10)), nil))
+	fileHeader := &multipart.FileHeader{Filename: "photo.gif", Size: int64(buf.Len())}
+
+	err := service.UploadImage(context.Background(), 1, NewMockFile(buf.String()), fileHeader)
+
+	assert.ErrorIs(t, err, productDomain.ErrInvalidImageFormat)
+	var formatErr *imaging.FormatError
+	require.ErrorAs(t, err, &formatErr)
+	assert.Equal(t, "JPEG, PNG", formatErr.AllowedNames())
+	mockRepo.AssertNotCalled(t, "GetByID")
+}
+
+// The policy only applies to the upload: the files the pipeline encodes are
+// stored even when their format may not be uploaded
+func TestProductService_UploadImage_StoresFormatNotAllowedForUploads(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		imageRepository:    &MockProductImageRepository{},
+		fileService:        mockFileService,
+		imagePolicy:        imaging.Policy{AllowedFormats: []string{imaging.FormatGIF}, MaxPixels: 4000000},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, image.NewGray(image.Rect(0, 0, 100, 50)), nil))
+	fileHeader := &multipart.FileHeader{Filename: "photo.gif", Size: int64(buf.Len())}
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1}, nil)
+	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "jpeg").
+		Return("products/1/product-1-image.jpg", nil)
+	mockFileService.On("GetFileURL", mock.Anything, "products/1/product-1-image.jpg", time.Duration(0)).
+		Return("http://localhost:8080/uploads/products/1/product-1-image.jpg", nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	err := service.UploadImage(context.Background(), 1, NewMockFile(buf.String()), fileHeader)
+
+	require.NoError(t, err)
+	mockFileService.AssertExpectations(t)
+}
+
+func TestProductService_UploadImage_TooManyPixels(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		repository:  mockRepo,
+		imagePolicy: imaging.Policy{AllowedFormats: testImagePolicy.AllowedFormats, MaxPixels: 1000},
+	}
+
+	file := NewMockFile(newTestJPEG(t, 40, 30))
+	fileHeader := &multipart.FileHeader{Filename: "photo.jpg", Size: 100}
+
+	err := service.UploadImage(context.Background(), 1, file, fileHeader)
+
+	assert.ErrorIs(t, err, productDomain.ErrImageTooManyPixels)
+	mockRepo.AssertNotCalled(t, "GetByID")
+}
+
+// A phone photo: a JPEG taken sideways, with the orientation and a GPS
+// position in its EXIF data
+func newTestPhoto(t *testing.T, width, height int) string {
+	t.Helper()
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x06, 0x00, 0x00) // orientation 6
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00)
+	tiff = append(tiff, "GPS 6.2088 S 106.8456 E"...)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := append([]byte{0xFF, 0xE1, byte((len(segment) + 2) >> 8), byte(len(segment) + 2)}, segment...)
+
+	data := newTestJPEG(t, width, height)
+	return data[:2] + string(app1) + data[2:]
+}
+
+func TestProductService_UploadImage_NormalizesPhoto(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		imageRepository:    &MockProductImageRepository{},
+		fileService:        mockFileService,
+		imagePolicy:        testImagePolicy,
+		imagePipeline:      imaging.Pipeline{MaxDimension: 100},
+	}
+
+	photo := newTestPhoto(t, 200, 120)
+	fileHeader := &multipart.FileHeader{Filename: "IMG_0001.jpg", Size: int64(len(photo))}
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1}, nil)
+	var stored []byte
+	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "jpeg").
+		Run(func(args mock.Arguments) {
+			stored, _ = io.ReadAll(args.Get(2).(io.Reader))
+		}).
+		Return("products/1/product-1-image.jpg", nil)
+	mockFileService.On("GetFileURL", mock.Anything, "products/1/product-1-image.jpg", time.Duration(0)).
+		Return("http://localhost:8080/uploads/products/1/product-1-image.jpg", nil)
+	var saved []productDomain.ImageVariant
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
+		return req.ImageVariants != nil
+	})).Run(func(args mock.Arguments) {
+		saved = *args.Get(1).(productDomain.UpdateProductRequest).ImageVariants
+	}).Return(nil)
+
+	err := service.UploadImage(context.Background(), 1, NewMockFile(photo), fileHeader)
+
+	require.NoError(t, err)
+	// Turned upright, then scaled down to 100 px on the longest side
+	require.Len(t, saved, 1)
+	assert.Equal(t, 60, saved[0].Width)
+	assert.Equal(t, 100, saved[0].Height)
+	config, err := jpeg.DecodeConfig(bytes.NewReader(stored))
+	require.NoError(t, err)
+	assert.Equal(t, 60, config.Width)
+	assert.Equal(t, 100, config.Height)
+	assert.NotContains(t, string(stored), "Exif")
+	assert.NotContains(t, string(stored), "GPS")
+}
+
+func TestProductService_DeleteImage_RemovesVariants(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		imageRepository:    &MockProductImageRepository{},
+		fileService:        mockFileService,
+	}
+
+	imageURL := "http://localhost:8080/uploads/products/1/photo.jpg"
+	product := productDomain.Product{
+		ID:       1,
+		ImageURL: &imageURL,
+		ImageVariants: []productDomain.ImageVariant{
+			{Name: "original", Format: "jpeg", URL: imageURL, Width: 800, Height: 800},
+			{Name: "thumb", Format: "jpeg", URL: "http://localhost:8080/uploads/products/1/photo-thumb.jpg", Width: 150, Height: 150},
+			{Name: "card", Format: "jpeg", URL: "http://localhost:8080/uploads/products/1/photo-card.jpg", Width: 600, Height: 600},
+		},
+	}
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(product, nil)
+	for _, path := range []string{"products/1/photo.jpg", "products/1/photo-thumb.jpg", "products/1/photo-card.jpg"} {
+		mockFileService.On("DeleteFile", mock.Anything, path).Return(nil).Once()
+	}
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
+		return req.ImageURL != nil && *req.ImageURL == "" && req.ImageVariants != nil && len(*req.ImageVariants) == 0
+	})).Return(nil)
+
+	err := service.DeleteImage(context.Background(), 1)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockFileService.AssertExpectations(t)
+}
+
+func TestProductService_DeleteImage_NoImage(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		transactor:  &MockTransactor{},
+		repository:  mockRepo,
+		fileService: mockFileService,
+	}
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(productDomain.Product{ID: 1}, nil)
+
+	err := service.DeleteImage(context.Background(), 1)
+
+	assert.Equal(t, productDomain.ErrImageNotFound, err)
+	mockFileService.AssertNotCalled(t, "DeleteFile")
+}
+
+// The files stay when the product could not be updated, it still points at them
+func TestProductService_DeleteImage_KeepsFilesOnFailure(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		transactor:      &MockTransactor{},
+		repository:      mockRepo,
+		imageRepository: &MockProductImageRepository{},
+		fileService:     mockFileService,
+	}
+
+	imageURL := "http://localhost:8080/uploads/products/1/photo.jpg"
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(productDomain.Product{ID: 1, ImageURL: &imageURL}, nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("connection reset"))
+
+	err := service.DeleteImage(context.Background(), 1)
+
+	assert.Error(t, err)
+	mockFileService.AssertNotCalled(t, "DeleteFile")
+}
+
+// Tests for the image gallery
+func newGalleryTestService(mockRepo *MockProductRepository, mockFileService *MockFileService, imageRepo *MockProductImageRepository) *ProductServiceImpl {
+	return &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		imageRepository:    imageRepo,
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		fileService:        mockFileService,
+		imagePolicy:        testImagePolicy,
+	}
+}
+
+func TestProductService_AddGalleryImage_FirstBecomesPrimary(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+	imageRepo := &MockProductImageRepository{}
+	service := newGalleryTestService(mockRepo, mockFileService, imageRepo)
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1}, nil)
+	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "png").
+		Return("products/1/front.png", nil)
+	mockFileService.On("GetFileURL", mock.Anything, "products/1/front.png", time.Duration(0)).
+		Return("http://localhost:8080/uploads/products/1/front.png", nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
+		return *req.ImageURL == "http://localhost:8080/uploads/products/1/front.png" && len(*req.ImageVariants) == 1
+	})).Return(nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 40, 40))))
+	altText := "Front view"
+	result, err := service.AddGalleryImage(context.Background(), productDomain.AddProductImageRequest{
+		ProductID:  1,
+		File:       NewMockFile(buf.String()),
+		FileHeader: &multipart.FileHeader{Filename: "front.png", Size: int64(buf.Len())},
+		AltText:    &altText,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.IsPrimary)
+	assert.Equal(t, "Front view", *result.AltText)
+	assert.Equal(t, 40, result.Images["original"].Width)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_AddGalleryImage_AppendsToGallery(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+	primary := productDomain.ProductImage{ID: 7, ProductID: 1, URL: "http://localhost:8080/uploads/products/1/front.jpg", IsPrimary: true}
+	imageRepo := &MockProductImageRepository{images: []productDomain.ProductImage{primary}}
+	service := newGalleryTestService(mockRepo, mockFileService, imageRepo)
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, ImageURL: &primary.URL, Images: imageRepo.images}, nil)
+	mockFileService.On("UploadProductImage", mock.Anything, "1", mock.Anything, "jpeg").
+		Return("products/1/back.jpg", nil)
+	mockFileService.On("GetFileURL", mock.Anything, "products/1/back.jpg", time.Duration(0)).
+		Return("http://localhost:8080/uploads/products/1/back.jpg", nil)
+
+	result, err := service.AddGalleryImage(context.Background(), productDomain.AddProductImageRequest{
+		ProductID:  1,
+		File:       NewMockFile(newTestJPEG(t, 40, 40)),
+		FileHeader: &multipart.FileHeader{Filename: "back.jpg", Size: 100},
+	})
+
+	require.NoError(t, err)
+	assert.False(t, result.IsPrimary)
+	assert.Equal(t, 1, result.Position)
+	assert.Nil(t, result.AltText)
+	mockRepo.AssertNotCalled(t, "Update")
+	mockFileService.AssertNotCalled(t, "DeleteFile")
+
+	// The audit record names the added image
+	entries := service.auditService.(*MockAuditService).entries
+	require.Len(t, entries, 1)
+	assert.Equal(t, auditDomain.ActionImageUpload, entries[0].Action)
+	assert.Equal(t, map[string]auditDomain.Change{
+		"image": {After: map[string]any{"id": result.ID, "url": result.URL}},
+	}, entries[0].Changes)
+}
+
+func TestProductService_SetPrimaryImage(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	front := "http://localhost:8080/uploads/products/1/front.jpg"
+	images := []productDomain.ProductImage{
+		{ID: 7, ProductID: 1, URL: front, IsPrimary: true},
+		{ID: 8, ProductID: 1, URL: "http://localhost:8080/uploads/products/1/back.jpg", Position: 1},
+	}
+	imageRepo := &MockProductImageRepository{images: append([]productDomain.ProductImage{}, images...)}
+	service := newGalleryTestService(mockRepo, new(MockFileService), imageRepo)
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, ImageURL: &front, Images: images}, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
+		return *req.ImageURL == "http://localhost:8080/uploads/products/1/back.jpg"
+	})).Return(nil)
+
+	_, err := service.SetPrimaryImage(context.Background(), 1, 8)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	gallery, _ := imageRepo.List(context.Background(), 1)
+	assert.False(t, gallery[0].IsPrimary)
+	assert.True(t, gallery[1].IsPrimary)
+
+	_, err = service.SetPrimaryImage(context.Background(), 1, 99)
+	assert.Equal(t, productDomain.ErrImageNotFound, err)
+}
+
+func TestProductService_DeleteGalleryImage_PromotesNext(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+	front := "http://localhost:8080/uploads/products/1/front.jpg"
+	images := []productDomain.ProductImage{
+		{ID: 7, ProductID: 1, URL: front, IsPrimary: true, Variants: []productDomain.ImageVariant{
+			{Name: "original", URL: front},
+			{Name: "thumb", URL: "http://localhost:8080/uploads/products/1/front-thumb.jpg"},
+		}},
+		{ID: 8, ProductID: 1, URL: "http://localhost:8080/uploads/products/1/back.jpg", Position: 1},
+	}
+	imageRepo := &MockProductImageRepository{images: append([]productDomain.ProductImage{}, images...)}
+	service := newGalleryTestService(mockRepo, mockFileService, imageRepo)
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, ImageURL: &front, Images: images}, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(req productDomain.UpdateProductRequest) bool {
+		return *req.ImageURL == "http://localhost:8080/uploads/products/1/back.jpg"
+	})).Return(nil)
+	mockFileService.On("DeleteFile", mock.Anything, "products/1/front.jpg").Return(nil)
+	mockFileService.On("DeleteFile", mock.Anything, "products/1/front-thumb.jpg").Return(nil)
+
+	err := service.DeleteGalleryImage(context.Background(), 1, 7)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockFileService.AssertExpectations(t)
+	gallery, _ := imageRepo.List(context.Background(), 1)
+	require.Len(t, gallery, 1)
+	assert.True(t, gallery[0].IsPrimary)
+}
+
+func TestProductService_DeleteGalleryImage_NotPrimary(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockFileService := new(MockFileService)
+	front := "http://localhost:8080/uploads/products/1/front.jpg"
+	back := "http://localhost:8080/uploads/products/1/back.jpg"
+	images := []productDomain.ProductImage{
+		{ID: 7, ProductID: 1, URL: front, IsPrimary: true},
+		{ID: 8, ProductID: 1, URL: back, Position: 1},
+	}
+	imageRepo := &MockProductImageRepository{images: append([]productDomain.ProductImage{}, images...)}
+	service := newGalleryTestService(mockRepo, mockFileService, imageRepo)
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, ImageURL: &front, Images: images}, nil)
+	mockFileService.On("DeleteFile", mock.Anything, "products/1/back.jpg").Return(nil)
+
+	err := service.DeleteGalleryImage(context.Background(), 1, 8)
+
+	require.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Update")
+	mockFileService.AssertExpectations(t)
+
+	entries := service.auditService.(*MockAuditService).entries
+	require.Len(t, entries, 1)
+	assert.Equal(t, auditDomain.ActionImageDelete, entries[0].Action)
+	assert.Equal(t, map[string]auditDomain.Change{
+		"image": {Before: map[string]any{"id": int64(8), "url": back}},
+	}, entries[0].Changes)
+}
+
+func TestProductService_ReorderGallery(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	images := []productDomain.ProductImage{
+		{ID: 7, ProductID: 1, URL: "front.jpg", IsPrimary: true},
+		{ID: 8, ProductID: 1, URL: "back.jpg", Position: 1},
+		{ID: 9, ProductID: 1, URL: "side.jpg", Position: 2},
+	}
+	imageRepo := &MockProductImageRepository{images: append([]productDomain.ProductImage{}, images...)}
+	service := newGalleryTestService(mockRepo, new(MockFileService), imageRepo)
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(productDomain.Product{ID: 1, Images: images}, nil)
+
+	gallery, err := service.ReorderGallery(context.Background(), productDomain.ReorderProductImagesRequest{ProductID: 1, ImageIDs: []int64{9, 7, 8}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{9, 7, 8}, []int64{gallery[0].ID, gallery[1].ID, gallery[2].ID})
+	assert.True(t, gallery[1].IsPrimary)
+
+	var validationErrs validator.ValidationErrors
+	_, err = service.ReorderGallery(context.Background(), productDomain.ReorderProductImagesRequest{ProductID: 1, ImageIDs: []int64{9, 7}})
+	assert.ErrorAs(t, err, &validationErrs)
+
+	_, err = service.ReorderGallery(context.Background(), productDomain.ReorderProductImagesRequest{ProductID: 1, ImageIDs: []int64{9, 7, 10}})
+	assert.Equal(t, productDomain.ErrImageNotFound, err)
+}
+
+// Tests for SuggestProducts
+func TestProductService_SuggestProducts_Success(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	filter := productDomain.SuggestProductFilter{Q: "  lpatop "}
+	assert.NoError(t, filter.Validate())
+	assert.Equal(t, "lpatop", filter.Q)
+	assert.Equal(t, 10, filter.Limit)
+
+	mockRepo.On("Suggest", mock.Anything, filter).Return([]productDomain.ProductSuggestion{
+		{ID: 3, SKU: "LPT-001", Name: "Laptop Pro 14", Score: 0.5},
+		{ID: 9, SKU: "LPT-002", Name: "Laptop Air", Score: 0.42},
+	}, nil)
+
+	result, err := service.SuggestProducts(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "Laptop Pro 14", result[0].Name)
+	assert.Equal(t, 0.5, result[0].Score)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_SuggestProducts_NoMatches(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		transactor: &MockTransactor{},
+		repository: mockRepo,
+	}
+
+	filter := productDomain.SuggestProductFilter{Q: "zzz", Limit: 5}
+	mockRepo.On("Suggest", mock.Anything, filter).Return([]productDomain.ProductSuggestion{}, nil)
+
+	result, err := service.SuggestProducts(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Empty(t, result)
+}
+
+// Tests for BulkProducts
+func TestProductService_BulkProducts_AtomicRollsBack(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+	}
+
+	categoryID := int64(3)
+	name := "Renamed"
+	req := productDomain.BulkProductRequest{
+		Mode: productDomain.BulkModeAtomic,
+		Operations: []productDomain.BulkOperation{
+			{Create: &productDomain.CreateProductRequest{SKU: "BULK-001", Name: "Bulk Product", Price: decimal.NewFromInt(10000), CategoryID: &categoryID, Status: productDomain.ProductStatusActive}},
+			{Update: &productDomain.UpdateProductRequest{ID: 5, Name: &name}},
+			{Delete: &productDomain.BulkDeleteRequest{ID: 7}},
+		},
+	}
+
+	mockCategoryRepo.On("GetByID", mock.Anything, int64(3)).
+		Return(categoryDomain.Category{ID: 3}, nil)
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(productDomain.Product{ID: 40, SKU: "BULK-001"}, nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(5)).
+		Return(productDomain.Product{}, pgx.ErrNoRows)
+
+	result, err := service.BulkProducts(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.False(t, result.Committed)
+	assert.Equal(t, productDomain.BulkStatusRolledBack, result.Results[0].Status)
+	assert.Equal(t, productDomain.BulkStatusFailed, result.Results[1].Status)
+	assert.Equal(t, "NOT_FOUND", result.Results[1].ErrorCode)
+	assert.Equal(t, productDomain.BulkStatusSkipped, result.Results[2].Status)
+	assert.Equal(t, 0, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	mockRepo.AssertNotCalled(t, "Delete")
+}
+
+func TestProductService_BulkProducts_AtomicInvalidItem(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+	}
+
+	req := productDomain.BulkProductRequest{
+		Mode: productDomain.BulkModeAtomic,
+		Operations: []productDomain.BulkOperation{
+			{Delete: &productDomain.BulkDeleteRequest{ID: 7}},
+			{Create: &productDomain.CreateProductRequest{Name: "No SKU", Status: productDomain.ProductStatusActive}},
+		},
+	}
+
+	result, err := service.BulkProducts(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.False(t, result.Committed)
+	assert.Equal(t, productDomain.BulkStatusSkipped, result.Results[0].Status)
+	assert.Equal(t, "VALIDATION_ERROR", result.Results[1].ErrorCode)
+	assert.Contains(t, result.Results[1].Details, "sku")
+	mockRepo.AssertNotCalled(t, "GetByIDForUpdate")
+}
+
+func TestProductService_BulkProducts_BestEffort(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockFileService := new(MockFileService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+		fileService:        mockFileService,
+	}
+
+	categoryID := int64(3)
+	req := productDomain.BulkProductRequest{
+		Mode: productDomain.BulkModeBestEffort,
+		Operations: []productDomain.BulkOperation{
+			{},
+			{Delete: &productDomain.BulkDeleteRequest{ID: 7}},
+			{Create: &productDomain.CreateProductRequest{SKU: "DUP-001", Name: "Duplicate", Price: decimal.NewFromInt(10000), CategoryID: &categoryID, Status: productDomain.ProductStatusActive}},
+		},
+	}
+
+	imageURL := "products/7/photo.jpg"
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(7)).
+		Return(productDomain.Product{ID: 7, ImageURL: &imageURL}, nil)
+	mockRepo.On("Delete", mock.Anything, int64(7), (*int)(nil)).
+		Return(nil)
+	mockCategoryRepo.On("GetByID", mock.Anything, int64(3)).
+		Return(categoryDomain.Category{ID: 3}, nil)
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(productDomain.Product{}, &pgconn.PgError{Code: "23505"})
+
+	result, err := service.BulkProducts(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.True(t, result.Committed)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, "VALIDATION_ERROR", result.Results[0].ErrorCode)
+	assert.Equal(t, int64(7), *result.Results[1].ID)
+	assert.Equal(t, "SKU_EXISTS", result.Results[2].ErrorCode)
+	mockFileService.AssertNotCalled(t, "DeleteFile")
+}
+
+// Tests for ImportProducts
+func TestProductService_ImportProducts_DryRun(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		importJobs:         newImportJobStore(),
+	}
+
+	csv := "SKU,Name,Price,Stock,Category_ID,Status\n" +
+		"IMP-001,Imported Product,15000,10,3,active\n" +
+		"IMP-002,,abc,5,3,Active\n" +
+		"IMP-001,Duplicate,1000,1,3,Active\n"
+
+	result, err := service.ImportProducts(context.Background(), productDomain.ImportProductsRequest{
+		File:   strings.NewReader(csv),
+		DryRun: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, productDomain.ImportStatusCompleted, result.Status)
+	assert.Equal(t, 3, result.TotalRows)
+	assert.Equal(t, 3, result.Processed)
+	assert.Equal(t, 1, result.Valid)
+	assert.Equal(t, 2, result.Failed)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, 3, result.Errors[0].Row)
+	assert.Equal(t, "name is required", result.Errors[0].Details["name"])
+	assert.Equal(t, "price must be a number", result.Errors[0].Details["price"])
+	assert.Equal(t, 4, result.Errors[1].Row)
+	assert.Equal(t, "sku already appears on row 2", result.Errors[1].Details["sku"])
+	mockRepo.AssertNotCalled(t, "GetBySKU")
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestProductService_ImportProducts_UpsertsBySKU(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockInventoryService := new(MockInventoryService)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+		inventoryService:   mockInventoryService,
+		importJobs:         newImportJobStore(),
+	}
+
+	csv := "sku,name,price,stock,category_id,status\n" +
+		"IMP-001,Existing Product,15000,20,3,Active\n" +
+		"IMP-002,New Product,9000,0,3,Inactive\n"
+
+	mockRepo.On("GetBySKU", mock.Anything, "IMP-001").
+		Return(productDomain.Product{ID: 5, SKU: "IMP-001"}, nil)
+	mockRepo.On("GetBySKU", mock.Anything, "IMP-002").
+		Return(productDomain.Product{}, pgx.ErrNoRows)
+	mockCategoryRepo.On("GetByID", mock.Anything, int64(3)).
+		Return(categoryDomain.Category{ID: 3}, nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(5)).
+		Return(productDomain.Product{ID: 5, SKU: "IMP-001"}, nil)
+	mockRepo.On("GetByID", mock.Anything, int64(5)).
+		Return(productDomain.Product{ID: 5, SKU: "IMP-001", Name: "Existing Product"}, nil)
+	mockInventoryService.On("CorrectStock", mock.Anything, int64(5), 20).
+		Return(nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(r productDomain.UpdateProductRequest) bool {
+		return r.ID == 5 && *r.Name == "Existing Product" && r.Price.Equal(decimal.NewFromInt(15000)) &&
+			*r.CategoryID == 3 && r.Description == nil && r.Stock == nil
+	})).Return(nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(p productDomain.Product) bool {
+		return p.SKU == "IMP-002" && p.Status == productDomain.ProductStatusInactive
+	})).Return(productDomain.Product{ID: 6, SKU: "IMP-002"}, nil)
+
+	result, err := service.ImportProducts(context.Background(), productDomain.ImportProductsRequest{
+		File: strings.NewReader(csv),
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, result.JobID)
+	assert.Equal(t, productDomain.ImportStatusCompleted, result.Status)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 0, result.Failed)
+	mockRepo.AssertExpectations(t)
+	mockInventoryService.AssertExpectations(t)
+}
+
+func TestProductService_ImportProducts_MissingColumns(t *testing.T) {
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		importJobs:         newImportJobStore(),
+	}
+
+	csv := "sku,title,price\nIMP-001,Product,1000\n"
+
+	_, err := service.ImportProducts(context.Background(), productDomain.ImportProductsRequest{
+		File:   strings.NewReader(csv),
+		DryRun: true,
+	})
+
+	var validationErrs validator.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	message := validationErrs.ToMap()["columns"]
+	assert.Contains(t, message, "unknown column 'title'")
+	assert.Contains(t, message, "missing column 'name'")
+	assert.Contains(t, message, "missing column 'category_id' or 'category'")
+}
+
+func TestProductService_ImportProducts_LargeFileRunsAsJob(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+
+	service := &ProductServiceImpl{
+		auditService:       &MockAuditService{},
+		revisionRepository: &MockProductRevisionRepository{},
+		transactor:         &MockTransactor{},
+		repository:         mockRepo,
+		categoryRepository: mockCategoryRepo,
+		importJobs:         newImportJobStore(),
+	}
+
+	var csv strings.Builder
+	csv.WriteString("sku,name,price,category_id,status\n")
+	rows := productDomain.ImportSyncRowLimit + 1
+	for i := range rows {
+		fmt.Fprintf(&csv, "JOB-%03d,Product %d,1000,3,Active\n", i, i)
+	}
+
+	mockRepo.On("GetBySKU", mock.Anything, mock.Anything).
+		Return(productDomain.Product{}, pgx.ErrNoRows)
+	mockCategoryRepo.On("GetByID", mock.Anything, int64(3)).
+		Return(categoryDomain.Category{ID: 3}, nil)
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(productDomain.Product{ID: 1}, nil)
+
+	ctx := requestctx.WithTenantID(context.Background(), 2)
+	result, err := service.ImportProducts(ctx, productDomain.ImportProductsRequest{
+		File: strings.NewReader(csv.String()),
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, result.JobID)
+	assert.Equal(t, productDomain.ImportStatusRunning, result.Status)
+
+	var job productDomain.ImportJobResponse
+	require.Eventually(t, func() bool {
+		job, err = service.GetImportJob(ctx, result.JobID)
+		return err == nil && job.Status == productDomain.ImportStatusCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, rows, job.Processed)
+	assert.Equal(t, rows, job.Created)
+	assert.NotNil(t, job.FinishedAt)
+
+	_, err = service.GetImportJob(ctx, "unknown")
+	assert.Equal(t, productDomain.ErrImportJobNotFound, err)
+
+	// Another tenant cannot poll the job
+	_, err = service.GetImportJob(requestctx.WithTenantID(context.Background(), 3), result.JobID)
+	assert.Equal(t, productDomain.ErrImportJobNotFound, err)
+}
+
+// Tests for ExportProducts
+func exportTestProducts() []productDomain.Product {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	description := "Semicolon; inside"
+	return []productDomain.Product{
+		{ID: 1, SKU: "EXP-001", Name: "Laptop", Description: &description, Price: decimal.RequireFromString("15000000.5"), Stock: 3, CategoryID: 3, Category: "Electronics", Status: productDomain.ProductStatusActive, Version: 2, CreatedAt: now, UpdatedAt: now},
+		{ID: 2, SKU: "EXP-002", Name: "Mouse", Price: decimal.NewFromInt(250000), Stock: 0, CategoryID: 3, Category: "Electronics", Status: productDomain.ProductStatusInactive, Version: 1, CreatedAt: now, UpdatedAt: now},
+	}
+}
+
+// streamProducts makes a mocked Export call fn for every product
+func streamProducts(products []productDomain.Product) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		fn := args.Get(2).(func(productDomain.Product) error)
+		for _, p := range products {
+			if err := fn(p); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func TestProductService_ExportProducts_CSVDialect(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := &ProductServiceImpl{repository: mockRepo}
+
+	filter := productDomain.ExportProductFilter{Format: productDomain.ExportFormatCSV, Delimiter: ";", DecimalSeparator: ","}
+	require.NoError(t, filter.Validate())
+
+	mockRepo.On("Export", mock.Anything, filter.ListProductFilter, mock.Anything).
+		Run(streamProducts(exportTestProducts())).Return(nil)
+
+	var out strings.Builder
+	err := service.ExportProducts(context.Background(), filter, &out)
+
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "\ufeffid;sku;name;description;price;stock;category_id;category;status;image_url;version;created_at;updated_at", lines[0])
+	assert.Equal(t, `1;EXP-001;Laptop;"Semicolon; inside";15000000,50;3;3;Electronics;Active;;2;2026-01-02T03:04:05Z;2026-01-02T03:04:05Z`, lines[1])
+	assert.Contains(t, lines[2], ";250000,00;")
+}
+
+func TestProductService_ExportProducts_JSONL(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := &ProductServiceImpl{repository: mockRepo}
+
+	filter := productDomain.ExportProductFilter{Format: productDomain.ExportFormatJSONL}
+	require.NoError(t, filter.Validate())
+
+	mockRepo.On("Export", mock.Anything, filter.ListProductFilter, mock.Anything).
+		Run(streamProducts(exportTestProducts())).Return(nil)
+
+	var out strings.Builder
+	err := service.ExportProducts(context.Background(), filter, &out)
+
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var first productDomain.ProductResponse
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "EXP-001", first.SKU)
+	assert.True(t, first.Price.Equal(decimal.RequireFromString("15000000.5")))
+}
+
+func TestProductService_ExportProducts_XLSX(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := &ProductServiceImpl{repository: mockRepo}
+
+	filter := productDomain.ExportProductFilter{Format: productDomain.ExportFormatXLSX}
+	require.NoError(t, filter.Validate())
+
+	mockRepo.On("Export", mock.Anything, filter.ListProductFilter, mock.Anything).
+		Run(streamProducts(exportTestProducts())).Return(nil)
+
+	var out bytes.Buffer
+	err := service.ExportProducts(context.Background(), filter, &out)
+	require.NoError(t, err)
+
+	workbook, err := excelize.OpenReader(&out)
+	require.NoError(t, err)
+	defer workbook.Close()
+
+	rows, err := workbook.GetRows("Products")
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, productDomain.ExportColumns, rows[0])
+	assert.Equal(t, "EXP-002", rows[2][1])
+
+	price, err := workbook.GetCellValue("Products", "E2", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Equal(t, "15000000.5", price)
+}
+
+func TestProductService_ExportProducts_RepositoryError(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := &ProductServiceImpl{repository: mockRepo}
+
+	filter := productDomain.ExportProductFilter{}
+	require.NoError(t, filter.Validate())
+
+	mockRepo.On("Export", mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("database error"))
+
+	err := service.ExportProducts(context.Background(), filter, io.Discard)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to export products")
 }
@@ -0,0 +1,219 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	userDomain "github.com/naxumi/bnsp-jwd/internal/domain/user"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBcryptCost is the bcrypt work factor for stored passwords
+const DefaultBcryptCost = 12
+
+type UserServiceImpl struct {
+	transactor             database.Transactor
+	repository             userDomain.UserRepository
+	refreshTokenRepository userDomain.RefreshTokenRepository
+	signer                 *auth.Signer
+	refreshTTL             time.Duration
+	bcryptCost             int
+
+	// Compared against when the username is unknown, so a login takes as
+	// long whether or not the user exists
+	dummyHash []byte
+}
+
+func NewUserService(transactor database.Transactor, repository userDomain.UserRepository, refreshTokenRepository userDomain.RefreshTokenRepository, signer *auth.Signer, refreshTTL time.Duration) userDomain.UserService {
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), DefaultBcryptCost)
+	return &UserServiceImpl{
+		transactor:             transactor,
+		repository:             repository,
+		refreshTokenRepository: refreshTokenRepository,
+		signer:                 signer,
+		refreshTTL:             refreshTTL,
+		bcryptCost:             DefaultBcryptCost,
+		dummyHash:              dummyHash,
+	}
+}
+
+func (s *UserServiceImpl) CreateUser(ctx context.Context, req userDomain.CreateUserRequest) (userDomain.UserResponse, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), s.bcryptCost)
+	if err != nil {
+		return userDomain.UserResponse{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	newUser := userDomain.User{
+		Username:     strings.TrimSpace(req.Username),
+		PasswordHash: string(hash),
+		Role:         req.Role,
+		TenantID:     req.TenantID,
+		IsActive:     true,
+	}
+
+	createdUser, err := s.repository.Create(ctx, newUser)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
+			return userDomain.UserResponse{}, userDomain.ErrUsernameExists
+		}
+		return userDomain.UserResponse{}, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return toUserResponse(createdUser), nil
+}
+
+func (s *UserServiceImpl) Login(ctx context.Context, req userDomain.LoginRequest) (userDomain.TokenResponse, error) {
+	u, err := s.repository.GetByUsername(ctx, strings.TrimSpace(req.Username))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(req.Password))
+			return userDomain.TokenResponse{}, userDomain.ErrInvalidCredentials
+		}
+		return userDomain.TokenResponse{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)); err != nil {
+		return userDomain.TokenResponse{}, userDomain.ErrInvalidCredentials
+	}
+	if !u.IsActive {
+		return userDomain.TokenResponse{}, userDomain.ErrInvalidCredentials
+	}
+
+	// Every login starts a new token family
+	return s.issueTokens(ctx, u, uuid.NewString())
+}
+
+func (s *UserServiceImpl) Refresh(ctx context.Context, req userDomain.RefreshRequest) (userDomain.TokenResponse, error) {
+	var tokens userDomain.TokenResponse
+	reused := false
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.refreshTokenRepository.GetByHashForUpdate(ctx, hashToken(req.RefreshToken))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return userDomain.ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("failed to get refresh token: %w", err)
+		}
+
+		// A rotated token is presented again: either the client or an attacker
+		// holds a stolen copy, so the whole login is ended. The revocation has
+		// to be committed, the error is returned after the transaction.
+		if current.RevokedAt != nil {
+			reused = true
+			return s.refreshTokenRepository.RevokeFamily(ctx, current.FamilyID)
+		}
+		if !time.Now().Before(current.ExpiresAt) {
+			return userDomain.ErrInvalidRefreshToken
+		}
+
+		u, err := s.repository.GetByID(ctx, current.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if !u.IsActive {
+			return userDomain.ErrInvalidRefreshToken
+		}
+
+		if err := s.refreshTokenRepository.Revoke(ctx, current.ID); err != nil {
+			return err
+		}
+
+		tokens, err = s.issueTokens(ctx, u, current.FamilyID)
+		return err
+	})
+	if err != nil {
+		return userDomain.TokenResponse{}, err
+	}
+	if reused {
+		return userDomain.TokenResponse{}, userDomain.ErrInvalidRefreshToken
+	}
+
+	return tokens, nil
+}
+
+func (s *UserServiceImpl) Logout(ctx context.Context, req userDomain.RefreshRequest) error {
+	token, err := s.refreshTokenRepository.GetByHashForUpdate(ctx, hashToken(req.RefreshToken))
+	if err != nil {
+		// Logging out with an unknown token leaves nothing to revoke
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return s.refreshTokenRepository.RevokeFamily(ctx, token.FamilyID)
+}
+
+// issueTokens signs an access token for the user and stores a new refresh
+// token in the given family
+func (s *UserServiceImpl) issueTokens(ctx context.Context, u userDomain.User, familyID string) (userDomain.TokenResponse, error) {
+	accessToken, accessExpiresAt, err := s.signer.Sign(u.Username, map[string]any{"uid": u.ID, "role": u.Role, auth.TenantClaim: u.TenantID})
+	if err != nil {
+		return userDomain.TokenResponse{}, err
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return userDomain.TokenResponse{}, err
+	}
+
+	stored, err := s.refreshTokenRepository.Create(ctx, userDomain.RefreshToken{
+		UserID:    u.ID,
+		TokenHash: hashToken(refreshToken),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	})
+	if err != nil {
+		return userDomain.TokenResponse{}, err
+	}
+
+	return userDomain.TokenResponse{
+		AccessToken:           accessToken,
+		TokenType:             "Bearer",
+		ExpiresIn:             int(time.Until(accessExpiresAt).Round(time.Second).Seconds()),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: stored.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+	}, nil
+}
+
+// newRefreshToken returns 256 random bits, URL-safe encoded
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is the stored form of a refresh token. The token is random, a
+// fast hash is enough to keep a database leak from exposing usable tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func toUserResponse(u userDomain.User) userDomain.UserResponse {
+	return userDomain.UserResponse{
+		ID:        u.ID,
+		Username:  u.Username,
+		Role:      u.Role,
+		TenantID:  u.TenantID,
+		IsActive:  u.IsActive,
+		CreatedAt: u.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: u.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,260 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	userDomain "github.com/naxumi/bnsp-jwd/internal/domain/user"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Mock Transactor runs the function directly, without a database
+type MockTransactor struct{}
+
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// Mock Repositories
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user userDomain.User) (userDomain.User, error) {
+	args := m.Called(ctx, user)
+	return args.Get(0).(userDomain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id int64) (userDomain.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(userDomain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (userDomain.User, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(userDomain.User), args.Error(1)
+}
+
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) Create(ctx context.Context, token userDomain.RefreshToken) (userDomain.RefreshToken, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(userDomain.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) GetByHashForUpdate(ctx context.Context, tokenHash string) (userDomain.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(userDomain.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Revoke(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	args := m.Called(ctx, familyID)
+	return args.Error(0)
+}
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func newTestService(t *testing.T) (*UserServiceImpl, *MockUserRepository, *MockRefreshTokenRepository) {
+	t.Helper()
+	signer, err := auth.NewSigner(auth.SignerOptions{Secret: testSecret, TTL: 15 * time.Minute})
+	require.NoError(t, err)
+
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	mockRepo := new(MockUserRepository)
+	mockTokenRepo := new(MockRefreshTokenRepository)
+	service := &UserServiceImpl{
+		transactor:             &MockTransactor{},
+		repository:             mockRepo,
+		refreshTokenRepository: mockTokenRepo,
+		signer:                 signer,
+		refreshTTL:             time.Hour,
+		bcryptCost:             bcrypt.MinCost,
+		dummyHash:              dummyHash,
+	}
+	return service, mockRepo, mockTokenRepo
+}
+
+func testUser(t *testing.T, password string) userDomain.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	return userDomain.User{ID: 1, Username: "admin", PasswordHash: string(hash), Role: auth.RoleStaff, TenantID: 2, IsActive: true}
+}
+
+// Tests for CreateUser
+func TestUserService_CreateUser_HashesPassword(t *testing.T) {
+	service, mockRepo, _ := newTestService(t)
+
+	var stored userDomain.User
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(userDomain.User) }).
+		Return(userDomain.User{ID: 1, Username: "admin", IsActive: true}, nil)
+
+	result, err := service.CreateUser(context.Background(), userDomain.CreateUserRequest{Username: " admin ", Password: "s3cret-pass", Role: auth.RoleAdmin, TenantID: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.ID)
+	assert.Equal(t, "admin", stored.Username)
+	assert.Equal(t, auth.RoleAdmin, stored.Role)
+	assert.Equal(t, int64(2), stored.TenantID)
+	assert.NotEqual(t, "s3cret-pass", stored.PasswordHash)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("s3cret-pass")))
+}
+
+func TestUserService_CreateUser_DuplicateUsername(t *testing.T) {
+	service, mockRepo, _ := newTestService(t)
+
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(userDomain.User{}, &pgconn.PgError{Code: "23505"})
+
+	_, err := service.CreateUser(context.Background(), userDomain.CreateUserRequest{Username: "admin", Password: "s3cret-pass"})
+
+	assert.Equal(t, userDomain.ErrUsernameExists, err)
+}
+
+// Tests for Login
+func TestUserService_Login_Success(t *testing.T) {
+	service, mockRepo, mockTokenRepo := newTestService(t)
+
+	mockRepo.On("GetByUsername", mock.Anything, "admin").Return(testUser(t, "s3cret-pass"), nil)
+	var stored userDomain.RefreshToken
+	mockTokenRepo.On("Create", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(userDomain.RefreshToken) }).
+		Return(userDomain.RefreshToken{ID: 10, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	result, err := service.Login(context.Background(), userDomain.LoginRequest{Username: "admin", Password: "s3cret-pass"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", result.TokenType)
+	assert.Equal(t, 900, result.ExpiresIn)
+	assert.NotEmpty(t, result.AccessToken)
+	assert.NotEmpty(t, result.RefreshToken)
+
+	// Only the hash of the refresh token is stored
+	assert.Equal(t, hashToken(result.RefreshToken), stored.TokenHash)
+	assert.NotEmpty(t, stored.FamilyID)
+	assert.Equal(t, int64(1), stored.UserID)
+
+	verifier, err := auth.NewVerifier(auth.VerifierOptions{Secret: testSecret})
+	require.NoError(t, err)
+	claims, err := verifier.Verify(context.Background(), result.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", claims.Subject)
+	assert.Equal(t, auth.RoleStaff, claims.Role())
+	assert.Equal(t, int64(2), claims.TenantID)
+}
+
+func TestUserService_Login_InvalidCredentials(t *testing.T) {
+	tests := map[string]struct {
+		user     userDomain.User
+		err      error
+		password string
+	}{
+		"wrong password": {user: testUser(t, "s3cret-pass"), password: "wrong-pass"},
+		"unknown user":   {err: pgx.ErrNoRows, password: "s3cret-pass"},
+		"inactive user": {
+			user:     func() userDomain.User { u := testUser(t, "s3cret-pass"); u.IsActive = false; return u }(),
+			password: "s3cret-pass",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			service, mockRepo, mockTokenRepo := newTestService(t)
+			mockRepo.On("GetByUsername", mock.Anything, "admin").Return(tc.user, tc.err)
+
+			_, err := service.Login(context.Background(), userDomain.LoginRequest{Username: "admin", Password: tc.password})
+
+			assert.Equal(t, userDomain.ErrInvalidCredentials, err)
+			mockTokenRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+// Tests for Refresh
+func TestUserService_Refresh_RotatesToken(t *testing.T) {
+	service, mockRepo, mockTokenRepo := newTestService(t)
+
+	current := userDomain.RefreshToken{ID: 10, UserID: 1, FamilyID: "family-1", ExpiresAt: time.Now().Add(time.Hour)}
+	mockTokenRepo.On("GetByHashForUpdate", mock.Anything, hashToken("old-token")).Return(current, nil)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(testUser(t, "s3cret-pass"), nil)
+	mockTokenRepo.On("Revoke", mock.Anything, int64(10)).Return(nil)
+	mockTokenRepo.On("Create", mock.Anything, mock.MatchedBy(func(token userDomain.RefreshToken) bool {
+		return token.FamilyID == "family-1" && token.TokenHash != hashToken("old-token")
+	})).Return(userDomain.RefreshToken{ID: 11, FamilyID: "family-1", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	result, err := service.Refresh(context.Background(), userDomain.RefreshRequest{RefreshToken: "old-token"})
+
+	require.NoError(t, err)
+	assert.NotEqual(t, "old-token", result.RefreshToken)
+	assert.NotEmpty(t, result.AccessToken)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestUserService_Refresh_ReusedTokenRevokesFamily(t *testing.T) {
+	service, _, mockTokenRepo := newTestService(t)
+
+	revokedAt := time.Now().Add(-time.Minute)
+	current := userDomain.RefreshToken{ID: 10, UserID: 1, FamilyID: "family-1", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+	mockTokenRepo.On("GetByHashForUpdate", mock.Anything, hashToken("stolen-token")).Return(current, nil)
+	mockTokenRepo.On("RevokeFamily", mock.Anything, "family-1").Return(nil)
+
+	_, err := service.Refresh(context.Background(), userDomain.RefreshRequest{RefreshToken: "stolen-token"})
+
+	assert.Equal(t, userDomain.ErrInvalidRefreshToken, err)
+	mockTokenRepo.AssertExpectations(t)
+	mockTokenRepo.AssertNotCalled(t, "Create")
+}
+
+func TestUserService_Refresh_Rejected(t *testing.T) {
+	tests := map[string]struct {
+		token userDomain.RefreshToken
+		err   error
+	}{
+		"unknown": {err: pgx.ErrNoRows},
+		"expired": {token: userDomain.RefreshToken{ID: 10, UserID: 1, FamilyID: "family-1", ExpiresAt: time.Now().Add(-time.Minute)}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			service, _, mockTokenRepo := newTestService(t)
+			mockTokenRepo.On("GetByHashForUpdate", mock.Anything, mock.Anything).Return(tc.token, tc.err)
+
+			_, err := service.Refresh(context.Background(), userDomain.RefreshRequest{RefreshToken: "some-token"})
+
+			assert.Equal(t, userDomain.ErrInvalidRefreshToken, err)
+			mockTokenRepo.AssertNotCalled(t, "Revoke")
+			mockTokenRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+// Tests for Logout
+func TestUserService_Logout_RevokesFamily(t *testing.T) {
+	service, _, mockTokenRepo := newTestService(t)
+
+	mockTokenRepo.On("GetByHashForUpdate", mock.Anything, hashToken("token")).
+		Return(userDomain.RefreshToken{ID: 10, FamilyID: "family-1"}, nil)
+	mockTokenRepo.On("RevokeFamily", mock.Anything, "family-1").Return(nil)
+
+	err := service.Logout(context.Background(), userDomain.RefreshRequest{RefreshToken: "token"})
+
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+package variant
+
+import (
+	"context"
+
+	variantDomain "github.com/naxumi/bnsp-jwd/internal/domain/variant"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+)
+
+// AuthorizedVariantService checks the caller's permissions, from the token
+// claims in the context, before handing a call to the wrapped service. Like
+// a product's price, a variant's price override needs product:price.
+type AuthorizedVariantService struct {
+	next variantDomain.VariantService
+}
+
+func NewAuthorizedVariantService(next variantDomain.VariantService) variantDomain.VariantService {
+	return &AuthorizedVariantService{
+		next: next,
+	}
+}
+
+func (s *AuthorizedVariantService) SetOptions(ctx context.Context, req variantDomain.SetOptionsRequest) ([]variantDomain.OptionDefinition, error) {
+	if err := auth.Require(ctx, auth.PermProductWrite); err != nil {
+		return nil, err
+	}
+	return s.next.SetOptions(ctx, req)
+}
+
+func (s *AuthorizedVariantService) CreateVariant(ctx context.Context, req variantDomain.CreateVariantRequest) (variantDomain.VariantResponse, error) {
+	if err := requireWrite(ctx, req.PriceOverride != nil); err != nil {
+		return variantDomain.VariantResponse{}, err
+	}
+	return s.next.CreateVariant(ctx, req)
+}
+
+func (s *AuthorizedVariantService) ListVariants(ctx context.Context, productID int64) (variantDomain.ListVariantResponse, error) {
+	return s.next.ListVariants(ctx, productID)
+}
+
+func (s *AuthorizedVariantService) UpdateVariant(ctx context.Context, req variantDomain.UpdateVariantRequest) error {
+	if err := requireWrite(ctx, req.PriceOverride != nil || req.ClearPriceOverride); err != nil {
+		return err
+	}
+	if req.ImageURL != nil {
+		if err := auth.Require(ctx, auth.PermProductImage); err != nil {
+			return err
+		}
+	}
+	return s.next.UpdateVariant(ctx, req)
+}
+
+func (s *AuthorizedVariantService) GenerateVariants(ctx context.Context, req variantDomain.GenerateVariantsRequest) ([]variantDomain.VariantResponse, error) {
+	if err := requireWrite(ctx, req.PriceOverride != nil); err != nil {
+		return nil, err
+	}
+	return s.next.GenerateVariants(ctx, req)
+}
+
+// requireWrite demands product:write, and product:price as well when the
+// request sets or clears a price override
+func requireWrite(ctx context.Context, pricing bool) error {
+	if pricing {
+		return auth.Require(ctx, auth.PermProductWrite, auth.PermProductPrice)
+	}
+	return auth.Require(ctx, auth.PermProductWrite)
+}
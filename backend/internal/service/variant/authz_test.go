@@ -0,0 +1,96 @@
+package variant
+
+import (
+	"context"
+	"testing"
+
+	variantDomain "github.com/naxumi/bnsp-jwd/internal/domain/variant"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/auth"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Variant Service, only the methods the tests reach are implemented
+type MockVariantService struct {
+	mock.Mock
+	variantDomain.VariantService
+}
+
+func (m *MockVariantService) CreateVariant(ctx context.Context, req variantDomain.CreateVariantRequest) (variantDomain.VariantResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(variantDomain.VariantResponse), args.Error(1)
+}
+
+func (m *MockVariantService) UpdateVariant(ctx context.Context, req variantDomain.UpdateVariantRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockVariantService) GenerateVariants(ctx context.Context, req variantDomain.GenerateVariantsRequest) ([]variantDomain.VariantResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]variantDomain.VariantResponse), args.Error(1)
+}
+
+func contextWithRole(role string) context.Context {
+	return auth.NewContext(context.Background(), auth.Claims{
+		Subject: "alice",
+		All:     map[string]any{"role": role},
+	})
+}
+
+func TestAuthorizedVariantService_PriceOverrideForbidden(t *testing.T) {
+	next := new(MockVariantService)
+	service := NewAuthorizedVariantService(next)
+	ctx := contextWithRole(auth.RoleStaff)
+	price := decimal.NewFromInt(15000)
+
+	_, err := service.CreateVariant(ctx, variantDomain.CreateVariantRequest{ProductID: 1, PriceOverride: &price})
+	var permissionErr *auth.PermissionError
+	assert.ErrorAs(t, err, &permissionErr)
+	assert.Equal(t, auth.PermProductPrice, permissionErr.Permission)
+
+	err = service.UpdateVariant(ctx, variantDomain.UpdateVariantRequest{ID: 2, PriceOverride: &price})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	err = service.UpdateVariant(ctx, variantDomain.UpdateVariantRequest{ID: 2, ClearPriceOverride: true})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	_, err = service.GenerateVariants(ctx, variantDomain.GenerateVariantsRequest{ProductID: 1, PriceOverride: &price})
+	assert.ErrorIs(t, err, auth.ErrForbidden)
+
+	next.AssertNotCalled(t, "CreateVariant")
+	next.AssertNotCalled(t, "UpdateVariant")
+	next.AssertNotCalled(t, "GenerateVariants")
+}
+
+func TestAuthorizedVariantService_WithoutPriceOverrideAllowed(t *testing.T) {
+	next := new(MockVariantService)
+	service := NewAuthorizedVariantService(next)
+	ctx := contextWithRole(auth.RoleStaff)
+
+	next.On("GenerateVariants", mock.Anything, mock.Anything).Return([]variantDomain.VariantResponse{}, nil)
+	next.On("UpdateVariant", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := service.GenerateVariants(ctx, variantDomain.GenerateVariantsRequest{ProductID: 1, Stock: 5})
+	assert.NoError(t, err)
+
+	stock := 3
+	err = service.UpdateVariant(ctx, variantDomain.UpdateVariantRequest{ID: 2, Stock: &stock})
+	assert.NoError(t, err)
+
+	next.AssertExpectations(t)
+}
+
+func TestAuthorizedVariantService_PriceOverrideAllowedForManager(t *testing.T) {
+	next := new(MockVariantService)
+	service := NewAuthorizedVariantService(next)
+
+	next.On("CreateVariant", mock.Anything, mock.Anything).Return(variantDomain.VariantResponse{ID: 2}, nil)
+
+	price := decimal.NewFromInt(15000)
+	_, err := service.CreateVariant(contextWithRole(auth.RoleManager), variantDomain.CreateVariantRequest{ProductID: 1, PriceOverride: &price})
+
+	assert.NoError(t, err)
+	next.AssertExpectations(t)
+}
@@ -0,0 +1,346 @@
+package variant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	variantDomain "github.com/naxumi/bnsp-jwd/internal/domain/variant"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/slug"
+	"github.com/shopspring/decimal"
+)
+
+type VariantServiceImpl struct {
+	transactor        database.Transactor
+	repository        variantDomain.VariantRepository
+	productRepository productDomain.ProductRepository
+	inventoryService  inventoryDomain.InventoryService
+}
+
+func NewVariantService(transactor database.Transactor, repository variantDomain.VariantRepository, productRepository productDomain.ProductRepository, inventoryService inventoryDomain.InventoryService) variantDomain.VariantService {
+	return &VariantServiceImpl{
+		transactor:        transactor,
+		repository:        repository,
+		productRepository: productRepository,
+		inventoryService:  inventoryService,
+	}
+}
+
+func (s *VariantServiceImpl) SetOptions(ctx context.Context, req variantDomain.SetOptionsRequest) ([]variantDomain.OptionDefinition, error) {
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.getParent(ctx, req.ProductID); err != nil {
+			return err
+		}
+		return s.replaceOptions(ctx, req.ProductID, req.Options)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return req.Options, nil
+}
+
+func (s *VariantServiceImpl) CreateVariant(ctx context.Context, req variantDomain.CreateVariantRequest) (variantDomain.VariantResponse, error) {
+	var created variantDomain.Variant
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		parent, err := s.getParent(ctx, req.ProductID)
+		if err != nil {
+			return err
+		}
+
+		options, err := s.repository.GetOptions(ctx, req.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to get product options: %w", err)
+		}
+		if len(options) == 0 {
+			return variantDomain.ErrNoOptions
+		}
+		if !matchesOptions(options, req.Options) {
+			return variantDomain.ErrInvalidOptions
+		}
+
+		created, err = s.createVariant(ctx, parent, options, req.SKU, req.Options, req.PriceOverride, req.Stock, req.Status)
+		return err
+	})
+	if err != nil {
+		return variantDomain.VariantResponse{}, err
+	}
+
+	return toVariantResponse(created), nil
+}
+
+// GenerateVariants expands the option matrix, e.g. Size [38, 39] x Color
+// [Red, Blue] gives four variants. Combinations that already exist are skipped,
+// so the endpoint can be called again after adding a new option value.
+func (s *VariantServiceImpl) GenerateVariants(ctx context.Context, req variantDomain.GenerateVariantsRequest) ([]variantDomain.VariantResponse, error) {
+	createdVariants := []variantDomain.VariantResponse{}
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		parent, err := s.getParent(ctx, req.ProductID)
+		if err != nil {
+			return err
+		}
+
+		if len(req.Options) > 0 {
+			if err := s.replaceOptions(ctx, req.ProductID, req.Options); err != nil {
+				return err
+			}
+		}
+
+		options, err := s.repository.GetOptions(ctx, req.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to get product options: %w", err)
+		}
+		if len(options) == 0 {
+			return variantDomain.ErrNoOptions
+		}
+
+		combinations := 1
+		for _, o := range options {
+			combinations *= len(o.Values)
+		}
+		if combinations > variantDomain.MaxGeneratedVariants {
+			return variantDomain.ErrTooManyCombinations
+		}
+
+		existing, err := s.repository.GetByParent(ctx, req.ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to get variants: %w", err)
+		}
+		existingKeys := map[string]bool{}
+		for _, v := range existing {
+			existingKeys[combinationKey(options, v.Options)] = true
+		}
+
+		for _, values := range expandMatrix(options) {
+			if existingKeys[combinationKey(options, values)] {
+				continue
+			}
+
+			sku := parent.SKU
+			for _, o := range options {
+				sku += "-" + strings.ToUpper(slug.Make(values[o.Name]))
+			}
+
+			created, err := s.createVariant(ctx, parent, options, sku, values, req.PriceOverride, req.Stock, req.Status)
+			if err != nil {
+				return err
+			}
+			createdVariants = append(createdVariants, toVariantResponse(created))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return createdVariants, nil
+}
+
+func (s *VariantServiceImpl) ListVariants(ctx context.Context, productID int64) (variantDomain.ListVariantResponse, error) {
+	if _, err := s.getParent(ctx, productID); err != nil {
+		return variantDomain.ListVariantResponse{}, err
+	}
+
+	options, err := s.repository.GetOptions(ctx, productID)
+	if err != nil {
+		return variantDomain.ListVariantResponse{}, fmt.Errorf("failed to get product options: %w", err)
+	}
+
+	variants, err := s.repository.GetByParent(ctx, productID)
+	if err != nil {
+		return variantDomain.ListVariantResponse{}, fmt.Errorf("failed to list variants: %w", err)
+	}
+
+	result := variantDomain.ListVariantResponse{
+		ProductID: productID,
+		Options:   []variantDomain.OptionDefinition{},
+		Variants:  []variantDomain.VariantResponse{},
+	}
+	for _, o := range options {
+		result.Options = append(result.Options, variantDomain.OptionDefinition{Name: o.Name, Values: o.Values})
+	}
+	for _, v := range variants {
+		result.Variants = append(result.Variants, toVariantResponse(v))
+	}
+
+	return result, nil
+}
+
+func (s *VariantServiceImpl) UpdateVariant(ctx context.Context, req variantDomain.UpdateVariantRequest) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repository.GetByID(ctx, req.ProductID, req.ID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return variantDomain.ErrVariantNotFound
+			}
+			return fmt.Errorf("failed to get variant: %w", err)
+		}
+
+		// Like products, a new stock level is booked as a ledger correction
+		if req.Stock != nil {
+			if err := s.inventoryService.CorrectStock(ctx, req.ID, *req.Stock); err != nil {
+				return err
+			}
+			req.Stock = nil
+		}
+
+		if err := s.repository.Update(ctx, req); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return variantDomain.ErrVariantNotFound
+			}
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
+				return productDomain.ErrProductSKUExists
+			}
+			return fmt.Errorf("failed to update variant: %w", err)
+		}
+		return nil
+	})
+}
+
+// getParent loads the product variants are attached to, which must not be a
+// variant itself
+func (s *VariantServiceImpl) getParent(ctx context.Context, productID int64) (productDomain.Product, error) {
+	parent, err := s.productRepository.GetByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return productDomain.Product{}, productDomain.ErrProductNotFound
+		}
+		return productDomain.Product{}, fmt.Errorf("failed to get product: %w", err)
+	}
+	if parent.ParentID != nil {
+		return productDomain.Product{}, variantDomain.ErrNestedVariant
+	}
+	return parent, nil
+}
+
+func (s *VariantServiceImpl) replaceOptions(ctx context.Context, productID int64, definitions []variantDomain.OptionDefinition) error {
+	options := make([]variantDomain.Option, 0, len(definitions))
+	for _, d := range definitions {
+		options = append(options, variantDomain.Option{Name: d.Name, Values: d.Values})
+	}
+	if err := s.repository.ReplaceOptions(ctx, productID, options); err != nil {
+		return fmt.Errorf("failed to set product options: %w", err)
+	}
+	return nil
+}
+
+// createVariant inserts one variant, named after the parent and its option
+// values, and books its opening stock in the ledger
+func (s *VariantServiceImpl) createVariant(ctx context.Context, parent productDomain.Product, options []variantDomain.Option, sku string, values map[string]string, priceOverride *decimal.Decimal, stock int, status *productDomain.ProductStatus) (variantDomain.Variant, error) {
+	labels := make([]string, 0, len(options))
+	for _, o := range options {
+		labels = append(labels, values[o.Name])
+	}
+
+	newVariant := variantDomain.Variant{
+		ParentID:      parent.ID,
+		SKU:           sku,
+		Name:          parent.Name + " - " + strings.Join(labels, " / "),
+		Options:       values,
+		PriceOverride: priceOverride,
+		Status:        parent.Status,
+	}
+	if status != nil {
+		newVariant.Status = *status
+	}
+
+	created, err := s.repository.Create(ctx, newVariant)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
+			if pgErr.ConstraintName == "idx_products_variant_options" {
+				return variantDomain.Variant{}, variantDomain.ErrVariantExists
+			}
+			return variantDomain.Variant{}, productDomain.ErrProductSKUExists
+		}
+		return variantDomain.Variant{}, fmt.Errorf("failed to create variant: %w", err)
+	}
+
+	if stock > 0 {
+		_, err := s.inventoryService.PostMovement(ctx, inventoryDomain.CreateStockMovementRequest{
+			ProductID:  created.ID,
+			Type:       inventoryDomain.MovementTypeReceipt,
+			ReasonCode: inventoryDomain.ReasonOpeningBalance,
+			Quantity:   stock,
+		})
+		if err != nil {
+			return variantDomain.Variant{}, fmt.Errorf("failed to record opening stock: %w", err)
+		}
+		created.Stock = stock
+	}
+
+	return created, nil
+}
+
+// matchesOptions reports whether values picks an allowed value for every
+// option, and nothing else
+func matchesOptions(options []variantDomain.Option, values map[string]string) bool {
+	if len(values) != len(options) {
+		return false
+	}
+	for _, o := range options {
+		value, ok := values[o.Name]
+		if !ok || !slices.Contains(o.Values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// combinationKey identifies a combination of option values independent of map order
+func combinationKey(options []variantDomain.Option, values map[string]string) string {
+	parts := make([]string, 0, len(options))
+	for _, o := range options {
+		parts = append(parts, o.Name+"="+values[o.Name])
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// expandMatrix returns every combination of option values
+func expandMatrix(options []variantDomain.Option) []map[string]string {
+	combinations := []map[string]string{{}}
+	for _, o := range options {
+		var next []map[string]string
+		for _, c := range combinations {
+			for _, v := range o.Values {
+				combination := make(map[string]string, len(c)+1)
+				for k, existing := range c {
+					combination[k] = existing
+				}
+				combination[o.Name] = v
+				next = append(next, combination)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+func toVariantResponse(v variantDomain.Variant) variantDomain.VariantResponse {
+	return variantDomain.VariantResponse{
+		ID:            v.ID,
+		ProductID:     v.ParentID,
+		SKU:           v.SKU,
+		Name:          v.Name,
+		Options:       v.Options,
+		Price:         v.Price,
+		PriceOverride: v.PriceOverride,
+		Stock:         v.Stock,
+		Status:        v.Status,
+		ImageURL: func() *string {
+			if v.ImageURL == nil || *v.ImageURL == "" {
+				return nil
+			}
+			return v.ImageURL
+		}(),
+		CreatedAt: v.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: v.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,334 @@
+package variant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
+	productDomain "github.com/naxumi/bnsp-jwd/internal/domain/product"
+	variantDomain "github.com/naxumi/bnsp-jwd/internal/domain/variant"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Transactor runs the function directly, without a database
+type MockTransactor struct{}
+
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// Mock Repository
+type MockVariantRepository struct {
+	mock.Mock
+}
+
+func (m *MockVariantRepository) GetOptions(ctx context.Context, productID int64) ([]variantDomain.Option, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).([]variantDomain.Option), args.Error(1)
+}
+
+func (m *MockVariantRepository) ReplaceOptions(ctx context.Context, productID int64, options []variantDomain.Option) error {
+	args := m.Called(ctx, productID, options)
+	return args.Error(0)
+}
+
+func (m *MockVariantRepository) Create(ctx context.Context, variant variantDomain.Variant) (variantDomain.Variant, error) {
+	args := m.Called(ctx, variant)
+	return args.Get(0).(variantDomain.Variant), args.Error(1)
+}
+
+func (m *MockVariantRepository) GetByID(ctx context.Context, parentID int64, id int64) (variantDomain.Variant, error) {
+	args := m.Called(ctx, parentID, id)
+	return args.Get(0).(variantDomain.Variant), args.Error(1)
+}
+
+func (m *MockVariantRepository) GetByParent(ctx context.Context, parentID int64) ([]variantDomain.Variant, error) {
+	args := m.Called(ctx, parentID)
+	return args.Get(0).([]variantDomain.Variant), args.Error(1)
+}
+
+func (m *MockVariantRepository) Update(ctx context.Context, variant variantDomain.UpdateVariantRequest) error {
+	args := m.Called(ctx, variant)
+	return args.Error(0)
+}
+
+// Mock Product Repository
+type MockProductRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductRepository) Create(ctx context.Context, product productDomain.Product) (productDomain.Product, error) {
+	args := m.Called(ctx, product)
+	return args.Get(0).(productDomain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetByID(ctx context.Context, id int64) (productDomain.Product, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(productDomain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetByIDForUpdate(ctx context.Context, id int64) (productDomain.Product, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(productDomain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetBySKU(ctx context.Context, sku string) (productDomain.Product, error) {
+	args := m.Called(ctx, sku)
+	return args.Get(0).(productDomain.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetAll(ctx context.Context, filter productDomain.ListProductFilter) ([]productDomain.Product, int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]productDomain.Product), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockProductRepository) Export(ctx context.Context, filter productDomain.ListProductFilter, fn func(productDomain.Product) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) GetFacets(ctx context.Context, filter productDomain.ListProductFilter) (productDomain.Facets, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(productDomain.Facets), args.Error(1)
+}
+
+func (m *MockProductRepository) Suggest(ctx context.Context, filter productDomain.SuggestProductFilter) ([]productDomain.ProductSuggestion, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]productDomain.ProductSuggestion), args.Error(1)
+}
+
+func (m *MockProductRepository) Update(ctx context.Context, product productDomain.UpdateProductRequest) error {
+	args := m.Called(ctx, product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Delete(ctx context.Context, id int64, expectedVersion *int) error {
+	args := m.Called(ctx, id, expectedVersion)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Restore(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Purge(ctx context.Context, before time.Time) (int64, []string, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Get(1).([]string), args.Error(2)
+}
+
+// Mock Inventory Service
+type MockInventoryService struct {
+	mock.Mock
+}
+
+func (m *MockInventoryService) PostMovement(ctx context.Context, req inventoryDomain.CreateStockMovementRequest) (inventoryDomain.StockMovementResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(inventoryDomain.StockMovementResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) CorrectStock(ctx context.Context, productID int64, target int) error {
+	args := m.Called(ctx, productID, target)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) ListMovements(ctx context.Context, filter inventoryDomain.ListStockMovementFilter) (inventoryDomain.ListStockMovementResponse, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(inventoryDomain.ListStockMovementResponse), args.Error(1)
+}
+
+func newTestService() (*VariantServiceImpl, *MockVariantRepository, *MockProductRepository, *MockInventoryService) {
+	mockRepo := new(MockVariantRepository)
+	mockProductRepo := new(MockProductRepository)
+	mockInventoryService := new(MockInventoryService)
+	service := &VariantServiceImpl{
+		transactor:        &MockTransactor{},
+		repository:        mockRepo,
+		productRepository: mockProductRepo,
+		inventoryService:  mockInventoryService,
+	}
+	return service, mockRepo, mockProductRepo, mockInventoryService
+}
+
+var shoeOptions = []variantDomain.Option{
+	{Name: "Size", Values: []string{"38", "39"}},
+	{Name: "Color", Values: []string{"Red", "Navy Blue"}},
+}
+
+var shoes = productDomain.Product{
+	ID:     12,
+	SKU:    "SKU-012",
+	Name:   "Women's Running Shoes",
+	Price:  decimal.NewFromInt(890000),
+	Status: productDomain.ProductStatusActive,
+}
+
+// Tests for CreateVariant
+func TestVariantService_CreateVariant_Success(t *testing.T) {
+	service, mockRepo, mockProductRepo, mockInventoryService := newTestService()
+
+	price := decimal.NewFromInt(950000)
+	req := variantDomain.CreateVariantRequest{
+		ProductID:     12,
+		SKU:           "SKU-012-38-RED",
+		Options:       map[string]string{"Size": "38", "Color": "Red"},
+		PriceOverride: &price,
+		Stock:         10,
+	}
+
+	mockProductRepo.On("GetByID", mock.Anything, int64(12)).Return(shoes, nil)
+	mockRepo.On("GetOptions", mock.Anything, int64(12)).Return(shoeOptions, nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(v variantDomain.Variant) bool {
+		return v.ParentID == 12 && v.SKU == "SKU-012-38-RED" &&
+			v.Name == "Women's Running Shoes - 38 / Red" &&
+			v.PriceOverride.Equal(price) &&
+			v.Status == productDomain.ProductStatusActive
+	})).Return(variantDomain.Variant{ID: 51, ParentID: 12, SKU: "SKU-012-38-RED", Price: price, PriceOverride: &price, CreatedAt: time.Now()}, nil)
+	mockInventoryService.On("PostMovement", mock.Anything, mock.MatchedBy(func(m inventoryDomain.CreateStockMovementRequest) bool {
+		return m.ProductID == 51 && m.Quantity == 10 && m.ReasonCode == inventoryDomain.ReasonOpeningBalance
+	})).Return(inventoryDomain.StockMovementResponse{ID: 1}, nil)
+
+	result, err := service.CreateVariant(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(51), result.ID)
+	assert.Equal(t, int64(12), result.ProductID)
+	assert.Equal(t, 10, result.Stock)
+	mockRepo.AssertExpectations(t)
+	mockInventoryService.AssertExpectations(t)
+}
+
+func TestVariantService_CreateVariant_InvalidOptions(t *testing.T) {
+	service, mockRepo, mockProductRepo, _ := newTestService()
+
+	req := variantDomain.CreateVariantRequest{
+		ProductID: 12,
+		SKU:       "SKU-012-41",
+		Options:   map[string]string{"Size": "41", "Color": "Red"},
+	}
+
+	mockProductRepo.On("GetByID", mock.Anything, int64(12)).Return(shoes, nil)
+	mockRepo.On("GetOptions", mock.Anything, int64(12)).Return(shoeOptions, nil)
+
+	_, err := service.CreateVariant(context.Background(), req)
+
+	assert.Equal(t, variantDomain.ErrInvalidOptions, err)
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestVariantService_CreateVariant_OfVariant(t *testing.T) {
+	service, mockRepo, mockProductRepo, _ := newTestService()
+
+	parentID := int64(12)
+	mockProductRepo.On("GetByID", mock.Anything, int64(51)).
+		Return(productDomain.Product{ID: 51, ParentID: &parentID}, nil)
+
+	_, err := service.CreateVariant(context.Background(), variantDomain.CreateVariantRequest{
+		ProductID: 51,
+		SKU:       "SKU-012-38-RED-X",
+		Options:   map[string]string{"Size": "38"},
+	})
+
+	assert.Equal(t, variantDomain.ErrNestedVariant, err)
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+func TestVariantService_CreateVariant_DuplicateCombination(t *testing.T) {
+	service, mockRepo, mockProductRepo, _ := newTestService()
+
+	mockProductRepo.On("GetByID", mock.Anything, int64(12)).Return(shoes, nil)
+	mockRepo.On("GetOptions", mock.Anything, int64(12)).Return(shoeOptions, nil)
+	pgErr := &pgconn.PgError{Code: "23505", ConstraintName: "idx_products_variant_options"}
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(variantDomain.Variant{}, pgErr)
+
+	_, err := service.CreateVariant(context.Background(), variantDomain.CreateVariantRequest{
+		ProductID: 12,
+		SKU:       "SKU-012-OTHER",
+		Options:   map[string]string{"Size": "38", "Color": "Red"},
+	})
+
+	assert.Equal(t, variantDomain.ErrVariantExists, err)
+}
+
+// Tests for GenerateVariants
+func TestVariantService_GenerateVariants_SkipsExisting(t *testing.T) {
+	service, mockRepo, mockProductRepo, mockInventoryService := newTestService()
+
+	mockProductRepo.On("GetByID", mock.Anything, int64(12)).Return(shoes, nil)
+	mockRepo.On("GetOptions", mock.Anything, int64(12)).Return(shoeOptions, nil)
+	mockRepo.On("GetByParent", mock.Anything, int64(12)).Return([]variantDomain.Variant{
+		{ID: 51, ParentID: 12, Options: map[string]string{"Color": "Red", "Size": "38"}},
+	}, nil)
+
+	var createdSKUs []string
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			createdSKUs = append(createdSKUs, args.Get(1).(variantDomain.Variant).SKU)
+		}).
+		Return(variantDomain.Variant{ID: 60, ParentID: 12}, nil)
+
+	result, err := service.GenerateVariants(context.Background(), variantDomain.GenerateVariantsRequest{ProductID: 12})
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+	assert.Equal(t, []string{"SKU-012-38-NAVY-BLUE", "SKU-012-39-RED", "SKU-012-39-NAVY-BLUE"}, createdSKUs)
+	mockInventoryService.AssertNotCalled(t, "PostMovement")
+}
+
+func TestVariantService_GenerateVariants_TooManyCombinations(t *testing.T) {
+	service, mockRepo, mockProductRepo, _ := newTestService()
+
+	values := make([]string, 11)
+	for i := range values {
+		values[i] = string(rune('A' + i))
+	}
+	mockProductRepo.On("GetByID", mock.Anything, int64(12)).Return(shoes, nil)
+	mockRepo.On("GetOptions", mock.Anything, int64(12)).Return([]variantDomain.Option{
+		{Name: "Size", Values: values},
+		{Name: "Color", Values: values},
+	}, nil)
+
+	_, err := service.GenerateVariants(context.Background(), variantDomain.GenerateVariantsRequest{ProductID: 12})
+
+	assert.Equal(t, variantDomain.ErrTooManyCombinations, err)
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+// Tests for UpdateVariant
+func TestVariantService_UpdateVariant_StockGoesThroughLedger(t *testing.T) {
+	service, mockRepo, _, mockInventoryService := newTestService()
+
+	stock := 4
+	req := variantDomain.UpdateVariantRequest{ID: 51, ProductID: 12, Stock: &stock, ClearPriceOverride: true}
+
+	mockRepo.On("GetByID", mock.Anything, int64(12), int64(51)).
+		Return(variantDomain.Variant{ID: 51, ParentID: 12}, nil)
+	mockInventoryService.On("CorrectStock", mock.Anything, int64(51), 4).
+		Return(nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u variantDomain.UpdateVariantRequest) bool {
+		return u.Stock == nil && u.ClearPriceOverride
+	})).Return(nil)
+
+	err := service.UpdateVariant(context.Background(), req)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockInventoryService.AssertExpectations(t)
+}
+
+func TestVariantService_UpdateVariant_NotFound(t *testing.T) {
+	service, mockRepo, _, _ := newTestService()
+
+	mockRepo.On("GetByID", mock.Anything, int64(12), int64(99)).
+		Return(variantDomain.Variant{}, pgx.ErrNoRows)
+
+	err := service.UpdateVariant(context.Background(), variantDomain.UpdateVariantRequest{ID: 99, ProductID: 12})
+
+	assert.Equal(t, variantDomain.ErrVariantNotFound, err)
+	mockRepo.AssertNotCalled(t, "Update")
+}
@@ -0,0 +1,201 @@
+package warehouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
+	warehouseDomain "github.com/naxumi/bnsp-jwd/internal/domain/warehouse"
+	"github.com/naxumi/bnsp-jwd/internal/pkg/database"
+)
+
+type WarehouseServiceImpl struct {
+	transactor       database.Transactor
+	repository       warehouseDomain.WarehouseRepository
+	inventoryService inventoryDomain.InventoryService
+}
+
+func NewWarehouseService(transactor database.Transactor, repository warehouseDomain.WarehouseRepository, inventoryService inventoryDomain.InventoryService) warehouseDomain.WarehouseService {
+	return &WarehouseServiceImpl{
+		transactor:       transactor,
+		repository:       repository,
+		inventoryService: inventoryService,
+	}
+}
+
+func (s *WarehouseServiceImpl) CreateWarehouse(ctx context.Context, req warehouseDomain.CreateWarehouseRequest) (warehouseDomain.WarehouseResponse, error) {
+	newWarehouse := warehouseDomain.Warehouse{
+		Code:    req.Code,
+		Name:    req.Name,
+		Address: req.Address,
+	}
+
+	createdWarehouse, err := s.repository.Create(ctx, newWarehouse)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
+			return warehouseDomain.WarehouseResponse{}, warehouseDomain.ErrWarehouseCodeExists
+		}
+		return warehouseDomain.WarehouseResponse{}, fmt.Errorf("failed to create warehouse: %w", err)
+	}
+
+	return toWarehouseResponse(createdWarehouse), nil
+}
+
+func (s *WarehouseServiceImpl) GetWarehouse(ctx context.Context, id int64) (warehouseDomain.WarehouseResponse, error) {
+	w, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return warehouseDomain.WarehouseResponse{}, warehouseDomain.ErrWarehouseNotFound
+		}
+		return warehouseDomain.WarehouseResponse{}, fmt.Errorf("failed to get warehouse: %w", err)
+	}
+
+	return toWarehouseResponse(w), nil
+}
+
+func (s *WarehouseServiceImpl) ListWarehouses(ctx context.Context) ([]warehouseDomain.WarehouseResponse, error) {
+	warehouses, err := s.repository.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warehouses: %w", err)
+	}
+
+	warehouseResponses := []warehouseDomain.WarehouseResponse{}
+	for _, w := range warehouses {
+		warehouseResponses = append(warehouseResponses, toWarehouseResponse(w))
+	}
+
+	return warehouseResponses, nil
+}
+
+func (s *WarehouseServiceImpl) UpdateWarehouse(ctx context.Context, req warehouseDomain.UpdateWarehouseRequest) error {
+	if err := s.repository.Update(ctx, req); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return warehouseDomain.ErrWarehouseNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique violation
+			return warehouseDomain.ErrWarehouseCodeExists
+		}
+		return fmt.Errorf("failed to update warehouse: %w", err)
+	}
+	return nil
+}
+
+// DeleteWarehouse deletes an empty warehouse. The warehouse row stays locked
+// from the stock check to the delete, so a receipt or transfer into it waits
+// and then fails instead of being lost.
+func (s *WarehouseServiceImpl) DeleteWarehouse(ctx context.Context, id int64) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		w, err := s.repository.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return warehouseDomain.ErrWarehouseNotFound
+			}
+			return fmt.Errorf("failed to get warehouse: %w", err)
+		}
+
+		if w.IsDefault {
+			return warehouseDomain.ErrDefaultWarehouseDelete
+		}
+
+		// Stock must be transferred out first, otherwise products.stock would no
+		// longer match the sum of its warehouse levels
+		total, err := s.repository.TotalStock(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get warehouse stock: %w", err)
+		}
+		if total > 0 {
+			return warehouseDomain.ErrWarehouseHasStock
+		}
+
+		if err := s.repository.Delete(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return warehouseDomain.ErrWarehouseNotFound
+			}
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign key violation
+				return warehouseDomain.ErrWarehouseHasStock
+			}
+			return fmt.Errorf("failed to delete warehouse: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// TransferStock books an issue at the source and a receipt at the destination
+// in one transaction, so the product's total stock never changes.
+func (s *WarehouseServiceImpl) TransferStock(ctx context.Context, req warehouseDomain.TransferStockRequest) (warehouseDomain.TransferStockResponse, error) {
+	if req.FromWarehouseID == req.ToWarehouseID {
+		return warehouseDomain.TransferStockResponse{}, warehouseDomain.ErrSameWarehouseTransfer
+	}
+
+	result := warehouseDomain.TransferStockResponse{
+		ProductID:       req.ProductID,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		Quantity:        req.Quantity,
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, id := range []int64{req.FromWarehouseID, req.ToWarehouseID} {
+			if _, err := s.repository.GetByID(ctx, id); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return warehouseDomain.ErrWarehouseNotFound
+				}
+				return fmt.Errorf("failed to get warehouse: %w", err)
+			}
+		}
+
+		outgoing, err := s.inventoryService.PostMovement(ctx, inventoryDomain.CreateStockMovementRequest{
+			ProductID:   req.ProductID,
+			WarehouseID: &req.FromWarehouseID,
+			Type:        inventoryDomain.MovementTypeIssue,
+			ReasonCode:  inventoryDomain.ReasonTransferOut,
+			Quantity:    req.Quantity,
+			Reference:   req.Reference,
+			Note:        req.Note,
+		})
+		if err != nil {
+			return err
+		}
+		result.OutgoingMovementID = outgoing.ID
+
+		incoming, err := s.inventoryService.PostMovement(ctx, inventoryDomain.CreateStockMovementRequest{
+			ProductID:   req.ProductID,
+			WarehouseID: &req.ToWarehouseID,
+			Type:        inventoryDomain.MovementTypeReceipt,
+			ReasonCode:  inventoryDomain.ReasonTransferIn,
+			Quantity:    req.Quantity,
+			Reference:   req.Reference,
+			Note:        req.Note,
+		})
+		if err != nil {
+			return err
+		}
+		result.IncomingMovementID = incoming.ID
+
+		return nil
+	})
+	if err != nil {
+		return warehouseDomain.TransferStockResponse{}, err
+	}
+
+	return result, nil
+}
+
+func toWarehouseResponse(w warehouseDomain.Warehouse) warehouseDomain.WarehouseResponse {
+	return warehouseDomain.WarehouseResponse{
+		ID:        w.ID,
+		Code:      w.Code,
+		Name:      w.Name,
+		Address:   w.Address,
+		IsDefault: w.IsDefault,
+		CreatedAt: w.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: w.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,273 @@
+package warehouse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	inventoryDomain "github.com/naxumi/bnsp-jwd/internal/domain/inventory"
+	warehouseDomain "github.com/naxumi/bnsp-jwd/internal/domain/warehouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock Transactor runs the function directly, without a database
+type MockTransactor struct{}
+
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// Mock Repository
+type MockWarehouseRepository struct {
+	mock.Mock
+}
+
+func (m *MockWarehouseRepository) Create(ctx context.Context, warehouse warehouseDomain.Warehouse) (warehouseDomain.Warehouse, error) {
+	args := m.Called(ctx, warehouse)
+	return args.Get(0).(warehouseDomain.Warehouse), args.Error(1)
+}
+
+func (m *MockWarehouseRepository) GetByID(ctx context.Context, id int64) (warehouseDomain.Warehouse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(warehouseDomain.Warehouse), args.Error(1)
+}
+
+func (m *MockWarehouseRepository) GetByIDForUpdate(ctx context.Context, id int64) (warehouseDomain.Warehouse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(warehouseDomain.Warehouse), args.Error(1)
+}
+
+func (m *MockWarehouseRepository) GetAll(ctx context.Context) ([]warehouseDomain.Warehouse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]warehouseDomain.Warehouse), args.Error(1)
+}
+
+func (m *MockWarehouseRepository) Update(ctx context.Context, warehouse warehouseDomain.UpdateWarehouseRequest) error {
+	args := m.Called(ctx, warehouse)
+	return args.Error(0)
+}
+
+func (m *MockWarehouseRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWarehouseRepository) TotalStock(ctx context.Context, id int64) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Mock Inventory Service
+type MockInventoryService struct {
+	mock.Mock
+}
+
+func (m *MockInventoryService) PostMovement(ctx context.Context, req inventoryDomain.CreateStockMovementRequest) (inventoryDomain.StockMovementResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(inventoryDomain.StockMovementResponse), args.Error(1)
+}
+
+func (m *MockInventoryService) CorrectStock(ctx context.Context, productID int64, target int) error {
+	args := m.Called(ctx, productID, target)
+	return args.Error(0)
+}
+
+func (m *MockInventoryService) ListMovements(ctx context.Context, filter inventoryDomain.ListStockMovementFilter) (inventoryDomain.ListStockMovementResponse, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(inventoryDomain.ListStockMovementResponse), args.Error(1)
+}
+
+func newTestService() (*WarehouseServiceImpl, *MockWarehouseRepository, *MockInventoryService) {
+	mockRepo := new(MockWarehouseRepository)
+	mockInventoryService := new(MockInventoryService)
+	service := &WarehouseServiceImpl{
+		transactor:       &MockTransactor{},
+		repository:       mockRepo,
+		inventoryService: mockInventoryService,
+	}
+	return service, mockRepo, mockInventoryService
+}
+
+// Tests for CreateWarehouse
+func TestWarehouseService_CreateWarehouse_Success(t *testing.T) {
+	service, mockRepo, _ := newTestService()
+
+	req := warehouseDomain.CreateWarehouseRequest{Code: "WH-2", Name: "Branch Warehouse"}
+
+	now := time.Now()
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(w warehouseDomain.Warehouse) bool {
+		return w.Code == "WH-2" && w.Name == "Branch Warehouse"
+	})).Return(warehouseDomain.Warehouse{ID: 2, Code: "WH-2", Name: "Branch Warehouse", CreatedAt: now, UpdatedAt: now}, nil)
+
+	result, err := service.CreateWarehouse(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.ID)
+	assert.False(t, result.IsDefault)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWarehouseService_CreateWarehouse_DuplicateCode(t *testing.T) {
+	service, mockRepo, _ := newTestService()
+
+	pgErr := &pgconn.PgError{Code: "23505"}
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(warehouseDomain.Warehouse{}, pgErr)
+
+	_, err := service.CreateWarehouse(context.Background(), warehouseDomain.CreateWarehouseRequest{Code: "MAIN", Name: "Dup"})
+
+	assert.Equal(t, warehouseDomain.ErrWarehouseCodeExists, err)
+}
+
+// Tests for DeleteWarehouse
+func TestWarehouseService_DeleteWarehouse_Default(t *testing.T) {
+	service, mockRepo, _ := newTestService()
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(1)).
+		Return(warehouseDomain.Warehouse{ID: 1, Code: "MAIN", IsDefault: true}, nil)
+
+	err := service.DeleteWarehouse(context.Background(), 1)
+
+	assert.Equal(t, warehouseDomain.ErrDefaultWarehouseDelete, err)
+	mockRepo.AssertNotCalled(t, "Delete")
+}
+
+func TestWarehouseService_DeleteWarehouse_HasStock(t *testing.T) {
+	service, mockRepo, _ := newTestService()
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(2)).
+		Return(warehouseDomain.Warehouse{ID: 2, Code: "WH-2"}, nil)
+	mockRepo.On("TotalStock", mock.Anything, int64(2)).
+		Return(int64(12), nil)
+
+	err := service.DeleteWarehouse(context.Background(), 2)
+
+	assert.Equal(t, warehouseDomain.ErrWarehouseHasStock, err)
+	mockRepo.AssertNotCalled(t, "Delete")
+}
+
+func TestWarehouseService_DeleteWarehouse_Success(t *testing.T) {
+	service, mockRepo, _ := newTestService()
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(2)).
+		Return(warehouseDomain.Warehouse{ID: 2, Code: "WH-2"}, nil)
+	mockRepo.On("TotalStock", mock.Anything, int64(2)).
+		Return(int64(0), nil)
+	mockRepo.On("Delete", mock.Anything, int64(2)).
+		Return(nil)
+
+	err := service.DeleteWarehouse(context.Background(), 2)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// A stock row booked after the check makes the database refuse the delete
+func TestWarehouseService_DeleteWarehouse_StockBookedMeanwhile(t *testing.T) {
+	service, mockRepo, _ := newTestService()
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, int64(2)).
+		Return(warehouseDomain.Warehouse{ID: 2, Code: "WH-2"}, nil)
+	mockRepo.On("TotalStock", mock.Anything, int64(2)).
+		Return(int64(0), nil)
+	mockRepo.On("Delete", mock.Anything, int64(2)).
+		Return(&pgconn.PgError{Code: "23503"})
+
+	err := service.DeleteWarehouse(context.Background(), 2)
+
+	assert.Equal(t, warehouseDomain.ErrWarehouseHasStock, err)
+}
+
+// Tests for TransferStock
+func TestWarehouseService_TransferStock_Success(t *testing.T) {
+	service, mockRepo, mockInventoryService := newTestService()
+
+	req := warehouseDomain.TransferStockRequest{
+		ProductID:       7,
+		FromWarehouseID: 1,
+		ToWarehouseID:   2,
+		Quantity:        5,
+	}
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(warehouseDomain.Warehouse{ID: 1}, nil)
+	mockRepo.On("GetByID", mock.Anything, int64(2)).
+		Return(warehouseDomain.Warehouse{ID: 2}, nil)
+
+	mockInventoryService.On("PostMovement", mock.Anything, mock.MatchedBy(func(m inventoryDomain.CreateStockMovementRequest) bool {
+		return m.Type == inventoryDomain.MovementTypeIssue && *m.WarehouseID == 1 && m.Quantity == 5 &&
+			m.ReasonCode == inventoryDomain.ReasonTransferOut
+	})).Return(inventoryDomain.StockMovementResponse{ID: 100}, nil)
+	mockInventoryService.On("PostMovement", mock.Anything, mock.MatchedBy(func(m inventoryDomain.CreateStockMovementRequest) bool {
+		return m.Type == inventoryDomain.MovementTypeReceipt && *m.WarehouseID == 2 && m.Quantity == 5 &&
+			m.ReasonCode == inventoryDomain.ReasonTransferIn
+	})).Return(inventoryDomain.StockMovementResponse{ID: 101}, nil)
+
+	result, err := service.TransferStock(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), result.OutgoingMovementID)
+	assert.Equal(t, int64(101), result.IncomingMovementID)
+	mockInventoryService.AssertExpectations(t)
+}
+
+func TestWarehouseService_TransferStock_InsufficientStock(t *testing.T) {
+	service, mockRepo, mockInventoryService := newTestService()
+
+	req := warehouseDomain.TransferStockRequest{
+		ProductID:       7,
+		FromWarehouseID: 1,
+		ToWarehouseID:   2,
+		Quantity:        500,
+	}
+
+	mockRepo.On("GetByID", mock.Anything, mock.Anything).
+		Return(warehouseDomain.Warehouse{}, nil)
+	mockInventoryService.On("PostMovement", mock.Anything, mock.Anything).
+		Return(inventoryDomain.StockMovementResponse{}, inventoryDomain.ErrInsufficientStock).Once()
+
+	_, err := service.TransferStock(context.Background(), req)
+
+	assert.Equal(t, inventoryDomain.ErrInsufficientStock, err)
+	mockInventoryService.AssertNumberOfCalls(t, "PostMovement", 1)
+}
+
+func TestWarehouseService_TransferStock_UnknownWarehouse(t *testing.T) {
+	service, mockRepo, mockInventoryService := newTestService()
+
+	req := warehouseDomain.TransferStockRequest{
+		ProductID:       7,
+		FromWarehouseID: 1,
+		ToWarehouseID:   99,
+		Quantity:        5,
+	}
+
+	mockRepo.On("GetByID", mock.Anything, int64(1)).
+		Return(warehouseDomain.Warehouse{ID: 1}, nil)
+	mockRepo.On("GetByID", mock.Anything, int64(99)).
+		Return(warehouseDomain.Warehouse{}, pgx.ErrNoRows)
+
+	_, err := service.TransferStock(context.Background(), req)
+
+	assert.Equal(t, warehouseDomain.ErrWarehouseNotFound, err)
+	mockInventoryService.AssertNotCalled(t, "PostMovement")
+}
+
+func TestWarehouseService_TransferStock_SameWarehouse(t *testing.T) {
+	service, _, mockInventoryService := newTestService()
+
+	req := warehouseDomain.TransferStockRequest{
+		ProductID:       7,
+		FromWarehouseID: 1,
+		ToWarehouseID:   1,
+		Quantity:        5,
+	}
+
+	_, err := service.TransferStock(context.Background(), req)
+
+	assert.Equal(t, warehouseDomain.ErrSameWarehouseTransfer, err)
+	mockInventoryService.AssertNotCalled(t, "PostMovement")
+}
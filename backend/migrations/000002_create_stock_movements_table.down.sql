@@ -0,0 +1,3 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_stock_non_negative;
+DROP TABLE IF EXISTS stock_movements;
+DROP TYPE IF EXISTS stock_movement_type;
@@ -0,0 +1,38 @@
+CREATE TYPE stock_movement_type AS ENUM (
+    'Receipt',
+    'Issue',
+    'Adjustment',
+    'Correction'
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+
+    movement_type stock_movement_type NOT NULL,
+    reason_code VARCHAR(50) NOT NULL,
+
+    -- Signed delta applied to products.stock
+    quantity INT NOT NULL CHECK (quantity <> 0),
+    balance_after INT NOT NULL,
+
+    reference VARCHAR(255) NULL,
+    note TEXT NULL,
+    actor VARCHAR(255) NOT NULL,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_created
+    ON stock_movements (product_id, created_at DESC, id DESC);
+
+-- products.stock is now maintained through the ledger and can never go negative
+ALTER TABLE products
+    ADD CONSTRAINT products_stock_non_negative CHECK (stock >= 0);
+
+-- Opening balance for every product that already holds stock
+INSERT INTO stock_movements (product_id, movement_type, reason_code, quantity, balance_after, reference, actor)
+SELECT id, 'Adjustment', 'OPENING_BALANCE', stock, stock, 'migration 000002', 'system'
+FROM products
+WHERE stock <> 0;
@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+    id SERIAL PRIMARY KEY,
+
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    address TEXT NULL,
+
+    -- Movements that don't name a warehouse are booked here
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- At most one default warehouse
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_single_default
+    ON warehouses (is_default) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS warehouse_stock (
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    warehouse_id INT NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+
+    quantity INT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (product_id, warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_stock_warehouse
+    ON warehouse_stock (warehouse_id, quantity);
+
+ALTER TABLE stock_movements
+    ADD COLUMN warehouse_id INT NULL REFERENCES warehouses(id) ON DELETE SET NULL;
+
+-- Existing stock is assumed to sit in the main warehouse
+INSERT INTO warehouses (code, name, is_default)
+VALUES ('MAIN', 'Main Warehouse', TRUE);
+
+INSERT INTO warehouse_stock (product_id, warehouse_id, quantity)
+SELECT p.id, w.id, p.stock
+FROM products p
+CROSS JOIN warehouses w
+WHERE w.is_default AND p.stock > 0;
+
+UPDATE stock_movements
+SET warehouse_id = (SELECT id FROM warehouses WHERE is_default);
@@ -0,0 +1,10 @@
+ALTER TABLE products
+    ADD COLUMN category VARCHAR(100) NOT NULL DEFAULT 'Uncategorized';
+
+UPDATE products p
+SET category = c.name
+FROM categories c
+WHERE c.id = p.category_id;
+
+ALTER TABLE products DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS categories;
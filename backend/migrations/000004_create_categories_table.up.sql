@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+
+    parent_id INT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(255) NOT NULL UNIQUE,
+
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    CHECK (parent_id IS NULL OR parent_id <> id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+
+-- Convert the free-text categories into rows. Spellings that only differ in
+-- case or "&" vs "and" collapse into one category (same rules as slug.Make),
+-- named after the most used spelling.
+INSERT INTO categories (name, slug)
+SELECT DISTINCT ON (slug) name, slug
+FROM (
+    SELECT
+        category AS name,
+        COALESCE(NULLIF(
+            trim(both '-' from regexp_replace(replace(lower(category), '&', ' and '), '[^a-z0-9]+', '-', 'g')),
+            ''), 'uncategorized') AS slug,
+        COUNT(*) AS usage
+    FROM products
+    GROUP BY category
+) spellings
+ORDER BY slug, usage DESC, name;
+
+-- Fallback for products created without a category
+INSERT INTO categories (name, slug)
+VALUES ('Uncategorized', 'uncategorized')
+ON CONFLICT (slug) DO NOTHING;
+
+ALTER TABLE products
+    ADD COLUMN category_id INT NULL REFERENCES categories(id) ON DELETE RESTRICT;
+
+UPDATE products p
+SET category_id = c.id
+FROM categories c
+WHERE c.slug = COALESCE(NULLIF(
+    trim(both '-' from regexp_replace(replace(lower(p.category), '&', ' and '), '[^a-z0-9]+', '-', 'g')),
+    ''), 'uncategorized');
+
+ALTER TABLE products ALTER COLUMN category_id SET NOT NULL;
+ALTER TABLE products DROP COLUMN category;
+
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products (category_id);
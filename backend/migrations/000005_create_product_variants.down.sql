@@ -0,0 +1,16 @@
+DELETE FROM products WHERE parent_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_products_variant_options;
+DROP INDEX IF EXISTS idx_products_parent_id;
+
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_variant_options,
+    DROP CONSTRAINT IF EXISTS products_price_required;
+
+ALTER TABLE products ALTER COLUMN price SET NOT NULL;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS option_values,
+    DROP COLUMN IF EXISTS parent_id;
+
+DROP TABLE IF EXISTS product_options;
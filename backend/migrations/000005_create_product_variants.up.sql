@@ -0,0 +1,31 @@
+-- Option definitions of a parent product, e.g. Size: [38, 39, 40]
+CREATE TABLE IF NOT EXISTS product_options (
+    id SERIAL PRIMARY KEY,
+
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+
+    name VARCHAR(50) NOT NULL,
+    allowed_values TEXT[] NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+
+    UNIQUE (product_id, name)
+);
+
+-- Variants are products with a parent. They keep their own SKU, stock, ledger
+-- and image; a NULL price means the variant sells at the parent's price.
+ALTER TABLE products
+    ADD COLUMN parent_id INT NULL REFERENCES products(id) ON DELETE CASCADE,
+    ADD COLUMN option_values JSONB NULL;
+
+ALTER TABLE products ALTER COLUMN price DROP NOT NULL;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_price_required CHECK (parent_id IS NOT NULL OR price IS NOT NULL),
+    ADD CONSTRAINT products_variant_options CHECK ((parent_id IS NULL) = (option_values IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_products_parent_id ON products (parent_id);
+
+-- A parent can only have one variant per option combination
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_variant_options
+    ON products (parent_id, option_values)
+    WHERE parent_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_search_vector;
+DROP TRIGGER IF EXISTS trg_products_search_vector ON products;
+DROP FUNCTION IF EXISTS products_search_vector_update();
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS product_search_config();
@@ -0,0 +1,30 @@
+-- Text search configuration used for products. Defaults to 'simple', which
+-- does no stemming and so treats Indonesian and English text alike. Set
+-- app.search_config (DB_SEARCH_CONFIG, or ALTER DATABASE ... SET) to use a
+-- language configuration instead, then rebuild with:
+--   UPDATE products SET search_vector = NULL;
+CREATE OR REPLACE FUNCTION product_search_config() RETURNS regconfig AS $$
+    SELECT COALESCE(NULLIF(current_setting('app.search_config', true), ''), 'simple')::regconfig
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE products ADD COLUMN search_vector tsvector;
+
+-- SKUs are always indexed with 'simple', they are codes and must not be stemmed
+CREATE OR REPLACE FUNCTION products_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', COALESCE(NEW.sku, '')), 'A') ||
+        setweight(to_tsvector(product_search_config(), COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector(product_search_config(), COALESCE(NEW.description, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_search_vector
+    BEFORE INSERT OR UPDATE ON products
+    FOR EACH ROW EXECUTE FUNCTION products_search_vector_update();
+
+-- Fills the column for existing rows through the trigger
+UPDATE products SET search_vector = NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_sku_trgm;
+DROP INDEX IF EXISTS idx_products_name_trgm;
+-- The extension is left installed, other database objects may depend on it
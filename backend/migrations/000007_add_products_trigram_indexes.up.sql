@@ -0,0 +1,6 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Back both the suggest endpoint (word_similarity, <%) and the existing
+-- name/sku ILIKE '%...%' filters, which pg_trgm can also answer from a GIN index
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_products_sku_trgm ON products USING GIN (sku gin_trgm_ops);
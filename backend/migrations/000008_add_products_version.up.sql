@@ -0,0 +1,3 @@
+-- Optimistic concurrency: every product update increments version, and a
+-- write carrying If-Match only applies when the version still matches
+ALTER TABLE products ADD COLUMN version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,13 @@
+-- Trashed products are dropped, their SKUs may collide with live products
+DELETE FROM products WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_products_variant_options;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_variant_options
+    ON products (parent_id, option_values)
+    WHERE parent_id IS NOT NULL;
+
+DROP INDEX IF EXISTS products_sku_key;
+ALTER TABLE products ADD CONSTRAINT products_sku_key UNIQUE (sku);
+
+DROP INDEX IF EXISTS idx_products_deleted_at;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,16 @@
+-- Deleting a product only stamps deleted_at; the row stays in the trash
+-- until the purge removes it once the retention period has passed
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+
+-- The purge looks up expired rows by deletion time
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- SKUs and option combinations of trashed products may be reused by live
+-- ones; restoring a product whose SKU was taken fails instead
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_sku_key;
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_key ON products (sku) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_products_variant_options;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_variant_options
+    ON products (parent_id, option_values)
+    WHERE parent_id IS NOT NULL AND deleted_at IS NULL;
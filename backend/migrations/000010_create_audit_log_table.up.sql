@@ -0,0 +1,24 @@
+-- Append-only trail of product mutations. There is no foreign key to the
+-- audited row, the trail outlives products that are purged from the trash.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id BIGINT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(100) NULL,
+
+    -- Changed fields as {"field": {"before": ..., "after": ...}}
+    changes JSONB NOT NULL DEFAULT '{}'::jsonb,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity
+    ON audit_log (entity_type, entity_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor
+    ON audit_log (actor, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created
+    ON audit_log (created_at DESC, id DESC);
@@ -0,0 +1,14 @@
+-- Full snapshot of a product row after every write, numbered by the version
+-- it captured. Revisions go with the product when it is purged.
+CREATE TABLE IF NOT EXISTS product_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+
+    snapshot JSONB NOT NULL,
+
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT product_revisions_product_revision_key UNIQUE (product_id, revision)
+);
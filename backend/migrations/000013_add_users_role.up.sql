@@ -0,0 +1,6 @@
+-- Role granting the user's permissions, see internal/pkg/auth/permission.go.
+-- Existing users keep full access.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'admin';
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'viewer';
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'manager', 'staff', 'viewer'));
@@ -0,0 +1,18 @@
+-- Keys for machine clients, sent in X-API-Key. The key is stored as its
+-- SHA-256 hex; prefix is the start of the key, kept to tell keys apart.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+
+    -- Permissions granted to the key, e.g. {product:read,product:write}
+    scopes TEXT[] NOT NULL,
+
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+
+    CONSTRAINT api_keys_key_hash_key UNIQUE (key_hash)
+);
@@ -0,0 +1,22 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS products_sku_key;
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_key ON products (sku) WHERE deleted_at IS NULL;
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['products', 'stock_movements', 'warehouse_stock', 'product_options', 'product_revisions', 'audit_log']
+    LOOP
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS tenant_id', t);
+    END LOOP;
+END
+$$;
+
+DROP FUNCTION IF EXISTS current_tenant_id();
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,49 @@
+-- Several shops share one deployment. Every row of products and the tables
+-- hanging off them belongs to a tenant, and row-level security hides the
+-- rows of other tenants. The application sets app.tenant_id on each
+-- connection from the request; without it no tenant's rows are visible and
+-- inserts fail. Existing rows go to tenant 1.
+CREATE TABLE IF NOT EXISTS tenants (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO tenants (id, name) VALUES (1, 'Default') ON CONFLICT (id) DO NOTHING;
+SELECT setval('tenants_id_seq', (SELECT MAX(id) FROM tenants));
+
+CREATE OR REPLACE FUNCTION current_tenant_id() RETURNS INT AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', true), '')::int
+$$ LANGUAGE sql STABLE;
+
+-- Tenant-owned tables: the column defaults to the connection's tenant, so
+-- inserts need not name it
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['products', 'stock_movements', 'warehouse_stock', 'product_options', 'product_revisions', 'audit_log']
+    LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id)', t);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenant_id SET DEFAULT current_tenant_id()', t);
+        EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I (tenant_id)', 'idx_' || t || '_tenant_id', t);
+
+        -- FORCE applies the policy to the table owner too; superusers and
+        -- roles with BYPASSRLS still see every row
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I USING (tenant_id = current_tenant_id()) WITH CHECK (tenant_id = current_tenant_id())', t);
+    END LOOP;
+END
+$$;
+
+-- SKUs are unique per tenant
+DROP INDEX IF EXISTS products_sku_key;
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_key ON products (tenant_id, sku) WHERE deleted_at IS NULL;
+
+-- Users and API keys are looked up before the tenant is known, so they are
+-- not under row-level security; they name the tenant they act for
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
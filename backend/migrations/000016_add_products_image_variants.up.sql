@@ -0,0 +1,3 @@
+-- Resized copies of the product image generated on upload, as a JSON array
+-- of {name, format, url, width, height}. NULL when the product has no image.
+ALTER TABLE products ADD COLUMN image_variants JSONB;
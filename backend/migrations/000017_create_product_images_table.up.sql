@@ -0,0 +1,40 @@
+-- A product's image gallery. products.image_url and image_variants keep a
+-- copy of the primary image so existing readers see no change; the product
+-- service updates both together. Images go with the product when it is purged.
+CREATE TABLE IF NOT EXISTS product_images (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL DEFAULT current_tenant_id() REFERENCES tenants(id),
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+
+    url TEXT NOT NULL,
+    variants JSONB,
+    alt_text VARCHAR(255) NULL,
+
+    position INT NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_position ON product_images (product_id, position);
+CREATE INDEX IF NOT EXISTS idx_product_images_tenant_id ON product_images (tenant_id);
+
+-- At most one primary image per product
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_images_primary ON product_images (product_id) WHERE is_primary;
+
+-- The existing image of a product becomes its primary gallery image. The
+-- migration has no app.tenant_id, so the policy on products would hide every
+-- row; it is lifted for the owner while copying, for all tenants at once, and
+-- product_images gets its own policy afterwards.
+ALTER TABLE products NO FORCE ROW LEVEL SECURITY;
+
+INSERT INTO product_images (tenant_id, product_id, url, variants, position, is_primary)
+SELECT tenant_id, id, image_url, image_variants, 0, TRUE
+FROM products
+WHERE image_url IS NOT NULL AND image_url <> '';
+
+ALTER TABLE products FORCE ROW LEVEL SECURITY;
+
+ALTER TABLE product_images ENABLE ROW LEVEL SECURITY;
+ALTER TABLE product_images FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON product_images USING (tenant_id = current_tenant_id()) WITH CHECK (tenant_id = current_tenant_id());
@@ -0,0 +1,4 @@
+ALTER TABLE warehouse_stock
+    DROP CONSTRAINT IF EXISTS warehouse_stock_warehouse_id_fkey,
+    ADD CONSTRAINT warehouse_stock_warehouse_id_fkey
+        FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+-- A warehouse holding stock cannot be deleted: removing its stock rows along
+-- with it would leave products.stock counting units that are nowhere
+ALTER TABLE warehouse_stock
+    DROP CONSTRAINT IF EXISTS warehouse_stock_warehouse_id_fkey,
+    ADD CONSTRAINT warehouse_stock_warehouse_id_fkey
+        FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON DELETE RESTRICT;
@@ -0,0 +1,89 @@
+DROP TRIGGER IF EXISTS tenants_create_defaults ON tenants;
+DROP FUNCTION IF EXISTS create_tenant_defaults();
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['categories', 'warehouses']
+    LOOP
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+    END LOOP;
+END
+$$;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_category_id_fkey;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_parent_id_fkey;
+ALTER TABLE warehouse_stock DROP CONSTRAINT IF EXISTS warehouse_stock_warehouse_id_fkey;
+
+ALTER TABLE products NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE warehouse_stock NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE stock_movements NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE product_revisions NO FORCE ROW LEVEL SECURITY;
+
+-- Point the other tenants back at tenant 1's category or warehouse with the
+-- same slug or code and drop their copies. Rows without a counterpart stay.
+CREATE TEMPORARY TABLE category_copies AS
+SELECT c.id AS old_id, original.id AS new_id
+FROM categories c
+JOIN categories original ON original.tenant_id = 1 AND original.slug = c.slug
+WHERE c.tenant_id <> 1;
+
+CREATE TEMPORARY TABLE warehouse_copies AS
+SELECT w.id AS old_id, original.id AS new_id
+FROM warehouses w
+JOIN warehouses original ON original.tenant_id = 1 AND original.code = w.code
+WHERE w.tenant_id <> 1;
+
+UPDATE products p SET category_id = m.new_id FROM category_copies m WHERE m.old_id = p.category_id;
+UPDATE categories c SET parent_id = m.new_id FROM category_copies m WHERE m.old_id = c.parent_id;
+UPDATE product_revisions r
+SET snapshot = jsonb_set(r.snapshot, '{category_id}', to_jsonb(m.new_id))
+FROM category_copies m
+WHERE m.old_id = (r.snapshot->>'category_id')::int;
+UPDATE warehouse_stock ws SET warehouse_id = m.new_id FROM warehouse_copies m WHERE m.old_id = ws.warehouse_id;
+UPDATE stock_movements sm SET warehouse_id = m.new_id FROM warehouse_copies m WHERE m.old_id = sm.warehouse_id;
+
+DELETE FROM categories WHERE id IN (SELECT old_id FROM category_copies);
+DELETE FROM warehouses WHERE id IN (SELECT old_id FROM warehouse_copies);
+
+DROP TABLE category_copies, warehouse_copies;
+
+ALTER TABLE products FORCE ROW LEVEL SECURITY;
+ALTER TABLE warehouse_stock FORCE ROW LEVEL SECURITY;
+ALTER TABLE stock_movements FORCE ROW LEVEL SECURITY;
+ALTER TABLE product_revisions FORCE ROW LEVEL SECURITY;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_category_id_fkey
+        FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE RESTRICT;
+ALTER TABLE categories
+    ADD CONSTRAINT categories_parent_id_fkey
+        FOREIGN KEY (parent_id) REFERENCES categories(id) ON DELETE RESTRICT;
+ALTER TABLE warehouse_stock
+    ADD CONSTRAINT warehouse_stock_warehouse_id_fkey
+        FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON DELETE RESTRICT;
+
+DROP INDEX IF EXISTS idx_warehouses_single_default;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_single_default
+    ON warehouses (is_default) WHERE is_default;
+
+ALTER TABLE warehouses DROP CONSTRAINT IF EXISTS warehouses_code_key;
+ALTER TABLE warehouses ADD CONSTRAINT warehouses_code_key UNIQUE (code);
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_slug_key;
+ALTER TABLE categories ADD CONSTRAINT categories_slug_key UNIQUE (slug);
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['categories', 'warehouses']
+    LOOP
+        EXECUTE format('ALTER TABLE %I DROP CONSTRAINT IF EXISTS %I', t, t || '_tenant_id_id_key');
+        EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS tenant_id', t);
+    END LOOP;
+END
+$$;
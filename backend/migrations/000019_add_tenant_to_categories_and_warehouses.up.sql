@@ -0,0 +1,139 @@
+-- Categories and warehouses belong to a tenant like the products that use
+-- them. Existing rows go to tenant 1; every other tenant gets its own copy of
+-- them, and its products, stock, movements and revisions are pointed at the
+-- copies. Slugs and warehouse codes become unique per tenant.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['categories', 'warehouses']
+    LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants(id)', t);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenant_id SET DEFAULT current_tenant_id()', t);
+        EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I (tenant_id)', 'idx_' || t || '_tenant_id', t);
+        -- Target of the tenant-checking foreign keys below
+        EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I UNIQUE (tenant_id, id)', t, t || '_tenant_id_id_key');
+    END LOOP;
+END
+$$;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_slug_key;
+ALTER TABLE categories ADD CONSTRAINT categories_slug_key UNIQUE (tenant_id, slug);
+
+ALTER TABLE warehouses DROP CONSTRAINT IF EXISTS warehouses_code_key;
+ALTER TABLE warehouses ADD CONSTRAINT warehouses_code_key UNIQUE (tenant_id, code);
+
+-- One default warehouse per tenant
+DROP INDEX IF EXISTS idx_warehouses_single_default;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_single_default
+    ON warehouses (tenant_id) WHERE is_default;
+
+-- The migration has no app.tenant_id, so the policies would hide every row
+-- it has to repoint; they are lifted for the owner until the copies are made
+ALTER TABLE products NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE warehouse_stock NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE stock_movements NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE product_revisions NO FORCE ROW LEVEL SECURITY;
+
+CREATE TEMPORARY TABLE category_copies AS
+SELECT t.id AS tenant_id, c.id AS old_id, nextval('categories_id_seq')::int AS new_id
+FROM tenants t
+CROSS JOIN categories c
+WHERE t.id <> 1;
+
+INSERT INTO categories (id, tenant_id, parent_id, name, slug, created_at, updated_at)
+SELECT m.new_id, m.tenant_id, parent.new_id, c.name, c.slug, c.created_at, c.updated_at
+FROM category_copies m
+JOIN categories c ON c.id = m.old_id
+LEFT JOIN category_copies parent ON parent.tenant_id = m.tenant_id AND parent.old_id = c.parent_id;
+
+CREATE TEMPORARY TABLE warehouse_copies AS
+SELECT t.id AS tenant_id, w.id AS old_id, nextval('warehouses_id_seq')::int AS new_id
+FROM tenants t
+CROSS JOIN warehouses w
+WHERE t.id <> 1;
+
+INSERT INTO warehouses (id, tenant_id, code, name, address, is_default, created_at, updated_at)
+SELECT m.new_id, m.tenant_id, w.code, w.name, w.address, w.is_default, w.created_at, w.updated_at
+FROM warehouse_copies m
+JOIN warehouses w ON w.id = m.old_id;
+
+UPDATE products p
+SET category_id = m.new_id
+FROM category_copies m
+WHERE m.tenant_id = p.tenant_id AND m.old_id = p.category_id;
+
+UPDATE product_revisions r
+SET snapshot = jsonb_set(r.snapshot, '{category_id}', to_jsonb(m.new_id))
+FROM category_copies m
+WHERE m.tenant_id = r.tenant_id AND m.old_id = (r.snapshot->>'category_id')::int;
+
+UPDATE warehouse_stock ws
+SET warehouse_id = m.new_id
+FROM warehouse_copies m
+WHERE m.tenant_id = ws.tenant_id AND m.old_id = ws.warehouse_id;
+
+UPDATE stock_movements sm
+SET warehouse_id = m.new_id
+FROM warehouse_copies m
+WHERE m.tenant_id = sm.tenant_id AND m.old_id = sm.warehouse_id;
+
+DROP TABLE category_copies, warehouse_copies;
+
+ALTER TABLE products FORCE ROW LEVEL SECURITY;
+ALTER TABLE warehouse_stock FORCE ROW LEVEL SECURITY;
+ALTER TABLE stock_movements FORCE ROW LEVEL SECURITY;
+ALTER TABLE product_revisions FORCE ROW LEVEL SECURITY;
+
+-- Foreign keys are checked without row-level security, so they name the
+-- tenant too: a row can only point at a category or warehouse of its tenant
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_category_id_fkey,
+    ADD CONSTRAINT products_category_id_fkey
+        FOREIGN KEY (tenant_id, category_id) REFERENCES categories(tenant_id, id) ON DELETE RESTRICT;
+
+ALTER TABLE categories
+    DROP CONSTRAINT IF EXISTS categories_parent_id_fkey,
+    ADD CONSTRAINT categories_parent_id_fkey
+        FOREIGN KEY (tenant_id, parent_id) REFERENCES categories(tenant_id, id) ON DELETE RESTRICT;
+
+ALTER TABLE warehouse_stock
+    DROP CONSTRAINT IF EXISTS warehouse_stock_warehouse_id_fkey,
+    ADD CONSTRAINT warehouse_stock_warehouse_id_fkey
+        FOREIGN KEY (tenant_id, warehouse_id) REFERENCES warehouses(tenant_id, id) ON DELETE RESTRICT;
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['categories', 'warehouses']
+    LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I USING (tenant_id = current_tenant_id()) WITH CHECK (tenant_id = current_tenant_id())', t);
+    END LOOP;
+END
+$$;
+
+-- A new tenant starts with a default warehouse, where movements without a
+-- warehouse are booked, and the fallback category
+CREATE OR REPLACE FUNCTION create_tenant_defaults() RETURNS TRIGGER AS $$
+DECLARE
+    previous TEXT := current_setting('app.tenant_id', true);
+BEGIN
+    PERFORM set_config('app.tenant_id', NEW.id::text, true);
+
+    INSERT INTO warehouses (code, name, is_default)
+    VALUES ('MAIN', 'Main Warehouse', TRUE);
+
+    INSERT INTO categories (name, slug)
+    VALUES ('Uncategorized', 'uncategorized');
+
+    PERFORM set_config('app.tenant_id', COALESCE(previous, ''), true);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tenants_create_defaults
+    AFTER INSERT ON tenants
+    FOR EACH ROW EXECUTE FUNCTION create_tenant_defaults();